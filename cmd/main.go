@@ -2,8 +2,6 @@ package main
 
 import (
 	"context"
-	"errors"
-	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -13,21 +11,48 @@ import (
 	"service-order-avito/internal/adapters/logger"
 	"service-order-avito/internal/adapters/logger/sl"
 	"service-order-avito/internal/config"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/gateway/blob"
+	"service-order-avito/internal/gateway/events"
 	order2 "service-order-avito/internal/gateway/order"
+	"service-order-avito/internal/handler/http/middleware/auth"
+	"service-order-avito/internal/handler/http/middleware/idempotency"
 	"service-order-avito/internal/handler/http/middleware/rate_limiter"
+	"service-order-avito/internal/handler/http/openapi"
 	"service-order-avito/internal/handler/http/server"
+	"service-order-avito/internal/handler/http/server/handler/admin"
 	courier2 "service-order-avito/internal/handler/http/server/handler/courier"
+	"service-order-avito/internal/handler/http/server/handler/debug"
 	delivery2 "service-order-avito/internal/handler/http/server/handler/delivery"
+	feedback2 "service-order-avito/internal/handler/http/server/handler/feedback"
+	health2 "service-order-avito/internal/handler/http/server/handler/health"
+	proof2 "service-order-avito/internal/handler/http/server/handler/proof"
+	stats2 "service-order-avito/internal/handler/http/server/handler/stats"
 	order4 "service-order-avito/internal/handler/queues/order"
+	"service-order-avito/internal/lifecycle"
+	"service-order-avito/internal/observability/health"
 	"service-order-avito/internal/observability/metrics/prometheus"
+	"service-order-avito/internal/observability/requestid"
+	"service-order-avito/internal/observability/tracing"
 	"service-order-avito/internal/repository/postgres"
 	"service-order-avito/internal/service/courier"
 	"service-order-avito/internal/service/delivery"
+	"service-order-avito/internal/service/feedback"
+	"service-order-avito/internal/service/proof"
 	order3 "service-order-avito/internal/service/queues/order"
+	"service-order-avito/internal/service/stats"
 	delivery_worker "service-order-avito/internal/worker/delivery"
+	"service-order-avito/internal/worker/leader"
+	order_worker "service-order-avito/internal/worker/order"
 	"service-order-avito/internal/worker/queues/kafka"
+	"service-order-avito/internal/worker/scheduler"
+	"service-order-avito/pkg/jwt"
+	"service-order-avito/pkg/phone"
 	"syscall"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -37,16 +62,40 @@ func main() {
 	cfg := config.MustLoad()
 
 	// LOGGER
-	log := sl.NewSlogLogger(cfg.Env)
-	log.Info("logger initialized")
+	logLevels := sl.NewLevels(sl.DefaultLevel(cfg.Env), nil)
+	log := sl.NewSlogLogger(cfg.Env, logLevels)
+	if err := logLevels.Configure(cfg.Env, cfg.Log.Level, cfg.Log.Components); err != nil {
+		log.Error("init logger: " + err.Error())
+		os.Exit(1)
+	}
+	log.Info("logger initialized", "level", logLevels.Global().String())
 
 	// GS context
 	ctxApp, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
+	go reloadLogLevelsOnSIGHUP(ctxApp, cfg.Env, logLevels, log)
+
+	// Lifecycle, компоненты регистрируют в нем свою остановку по мере создания
+	lc := lifecycle.NewManager(log, cfg.Shutdown.ComponentTimeout)
+
+	// Tracing
+	shutdownTracing, err := tracing.Setup(ctxApp, cfg.Tracing)
+	if err != nil {
+		log.Error("init tracing: " + err.Error())
+		os.Exit(1)
+	}
+	// оставшиеся спаны дописываются после остановки воркеров, чтобы в них попали и спаны остановки
+	lc.Add(lifecycle.Hook{Name: "tracing", Phase: lifecycle.PhaseFlush, Stop: shutdownTracing})
+	log.Info("tracing initialized", "exporter", cfg.Tracing.Exporter)
 
-	// context для бд, отменяется после завершения сервера
+	// Prometheus registry, в него регистрируются все метрики сервиса
+	metricsRegistry := prometheus.NewRegistry()
+
+	// Health probes, проверки добавляются по мере подключения зависимостей
+	probes := health.NewHealth(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+
+	// context для бд, отменяется после остановки всех, кто ходит в базу
 	ctxDB, cancelDB := context.WithCancel(context.Background())
-	defer cancelDB()
 
 	// DB connection
 	pool, err := postgres.ConnectPostgres(ctxDB, cfg.Postgres, cfg.Env)
@@ -54,10 +103,12 @@ func main() {
 		log.Error("connect database " + err.Error())
 		os.Exit(1)
 	}
-	defer func() {
+	lc.Add(lifecycle.Hook{Name: "postgres", Phase: lifecycle.PhaseConnections, Stop: func(context.Context) error {
 		pool.Close()
-		log.Info("connection with database closed")
-	}()
+		cancelDB()
+		return nil
+	}})
+	probes.AddReadiness("postgres", health.Postgres(pool))
 
 	// Kafka connection
 	kafkaClient, err := client2.NewOrderKafkaClient(
@@ -72,106 +123,397 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Kafka producer для событий сервиса
+	eventsProducer, err := client2.NewEventsKafkaProducer(cfg.Kafka.ClientDSN)
+	if err != nil {
+		log.Error("init kafka events producer")
+		os.Exit(1)
+	}
+	// сервисы публикуют события синхронно, но Close дожидается ответа брокера на последние из них
+	lc.Add(lifecycle.Hook{Name: "kafka_events_producer", Phase: lifecycle.PhaseFlush, Stop: func(context.Context) error {
+		return eventsProducer.Close()
+	}})
+	eventPublisher := events.NewEventPublisherKafka(log, eventsProducer, cfg.Kafka.EventsTopic)
+
 	// Repository Lay
 	transactionManager := postgres.NewTransactionManagerPostgres(pool)
 	courierRepository := postgres.NewCourierRepositoryPostgres(pool)
 	deliveryRepository := postgres.NewDeliveryRepositoryPostgres(pool)
+	statsRepository := postgres.NewStatsRepositoryPostgres(pool)
+	feedbackRepository := postgres.NewFeedbackRepositoryPostgres(pool)
+	proofRepository := postgres.NewProofRepositoryPostgres(pool)
 	log.Info("repository lay is initialized")
 
+	// Blob storage для подтверждений доставки
+	proofStorage, err := blob.NewLocalBlobStorage(cfg.Proof.LocalDir)
+	if err != nil {
+		log.Error("init proof storage: " + err.Error())
+		os.Exit(1)
+	}
+
+	// Phone parser
+	phoneParser, err := phone.NewParser(cfg.Phone.DefaultCountry, cfg.Phone.AllowedCountries...)
+	if err != nil {
+		log.Error("init phone parser: " + err.Error())
+		os.Exit(1)
+	}
+
 	// Service lay
-	courierService := courier.NewCourierService(transactionManager, courierRepository)
-	deliveryService := delivery.NewDeliveryService(transactionManager, courierRepository, deliveryRepository)
-	orderChangedService := order3.NewOrderChangedService(deliveryService)
+	courierService := courier.NewCourierService(transactionManager, courierRepository, phoneParser)
+	ratingPolicy := model.RatingPolicy{
+		LowThreshold: cfg.Rating.LowThreshold,
+		MinCount:     cfg.Rating.MinCount,
+		AlertRating:  cfg.Rating.AlertRating,
+	}
+	slaPolicy := model.SLAPolicy{
+		AtRiskFraction: cfg.SLA.AtRiskFraction,
+		UnassignGrace:  cfg.SLA.UnassignGrace,
+	}
+	proofPolicy := model.ProofPolicy{
+		MaxPhotoSize:     cfg.Proof.MaxPhotoSize,
+		MaxSignatureSize: cfg.Proof.MaxSignatureSize,
+		RequiredForAll:   cfg.Proof.RequiredForAll,
+		PINSecret:        []byte(cfg.Proof.PINSecret),
+	}
+	handoffPolicy := model.HandoffPolicy{
+		PriceThreshold: cfg.Handoff.PriceThreshold,
+		MaxAttempts:    cfg.Handoff.MaxAttempts,
+		Lockout:        cfg.Handoff.Lockout,
+		Secret:         []byte(cfg.Handoff.Secret),
+	}
+	deliveryService := delivery.NewDeliveryService(
+		transactionManager,
+		courierRepository,
+		deliveryRepository,
+		eventPublisher,
+		prometheus.NewPrometheusDeliveryObserver(metricsRegistry),
+		ratingPolicy,
+		slaPolicy,
+		proofPolicy,
+		handoffPolicy,
+	)
+	feedbackService := feedback.NewFeedbackService(
+		transactionManager,
+		deliveryRepository,
+		courierRepository,
+		feedbackRepository,
+		eventPublisher,
+		ratingPolicy,
+	)
+	statsService := stats.NewStatsService(courierRepository, statsRepository)
+	proofService := proof.NewProofService(deliveryRepository, proofRepository, proofStorage, proofPolicy)
 	log.Info("service lay is initialized")
 
 	// Workers
+	// синглтон воркеры работают только на реплике-лидере
+	elector := leader.NewElector(
+		postgres.NewLeaderLeaseRepositoryPostgres(pool),
+		"singleton_workers",
+		cfg.Leader.InstanceId,
+		cfg.Leader.LeaseTTL,
+		cfg.Leader.RenewInterval,
+		log,
+		prometheus.NewPrometheusLeaderObserver(metricsRegistry),
+	)
+	// воркеры добавляются в elector.Run ниже, запустится он вместе с остальными в lc.Start
+	lc.Add(lifecycle.Worker("leader_elector", lifecycle.PhaseWorkers, elector.Start))
+
+	// фоновые задачи идут по расписанию в планировщике, сам планировщик работает только на лидере
+	deliveryMonitorSchedule, err := scheduler.ParseSchedule(cfg.Jobs.DeliveryMonitorSchedule)
+	if err != nil {
+		log.Error("init scheduler: " + err.Error())
+		os.Exit(1)
+	}
+	idempotencyCleanupSchedule, err := scheduler.ParseSchedule(cfg.Jobs.IdempotencyCleanupSchedule)
+	if err != nil {
+		log.Error("init scheduler: " + err.Error())
+		os.Exit(1)
+	}
+	jobScheduler := scheduler.NewScheduler(cfg.Leader.InstanceId, log, prometheus.NewPrometheusJobsObserver(metricsRegistry))
+	probes.AddLiveness("scheduler", jobScheduler)
+	elector.Run("scheduler", jobScheduler.Start)
+
 	// monitor worker
-	deliveryMonitorWorker := delivery_worker.NewDeliveryMonitorWorker(cfg.DeliveryWorkerTickInterval, log, deliveryService)
-	go deliveryMonitorWorker.Start(ctxApp)
-	log.Info("delivery monitor worker is started")
+	deliveryMonitorWorker := delivery_worker.NewDeliveryMonitorWorker(
+		log,
+		deliveryService,
+		prometheus.NewPrometheusSLAObserver(metricsRegistry),
+		cfg.SLA.AutoUnassign,
+	)
+	jobScheduler.Add(scheduler.Job{
+		Name:       "delivery_monitor",
+		Schedule:   deliveryMonitorSchedule,
+		Run:        deliveryMonitorWorker.Run,
+		Jitter:     cfg.Jobs.Jitter,
+		MaxRuntime: cfg.Jobs.MaxRuntime,
+		Retries:    cfg.Jobs.Retries,
+		RetryDelay: cfg.Jobs.RetryDelay,
+	})
 
 	//order service gRPC
-	connRPC, err := grpc.NewClient(cfg.GRPC.OrderServiceDSN, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// otelgrpc отдает клиентскую инструментацию через stats handler, interceptors в нем больше нет
+	connRPC, err := grpc.NewClient(cfg.GRPC.OrderServiceDSN,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(requestid.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		log.Error("unable to connect to order-service")
 		os.Exit(1)
 	}
+	probes.AddReadiness("order_service", health.GRPC(connRPC))
+	lc.Add(lifecycle.Hook{Name: "order_service_grpc", Phase: lifecycle.PhaseConnections, Stop: func(context.Context) error {
+		return connRPC.Close()
+	}})
 	orderServiceClient := order.NewOrdersServiceClient(connRPC)
 	orderGateway := order2.NewOrderGateway(orderServiceClient)
-	//
-	//orderServiceMonitorWorker := order_worker.NewOrderMonitorWorker(
-	//	time.Second*5,
-	//	log,
-	//	deliveryService,
-	//	orderGateway,
-	//)
-	//go orderServiceMonitorWorker.Start(ctxApp)
-	//log.Info("order-service monitor worker is started")
-
-	// Prometheus
-	prometheusHTTPObserver := prometheus.NewPrometheusHTTPObserver()
+
+	// сверка с order-service на случай потерянных событий order.changed
+	orderReconciliationSchedule, err := scheduler.ParseSchedule(cfg.Jobs.OrderReconciliationSchedule)
+	if err != nil {
+		log.Error("init scheduler: " + err.Error())
+		os.Exit(1)
+	}
+	reconciliationWorker := order_worker.NewReconciliationWorker(
+		log,
+		deliveryService,
+		orderGateway,
+		postgres.NewReconciliationCursorRepositoryPostgres(pool),
+		prometheus.NewPrometheusReconciliationObserver(metricsRegistry),
+		model.ReconciliationPolicy{
+			BatchSize:       cfg.Reconciliation.BatchSize,
+			ActiveBatchSize: cfg.Reconciliation.ActiveBatchSize,
+			Grace:           cfg.Reconciliation.Grace,
+			Lookback:        cfg.Reconciliation.Lookback,
+			ReportOnly:      cfg.Reconciliation.ReportOnly,
+			MaxFixAttempts:  cfg.Reconciliation.MaxFixAttempts,
+		},
+	)
+	jobScheduler.Add(scheduler.Job{
+		Name:       "order_reconciliation",
+		Schedule:   orderReconciliationSchedule,
+		Run:        reconciliationWorker.Run,
+		Jitter:     cfg.Jobs.Jitter,
+		MaxRuntime: cfg.Jobs.MaxRuntime,
+		Retries:    cfg.Jobs.Retries,
+		RetryDelay: cfg.Jobs.RetryDelay,
+	})
+
+	// Prometheus HTTP & business metrics
+	routeMetrics, err := prometheus.ParseRouteConfigs(cfg.Metrics.HTTP.Routes)
+	if err != nil {
+		log.Error("init metrics: " + err.Error())
+		os.Exit(1)
+	}
+	prometheusHTTPObserver := prometheus.NewPrometheusHTTPObserver(
+		metricsRegistry,
+		prometheus.RouteConfig{Buckets: cfg.Metrics.HTTP.Buckets, SLO: cfg.Metrics.HTTP.SLO},
+		routeMetrics,
+	)
+	prometheus.NewBusinessCollector(metricsRegistry, statsRepository, cfg.Metrics.CollectorCacheTTL, cfg.Metrics.CollectorTimeout, log)
 
 	// kafka order-changed consumer
-	handler := order4.NewOrderChangedHandler(log, orderGateway, orderChangedService, prometheusHTTPObserver)
+	orderChangedService := order3.NewOrderChangedService(deliveryService, orderGateway, handoffPolicy)
+	kafkaObserver := prometheus.NewPrometheusKafkaObserver(metricsRegistry)
+	kafkaConsumerState := kafka.NewConsumerState()
+	probes.AddReadiness("kafka_consumer", health.KafkaConsumer(kafkaConsumerState, cfg.Health.KafkaGrace))
+	handler := order4.NewOrderChangedHandler(log, orderGateway, orderChangedService, prometheusHTTPObserver, kafkaObserver, kafkaConsumerState)
 	orderConsumerWorker := kafka.NewOrderConsumerWorker(
 		log,
 		kafkaClient,
 		handler,
 		cfg.Kafka.TopicName,
+		kafkaObserver,
 	)
-	go orderConsumerWorker.Start(ctxApp)
-	log.Info("kafka order-changed consumer worker is started")
+	lc.Add(lifecycle.Worker("kafka_order_consumer", lifecycle.PhaseIntake, orderConsumerWorker.Start))
 
 	// Controller lay
-	courierHandler := courier2.NewCourierHandler(courierService)
+	courierHandler := courier2.NewCourierHandler(log, courierService)
 	deliveryHandler := delivery2.NewDeliveryHandler(deliveryService)
+	feedbackHandler := feedback2.NewFeedbackHandler(feedbackService)
+	statsHandler := stats2.NewStatsHandler(statsService)
+	// к файлам добавляется запас на текстовые поля и границы multipart
+	proofHandler := proof2.NewProofHandler(proofService, cfg.Proof.MaxPhotoSize+cfg.Proof.MaxSignatureSize+64<<10)
 	log.Info("controller lay is initialized")
 
 	// pprof server
-	go func() {
-		if err := http.ListenAndServe("127.0.0.1:6060", nil); err != nil {
-			log.Error("pprof server error: %v", err)
+	lc.Add(lifecycle.Server("pprof", &http.Server{Addr: "127.0.0.1:6060", Handler: http.DefaultServeMux}, log))
+
+	// Auth
+	authenticator := auth.NewDisabledAuthenticator()
+	if cfg.Auth.Enabled {
+		authenticator, err = initAuthenticator(cfg.Auth, pool)
+		if err != nil {
+			log.Error("init auth: " + err.Error())
+			os.Exit(1)
 		}
-	}()
+	} else {
+		log.Warn("auth is disabled, all requests are executed as admin")
+	}
 
 	// Rate limiter
-	tokenBacketLimiter := rate_limiter.NewTokenBucket(cfg.HTTP.RateLimiter.MaxRPC, cfg.HTTP.RateLimiter.RPCRefill)
+	trustedProxies, err := rate_limiter.ParseTrustedProxies(cfg.HTTP.RateLimiter.TrustedProxies)
+	if err != nil {
+		log.Error("init rate limiter: " + err.Error())
+		os.Exit(1)
+	}
+	rateLimitKey, err := rate_limiter.NewKeyFunc(cfg.HTTP.RateLimiter.Key, cfg.HTTP.RateLimiter.APIKeyHeader, trustedProxies, authenticator.KnownAPIKey)
+	if err != nil {
+		log.Error("init rate limiter: " + err.Error())
+		os.Exit(1)
+	}
+	routePolicies, err := rate_limiter.ParseRoutePolicies(cfg.HTTP.RateLimiter.Routes)
+	if err != nil {
+		log.Error("init rate limiter: " + err.Error())
+		os.Exit(1)
+	}
+	keyedLimiter, err := rate_limiter.NewKeyedLimiter(cfg.HTTP.RateLimiter.Algorithm, cfg.HTTP.RateLimiter.MaxKeys, cfg.HTTP.RateLimiter.IdleTTL)
+	if err != nil {
+		log.Error("init rate limiter: " + err.Error())
+		os.Exit(1)
+	}
+	var clientLimiter rate_limiter.ClientLimiter = keyedLimiter
+	if cfg.HTTP.RateLimiter.Backend == "postgres" {
+		distributedLimiter := rate_limiter.NewDistributedLimiter(
+			postgres.NewRateLimitRepositoryPostgres(pool),
+			keyedLimiter,
+			cfg.HTTP.RateLimiter.SyncInterval,
+			cfg.HTTP.RateLimiter.MaxKeys,
+			log,
+		)
+		// последняя синхронизация счетчиков идет при остановке, поэтому воркер стоит до закрытия пула
+		lc.Add(lifecycle.Worker("distributed_rate_limiter", lifecycle.PhaseWorkers, distributedLimiter.Start))
+		clientLimiter = distributedLimiter
+	}
+	requestLimiter := rate_limiter.NewRequestLimiter(
+		clientLimiter,
+		rateLimitKey,
+		rate_limiter.Policy{Limit: cfg.HTTP.RateLimiter.RPCRefill, Window: time.Second, Burst: cfg.HTTP.RateLimiter.MaxRPC},
+		routePolicies,
+	)
+
+	// Idempotency-Key
+	idempotencyMiddleware := idempotency.NewIdempotency(
+		postgres.NewIdempotencyRepositoryPostgres(pool),
+		cfg.Idempotency.TTL,
+		cfg.Idempotency.LockTimeout,
+		cfg.Idempotency.MaxBodySize,
+		log,
+	)
+	// очистка общая для всех реплик, достаточно лидера
+	jobScheduler.Add(scheduler.Job{
+		Name:       "idempotency_keys_cleanup",
+		Schedule:   idempotencyCleanupSchedule,
+		Run:        idempotencyMiddleware.Cleanup,
+		Jitter:     cfg.Jobs.Jitter,
+		MaxRuntime: cfg.Jobs.MaxRuntime,
+		Retries:    cfg.Jobs.Retries,
+		RetryDelay: cfg.Jobs.RetryDelay,
+	})
+
+	// OpenAPI
+	apiSpec, err := openapi.Load()
+	if err != nil {
+		log.Error("load openapi spec: " + err.Error())
+		os.Exit(1)
+	}
 
 	// ROUTER & SERVER
-	r := server.InitRouter(log, courierHandler, deliveryHandler, prometheusHTTPObserver, tokenBacketLimiter)
+	r := server.InitRouter(log, courierHandler, deliveryHandler, feedbackHandler, statsHandler, proofHandler, health2.NewHealthHandler(probes), debug.NewDebugHandler(kafkaConsumerState, elector), admin.NewAdminHandler(log, logLevels, jobScheduler), prometheusHTTPObserver, prometheus.Handler(metricsRegistry), requestLimiter, authenticator, idempotencyMiddleware, apiSpec)
 
 	srv := &http.Server{
-		Addr:    ":" + cfg.HTTP.Port,
-		Handler: r,
-		BaseContext: func(net.Listener) context.Context {
-			return ctxApp
-		},
+		Addr:         ":" + cfg.HTTP.Port,
+		Handler:      r,
 		ReadTimeout:  cfg.HTTP.ReadTimeout,
 		WriteTimeout: cfg.HTTP.WriteTimeout,
-		IdleTimeout:  cfg.HTTP.ShutdownTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
 	}
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Error("server start up: %s", err.Error())
-		}
-	}()
-	log.Info("listening on: " + cfg.HTTP.Port)
+	httpServer := lifecycle.Server("http", srv, log)
+	httpServer.Timeout = cfg.HTTP.ShutdownTimeout
+	lc.Add(httpServer)
+	// сначала /readyz начинает отвечать 503, сервер еще работает, пока балансировщик не уберет под
+	lc.Add(lifecycle.Hook{
+		Name:    "readiness",
+		Phase:   lifecycle.PhaseReadiness,
+		Timeout: cfg.Health.ShutdownDelay + time.Second,
+		Stop: func(ctx context.Context) error {
+			probes.Shutdown()
+			select {
+			case <-time.After(cfg.Health.ShutdownDelay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
 
-	gracefulShutdown(ctxApp, cfg.HTTP, log, srv, cancelDB)
-}
+	if err := lc.Start(ctxApp); err != nil {
+		log.Error("start application: " + err.Error())
+		os.Exit(1)
+	}
+	log.Info("listening on: " + cfg.HTTP.Port)
 
-func gracefulShutdown(ctxApp context.Context, cfg config.HTTPServer, log logger.LoggerAdapter, srv *http.Server, cancelDB context.CancelFunc) {
 	<-ctxApp.Done()
+	// повторный сигнал завершит процесс сразу, если остановка зависла
+	stop()
 	log.Info("shutdown signal received. starting graceful shutdown")
+	if err := lc.Stop(context.Background()); err != nil {
+		log.Error("graceful shutdown: " + err.Error())
+		os.Exit(1)
+	}
+	log.Info("application gracefully stopped")
+}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
-	defer cancel()
+// reloadLogLevelsOnSIGHUP перечитывает LOG_LEVEL и LOG_COMPONENTS по SIGHUP и заменяет ими уровни,
+// в том числе выставленные через PUT /admin/loglevel
+func reloadLogLevelsOnSIGHUP(ctx context.Context, env string, levels *sl.Levels, log logger.LoggerAdapter) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Error("server shutdown: %s", err.Error())
-	} else {
-		log.Info("server gracefully stopped")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			cfg, err := config.LoadLog(env)
+			if err == nil {
+				err = levels.Configure(env, cfg.Level, cfg.Components)
+			}
+			if err != nil {
+				log.Error("reload log levels: " + err.Error())
+				continue
+			}
+			log.Info("log levels reloaded", "level", levels.Global().String(), "components", cfg.Components)
+		}
+	}
+}
+
+func initAuthenticator(cfg config.Auth, pool *pgxpool.Pool) (*auth.Authenticator, error) {
+	apiKeys, err := auth.ParseAPIKeys(cfg.APIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiKeyStore auth.APIKeyStore
+	if cfg.APIKeysFromDB {
+		apiKeyStore = postgres.NewAPIKeyRepositoryPostgres(pool)
+	}
+
+	var verifier *jwt.Verifier
+	if cfg.JWTSecret != "" || cfg.JWKSFile != "" {
+		jwtCfg := jwt.Config{
+			HS256Secret: []byte(cfg.JWTSecret),
+			Issuer:      cfg.JWTIssuer,
+			Audience:    cfg.JWTAudience,
+			Leeway:      cfg.JWTLeeway,
+		}
+		if cfg.JWKSFile != "" {
+			if jwtCfg.RSAKeys, err = jwt.LoadJWKS(cfg.JWKSFile); err != nil {
+				return nil, err
+			}
+		}
+		verifier = jwt.NewVerifier(jwtCfg)
 	}
 
-	cancelDB()
+	return auth.NewAuthenticator(cfg.APIKeyHeader, apiKeys, apiKeyStore, verifier), nil
 }
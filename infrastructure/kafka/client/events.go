@@ -0,0 +1,18 @@
+package client
+
+import (
+	"github.com/IBM/sarama"
+)
+
+// NewEventsKafkaProducer продюсер для событий сервиса. Ждем подтверждения от всех реплик,
+// событий немного, а терять алерты не хочется
+func NewEventsKafkaProducer(clientDSN string) (sarama.SyncProducer, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_1_0_0
+
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 3
+	config.Producer.Return.Successes = true // обязательно для SyncProducer
+
+	return sarama.NewSyncProducer([]string{clientDSN}, config)
+}
@@ -2,11 +2,14 @@ package adapters
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"service-order-avito/internal/domain/dto"
 	"service-order-avito/internal/domain/errors/repository"
 	"service-order-avito/internal/domain/errors/server"
 	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/observability/requestid"
+	"strings"
 )
 
 // Написал этот функционал, чтобы не передавать ошибки с уровня репозитория наверх к уровню контроллеров
@@ -19,9 +22,17 @@ var repoToServiceMap = map[error]error{
 	repository.ErrCourierExists:       service.ErrCourierExists,
 	repository.ErrCourierNotFound:     service.ErrCourierNotFound,
 	repository.ErrNoAvailableCouriers: service.ErrNoAvailableCouriers,
+	repository.ErrCourierBusy:         service.ErrCourierBusy,
 	// Delivery
 	repository.ErrDeliveryExists:   service.ErrDeliveryExists,
 	repository.ErrDeliveryNotFound: service.ErrDeliveryNotFound,
+	// этап уже сменился другим запросом, для клиента это тот же недопустимый переход
+	repository.ErrDeliveryStatusConflict: service.ErrInvalidStatusTransition,
+	repository.ErrHandoffLocked:          service.ErrHandoffLocked,
+	// Feedback
+	repository.ErrFeedbackExists: service.ErrFeedbackExists,
+	// Proof of delivery
+	repository.ErrProofExists: service.ErrProofExists,
 	// Default
 	repository.ErrInternalError: service.ErrInternalError,
 }
@@ -38,49 +49,140 @@ func ErrUnwrapRepoToService(err error) error {
 	return service.ErrInternalError
 }
 
-type errorMeta struct {
+// ServiceErrorRule описывает, как ошибка сервиса превращается в ответ: code попадает в type и code problem+json,
+// message в detail, status в статус ответа
+type ServiceErrorRule struct {
+	Err     error
+	Code    string
 	Message string
 	Status  int
 }
 
-var serviceErrorMap = map[error]errorMeta{
+// правила проверяются по порядку через errors.Is, поэтому обернутая ошибка (fmt.Errorf("...: %w", err))
+// находит свое правило. Более частные ошибки должны стоять выше
+var serviceErrorRules = []ServiceErrorRule{
 	// Courier
-	service.ErrInvalidName:          {server.ErrInvalidCourierName, http.StatusBadRequest},
-	service.ErrInvalidStatus:        {server.ErrInvalidCourierStatus, http.StatusBadRequest},
-	service.ErrInvalidPhone:         {server.ErrInvalidCourierPhone, http.StatusBadRequest},
-	service.ErrInvalidTransportType: {server.ErrInvalidTransportType, http.StatusBadRequest},
-	service.ErrCourierExists:        {server.ErrCourierExists, http.StatusConflict},
-	service.ErrCourierNotFound:      {server.ErrCourierNotFound, http.StatusNotFound},
-	service.ErrNoAvailableCouriers:  {server.ErrNoAvailableCouriers, http.StatusConflict},
+	{service.ErrInvalidName, "invalid_name", server.ErrInvalidCourierName, http.StatusBadRequest},
+	{service.ErrInvalidStatus, "invalid_status", server.ErrInvalidCourierStatus, http.StatusBadRequest},
+	{service.ErrInvalidPhone, "invalid_phone", server.ErrInvalidCourierPhone, http.StatusBadRequest},
+	{service.ErrPhoneCountry, "phone_country_not_allowed", server.ErrCourierPhoneCountry, http.StatusBadRequest},
+	{service.ErrInvalidTransportType, "invalid_transport_type", server.ErrInvalidTransportType, http.StatusBadRequest},
+	{service.ErrCourierExists, "courier_exists", server.ErrCourierExists, http.StatusConflict},
+	{service.ErrCourierNotFound, "courier_not_found", server.ErrCourierNotFound, http.StatusNotFound},
+	{service.ErrNoAvailableCouriers, "no_available_couriers", server.ErrNoAvailableCouriers, http.StatusConflict},
+	{service.ErrCourierBusy, "courier_busy", server.ErrCourierBusy, http.StatusConflict},
 	// Delivery
-	service.ErrDeliveryExists:   {server.ErrDeliveryExists, http.StatusConflict},
-	service.ErrDeliveryNotFound: {server.ErrDeliveryNotFound, http.StatusNotFound},
-	// Default
-	service.ErrInternalError: {server.ErrInternalError, http.StatusInternalServerError},
+	{service.ErrDeliveryExists, "delivery_exists", server.ErrDeliveryExists, http.StatusConflict},
+	{service.ErrDeliveryNotFound, "delivery_not_found", server.ErrDeliveryNotFound, http.StatusNotFound},
+	{service.ErrInvalidDeliveryStatus, "invalid_delivery_status", server.ErrInvalidDeliveryStatus, http.StatusBadRequest},
+	{service.ErrInvalidStatusTransition, "invalid_status_transition", server.ErrInvalidStatusTransition, http.StatusConflict},
+	{service.ErrHandoffCodeRequired, "handoff_code_required", server.ErrHandoffCodeRequired, http.StatusBadRequest},
+	{service.ErrInvalidHandoffCode, "invalid_handoff_code", server.ErrInvalidHandoffCode, http.StatusForbidden},
+	{service.ErrHandoffLocked, "handoff_locked", server.ErrHandoffLocked, http.StatusTooManyRequests},
+	// Feedback
+	{service.ErrInvalidRating, "invalid_rating", server.ErrInvalidRating, http.StatusBadRequest},
+	{service.ErrInvalidFeedbackTag, "invalid_tag", server.ErrInvalidFeedbackTag, http.StatusBadRequest},
+	{service.ErrFeedbackCommentTooLong, "comment_too_long", server.ErrFeedbackCommentTooLong, http.StatusBadRequest},
+	{service.ErrFeedbackExists, "feedback_exists", server.ErrFeedbackExists, http.StatusConflict},
+	{service.ErrDeliveryNotCompleted, "delivery_not_completed", server.ErrDeliveryNotCompleted, http.StatusConflict},
+	// Proof of delivery
+	{service.ErrInvalidProofPIN, "invalid_pin", server.ErrInvalidProofPIN, http.StatusBadRequest},
+	{service.ErrInvalidCoordinates, "invalid_coordinates", server.ErrInvalidCoordinates, http.StatusBadRequest},
+	{service.ErrProofFileTooLarge, "proof_file_too_large", server.ErrProofFileTooLarge, http.StatusRequestEntityTooLarge},
+	{service.ErrProofContentType, "proof_content_type", server.ErrProofContentType, http.StatusUnsupportedMediaType},
+	{service.ErrProofExists, "proof_exists", server.ErrProofExists, http.StatusConflict},
+	{service.ErrProofRequired, "proof_required", server.ErrProofRequired, http.StatusConflict},
+	// Stats
+	{service.ErrInvalidStatsPeriod, "invalid_stats_period", server.ErrInvalidStatsPeriod, http.StatusBadRequest},
+	// Auth
+	{service.ErrForbidden, "forbidden", server.ErrForbidden, http.StatusForbidden},
+	// Jobs
+	{service.ErrJobNotFound, "job_not_found", server.ErrJobNotFound, http.StatusNotFound},
+	{service.ErrJobAlreadyRunning, "job_already_running", server.ErrJobAlreadyRunning, http.StatusConflict},
+	{service.ErrSchedulerInactive, "scheduler_inactive", server.ErrSchedulerInactive, http.StatusConflict},
+}
+
+var internalErrorRule = ServiceErrorRule{service.ErrInternalError, "internal_error", server.ErrInternalError, http.StatusInternalServerError}
+
+// RegisterServiceError добавляет правило для новой ошибки сервиса. Правило проверяется раньше встроенных,
+// так можно переопределить ответ для ошибки, которая оборачивает одну из них. Вызывать до старта сервера
+func RegisterServiceError(rule ServiceErrorRule) {
+	serviceErrorRules = append([]ServiceErrorRule{rule}, serviceErrorRules...)
 }
 
-// WriteServiceError принимает ошибку уровня service и пишет ошибку уровня контроллера в ResponseWriter
-func WriteServiceError(w http.ResponseWriter, err error) {
-	meta, ok := serviceErrorMap[err]
-	if !ok {
-		meta = serviceErrorMap[service.ErrInternalError]
+func findServiceErrorRule(err error) ServiceErrorRule {
+	for _, rule := range serviceErrorRules {
+		if errors.Is(err, rule.Err) {
+			return rule
+		}
 	}
+	return internalErrorRule
+}
+
+const (
+	contentTypeProblem = "application/problem+json"
+	problemTypePrefix  = "/problems/"
+	validationCode     = "validation_error"
+)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(meta.Status)
-	_ = json.NewEncoder(w).Encode(dto.ErrorResponse{
-		Error: dto.ErrorDetail{
-			Message: meta.Message,
-		},
+// WriteServiceError принимает ошибку уровня service и пишет ошибку уровня контроллера в ResponseWriter.
+// Ошибка валидации отдается со списком всех невалидных полей
+func WriteServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if verr, ok := service.AsValidationError(err); ok {
+		fields := make([]dto.ProblemFieldError, len(verr.Fields))
+		messages := make([]string, len(verr.Fields))
+		for i, f := range verr.Fields {
+			rule := findServiceErrorRule(f.Err)
+			fields[i] = dto.ProblemFieldError{
+				Field:   f.Field,
+				Code:    rule.Code,
+				Message: rule.Message,
+			}
+			messages[i] = rule.Message
+		}
+		// для одного поля detail совпадает с текстом, который отдавался до появления списка ошибок
+		WriteValidationError(w, r, strings.Join(messages, "; "), fields)
+		return
+	}
+
+	rule := findServiceErrorRule(err)
+	WriteProblem(w, r, dto.Problem{
+		Type:   problemTypePrefix + rule.Code,
+		Title:  http.StatusText(rule.Status),
+		Status: rule.Status,
+		Detail: rule.Message,
+		Code:   rule.Code,
+	})
+}
+
+// WriteValidationError пишет 400 со списком ошибок полей
+func WriteValidationError(w http.ResponseWriter, r *http.Request, detail string, fields []dto.ProblemFieldError) {
+	WriteProblem(w, r, dto.Problem{
+		Type:   problemTypePrefix + validationCode,
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: detail,
+		Code:   validationCode,
+		Errors: fields,
 	})
 }
 
-func WriteError(w http.ResponseWriter, message string, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(dto.ErrorResponse{
-		Error: dto.ErrorDetail{
-			Message: message,
-		},
+// WriteError пишет ошибку без отдельного типа, только статус и текст
+func WriteError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	WriteProblem(w, r, dto.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: message,
 	})
 }
+
+// WriteProblem дополняет problem путем запроса и request id и пишет его в ResponseWriter
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem dto.Problem) {
+	problem.Instance = r.URL.Path
+	problem.RequestId = requestid.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", contentTypeProblem)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,73 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/observability/requestid"
+	"testing"
+)
+
+func writeServiceError(t *testing.T, err error) (*httptest.ResponseRecorder, dto.Problem) {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/courier", nil)
+	WriteServiceError(rec, r.WithContext(requestid.NewContext(r.Context(), "req-1")), err)
+
+	var problem dto.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	return rec, problem
+}
+
+func TestWriteServiceError(t *testing.T) {
+	rec, problem := writeServiceError(t, fmt.Errorf("load courier 7: %w", service.ErrCourierNotFound))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	require.Equal(t, "/problems/courier_not_found", problem.Type)
+	require.Equal(t, "Not Found", problem.Title)
+	require.Equal(t, http.StatusNotFound, problem.Status)
+	require.Equal(t, server.ErrCourierNotFound, problem.Detail)
+	require.Equal(t, "courier_not_found", problem.Code)
+	require.Equal(t, "/courier", problem.Instance)
+	require.Equal(t, "req-1", problem.RequestId)
+
+	rec, problem = writeServiceError(t, errors.New("unexpected"))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Equal(t, server.ErrInternalError, problem.Detail)
+}
+
+func TestWriteServiceError_Validation(t *testing.T) {
+	var verr service.ValidationError
+	verr.Add("name", service.ErrInvalidName)
+	verr.Add("phone", service.ErrPhoneCountry)
+
+	rec, problem := writeServiceError(t, verr.Err())
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "validation_error", problem.Code)
+	require.Equal(t, server.ErrInvalidCourierName+"; "+server.ErrCourierPhoneCountry, problem.Detail)
+	require.Equal(t, []dto.ProblemFieldError{
+		{Field: "name", Code: "invalid_name", Message: server.ErrInvalidCourierName},
+		{Field: "phone", Code: "phone_country_not_allowed", Message: server.ErrCourierPhoneCountry},
+	}, problem.Errors)
+}
+
+func TestRegisterServiceError(t *testing.T) {
+	rules := serviceErrorRules
+	t.Cleanup(func() { serviceErrorRules = rules })
+
+	errBusyCourier := fmt.Errorf("courier is on another delivery: %w", service.ErrNoAvailableCouriers)
+	RegisterServiceError(ServiceErrorRule{errBusyCourier, "courier_busy", "courier is busy", http.StatusConflict})
+
+	_, problem := writeServiceError(t, errBusyCourier)
+	require.Equal(t, "courier_busy", problem.Code)
+
+	_, problem = writeServiceError(t, service.ErrNoAvailableCouriers)
+	require.Equal(t, "no_available_couriers", problem.Code)
+}
@@ -1,11 +1,21 @@
 package logger
 
+import "context"
+
 // LoggerAdapter не получится объявлять по месту использования как я делал ранее из-за метода With
 // с другой стороны это как будто и не очень удобно было бы делать, так как логгер используется много где и каждый раз писать мини-интерфейс не очень хочется
+// WithContext добавляет в записи trace_id и span_id текущего спана из ctx, если он есть.
+// *Context методы делают то же для одной записи и дополнительно пишут request_id запроса,
+// в обработчиках запросов и сообщений стоит логировать через них
 type LoggerAdapter interface {
 	Info(string, ...any)
 	Error(string, ...any)
 	Warn(string, ...any)
 	Debug(string, ...any)
+	InfoContext(context.Context, string, ...any)
+	ErrorContext(context.Context, string, ...any)
+	WarnContext(context.Context, string, ...any)
+	DebugContext(context.Context, string, ...any)
 	With(...any) LoggerAdapter
+	WithContext(context.Context) LoggerAdapter
 }
@@ -0,0 +1,60 @@
+package sl
+
+import (
+	"context"
+	"log/slog"
+	"service-order-avito/internal/observability/requestid"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// minLevel уровень вложенного хендлера, фильтрацию целиком делает levelHandler
+const minLevel = slog.Level(-1 << 10)
+
+// levelHandler фильтрует записи по Levels с учетом компонента логгера и дописывает
+// request_id, trace_id и span_id из контекста записи
+type levelHandler struct {
+	inner     slog.Handler
+	levels    *Levels
+	component string
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.levels.enabled(h.component, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(contextAttrs(ctx)...)
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	for _, a := range attrs {
+		if a.Key == ComponentKey {
+			component = a.Value.String()
+		}
+	}
+	return &levelHandler{inner: h.inner.WithAttrs(attrs), levels: h.levels, component: component}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{inner: h.inner.WithGroup(name), levels: h.levels, component: h.component}
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	if id := requestid.FromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return attrs
+}
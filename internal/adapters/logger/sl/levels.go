@@ -0,0 +1,131 @@
+package sl
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync/atomic"
+)
+
+// ComponentKey атрибут, по которому выбирается уровень компонента. Компонент задается через
+// log.With(slog.String("component", "middleware/auth"))
+const ComponentKey = "component"
+
+// Levels уровни логирования, которые меняются без перезапуска: общий уровень и переопределения
+// для отдельных компонентов. Уровень проверяется на каждую запись, поэтому карта компонентов
+// не блокируется, а заменяется целиком
+type Levels struct {
+	global     slog.LevelVar
+	components atomic.Pointer[map[string]slog.Level]
+}
+
+func NewLevels(global slog.Level, components map[string]slog.Level) *Levels {
+	l := &Levels{}
+	l.Set(global, components)
+	return l
+}
+
+// DefaultLevel уровень для окружения, если он не задан в конфиге
+func DefaultLevel(env string) slog.Level {
+	switch env {
+	case "local", "dev":
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func (l *Levels) Global() slog.Level {
+	return l.global.Level()
+}
+
+func (l *Levels) SetGlobal(level slog.Level) {
+	l.global.Set(level)
+}
+
+// Components копия текущих переопределений
+func (l *Levels) Components() map[string]slog.Level {
+	return maps.Clone(*l.components.Load())
+}
+
+// SetComponent переопределяет уровень компонента, nil убирает переопределение
+func (l *Levels) SetComponent(component string, level *slog.Level) {
+	for {
+		old := l.components.Load()
+		next := maps.Clone(*old)
+		if level == nil {
+			delete(next, component)
+		} else {
+			next[component] = *level
+		}
+		if l.components.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// Set заменяет все уровни разом, например при перечитывании конфига
+func (l *Levels) Set(global slog.Level, components map[string]slog.Level) {
+	next := maps.Clone(components)
+	if next == nil {
+		next = map[string]slog.Level{}
+	}
+	l.global.Set(global)
+	l.components.Store(&next)
+}
+
+// Configure разбирает уровни из конфига и применяет их разом. Пустой level - уровень окружения.
+// При ошибке уровни не меняются
+func (l *Levels) Configure(env, level string, components []string) error {
+	global := DefaultLevel(env)
+	if level != "" {
+		parsed, err := ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		global = parsed
+	}
+	overrides, err := ParseComponents(components)
+	if err != nil {
+		return err
+	}
+	l.Set(global, overrides)
+	return nil
+}
+
+func (l *Levels) enabled(component string, level slog.Level) bool {
+	if component != "" {
+		if min, ok := (*l.components.Load())[component]; ok {
+			return level >= min
+		}
+	}
+	return level >= l.global.Level()
+}
+
+// ParseLevel принимает debug, info, warn, error в любом регистре, в том числе со смещением вроде "debug-4"
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// ParseComponents разбирает переопределения в формате "component=level"
+func ParseComponents(specs []string) (map[string]slog.Level, error) {
+	components := make(map[string]slog.Level, len(specs))
+	for _, spec := range specs {
+		component, level, ok := strings.Cut(spec, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid component log level %q, expected component=level", spec)
+		}
+		parsed, err := ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		components[component] = parsed
+	}
+	return components, nil
+}
@@ -1,6 +1,8 @@
 package sl
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
 	"service-order-avito/internal/adapters/logger"
@@ -11,17 +13,21 @@ type SlogLogger struct {
 	l *slog.Logger
 }
 
-func NewSlogLogger(env string) *SlogLogger {
+// NewSlogLogger формат логов выбирается по env, уровень берется из levels и может меняться на лету
+func NewSlogLogger(env string, levels *Levels) *SlogLogger {
+	return newSlogLogger(env, os.Stdout, levels)
+}
+
+func newSlogLogger(env string, out io.Writer, levels *Levels) *SlogLogger {
+	opts := &slog.HandlerOptions{Level: minLevel}
+	var inner slog.Handler
 	switch env {
 	case "local":
-		return &SlogLogger{l: slogpretty.SetupPrettySlog()}
-	case "dev":
-		return &SlogLogger{slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))}
-	case "prod":
-		return &SlogLogger{slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))}
+		inner = slogpretty.PrettyHandlerOptions{SlogOpts: opts}.NewPrettyHandler(out)
 	default:
-		return &SlogLogger{slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))}
+		inner = slog.NewJSONHandler(out, opts)
 	}
+	return &SlogLogger{l: slog.New(&levelHandler{inner: inner, levels: levels})}
 }
 
 func (sl *SlogLogger) Info(msg string, args ...any) {
@@ -40,6 +46,36 @@ func (sl *SlogLogger) Debug(msg string, args ...any) {
 	sl.l.Debug(msg, args...)
 }
 
+func (sl *SlogLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	sl.l.InfoContext(ctx, msg, args...)
+}
+
+func (sl *SlogLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	sl.l.ErrorContext(ctx, msg, args...)
+}
+
+func (sl *SlogLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	sl.l.WarnContext(ctx, msg, args...)
+}
+
+func (sl *SlogLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	sl.l.DebugContext(ctx, msg, args...)
+}
+
 func (sl *SlogLogger) With(args ...any) logger.LoggerAdapter {
 	return &SlogLogger{l: sl.l.With(args...)}
 }
+
+// WithContext фиксирует атрибуты контекста в логгере, чтобы передать его туда, где ctx нет.
+// Если ctx под рукой, лучше *Context методы, иначе атрибуты в записи задублируются
+func (sl *SlogLogger) WithContext(ctx context.Context) logger.LoggerAdapter {
+	attrs := contextAttrs(ctx)
+	if len(attrs) == 0 {
+		return sl
+	}
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return &SlogLogger{l: sl.l.With(args...)}
+}
@@ -0,0 +1,93 @@
+package sl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"service-order-avito/internal/observability/requestid"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		out = append(out, rec)
+	}
+	buf.Reset()
+	return out
+}
+
+func TestSlogLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	levels := NewLevels(slog.LevelInfo, map[string]slog.Level{"middleware/auth": slog.LevelDebug})
+	log := newSlogLogger("prod", &buf, levels)
+	auth := log.With(slog.String(ComponentKey, "middleware/auth"))
+
+	log.Debug("hidden")
+	auth.Debug("auth debug")
+	require.Len(t, records(t, &buf), 1, "component override enables debug only for auth")
+
+	levels.SetGlobal(slog.LevelDebug)
+	log.Debug("visible")
+	require.Len(t, records(t, &buf), 1)
+
+	warn := slog.LevelWarn
+	levels.SetComponent("middleware/auth", &warn)
+	auth.Info("hidden")
+	auth.With("key", "value").Warn("visible")
+	recs := records(t, &buf)
+	require.Len(t, recs, 1, "component is inherited by derived loggers")
+	require.Equal(t, "value", recs[0]["key"])
+
+	levels.SetComponent("middleware/auth", nil)
+	auth.Debug("falls back to global")
+	require.Len(t, records(t, &buf), 1)
+	require.Empty(t, levels.Components())
+}
+
+func TestSlogLogger_Context(t *testing.T) {
+	var buf bytes.Buffer
+	log := newSlogLogger("prod", &buf, NewLevels(slog.LevelInfo, nil))
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	log.InfoContext(ctx, "with request")
+	log.Info("without request")
+	log.WithContext(ctx).Warn("bound")
+
+	recs := records(t, &buf)
+	require.Len(t, recs, 3)
+	require.Equal(t, "req-1", recs[0]["request_id"])
+	require.NotContains(t, recs[1], "request_id")
+	require.Equal(t, "req-1", recs[2]["request_id"])
+}
+
+func TestParseComponents(t *testing.T) {
+	components, err := ParseComponents([]string{"middleware/auth=debug", " worker/delivery = WARN"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]slog.Level{"middleware/auth": slog.LevelDebug, "worker/delivery": slog.LevelWarn}, components)
+
+	_, err = ParseComponents([]string{"middleware/auth"})
+	require.Error(t, err)
+	_, err = ParseComponents([]string{"middleware/auth=verbose"})
+	require.EqualError(t, err, `invalid log level "verbose"`)
+}
+
+func TestLevels_Configure(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo, nil)
+	require.NoError(t, levels.Configure("dev", "", []string{"middleware/auth=error"}))
+	require.Equal(t, slog.LevelDebug, levels.Global(), "empty level falls back to env default")
+	require.Equal(t, map[string]slog.Level{"middleware/auth": slog.LevelError}, levels.Components())
+
+	require.Error(t, levels.Configure("prod", "warn", []string{"bad"}))
+	require.Equal(t, slog.LevelDebug, levels.Global(), "levels are untouched on error")
+}
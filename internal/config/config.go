@@ -19,13 +19,186 @@ type Config struct {
 	DeliveryWorkerTickInterval time.Duration   `env:"DELIVERY_WORKER_TICK_INTERVAL" envDefault:"60s"`
 	GRPC                       GRPC            `envPrefix:"GRPC_"`
 	Kafka                      Kafka           `envPrefix:"KAFKA_"`
+	Phone                      Phone           `envPrefix:"PHONE_"`
+	Rating                     Rating          `envPrefix:"RATING_"`
+	SLA                        SLA             `envPrefix:"SLA_"`
+	Proof                      Proof           `envPrefix:"PROOF_"`
+	Handoff                    Handoff         `envPrefix:"HANDOFF_"`
+	Auth                       Auth            `envPrefix:"AUTH_"`
+	Idempotency                Idempotency     `envPrefix:"IDEMPOTENCY_"`
+	Tracing                    Tracing         `envPrefix:"TRACING_"`
+	Metrics                    Metrics         `envPrefix:"METRICS_"`
+	Health                     Health          `envPrefix:"HEALTH_"`
+	Log                        Log             `envPrefix:"LOG_"`
+	Shutdown                   Shutdown        `envPrefix:"SHUTDOWN_"`
+	Leader                     Leader          `envPrefix:"LEADER_"`
+	Jobs                       Jobs            `envPrefix:"JOBS_"`
+	Reconciliation             Reconciliation  `envPrefix:"RECONCILIATION_"`
+}
+
+// Reconciliation сверка заказов с order-service на случай потерянных событий Kafka.
+// За проход проверяется до BatchSize новых заказов и до ActiveBatchSize доставок в работе
+// (по запросу в order-service на каждую), заказы моложе Grace ждут события.
+// Lookback - глубина первой сверки, пока курсор не сохранен. ReportOnly только считает расхождения.
+// MaxFixAttempts - сколько проходов подряд курсор ждет заказ, который не удается исправить (кроме нехватки курьеров)
+type Reconciliation struct {
+	BatchSize       int           `env:"BATCH_SIZE" envDefault:"500"`
+	ActiveBatchSize int           `env:"ACTIVE_BATCH_SIZE" envDefault:"100"`
+	Grace           time.Duration `env:"GRACE" envDefault:"2m"`
+	Lookback        time.Duration `env:"LOOKBACK" envDefault:"24h"`
+	ReportOnly      bool          `env:"REPORT_ONLY" envDefault:"false"`
+	MaxFixAttempts  int           `env:"MAX_FIX_ATTEMPTS" envDefault:"5"`
+}
+
+// Jobs расписания фоновых задач: cron из пяти полей ("*/5 * * * *") или "@every 30s".
+// Пустое расписание монитора доставок - "@every DELIVERY_WORKER_TICK_INTERVAL".
+// MaxRuntime ограничивает одну попытку, упавший запуск повторяется до Retries раз с удвоением RetryDelay
+type Jobs struct {
+	DeliveryMonitorSchedule     string        `env:"DELIVERY_MONITOR_SCHEDULE"`
+	IdempotencyCleanupSchedule  string        `env:"IDEMPOTENCY_CLEANUP_SCHEDULE" envDefault:"@every 10m"`
+	OrderReconciliationSchedule string        `env:"ORDER_RECONCILIATION_SCHEDULE" envDefault:"@every 1m"`
+	Jitter                      time.Duration `env:"JITTER" envDefault:"1s"`
+	MaxRuntime                  time.Duration `env:"MAX_RUNTIME" envDefault:"5m"`
+	Retries                     int           `env:"RETRIES" envDefault:"2"`
+	RetryDelay                  time.Duration `env:"RETRY_DELAY" envDefault:"5s"`
+}
+
+// Leader выбор реплики, на которой работают синглтон воркеры. InstanceId должен быть уникален
+// среди реплик, по умолчанию берется hostname (имя пода). Лидер продлевает аренду каждые RenewInterval,
+// после падения лидера другая реплика забирает ее не позже чем через LeaseTTL + RenewInterval
+type Leader struct {
+	InstanceId    string        `env:"INSTANCE_ID"`
+	LeaseTTL      time.Duration `env:"LEASE_TTL" envDefault:"15s"`
+	RenewInterval time.Duration `env:"RENEW_INTERVAL" envDefault:"5s"`
+}
+
+// Shutdown ComponentTimeout ограничивает остановку одного компонента: воркера, продюсера, соединения.
+// Для HTTP сервера действует HTTP_SHUTDOWN_TIMEOUT
+type Shutdown struct {
+	ComponentTimeout time.Duration `env:"COMPONENT_TIMEOUT" envDefault:"10s"`
+}
+
+// Log уровень логов. Пустой Level - уровень окружения: debug для local и dev, info для prod.
+// Components переопределяет уровень отдельных компонентов в формате "component=level" через ";",
+// например "middleware/auth=debug;worker/delivery=warn". Оба значения перечитываются по SIGHUP
+type Log struct {
+	Level      string   `env:"LEVEL"`
+	Components []string `env:"COMPONENTS" envSeparator:";"`
+}
+
+// Health пробы /livez и /readyz. Каждая проверка ограничена CheckTimeout, результат кешируется на CacheTTL.
+// KafkaGrace - сколько консьюмер может быть вне группы (ребалансировка), прежде чем readiness упадет.
+// ShutdownDelay - пауза между переводом readiness в fail и остановкой сервера, чтобы балансировщик успел убрать под
+type Health struct {
+	CheckTimeout  time.Duration `env:"CHECK_TIMEOUT" envDefault:"2s"`
+	CacheTTL      time.Duration `env:"CACHE_TTL" envDefault:"1s"`
+	KafkaGrace    time.Duration `env:"KAFKA_GRACE" envDefault:"1m"`
+	ShutdownDelay time.Duration `env:"SHUTDOWN_DELAY" envDefault:"0s"`
+}
+
+// Metrics бизнес-метрики, которые считаются запросом в базу во время scrape.
+// Результат кешируется на CollectorCacheTTL, запрос прерывается через CollectorTimeout
+type Metrics struct {
+	CollectorCacheTTL time.Duration `env:"COLLECTOR_CACHE_TTL" envDefault:"15s"`
+	CollectorTimeout  time.Duration `env:"COLLECTOR_TIMEOUT" envDefault:"2s"`
+	HTTP              HTTPMetrics   `envPrefix:"HTTP_"`
+}
+
+// HTTPMetrics бакеты гистограммы длительности HTTP запросов (в секундах) и порог SLO по умолчанию.
+// Routes задает их для отдельных роутов в формате "METHOD /pattern=SLO[:BUCKET,BUCKET]",
+// например "POST /couriers/import=5s:0.5,1,2.5,5,10,30"
+type HTTPMetrics struct {
+	Buckets []float64     `env:"BUCKETS" envDefault:"0.1,0.3,0.5,1,2" envSeparator:","`
+	SLO     time.Duration `env:"SLO" envDefault:"500ms"`
+	Routes  []string      `env:"ROUTES" envSeparator:";"`
+}
+
+// Tracing OpenTelemetry трейсинг. Exporter: otlp (gRPC на Endpoint), stdout или none.
+// SampleRatio - доля новых трейсов, которые записываются; для запросов с родительским спаном решение берется у родителя
+type Tracing struct {
+	Exporter    string  `env:"EXPORTER" envDefault:"none"`
+	Endpoint    string  `env:"ENDPOINT" envDefault:"localhost:4317"`
+	Insecure    bool    `env:"INSECURE" envDefault:"true"`
+	ServiceName string  `env:"SERVICE_NAME" envDefault:"service-courier"`
+	SampleRatio float64 `env:"SAMPLE_RATIO" envDefault:"1"`
+}
+
+// Idempotency ответы на POST запросы с заголовком Idempotency-Key хранятся TTL. LockTimeout - через сколько
+// незавершенный запрос считается брошенным и ключ можно занять повторно, должен быть больше HTTP_WRITE_TIMEOUT
+type Idempotency struct {
+	TTL         time.Duration `env:"TTL" envDefault:"24h"`
+	LockTimeout time.Duration `env:"LOCK_TIMEOUT" envDefault:"1m"`
+	MaxBodySize int64         `env:"MAX_BODY_SIZE" envDefault:"1048576"` // 1 MiB
+}
+
+// minJWTSecretLen минимальная длина секрета HS256, как размер выхода SHA-256
+const minJWTSecretLen = 32
+
+// Auth авторизация HTTP API. APIKeys - ключи из конфига в формате "name:role:sha256hex[:courier_id]" через ";",
+// sha256 считается как `echo -n KEY | sha256sum`. APIKeysFromDB дополнительно ищет ключи в таблице api_keys.
+// JWT принимается с HS256 (JWTSecret не короче minJWTSecretLen байт) и/или RS256 (ключи из локального JWKS файла).
+// Enabled=false выполняет все запросы как admin, только для локальной разработки
+type Auth struct {
+	Enabled       bool          `env:"ENABLED" envDefault:"true"`
+	APIKeyHeader  string        `env:"API_KEY_HEADER" envDefault:"X-API-Key"`
+	APIKeys       []string      `env:"API_KEYS" envSeparator:";"`
+	APIKeysFromDB bool          `env:"API_KEYS_FROM_DB" envDefault:"false"`
+	JWTSecret     string        `env:"JWT_HS256_SECRET"`
+	JWKSFile      string        `env:"JWT_JWKS_FILE"`
+	JWTIssuer     string        `env:"JWT_ISSUER"`
+	JWTAudience   string        `env:"JWT_AUDIENCE"`
+	JWTLeeway     time.Duration `env:"JWT_LEEWAY" envDefault:"30s"`
+}
+
+// Handoff код передачи заказа. Код выдается для заказов дороже PriceThreshold (0 отключает коды),
+// Secret - ключ HMAC для хранения кодов, обязателен, если коды включены
+type Handoff struct {
+	PriceThreshold int64         `env:"PRICE_THRESHOLD" envDefault:"0"`
+	MaxAttempts    int           `env:"MAX_ATTEMPTS" envDefault:"5"`
+	Lockout        time.Duration `env:"LOCKOUT" envDefault:"15m"`
+	Secret         string        `env:"SECRET"`
+}
+
+// Proof настройки подтверждения доставки. Storage выбирает реализацию blob-хранилища, пока есть только local.
+// RequiredForAll требует подтверждение для всех новых доставок, а не только для назначенных с proof_required.
+// PINSecret - ключ HMAC для хранения PIN из подтверждения, обязателен
+type Proof struct {
+	Storage          string `env:"STORAGE" envDefault:"local"`
+	LocalDir         string `env:"LOCAL_DIR" envDefault:"./data/proofs"`
+	MaxPhotoSize     int64  `env:"MAX_PHOTO_SIZE" envDefault:"10485760"`    // 10 MiB
+	MaxSignatureSize int64  `env:"MAX_SIGNATURE_SIZE" envDefault:"1048576"` // 1 MiB
+	RequiredForAll   bool   `env:"REQUIRED_FOR_ALL" envDefault:"false"`
+	PINSecret        string `env:"PIN_SECRET"`
+}
+
+// SLA настройки отслеживания сроков доставки. Доставка становится at_risk, когда прошла доля AtRiskFraction
+// времени от назначения до дедлайна. AutoUnassign включает снятие доставки с курьера через UnassignGrace после дедлайна
+type SLA struct {
+	AtRiskFraction float64       `env:"AT_RISK_FRACTION" envDefault:"0.8"`
+	AutoUnassign   bool          `env:"AUTO_UNASSIGN" envDefault:"true"`
+	UnassignGrace  time.Duration `env:"UNASSIGN_GRACE" envDefault:"0s"`
+}
+
+// Rating настройки рейтинга курьеров. Курьер с низким рейтингом получает заказы в последнюю очередь,
+// но только когда у него накопилось хотя бы MinCount оценок
+type Rating struct {
+	LowThreshold float64 `env:"LOW_THRESHOLD" envDefault:"3.5"`
+	MinCount     int     `env:"MIN_COUNT" envDefault:"5"`
+	AlertRating  int     `env:"ALERT_RATING" envDefault:"2"` // оценки не выше этой сразу уходят алертом
+}
+
+// Phone настройки разбора телефонов курьеров.
+// DefaultCountry используется для номеров без кода страны (например, "8 999 123-45-67")
+type Phone struct {
+	AllowedCountries []string `env:"ALLOWED_COUNTRIES" envDefault:"RU,KZ,UZ,AM,GE" envSeparator:","`
+	DefaultCountry   string   `env:"DEFAULT_COUNTRY" envDefault:"RU"`
 }
 
 type GRPC struct {
 	OrderServiceDSN string `env:"ORDER_SERVICE_DSN,required"`
 }
 
-// Пока будем исходить из логики, что мы слушаем только 1 топик
+// Пока будем исходить из логики, что мы слушаем только 1 топик, а пишем свои события в EventsTopic
 type Kafka struct {
 	ClientDSN                string        `env:"CLIENT_DSN,required"`
 	TopicName                string        `env:"TOPIC_NAME,required"`
@@ -33,6 +206,7 @@ type Kafka struct {
 	OffsetInitial            string        `env:"OFFSET_INITIAL,required"`
 	OffsetAutocommit         bool          `env:"OFFSET_AUTOCOMMIT" envDefault:"false"`
 	OffsetAutocommitInterval time.Duration `env:"KAFKA_OFFSET_AUTOCOMMIT_INTERVAL" envDefault:"1s"`
+	EventsTopic              string        `env:"EVENTS_TOPIC" envDefault:"courier-events"` // топик для событий самого сервиса
 }
 
 type HTTPServer struct {
@@ -44,9 +218,25 @@ type HTTPServer struct {
 	RateLimiter     RateLimiter   `envPrefix:"RATE_LIMITER_"`
 }
 
+// RateLimiter лимит по умолчанию: MaxRPC запросов сразу, дальше RPCRefill в секунду на каждого клиента.
+// Algorithm: token_bucket, sliding_log, sliding_window или gcra.
+// Key определяет клиента: ip, api_key (заголовок APIKeyHeader; без него и с неизвестным ключом по ip) или route (общий лимит на роут).
+// X-Forwarded-For учитывается только от TrustedProxies. Routes задает отдельные лимиты роутов
+// в формате "METHOD /pattern=LIMIT/WINDOW", например "POST /couriers/import=2/1m".
+// Backend postgres делает лимит общим для всех реплик: счетчики синхронизируются с базой раз в SyncInterval,
+// пока база недоступна, работает локальный лимитер
 type RateLimiter struct {
-	MaxRPC    int `env:"MAX_RPC" envDefault:"5"`
-	RPCRefill int `env:"RPC_REFILL" envDefault:"5"`
+	MaxRPC         int           `env:"MAX_RPC" envDefault:"5"`
+	RPCRefill      int           `env:"RPC_REFILL" envDefault:"5"`
+	Algorithm      string        `env:"ALGORITHM" envDefault:"token_bucket"`
+	Key            string        `env:"KEY" envDefault:"ip"`
+	APIKeyHeader   string        `env:"API_KEY_HEADER" envDefault:"X-API-Key"`
+	TrustedProxies []string      `env:"TRUSTED_PROXIES" envSeparator:","`
+	MaxKeys        int           `env:"MAX_KEYS" envDefault:"10000"`
+	IdleTTL        time.Duration `env:"IDLE_TTL" envDefault:"10m"`
+	Routes         []string      `env:"ROUTES" envSeparator:";"`
+	Backend        string        `env:"BACKEND" envDefault:"local"`
+	SyncInterval   time.Duration `env:"SYNC_INTERVAL" envDefault:"1s"`
 }
 
 type PostgresStorage struct {
@@ -84,6 +274,105 @@ func MustLoad() Config {
 
 	config.Env = environment
 
+	if config.SLA.AtRiskFraction <= 0 || config.SLA.AtRiskFraction > 1 {
+		log.Fatalf("unable to load config: \nSLA_AT_RISK_FRACTION must be in (0, 1], got %v", config.SLA.AtRiskFraction)
+	}
+
+	if config.Handoff.PriceThreshold > 0 && (config.Handoff.Secret == "" || config.Handoff.MaxAttempts < 1) {
+		log.Fatalf("unable to load config: \nHANDOFF_SECRET and positive HANDOFF_MAX_ATTEMPTS are required when HANDOFF_PRICE_THRESHOLD is set")
+	}
+
+	if config.Proof.PINSecret == "" {
+		log.Fatalf("unable to load config: \nPROOF_PIN_SECRET is required")
+	}
+
+	if config.HTTP.RateLimiter.MaxRPC < 1 || config.HTTP.RateLimiter.RPCRefill < 1 {
+		log.Fatalf("unable to load config: \nHTTP_RATE_LIMITER_MAX_RPC and HTTP_RATE_LIMITER_RPC_REFILL must be positive")
+	}
+
+	switch config.HTTP.RateLimiter.Backend {
+	case "local":
+	case "postgres":
+		if config.HTTP.RateLimiter.SyncInterval <= 0 {
+			log.Fatalf("unable to load config: \nHTTP_RATE_LIMITER_SYNC_INTERVAL must be positive")
+		}
+	default:
+		log.Fatalf("unable to load config: \nunsupported HTTP_RATE_LIMITER_BACKEND %q", config.HTTP.RateLimiter.Backend)
+	}
+
+	if config.Auth.Enabled && len(config.Auth.APIKeys) == 0 && !config.Auth.APIKeysFromDB &&
+		config.Auth.JWTSecret == "" && config.Auth.JWKSFile == "" {
+		log.Fatalf("unable to load config: \nAUTH_ENABLED requires AUTH_API_KEYS, AUTH_API_KEYS_FROM_DB, AUTH_JWT_HS256_SECRET or AUTH_JWT_JWKS_FILE")
+	}
+	// короткий секрет подбирается перебором, а с ним подделывается токен с любой ролью
+	if config.Auth.JWTSecret != "" && len(config.Auth.JWTSecret) < minJWTSecretLen {
+		log.Fatalf("unable to load config: \nAUTH_JWT_HS256_SECRET must be at least %d bytes", minJWTSecretLen)
+	}
+
+	if config.Idempotency.TTL <= 0 || config.Idempotency.MaxBodySize < 1 ||
+		config.Idempotency.LockTimeout <= config.HTTP.WriteTimeout {
+		log.Fatalf("unable to load config: \nIDEMPOTENCY_TTL and IDEMPOTENCY_MAX_BODY_SIZE must be positive, IDEMPOTENCY_LOCK_TIMEOUT must exceed HTTP_WRITE_TIMEOUT")
+	}
+
+	if config.Metrics.CollectorTimeout <= 0 || config.Metrics.CollectorCacheTTL < 0 {
+		log.Fatalf("unable to load config: \nMETRICS_COLLECTOR_TIMEOUT must be positive, METRICS_COLLECTOR_CACHE_TTL must not be negative")
+	}
+	for _, b := range config.Metrics.HTTP.Buckets {
+		if b <= 0 {
+			log.Fatalf("unable to load config: \nMETRICS_HTTP_BUCKETS must be positive, got %v", b)
+		}
+	}
+	if config.Metrics.HTTP.SLO < 0 {
+		log.Fatalf("unable to load config: \nMETRICS_HTTP_SLO must not be negative")
+	}
+
+	if config.Health.CheckTimeout <= 0 || config.Health.CacheTTL < 0 || config.Health.ShutdownDelay < 0 {
+		log.Fatalf("unable to load config: \nHEALTH_CHECK_TIMEOUT must be positive, HEALTH_CACHE_TTL and HEALTH_SHUTDOWN_DELAY must not be negative")
+	}
+
+	if config.Shutdown.ComponentTimeout <= 0 || config.HTTP.ShutdownTimeout <= 0 {
+		log.Fatalf("unable to load config: \nSHUTDOWN_COMPONENT_TIMEOUT and HTTP_SHUTDOWN_TIMEOUT must be positive")
+	}
+
+	if config.Leader.RenewInterval <= 0 || 2*config.Leader.RenewInterval > config.Leader.LeaseTTL {
+		log.Fatalf("unable to load config: \nLEADER_RENEW_INTERVAL must be positive and at most half of LEADER_LEASE_TTL")
+	}
+	if config.Leader.InstanceId == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Fatalf("unable to load config: \nLEADER_INSTANCE_ID is empty and hostname is unavailable: %s", err.Error())
+		}
+		config.Leader.InstanceId = hostname
+	}
+
+	if config.Jobs.Jitter < 0 || config.Jobs.MaxRuntime < 0 || config.Jobs.Retries < 0 || config.Jobs.RetryDelay < 0 {
+		log.Fatalf("unable to load config: \nJOBS_JITTER, JOBS_MAX_RUNTIME, JOBS_RETRIES and JOBS_RETRY_DELAY must not be negative")
+	}
+	if config.Jobs.DeliveryMonitorSchedule == "" {
+		if config.DeliveryWorkerTickInterval <= 0 {
+			log.Fatalf("unable to load config: \nDELIVERY_WORKER_TICK_INTERVAL must be positive")
+		}
+		config.Jobs.DeliveryMonitorSchedule = "@every " + config.DeliveryWorkerTickInterval.String()
+	}
+
+	if config.Reconciliation.BatchSize < 1 || config.Reconciliation.ActiveBatchSize < 1 || config.Reconciliation.MaxFixAttempts < 1 ||
+		config.Reconciliation.Grace < 0 || config.Reconciliation.Lookback <= 0 {
+		log.Fatalf("unable to load config: \nRECONCILIATION_BATCH_SIZE, RECONCILIATION_ACTIVE_BATCH_SIZE, RECONCILIATION_MAX_FIX_ATTEMPTS and RECONCILIATION_LOOKBACK must be positive, RECONCILIATION_GRACE must not be negative")
+	}
+
+	switch config.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		log.Fatalf("unable to load config: \nunsupported TRACING_EXPORTER %q", config.Tracing.Exporter)
+	}
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		log.Fatalf("unable to load config: \nTRACING_SAMPLE_RATIO must be in [0, 1], got %v", config.Tracing.SampleRatio)
+	}
+
+	if config.Proof.Storage != "local" {
+		log.Fatalf("unable to load config: \nunsupported PROOF_STORAGE %q", config.Proof.Storage)
+	}
+
 	// Значение порта переопределяется только в случае, если в --port передается какое-то значение
 	if httpPort != "" {
 		config.HTTP.Port = httpPort
@@ -91,3 +380,18 @@ func MustLoad() Config {
 
 	return config
 }
+
+// LoadLog перечитывает только настройки логов, вызывается по SIGHUP. Вне prod значения из .env
+// перезаписывают окружение, иначе правки в файле не были бы видны. Процесс при ошибке не завершается
+func LoadLog(environment string) (Log, error) {
+	if environment != "prod" {
+		if err := godotenv.Overload(); err != nil {
+			return Log{}, fmt.Errorf("load .env: %w", err)
+		}
+	}
+	var cfg Log
+	if err := env.ParseWithOptions(&cfg, env.Options{Prefix: "LOG_"}); err != nil {
+		return Log{}, err
+	}
+	return cfg, nil
+}
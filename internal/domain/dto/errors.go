@@ -1,9 +1,20 @@
 package dto
 
-type ErrorResponse struct {
-	Error ErrorDetail `json:"error"`
+// Problem тело ошибки по RFC 7807 (application/problem+json)
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	RequestId string              `json:"request_id,omitempty"`
+	Code      string              `json:"code,omitempty"`
+	Errors    []ProblemFieldError `json:"errors,omitempty"`
 }
 
-type ErrorDetail struct {
+// ProblemFieldError ошибка отдельного поля запроса
+type ProblemFieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"io"
+	"time"
+)
+
 // GetCourierRequest запрос за получение данных о курьере
 type GetCourierRequest struct {
 	Id int `json:"id"`
@@ -27,9 +32,16 @@ type DeleteCourierRequest struct {
 	Id int `json:"id"`
 }
 
-// AssignDeliveryRequest запрос на назначение доставки
+// AssignDeliveryRequest запрос на назначение доставки.
+// ProofRequired запрещает завершать доставку, пока курьер не загрузит подтверждение.
+// По TotalPrice решается, нужен ли код передачи заказа
 type AssignDeliveryRequest struct {
-	OrderId string `json:"order_id"`
+	OrderId       string `json:"order_id"`
+	ProofRequired bool   `json:"proof_required"`
+	TotalPrice    int64  `json:"total_price"`
+	// OrderCreatedAt время создания заказа из события order-service, по нему считается время до назначения.
+	// Через HTTP не передается
+	OrderCreatedAt time.Time `json:"-"`
 }
 
 // UnassignDeliveryRequest запрос на завершение доставки
@@ -41,3 +53,67 @@ type UnassignDeliveryRequest struct {
 type CompleteDeliveryRequest struct {
 	OrderId string `json:"order_id"`
 }
+
+// ImportCouriersRequest запрос на массовый импорт курьеров (CSV / NDJSON).
+// Номер строки в отчете соответствует индексу в Couriers + 1 (без учета заголовка CSV)
+type ImportCouriersRequest struct {
+	Couriers []CreateCourierRequest
+	DryRun   bool
+}
+
+// GetCourierStatsRequest запрос статистики курьера за период [From, To)
+type GetCourierStatsRequest struct {
+	CourierId int
+	From      time.Time
+	To        time.Time
+}
+
+// GetFleetStatsRequest запрос статистики по всем курьерам за период [From, To)
+type GetFleetStatsRequest struct {
+	From time.Time
+	To   time.Time
+}
+
+// LeaveFeedbackRequest отзыв клиента о завершенной доставке. OrderId берется из пути запроса
+type LeaveFeedbackRequest struct {
+	OrderId string   `json:"-"`
+	Rating  int      `json:"rating"`
+	Tags    []string `json:"tags"`
+	Comment string   `json:"comment"`
+}
+
+// AddDeliveryEventRequest событие из приложения курьера. OrderId берется из пути запроса.
+// HandoffCode нужен только для события delivered по дорогому заказу
+type AddDeliveryEventRequest struct {
+	OrderId     string `json:"-"`
+	Status      string `json:"status"`
+	HandoffCode string `json:"handoff_code,omitempty"`
+}
+
+// GetDeliveryTimelineRequest запрос таймлайна заказа
+type GetDeliveryTimelineRequest struct {
+	OrderId string
+}
+
+// ProofFile файл из multipart-формы подтверждения. Size берется из заголовка части формы
+type ProofFile struct {
+	Content io.Reader
+	Size    int64
+}
+
+// UploadProofRequest подтверждение доставки. OrderId берется из пути запроса, подпись клиента необязательна
+type UploadProofRequest struct {
+	OrderId   string
+	Photo     ProofFile
+	Signature *ProofFile
+	PIN       string
+	Latitude  float64
+	Longitude float64
+}
+
+// UpdateLogLevelsRequest запрос на смену уровней логов. Пустой Level оставляет общий уровень как есть,
+// пустой уровень компонента убирает его переопределение
+type UpdateLogLevelsRequest struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type PingResponse struct {
 	Message string `json:"message"`
@@ -12,11 +15,23 @@ type GetCourierResponse struct {
 	Name          string    `json:"name"`
 	Phone         string    `json:"phone"`
 	Status        string    `json:"status"`
-	TransportType string    `json:"transport-type"`
+	TransportType string    `json:"transport_type"`
+	Rating        float64   `json:"rating"` // средняя оценка клиентов, 0 если оценок еще нет
+	RatingCount   int       `json:"rating_count"`
 	CreatedAt     time.Time `json:"-"`
 	UpdatedAt     time.Time `json:"-"`
 }
 
+// MarshalJSON дублирует transport_type под старым именем transport-type, чтобы старые клиенты
+// пережили переименование. Deprecated: transport-type убираем через релиз
+func (r GetCourierResponse) MarshalJSON() ([]byte, error) {
+	type courier GetCourierResponse
+	return json.Marshal(struct {
+		courier
+		TransportTypeLegacy string `json:"transport-type"`
+	}{courier: courier(r), TransportTypeLegacy: r.TransportType})
+}
+
 // CreateCourierResponse ответ на создание профиля
 type CreateCourierResponse struct {
 	Id      int    `json:"id"`
@@ -33,12 +48,14 @@ type DeleteCourierResponse struct {
 	Message string `json:"message"`
 }
 
-// AssignDeliveryResponse запрос на назначение заказа
+// AssignDeliveryResponse запрос на назначение заказа.
+// HandoffCode отдается только здесь и в событии delivery.handoff_code, в базе лежит лишь его HMAC
 type AssignDeliveryResponse struct {
 	CourierId        int       `json:"courier_id"`
 	OrderId          string    `json:"order_id"`
 	TransportType    string    `json:"transport_type"`
 	DeliveryDeadline time.Time `json:"delivery_deadline"`
+	HandoffCode      string    `json:"handoff_code,omitempty"`
 }
 
 // UnassignDeliveryResponse запрос на снятие заказа
@@ -54,3 +71,251 @@ type CompleteDeliveryResponse struct {
 	Status    string `json:"status"`
 	CourierId int    `json:"courier_id"`
 }
+
+const (
+	ImportRowStatusCreated = "created"
+	ImportRowStatusValid   = "valid" // строка прошла проверку в режиме dry-run
+	ImportRowStatusError   = "error"
+)
+
+// ImportCouriersResponse отчет о массовом импорте курьеров
+type ImportCouriersResponse struct {
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"`
+	DryRun  bool                     `json:"dry_run"`
+	Rows    []ImportCourierRowResult `json:"rows"`
+}
+
+// ImportCourierRowResult результат обработки одной строки импорта
+type ImportCourierRowResult struct {
+	Row    int    `json:"row"`
+	Phone  string `json:"phone,omitempty"`
+	Status string `json:"status"` // created | valid | error
+	Error  string `json:"error,omitempty"`
+}
+
+// ExportCourier строка выгрузки курьеров. Поля совпадают с форматом импорта, чтобы выгрузку можно было загрузить обратно
+type ExportCourier struct {
+	Id              int       `json:"id"`
+	Name            string    `json:"name"`
+	Phone           string    `json:"phone"`
+	Status          string    `json:"status"`
+	TransportType   string    `json:"transport_type"`
+	TotalDeliveries int       `json:"total_deliveries"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// CourierStatsResponse статистика курьера за период
+type CourierStatsResponse struct {
+	CourierId  int       `json:"courier_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Completed  int       `json:"completed"`
+	Cancelled  int       `json:"cancelled"`
+	Expired    int       `json:"expired"`
+	Breached   int       `json:"breached"`     // доставки, нарушившие SLA (завершены или сняты после дедлайна)
+	OnTimeRate float64   `json:"on_time_rate"` // доля completed, завершенных не позже дедлайна
+	// AvgDeliveryDurationSec среднее время доставки по типу транспорта (только completed)
+	AvgDeliveryDurationSec map[string]float64 `json:"avg_delivery_duration_sec"`
+	ActiveHours            float64            `json:"active_hours"` // сколько часов периода курьер был занят доставками
+	Utilisation            float64            `json:"utilisation"`  // ActiveHours / длительность периода
+}
+
+// FleetStatsResponse агрегированная статистика по всем курьерам
+type FleetStatsResponse struct {
+	From             time.Time            `json:"from"`
+	To               time.Time            `json:"to"`
+	Couriers         []FleetCourierGroup  `json:"couriers"`
+	Deliveries       []FleetDeliveryGroup `json:"deliveries"`
+	ActiveDeliveries int                  `json:"active_deliveries"`
+}
+
+// FleetCourierGroup количество курьеров с данным статусом и транспортом (текущее состояние, не зависит от периода)
+type FleetCourierGroup struct {
+	Status        string `json:"status"`
+	TransportType string `json:"transport_type"`
+	Count         int    `json:"count"`
+}
+
+// FleetDeliveryGroup доставки, завершенные за период, по типу транспорта
+type FleetDeliveryGroup struct {
+	TransportType          string  `json:"transport_type"`
+	Completed              int     `json:"completed"`
+	Cancelled              int     `json:"cancelled"`
+	Expired                int     `json:"expired"`
+	Breached               int     `json:"breached"`
+	OnTimeRate             float64 `json:"on_time_rate"`
+	AvgDeliveryDurationSec float64 `json:"avg_delivery_duration_sec"`
+}
+
+// LeaveFeedbackResponse ответ на отзыв, вместе с обновленным рейтингом курьера
+type LeaveFeedbackResponse struct {
+	OrderId            string   `json:"order_id"`
+	CourierId          int      `json:"courier_id"`
+	Rating             int      `json:"rating"`
+	Tags               []string `json:"tags"`
+	Comment            string   `json:"comment"`
+	CourierRating      float64  `json:"courier_rating"`
+	CourierRatingCount int      `json:"courier_rating_count"`
+}
+
+// CourierLowRatingEvent payload события courier.low_rating
+type CourierLowRatingEvent struct {
+	CourierId     int      `json:"courier_id"`
+	OrderId       string   `json:"order_id"`
+	Rating        int      `json:"rating"`
+	Tags          []string `json:"tags"`
+	Comment       string   `json:"comment"`
+	CourierRating float64  `json:"courier_rating"`
+	RatingCount   int      `json:"rating_count"`
+	// LowAverage true, если средняя оценка курьера опустилась ниже порога (а не только текущая оценка низкая)
+	LowAverage bool `json:"low_average"`
+}
+
+// DeliverySLAEvent payload событий delivery.sla_at_risk и delivery.sla_breached
+type DeliverySLAEvent struct {
+	OrderId    string    `json:"order_id"`
+	CourierId  int       `json:"courier_id"`
+	SLAState   string    `json:"sla_state"`
+	AssignedAt time.Time `json:"assigned_at"`
+	Deadline   time.Time `json:"deadline"`
+}
+
+// DeliveryHandoffCodeEvent payload события delivery.handoff_code, по нему order-service показывает код клиенту
+type DeliveryHandoffCodeEvent struct {
+	OrderId     string `json:"order_id"`
+	CourierId   int    `json:"courier_id"`
+	HandoffCode string `json:"handoff_code"`
+}
+
+// AddDeliveryEventResponse ответ на событие доставки
+type AddDeliveryEventResponse struct {
+	OrderId    string    `json:"order_id"`
+	CourierId  int       `json:"courier_id"`
+	Status     string    `json:"status"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// DeliveryTimelineResponse таймлайн заказа. Status - этап последнего события
+type DeliveryTimelineResponse struct {
+	OrderId string                  `json:"order_id"`
+	Status  string                  `json:"status"`
+	Events  []DeliveryTimelineEvent `json:"events"`
+}
+
+type DeliveryTimelineEvent struct {
+	Status     string    `json:"status"`
+	CourierId  int       `json:"courier_id"`
+	Actor      string    `json:"actor,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// UploadProofResponse ответ на загрузку подтверждения доставки
+type UploadProofResponse struct {
+	OrderId   string         `json:"order_id"`
+	CourierId int            `json:"courier_id"`
+	Photo     ProofFileInfo  `json:"photo"`
+	Signature *ProofFileInfo `json:"signature,omitempty"`
+	Latitude  float64        `json:"latitude"`
+	Longitude float64        `json:"longitude"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type ProofFileInfo struct {
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// KafkaConsumerResponse состояние консьюмера для /debug/kafka: текущая сессия группы и ее партиции.
+// Active=false между сессиями, например во время ребалансировки
+type KafkaConsumerResponse struct {
+	Active           bool                 `json:"active"`
+	MemberId         string               `json:"member_id,omitempty"`
+	GenerationId     int32                `json:"generation_id,omitempty"`
+	SessionStartedAt *time.Time           `json:"session_started_at,omitempty"`
+	Rebalances       int                  `json:"rebalances"`
+	Claims           []KafkaClaimResponse `json:"claims"`
+}
+
+// KafkaClaimResponse партиция, полученная в текущей сессии. Оффсеты -1, пока сообщений не было.
+// MarkedOffset - следующий оффсет, который будет закоммичен, Lag считается от high water mark
+type KafkaClaimResponse struct {
+	Topic         string     `json:"topic"`
+	Partition     int32      `json:"partition"`
+	InitialOffset int64      `json:"initial_offset"`
+	LastOffset    int64      `json:"last_offset"`
+	MarkedOffset  int64      `json:"marked_offset"`
+	HighWaterMark int64      `json:"high_water_mark"`
+	Lag           int64      `json:"lag"`
+	Messages      int64      `json:"messages"`
+	ClaimedAt     time.Time  `json:"claimed_at"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+}
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthResponse ответ /livez и /readyz: общий статус и результат каждой проверки
+type HealthResponse struct {
+	Status string                `json:"status"`
+	Checks []HealthCheckResponse `json:"checks"`
+}
+
+type HealthCheckResponse struct {
+	Name       string         `json:"name"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	DurationMs float64        `json:"duration_ms"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// LogLevelsResponse текущий общий уровень логов и переопределения компонентов
+type LogLevelsResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// LeaderResponse лидерство реплик для /debug/leader. Holder - реплика, которая сейчас лидер,
+// InstanceId - реплика, которая ответила на запрос
+type LeaderResponse struct {
+	Lease      string     `json:"lease"`
+	InstanceId string     `json:"instance_id"`
+	Leader     bool       `json:"leader"`
+	Holder     string     `json:"holder,omitempty"`
+	AcquiredAt *time.Time `json:"acquired_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Jobs       []string   `json:"jobs"`
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+// JobsResponse задачи планировщика для /admin/jobs. Планировщик работает только на лидере,
+// на остальных репликах Active false, а время следующего запуска не заполнено
+type JobsResponse struct {
+	InstanceId string        `json:"instance_id"`
+	Active     bool          `json:"active"`
+	Jobs       []JobResponse `json:"jobs"`
+}
+
+type JobResponse struct {
+	Name          string          `json:"name"`
+	Schedule      string          `json:"schedule"`
+	Running       bool            `json:"running"`
+	RunningSince  *time.Time      `json:"running_since,omitempty"`
+	NextRun       *time.Time      `json:"next_run,omitempty"`
+	LastRun       *JobRunResponse `json:"last_run,omitempty"`
+	LastSuccessAt *time.Time      `json:"last_success_at,omitempty"`
+}
+
+// JobRunResponse Outcome: success, failed, timeout или canceled. Trigger: schedule или manual
+type JobRunResponse struct {
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Outcome    string    `json:"outcome"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error,omitempty"`
+}
@@ -7,9 +7,18 @@ var (
 	ErrCourierExists       = errors.New("courier already exists")
 	ErrNoAvailableCouriers = errors.New("no available couriers")
 	ErrCourierNotFound     = errors.New("courier not found")
+	ErrCourierBusy         = errors.New("courier is busy with a delivery")
 	// Delivery
-	ErrDeliveryExists   = errors.New("delivery already exists")
-	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrDeliveryExists         = errors.New("delivery already exists")
+	ErrDeliveryNotFound       = errors.New("delivery not found")
+	ErrDeliveryStatusConflict = errors.New("delivery status changed concurrently")
+	ErrHandoffLocked          = errors.New("handoff code input is locked")
+	// Feedback
+	ErrFeedbackExists = errors.New("feedback already exists")
+	// Proof of delivery
+	ErrProofExists = errors.New("proof of delivery already exists")
+	// Auth
+	ErrAPIKeyNotFound = errors.New("api key not found")
 	// Default
 	ErrInternalError = errors.New("internal error")
 )
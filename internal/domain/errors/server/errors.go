@@ -6,15 +6,60 @@ const (
 	ErrInvalidCourierName   = "invalid courier's name"
 	ErrInvalidCourierStatus = "invalid courier's status"
 	ErrInvalidCourierPhone  = "invalid courier's phone"
+	ErrCourierPhoneCountry  = "courier's phone country is not supported"
 	ErrInvalidTransportType = "invalid transport type"
 	ErrCourierExists        = "courier with this parameters already exists"
 	ErrCourierNotFound      = "courier not found"
 	ErrNoAvailableCouriers  = "no available couriers"
+	ErrCourierBusy          = "courier has an active delivery, status can't be changed"
 	// Delivery
-	ErrDeliveryExists   = "this delivery already exists"
-	ErrDeliveryNotFound = "delivery not found"
+	ErrDeliveryExists          = "this delivery already exists"
+	ErrDeliveryNotFound        = "delivery not found"
+	ErrInvalidDeliveryStatus   = "invalid delivery status"
+	ErrInvalidStatusTransition = "delivery can't move to this status from its current one"
+	ErrHandoffCodeRequired     = "handoff code is required to complete this delivery"
+	ErrInvalidHandoffCode      = "invalid handoff code"
+	ErrHandoffLocked           = "too many wrong handoff codes, try again later"
+	// Feedback
+	ErrInvalidOrderId         = "invalid order id"
+	ErrInvalidRating          = "rating must be between 1 and 5"
+	ErrInvalidFeedbackTag     = "invalid feedback tag"
+	ErrFeedbackCommentTooLong = "feedback comment is too long"
+	ErrFeedbackExists         = "feedback for this delivery already exists"
+	ErrDeliveryNotCompleted   = "delivery is not completed"
+	// Proof of delivery
+	ErrInvalidProofForm   = "invalid proof of delivery form"
+	ErrProofPhotoRequired = "photo is required"
+	ErrInvalidProofPIN    = "pin must be 4 to 8 digits"
+	ErrInvalidCoordinates = "invalid coordinates"
+	ErrProofFileTooLarge  = "proof file is too large"
+	ErrProofContentType   = "proof files must be jpeg, png or webp images"
+	ErrProofExists        = "proof for this delivery already exists"
+	ErrProofRequired      = "delivery can't be completed without proof of delivery"
+	// Stats
+	ErrInvalidStatsPeriod = "invalid stats period"
+	// Import / Export
+	ErrInvalidImportFile = "invalid import file"
+	ErrImportTooLarge    = "import file is too large"
+	ErrUnsupportedFormat = "unsupported format"
+	// Auth
+	ErrUnauthorized = "authentication required"
+	ErrForbidden    = "access denied"
+	// Admin
+	ErrInvalidLogLevel   = "invalid log level, expected debug, info, warn or error"
+	ErrJobNotFound       = "job not found"
+	ErrJobAlreadyRunning = "job is already running"
+	ErrSchedulerInactive = "scheduler runs only on the leader instance, retry the request"
+	// Idempotency
+	ErrInvalidIdempotencyKey    = "idempotency key must be 1 to 255 printable ASCII characters"
+	ErrIdempotencyKeyReused     = "idempotency key was already used with a different request"
+	ErrIdempotencyKeyInProgress = "request with this idempotency key is still in progress"
+	ErrRequestBodyTooLarge      = "request body is too large"
+	ErrInvalidRequestBody       = "unable to read request body"
 	// Default
-	ErrRequestCanceled = "request canceled"
-	ErrInvalidJSON     = "invalid JSON"
-	ErrInternalError   = "internal error"
+	ErrInvalidRequest    = "request does not match API schema"
+	ErrRateLimitExceeded = "rate limit exceeded"
+	ErrRequestCanceled   = "request canceled"
+	ErrInvalidJSON       = "invalid JSON"
+	ErrInternalError     = "internal error"
 )
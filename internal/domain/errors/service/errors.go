@@ -6,14 +6,42 @@ var (
 	// Couriers
 	ErrInvalidName          = errors.New("invalid name")
 	ErrInvalidPhone         = errors.New("invalid phone")
+	ErrPhoneCountry         = errors.New("phone country is not allowed")
 	ErrInvalidStatus        = errors.New("invalid status")
 	ErrInvalidTransportType = errors.New("invalid transport type")
 	ErrCourierExists        = errors.New("courier already exists")
 	ErrCourierNotFound      = errors.New("courier not found")
 	ErrNoAvailableCouriers  = errors.New("no available couriers")
+	ErrCourierBusy          = errors.New("courier is busy with a delivery")
 	// Delivery
-	ErrDeliveryExists   = errors.New("delivery already exists")
-	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrDeliveryExists          = errors.New("delivery already exists")
+	ErrDeliveryNotFound        = errors.New("delivery not found")
+	ErrInvalidDeliveryStatus   = errors.New("invalid delivery status")
+	ErrInvalidStatusTransition = errors.New("invalid delivery status transition")
+	ErrHandoffCodeRequired     = errors.New("handoff code is required")
+	ErrInvalidHandoffCode      = errors.New("invalid handoff code")
+	ErrHandoffLocked           = errors.New("handoff code input is locked")
+	// Feedback
+	ErrInvalidRating          = errors.New("invalid rating")
+	ErrInvalidFeedbackTag     = errors.New("invalid feedback tag")
+	ErrFeedbackCommentTooLong = errors.New("feedback comment is too long")
+	ErrFeedbackExists         = errors.New("feedback already exists")
+	ErrDeliveryNotCompleted   = errors.New("delivery is not completed")
+	// Proof of delivery
+	ErrInvalidProofPIN    = errors.New("invalid proof pin")
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+	ErrProofFileTooLarge  = errors.New("proof file is too large")
+	ErrProofContentType   = errors.New("unsupported proof content type")
+	ErrProofExists        = errors.New("proof of delivery already exists")
+	ErrProofRequired      = errors.New("proof of delivery is required")
+	// Stats
+	ErrInvalidStatsPeriod = errors.New("invalid stats period")
+	// Auth
+	ErrForbidden = errors.New("access denied")
+	// Jobs
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobAlreadyRunning = errors.New("job is already running")
+	ErrSchedulerInactive = errors.New("scheduler is not active on this instance")
 	// Message Broker
 	ErrUnknownOrderStatus = errors.New("unknown order status")
 	// Default
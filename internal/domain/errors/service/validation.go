@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"strings"
+)
+
+// FieldError ошибка одного поля запроса. Err - одна из ошибок этого пакета, по ней контроллер выбирает код и текст
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// ValidationError собирает ошибки всех полей запроса, чтобы клиент получил их разом.
+// errors.Is находит любую из ошибок полей, поэтому проверки вида errors.Is(err, ErrInvalidName) продолжают работать
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (v *ValidationError) Add(field string, err error) {
+	v.Fields = append(v.Fields, FieldError{Field: field, Err: err})
+}
+
+// Err nil, если ошибок не было. Возвращать сам *ValidationError без ошибок нельзя - это будет не nil error
+func (v *ValidationError) Err() error {
+	if len(v.Fields) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v *ValidationError) Error() string {
+	messages := make([]string, len(v.Fields))
+	for i, f := range v.Fields {
+		messages[i] = f.Err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (v *ValidationError) Unwrap() []error {
+	errs := make([]error, len(v.Fields))
+	for i, f := range v.Fields {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// AsValidationError достает ошибки полей из err
+func AsValidationError(err error) (*ValidationError, bool) {
+	var v *ValidationError
+	ok := errors.As(err, &v)
+	return v, ok
+}
@@ -20,6 +20,8 @@ type Courier struct {
 	Status          string // available | busy | paused
 	TransportType   string // on_foot | scooter | car
 	TotalDeliveries int
+	RatingAvg       float64 // средняя оценка клиентов, 0 если оценок нет
+	RatingCount     int
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
@@ -6,6 +6,21 @@ const (
 	StatusAssigned   = "assigned"
 	StatusUnassigned = "unassigned"
 	StatusCompleted  = "completed"
+	// этапы доставки, о которых сообщает приложение курьера
+	StatusAccepted            = "accepted"
+	StatusArrivedAtRestaurant = "arrived_at_restaurant"
+	StatusPickedUp            = "picked_up"
+	StatusEnRoute             = "en_route"
+	StatusArrived             = "arrived"
+	StatusDelivered           = "delivered"
+	// статусы завершенных доставок в delivery_history
+	StatusCancelled = "cancelled"
+	StatusExpired   = "expired"
+
+	// состояния SLA доставки
+	SLAStateOnTime   = "on_time"
+	SLAStateAtRisk   = "at_risk"  // прошла заданная доля времени до дедлайна
+	SLAStateBreached = "breached" // дедлайн прошел, а доставка не завершена
 )
 
 // Delivery сущность из таблицы delivery
@@ -15,4 +30,56 @@ type Delivery struct {
 	OrderId    string
 	AssignedAt time.Time
 	Deadline   time.Time
+	Status     string // текущий этап: assigned | accepted | arrived_at_restaurant | picked_up | en_route | arrived
+	SLAState   string // on_time | at_risk | breached
+	// ProofRequired доставку нельзя завершить без подтверждения, ProofAttached подтверждение уже загружено
+	ProofRequired bool
+	ProofAttached bool
+	// HandoffCodeHash HMAC кода передачи заказа, пустой если код не нужен
+	HandoffCodeHash string
+}
+
+// SLAReport результат одного прохода монитора SLA
+type SLAReport struct {
+	AtRisk   []Delivery     // доставки, перешедшие в at_risk на этом проходе
+	Breached []Delivery     // доставки, перешедшие в breached на этом проходе
+	Active   map[string]int // текущее количество доставок в каждом состоянии
+}
+
+// SLAPolicy настройки отслеживания SLA доставок
+type SLAPolicy struct {
+	AtRiskFraction float64       // доля времени от назначения до дедлайна, после которой доставка считается at_risk
+	UnassignGrace  time.Duration // сколько ждать после дедлайна, прежде чем снять доставку с курьера
+}
+
+// HandoffPolicy настройки кода передачи заказа. Код выдается для заказов дороже PriceThreshold,
+// после MaxAttempts неверных попыток ввод блокируется на Lockout
+type HandoffPolicy struct {
+	PriceThreshold int64 // 0 отключает коды
+	MaxAttempts    int
+	Lockout        time.Duration
+	Secret         []byte // ключ HMAC: по одной базе 4-значный код не подобрать
+}
+
+// Enabled выдаются ли коды вообще. Без них стоимость заказа для назначения не нужна
+func (p HandoffPolicy) Enabled() bool {
+	return p.PriceThreshold > 0
+}
+
+// Required нужен ли код для заказа с такой стоимостью
+func (p HandoffPolicy) Required(totalPrice int64) bool {
+	return p.Enabled() && totalPrice > p.PriceThreshold
+}
+
+// FinishedDelivery завершенная доставка из таблицы delivery_history
+type FinishedDelivery struct {
+	Id            int
+	CourierId     int
+	OrderId       string
+	TransportType string
+	Status        string // completed | cancelled | expired
+	SLAState      string // on_time | at_risk | breached
+	AssignedAt    time.Time
+	Deadline      time.Time
+	FinishedAt    time.Time
 }
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// DeliveryEvent запись таймлайна доставки, таблица delivery_events
+type DeliveryEvent struct {
+	Id         int
+	OrderId    string
+	CourierId  int
+	Status     string
+	Actor      string // пусто у внутренних событий
+	OccurredAt time.Time
+}
+
+// deliveryTransitions допустимые переходы между этапами доставки. Этапы идут строго по порядку,
+// delivered завершает доставку так же, как завершение заказа из order-service
+var deliveryTransitions = map[string]string{
+	StatusAssigned:            StatusAccepted,
+	StatusAccepted:            StatusArrivedAtRestaurant,
+	StatusArrivedAtRestaurant: StatusPickedUp,
+	StatusPickedUp:            StatusEnRoute,
+	StatusEnRoute:             StatusArrived,
+	StatusArrived:             StatusDelivered,
+}
+
+// IsDeliveryEventStatus можно ли сообщить о таком этапе через события доставки
+func IsDeliveryEventStatus(status string) bool {
+	for _, next := range deliveryTransitions {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransition разрешен ли переход доставки из этапа from в этап to
+func CanTransition(from, to string) bool {
+	return deliveryTransitions[from] == to
+}
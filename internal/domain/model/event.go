@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+const (
+	EventCourierLowRating    = "courier.low_rating"
+	EventDeliverySLAAtRisk   = "delivery.sla_at_risk"
+	EventDeliverySLABreached = "delivery.sla_breached"
+	EventDeliveryHandoffCode = "delivery.handoff_code"
+)
+
+// Event событие, которое сервис публикует в свой поток событий.
+// Payload сериализуется в JSON как есть
+type Event struct {
+	Type       string
+	Key        string // ключ партиционирования, события с одним ключом читаются по порядку
+	Payload    any
+	OccurredAt time.Time
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	FeedbackTagLate    = "late"
+	FeedbackTagRude    = "rude"
+	FeedbackTagDamaged = "damaged"
+)
+
+// Feedback отзыв клиента о завершенной доставке, таблица delivery_feedback
+type Feedback struct {
+	Id        int
+	OrderId   string
+	CourierId int
+	Rating    int // 1..5
+	Tags      []string
+	Comment   string
+	CreatedAt time.Time
+}
+
+// RatingPolicy определяет, какой рейтинг считается низким
+type RatingPolicy struct {
+	LowThreshold float64 // средняя оценка ниже порога считается низкой
+	MinCount     int     // пока оценок меньше, рейтинг курьера не учитывается
+	AlertRating  int     // оценка не выше этой сразу отправляется алертом
+}
+
+// IsLow низкий ли рейтинг у курьера с такой средней оценкой и количеством оценок
+func (p RatingPolicy) IsLow(avg float64, count int) bool {
+	return count > 0 && count >= p.MinCount && avg < p.LowThreshold
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// IdempotencyRecord запрос с заголовком Idempotency-Key и его ответ. Status == 0 - запрос еще выполняется
+type IdempotencyRecord struct {
+	Scope       string // клиент, которому принадлежит ключ
+	Key         string
+	Fingerprint string // sha256 метода, пути и тела запроса
+	LockToken   string
+	Status      int
+	ContentType string
+	Body        []byte
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// LeaderLease аренда лидерства name, которой владеет реплика Holder до ExpiresAt
+type LeaderLease struct {
+	Name       string
+	Holder     string
+	AcquiredAt time.Time
+	RenewedAt  time.Time
+	ExpiresAt  time.Time
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"context"
+	"strconv"
+)
+
+const (
+	RoleAdmin      = "admin"
+	RoleDispatcher = "dispatcher"
+	RoleCourier    = "courier"
+	RoleService    = "service" // другие сервисы: order-service, уведомления и т.д.
+)
+
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+	AuthMethodNone   = "none" // авторизация выключена в конфиге
+)
+
+// Principal тот, от чьего имени выполняется запрос. У курьера CourierId - его id в таблице couriers
+type Principal struct {
+	Subject   string
+	Role      string
+	CourierId int
+	Method    string
+}
+
+// String идентификатор для журнала событий, например "courier:7" или "dispatcher:ops-bot"
+func (p Principal) String() string {
+	if p.Role == RoleCourier && p.CourierId > 0 {
+		return RoleCourier + ":" + strconv.Itoa(p.CourierId)
+	}
+	return p.Role + ":" + p.Subject
+}
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleDispatcher, RoleCourier, RoleService:
+		return true
+	}
+	return false
+}
+
+// APIKey статический ключ доступа. Хранится только sha256 от ключа
+type APIKey struct {
+	Name      string
+	KeyHash   string
+	Role      string
+	CourierId int
+}
+
+type principalCtxKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, p)
+}
+
+// PrincipalFromContext у внутренних вызовов (Kafka, воркеры) principal нет
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(Principal)
+	return p, ok
+}
+
+// IsCourier запрос от имени курьера
+func IsCourier(ctx context.Context) bool {
+	p, ok := PrincipalFromContext(ctx)
+	return ok && p.Role == RoleCourier
+}
+
+// CanAccessCourier курьер работает только со своим профилем и своими доставками,
+// остальным ролям и внутренним вызовам доступны все курьеры
+func CanAccessCourier(ctx context.Context, courierId int) bool {
+	p, ok := PrincipalFromContext(ctx)
+	return !ok || p.Role != RoleCourier || p.CourierId == courierId
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Blob метаданные файла в blob-хранилище
+type Blob struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// DeliveryProof подтверждение доставки из таблицы delivery_proofs. Принадлежит доставке DeliveryId,
+// у переназначенного заказа подтверждение загружается заново
+type DeliveryProof struct {
+	Id         int
+	DeliveryId int
+	OrderId    string
+	CourierId  int
+	Photo      Blob
+	Signature  *Blob  // подпись клиента необязательна
+	PINHash    string // HMAC от PIN, сам PIN не хранится
+	Latitude   float64
+	Longitude  float64
+	CreatedAt  time.Time
+}
+
+// ProofPolicy ограничения на файлы подтверждения и обязательность подтверждения для завершения доставки
+type ProofPolicy struct {
+	MaxPhotoSize     int64
+	MaxSignatureSize int64
+	RequiredForAll   bool   // если false, подтверждение обязательно только для доставок с proof_required
+	PINSecret        []byte // ключ HMAC для PIN
+}
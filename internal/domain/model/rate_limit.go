@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// RateLimitCounter число запросов ключа в окне [WindowStart, ExpiresAt)
+type RateLimitCounter struct {
+	Key         string
+	WindowStart time.Time
+	ExpiresAt   time.Time
+	Count       int
+}
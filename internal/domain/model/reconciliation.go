@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// DeliveryActive доставка заказа есть в delivery, остальные состояния - статусы из delivery_history
+const DeliveryActive = "active"
+
+// виды расхождений между order-service и delivery
+const (
+	DriftMissingDelivery = "missing_delivery" // заказ создан, а доставки нет
+	DriftCancelledActive = "cancelled_active" // заказ отменен, а доставка в работе
+	DriftCompletedActive = "completed_active" // заказ выполнен, а доставка в работе
+)
+
+// Order заказ из order-service, статусы те же, что в событиях order.changed.
+// CreatedAt обрезан до микросекунд, как в колонке TIMESTAMP курсора
+type Order struct {
+	Id         string
+	Status     string
+	TotalPrice int64
+	CreatedAt  time.Time
+}
+
+// ReconciliationCursor последний проверенный заказ, заказы идут в порядке (CreatedAt, OrderId).
+// Курсор доставок в работе идет только по OrderId, CreatedAt в нем - время сохранения
+type ReconciliationCursor struct {
+	Name      string
+	CreatedAt time.Time
+	OrderId   string
+}
+
+// Passed заказ уже проверен
+func (c ReconciliationCursor) Passed(o Order) bool {
+	if o.CreatedAt.Equal(c.CreatedAt) {
+		return o.Id <= c.OrderId
+	}
+	return o.CreatedAt.Before(c.CreatedAt)
+}
+
+// ReconciliationPolicy настройки сверки. Заказы моложе Grace не проверяются: событие о них может быть еще в Kafka.
+// Lookback - с какого момента начать, если курсора еще нет. ActiveBatchSize - сколько доставок в работе
+// проверяется за проход, все доставки обходятся по кругу. ReportOnly только находит расхождения, не исправляя их.
+// MaxFixAttempts - после скольких неудачных исправлений подряд новый заказ пропускается
+type ReconciliationPolicy struct {
+	BatchSize       int
+	ActiveBatchSize int
+	Grace           time.Duration
+	Lookback        time.Duration
+	ReportOnly      bool
+	MaxFixAttempts  int
+}
@@ -0,0 +1,19 @@
+package model
+
+// DeliveryStats агрегаты по завершенным доставкам (таблица delivery_history) для одного типа транспорта
+type DeliveryStats struct {
+	TransportType   string
+	Completed       int
+	Cancelled       int
+	Expired         int
+	Breached        int     // завершены позже дедлайна или не завершены к дедлайну
+	CompletedOnTime int     // завершены не позже дедлайна
+	AvgDurationSec  float64 // среднее время от назначения до завершения, только по completed
+}
+
+// CourierGroupCount количество курьеров с данным статусом и типом транспорта
+type CourierGroupCount struct {
+	Status        string
+	TransportType string
+	Count         int
+}
@@ -0,0 +1,47 @@
+package blob
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalBlobStorage_PutDelete(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalBlobStorage(root)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	n, err := s.Put(ctx, "proofs/ORDER-1/photo.jpg", strings.NewReader("image"))
+	require.NoError(t, err)
+	require.Equal(t, int64(5), n)
+
+	data, err := os.ReadFile(filepath.Join(root, "proofs", "ORDER-1", "photo.jpg"))
+	require.NoError(t, err)
+	require.Equal(t, "image", string(data))
+
+	// временных файлов после записи не остается
+	entries, err := os.ReadDir(filepath.Join(root, "proofs", "ORDER-1"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, s.Delete(ctx, "proofs/ORDER-1/photo.jpg"))
+	_, err = os.Stat(filepath.Join(root, "proofs", "ORDER-1", "photo.jpg"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// повторное удаление не ошибка
+	require.NoError(t, s.Delete(ctx, "proofs/ORDER-1/photo.jpg"))
+}
+
+func TestLocalBlobStorage_InvalidKey(t *testing.T) {
+	s, err := NewLocalBlobStorage(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", ".", "..", "../escape.jpg", "proofs/../../escape.jpg", "/etc/passwd"} {
+		_, err = s.Put(context.Background(), key, strings.NewReader("x"))
+		require.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
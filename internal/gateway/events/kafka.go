@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/observability/requestid"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// envelope формат сообщения в топике событий. Тип дублируется в заголовке event-type,
+// чтобы консьюмеры могли отфильтровать события без разбора тела
+type envelope struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Payload    any       `json:"payload"`
+}
+
+type eventPublisherKafka struct {
+	l        logger.LoggerAdapter
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func NewEventPublisherKafka(l logger.LoggerAdapter, producer sarama.SyncProducer, topic string) *eventPublisherKafka {
+	return &eventPublisherKafka{l: l, producer: producer, topic: topic}
+}
+
+// Publish синхронно отправляет событие в топик. Ошибка логируется здесь же,
+// так как сервисы обычно не прерывают свою работу из-за неотправленного события
+func (p *eventPublisherKafka) Publish(ctx context.Context, event model.Event) error {
+	value, err := json.Marshal(envelope{
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Payload:    event.Payload,
+	})
+	if err != nil {
+		p.l.ErrorContext(ctx, "marshal event", "type", event.Type, "error", err.Error())
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(event.Key),
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event-type"), Value: []byte(event.Type)},
+		},
+		Timestamp: event.OccurredAt,
+	}
+	// traceparent в заголовках, чтобы консьюмеры продолжили трейс запроса, породившего событие
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	for key, value := range carrier {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(requestid.MetadataKey), Value: []byte(id)})
+	}
+
+	if _, _, err = p.producer.SendMessage(msg); err != nil {
+		p.l.ErrorContext(ctx, "publish event", "type", event.Type, "key", event.Key, "error", err.Error())
+		return err
+	}
+	return nil
+}
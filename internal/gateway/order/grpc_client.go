@@ -5,6 +5,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"service-order-avito/api/order"
+	"service-order-avito/internal/domain/model"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -21,21 +24,39 @@ func NewOrderGateway(c orderServiceRPCClient) *orderGateway {
 	return &orderGateway{client: c}
 }
 
-func (og *orderGateway) GetOrderIdsFrom(ctx context.Context, from time.Time) ([]string, error) {
+// GetOrdersAfter до limit заказов после курсора в порядке (CreatedAt, Id). Заказ определяется по Id,
+// OrderNumber - номер для клиента. В GetOrders нет лимита, поэтому order-service отдает все заказы
+// начиная с курсора, а страница отрезается здесь. Время создания обрезается до микросекунд:
+// курсор хранится в TIMESTAMP, и с наносекундами последний заказ страницы не совпал бы с сохраненным
+func (og *orderGateway) GetOrdersAfter(ctx context.Context, cursor model.ReconciliationCursor, limit int) ([]model.Order, error) {
 	resp, err := og.client.GetOrders(
 		ctx,
-		&order.GetOrdersRequest{From: timestamppb.New(from)},
+		&order.GetOrdersRequest{From: timestamppb.New(cursor.CreatedAt)},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	orderIds := make([]string, len(resp.Orders))
-	for i := range resp.Orders {
-		orderIds[i] = resp.Orders[i].OrderNumber
+	orders := make([]model.Order, 0, len(resp.Orders))
+	for _, o := range resp.Orders {
+		ord := model.Order{
+			Id:         o.GetId(),
+			Status:     o.GetStatus(),
+			TotalPrice: o.GetTotalPrice(),
+			CreatedAt:  o.GetCreatedAt().AsTime().Truncate(time.Microsecond),
+		}
+		if !cursor.Passed(ord) {
+			orders = append(orders, ord)
+		}
 	}
 
-	return orderIds, err
+	slices.SortFunc(orders, func(a, b model.Order) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return orders[:min(len(orders), limit)], nil
 }
 
 func (og *orderGateway) GetOrderStatusById(ctx context.Context, id string) (string, error) {
@@ -49,3 +70,16 @@ func (og *orderGateway) GetOrderStatusById(ctx context.Context, id string) (stri
 
 	return resp.Order.GetStatus(), nil
 }
+
+// GetOrderTotalPriceById стоимость заказа, по ней решается, нужен ли код передачи
+func (og *orderGateway) GetOrderTotalPriceById(ctx context.Context, id string) (int64, error) {
+	resp, err := og.client.GetOrderById(
+		ctx,
+		&order.GetOrderByIdRequest{Id: id},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Order.GetTotalPrice(), nil
+}
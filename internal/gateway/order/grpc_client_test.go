@@ -0,0 +1,49 @@
+package order
+
+import (
+	"context"
+	"service-order-avito/api/order"
+	"service-order-avito/internal/domain/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeOrdersClient struct {
+	orders []*order.Order
+	from   time.Time
+}
+
+func (c *fakeOrdersClient) GetOrders(_ context.Context, in *order.GetOrdersRequest, _ ...grpc.CallOption) (*order.GetOrdersResponse, error) {
+	c.from = in.GetFrom().AsTime()
+	return &order.GetOrdersResponse{Orders: c.orders}, nil
+}
+
+func (c *fakeOrdersClient) GetOrderById(context.Context, *order.GetOrderByIdRequest, ...grpc.CallOption) (*order.GetOrderByIdResponse, error) {
+	return nil, nil
+}
+
+func TestOrderGateway_GetOrdersAfter(t *testing.T) {
+	// у order-service наносекунды, курсор в базе хранит только микросекунды
+	createdAt := time.Date(2025, 12, 26, 12, 0, 0, 123456789, time.UTC)
+	stored := time.Date(2025, 12, 26, 12, 0, 0, 123456000, time.UTC)
+	client := &fakeOrdersClient{orders: []*order.Order{
+		{Id: "C", Status: "created", CreatedAt: timestamppb.New(createdAt.Add(time.Second))},
+		{Id: "B", Status: "created", CreatedAt: timestamppb.New(createdAt)},
+		{Id: "A", Status: "created", CreatedAt: timestamppb.New(createdAt)},
+		{Id: "D", Status: "created", CreatedAt: timestamppb.New(createdAt.Add(2 * time.Second))},
+	}}
+	og := NewOrderGateway(client)
+
+	orders, err := og.GetOrdersAfter(context.Background(), model.ReconciliationCursor{CreatedAt: stored, OrderId: "A"}, 2)
+	require.NoError(t, err)
+	require.Equal(t, stored, client.from)
+	// A уже проверен, хотя в order-service его время создания больше сохраненного
+	require.Equal(t, []model.Order{
+		{Id: "B", Status: "created", CreatedAt: stored},
+		{Id: "C", Status: "created", CreatedAt: stored.Add(time.Second)},
+	}, orders)
+}
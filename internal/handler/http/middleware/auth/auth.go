@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/pkg/jwt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNoCredentials в запросе нет ни API-ключа, ни токена
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials ключ неизвестен или отозван, токен не прошел проверку
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// APIKeyStore ключи из базы, реализация в repository/postgres
+type APIKeyStore interface {
+	GetByHash(ctx context.Context, hash string) (model.APIKey, error)
+}
+
+// Authenticator определяет principal по API-ключу из заголовка или по JWT из Authorization: Bearer.
+// Ключи из конфига проверяются до базы, store и verifier могут быть nil
+type Authenticator struct {
+	apiKeyHeader string
+	staticKeys   map[string]model.APIKey // sha256 ключа -> ключ
+	store        APIKeyStore
+	verifier     *jwt.Verifier
+	disabled     bool
+
+	verified sync.Map // sha256 ключей из базы, которые уже проходили проверку
+}
+
+func NewAuthenticator(apiKeyHeader string, staticKeys []model.APIKey, store APIKeyStore, verifier *jwt.Verifier) *Authenticator {
+	keys := make(map[string]model.APIKey, len(staticKeys))
+	for _, key := range staticKeys {
+		keys[key.KeyHash] = key
+	}
+	return &Authenticator{
+		apiKeyHeader: apiKeyHeader,
+		staticKeys:   keys,
+		store:        store,
+		verifier:     verifier,
+	}
+}
+
+func (a *Authenticator) Authenticate(r *http.Request) (model.Principal, error) {
+	if a.disabled {
+		return model.Principal{Subject: "anonymous", Role: model.RoleAdmin, Method: model.AuthMethodNone}, nil
+	}
+
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		scheme, token, ok := strings.Cut(authorization, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || a.verifier == nil {
+			return model.Principal{}, ErrInvalidCredentials
+		}
+		return a.fromToken(strings.TrimSpace(token))
+	}
+
+	if key := r.Header.Get(a.apiKeyHeader); key != "" {
+		return a.fromAPIKey(r.Context(), key)
+	}
+	return model.Principal{}, ErrNoCredentials
+}
+
+func (a *Authenticator) fromToken(token string) (model.Principal, error) {
+	claims, err := a.verifier.Verify(token)
+	if err != nil {
+		return model.Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	p := model.Principal{Subject: claims.Subject, Role: claims.Role, CourierId: claims.CourierId, Method: model.AuthMethodJWT}
+	if !validPrincipal(p) {
+		return model.Principal{}, ErrInvalidCredentials
+	}
+	return p, nil
+}
+
+func (a *Authenticator) fromAPIKey(ctx context.Context, raw string) (model.Principal, error) {
+	hash := HashAPIKey(raw)
+
+	key, ok := a.staticKeys[hash]
+	if !ok {
+		if a.store == nil {
+			return model.Principal{}, ErrInvalidCredentials
+		}
+		var err error
+		key, err = a.store.GetByHash(ctx, hash)
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return model.Principal{}, ErrInvalidCredentials
+		}
+		if err != nil {
+			return model.Principal{}, err
+		}
+	}
+
+	p := model.Principal{Subject: key.Name, Role: key.Role, CourierId: key.CourierId, Method: model.AuthMethodAPIKey}
+	if !validPrincipal(p) {
+		return model.Principal{}, ErrInvalidCredentials
+	}
+	if !ok {
+		a.verified.Store(hash, struct{}{})
+	}
+	return p, nil
+}
+
+// KnownAPIKey ключ из конфига или ключ из базы, который уже проходил проверку. Лимитер запросов работает
+// до авторизации и дает отдельное ведро только таким ключам, иначе новый случайный ключ получал бы полное ведро.
+// Запомненных ключей не больше, чем выдано в базе; отозванный ключ остается известным, но проверку уже не пройдет
+func (a *Authenticator) KnownAPIKey(raw string) bool {
+	if a.disabled {
+		return false
+	}
+	hash := HashAPIKey(raw)
+	if _, ok := a.staticKeys[hash]; ok {
+		return true
+	}
+	_, ok := a.verified.Load(hash)
+	return ok
+}
+
+// validPrincipal роль должна быть известной, а у курьера обязательно должен быть id
+func validPrincipal(p model.Principal) bool {
+	return p.Subject != "" && model.IsValidRole(p.Role) && (p.Role != model.RoleCourier || p.CourierId > 0)
+}
+
+// HashAPIKey в конфиге и в базе хранится sha256 ключа в hex, то же что выдает `echo -n KEY | sha256sum`
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseAPIKeys разбирает ключи из конфига в формате "name:role:sha256hex" или "name:role:sha256hex:courier_id"
+func ParseAPIKeys(items []string) ([]model.APIKey, error) {
+	keys := make([]model.APIKey, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 && len(parts) != 4 {
+			return nil, fmt.Errorf("api key %q: expected name:role:sha256[:courier_id]", parts[0])
+		}
+
+		key := model.APIKey{Name: parts[0], Role: parts[1], KeyHash: strings.ToLower(parts[2])}
+		if _, err := hex.DecodeString(key.KeyHash); err != nil || len(key.KeyHash) != sha256.Size*2 {
+			return nil, fmt.Errorf("api key %q: hash must be sha256 in hex", key.Name)
+		}
+		if len(parts) == 4 {
+			id, err := strconv.Atoi(parts[3])
+			if err != nil {
+				return nil, fmt.Errorf("api key %q: invalid courier id", key.Name)
+			}
+			key.CourierId = id
+		}
+		if !validPrincipal(model.Principal{Subject: key.Name, Role: key.Role, CourierId: key.CourierId}) {
+			return nil, fmt.Errorf("api key %q: invalid role or missing courier id", key.Name)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// NewDisabledAuthenticator для выключенной авторизации: любой запрос выполняется как admin
+func NewDisabledAuthenticator() *Authenticator {
+	return &Authenticator{disabled: true}
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/pkg/jwt"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+type stubKeyStore struct {
+	keys map[string]model.APIKey
+	err  error
+}
+
+func (s stubKeyStore) GetByHash(_ context.Context, hash string) (model.APIKey, error) {
+	if s.err != nil {
+		return model.APIKey{}, s.err
+	}
+	key, ok := s.keys[hash]
+	if !ok {
+		return model.APIKey{}, repository.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func token(t *testing.T, claims map[string]any) string {
+	enc := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := enc(map[string]string{"alg": jwt.AlgHS256, "typ": "JWT"}) + "." + enc(claims)
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func request(headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	static, err := ParseAPIKeys([]string{"ops:dispatcher:" + HashAPIKey("static-key")})
+	require.NoError(t, err)
+	store := stubKeyStore{keys: map[string]model.APIKey{
+		HashAPIKey("db-key"):      {Name: "courier-app-7", Role: model.RoleCourier, CourierId: 7},
+		HashAPIKey("broken-role"): {Name: "legacy", Role: "root"},
+	}}
+	a := NewAuthenticator("X-API-Key", static, store, jwt.NewVerifier(jwt.Config{HS256Secret: testSecret}))
+
+	exp := time.Now().Add(time.Minute).Unix()
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    model.Principal
+		wantErr error
+	}{
+		{name: "no credentials", wantErr: ErrNoCredentials},
+		{
+			name:    "static api key",
+			headers: map[string]string{"X-API-Key": "static-key"},
+			want:    model.Principal{Subject: "ops", Role: model.RoleDispatcher, Method: model.AuthMethodAPIKey},
+		},
+		{
+			name:    "api key from db",
+			headers: map[string]string{"X-API-Key": "db-key"},
+			want:    model.Principal{Subject: "courier-app-7", Role: model.RoleCourier, CourierId: 7, Method: model.AuthMethodAPIKey},
+		},
+		{name: "unknown api key", headers: map[string]string{"X-API-Key": "nope"}, wantErr: ErrInvalidCredentials},
+		{name: "unknown role in db", headers: map[string]string{"X-API-Key": "broken-role"}, wantErr: ErrInvalidCredentials},
+		{
+			name:    "jwt",
+			headers: map[string]string{"Authorization": "Bearer " + token(t, map[string]any{"sub": "u1", "role": "courier", "courier_id": 3, "exp": exp})},
+			want:    model.Principal{Subject: "u1", Role: model.RoleCourier, CourierId: 3, Method: model.AuthMethodJWT},
+		},
+		{
+			name:    "jwt courier without id",
+			headers: map[string]string{"Authorization": "Bearer " + token(t, map[string]any{"sub": "u1", "role": "courier", "exp": exp})},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "expired jwt",
+			headers: map[string]string{"Authorization": "Bearer " + token(t, map[string]any{"sub": "u1", "role": "admin", "exp": time.Now().Add(-time.Hour).Unix()})},
+			wantErr: jwt.ErrExpired,
+		},
+		{name: "basic auth", headers: map[string]string{"Authorization": "Basic dTpw"}, wantErr: ErrInvalidCredentials},
+		{
+			// заголовок Authorization приоритетнее ключа, невалидный токен не подменяется ключом
+			name:    "bad token with valid key",
+			headers: map[string]string{"Authorization": "Bearer x.y.z", "X-API-Key": "static-key"},
+			wantErr: ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(request(tt.headers))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, p)
+		})
+	}
+}
+
+func TestAuthenticator_KnownAPIKey(t *testing.T) {
+	static, err := ParseAPIKeys([]string{"ops:dispatcher:" + HashAPIKey("static-key")})
+	require.NoError(t, err)
+	store := stubKeyStore{keys: map[string]model.APIKey{
+		HashAPIKey("db-key"): {Name: "courier-app-7", Role: model.RoleCourier, CourierId: 7},
+	}}
+	a := NewAuthenticator("X-API-Key", static, store, nil)
+
+	require.True(t, a.KnownAPIKey("static-key"))
+	require.False(t, a.KnownAPIKey("nope"))
+	// ключ из базы становится известным только после успешной проверки
+	require.False(t, a.KnownAPIKey("db-key"))
+	_, err = a.Authenticate(request(map[string]string{"X-API-Key": "db-key"}))
+	require.NoError(t, err)
+	require.True(t, a.KnownAPIKey("db-key"))
+
+	_, err = a.Authenticate(request(map[string]string{"X-API-Key": "nope"}))
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	require.False(t, a.KnownAPIKey("nope"))
+
+	require.False(t, NewDisabledAuthenticator().KnownAPIKey("static-key"))
+}
+
+func TestAuthenticator_StoreErrors(t *testing.T) {
+	storeErr := errors.New("connection refused")
+	a := NewAuthenticator("X-API-Key", nil, stubKeyStore{err: storeErr}, nil)
+
+	_, err := a.Authenticate(request(map[string]string{"X-API-Key": "db-key"}))
+	require.ErrorIs(t, err, storeErr)
+	require.NotErrorIs(t, err, ErrInvalidCredentials)
+
+	// без JWT в конфиге Bearer токены не принимаются
+	_, err = a.Authenticate(request(map[string]string{"Authorization": "Bearer " + token(t, map[string]any{"sub": "u1", "role": "admin"})}))
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestNewDisabledAuthenticator(t *testing.T) {
+	p, err := NewDisabledAuthenticator().Authenticate(request(nil))
+	require.NoError(t, err)
+	require.Equal(t, model.RoleAdmin, p.Role)
+	require.Equal(t, model.AuthMethodNone, p.Method)
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	hash := HashAPIKey("key")
+
+	keys, err := ParseAPIKeys([]string{"bot:service:" + hash, " app:courier:" + hash + ":12 ", ""})
+	require.NoError(t, err)
+	require.Equal(t, []model.APIKey{
+		{Name: "bot", Role: model.RoleService, KeyHash: hash},
+		{Name: "app", Role: model.RoleCourier, KeyHash: hash, CourierId: 12},
+	}, keys)
+
+	for _, bad := range []string{
+		"bot:service",
+		"bot:service:plain-text-key",
+		"bot:root:" + hash,
+		"app:courier:" + hash,
+		"app:courier:" + hash + ":x",
+	} {
+		_, err = ParseAPIKeys([]string{bad})
+		require.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/model"
+	"testing"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type stubAuthenticator struct {
+	p   model.Principal
+	err error
+}
+
+func (s stubAuthenticator) Authenticate(*http.Request) (model.Principal, error) { return s.p, s.err }
+
+func serve(authn authenticator, roles []string) *httptest.ResponseRecorder {
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if roles != nil {
+		h = RequireRoles(roles...)(h)
+	}
+	h = WithAuthentication(authn, nopLogger{})(h)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+func errorMessage(t *testing.T, rec *httptest.ResponseRecorder) string {
+	var body dto.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	return body.Detail
+}
+
+func TestMiddleware(t *testing.T) {
+	dispatcher := model.Principal{Subject: "ops", Role: model.RoleDispatcher}
+	admin := model.Principal{Subject: "root", Role: model.RoleAdmin}
+
+	t.Run("open route without credentials", func(t *testing.T) {
+		rec := serve(stubAuthenticator{err: ErrNoCredentials}, nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("protected route without credentials", func(t *testing.T) {
+		rec := serve(stubAuthenticator{err: ErrNoCredentials}, []string{model.RoleDispatcher})
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		require.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+		require.Equal(t, server.ErrUnauthorized, errorMessage(t, rec))
+	})
+
+	t.Run("invalid credentials on open route", func(t *testing.T) {
+		rec := serve(stubAuthenticator{err: ErrInvalidCredentials}, nil)
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		rec := serve(stubAuthenticator{err: errors.New("db is down")}, nil)
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("role allowed", func(t *testing.T) {
+		rec := serve(stubAuthenticator{p: dispatcher}, []string{model.RoleDispatcher, model.RoleService})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("role forbidden", func(t *testing.T) {
+		rec := serve(stubAuthenticator{p: dispatcher}, []string{model.RoleService})
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Equal(t, server.ErrForbidden, errorMessage(t, rec))
+	})
+
+	t.Run("admin passes everywhere", func(t *testing.T) {
+		rec := serve(stubAuthenticator{p: admin}, []string{})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("principal in context", func(t *testing.T) {
+		var got model.Principal
+		h := WithAuthentication(stubAuthenticator{p: dispatcher}, nopLogger{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = model.PrincipalFromContext(r.Context())
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, dispatcher, got)
+	})
+}
@@ -0,0 +1,231 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/model"
+	"time"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// recordStore хранилище ключей, реализация в repository/postgres
+type recordStore interface {
+	Acquire(ctx context.Context, record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record model.IdempotencyRecord) error
+	Release(ctx context.Context, record model.IdempotencyRecord) error
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// Idempotency повторяет сохраненный ответ на запрос с тем же Idempotency-Key вместо повторного выполнения.
+// Ключ принадлежит клиенту (principal), тот же ключ с другим телом получает 422, а пока первый запрос
+// выполняется, повторы получают 409. Ответы 5xx не сохраняются, такой запрос можно повторить с тем же ключом.
+// Запросы без заголовка проходят как обычно
+type Idempotency struct {
+	store       recordStore
+	ttl         time.Duration
+	lockTimeout time.Duration
+	maxBodySize int64
+	log         logger.LoggerAdapter
+	now         func() time.Time
+}
+
+// NewIdempotency ttl - сколько хранится ответ, lockTimeout - через сколько блокировка выполняющегося запроса
+// считается брошенной, должен быть больше таймаута записи HTTP сервера
+func NewIdempotency(store recordStore, ttl, lockTimeout time.Duration, maxBodySize int64, log logger.LoggerAdapter) *Idempotency {
+	return &Idempotency{
+		store:       store,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+		maxBodySize: maxBodySize,
+		log:         log.With(slog.String("component", "middleware/idempotency")),
+		now:         time.Now,
+	}
+}
+
+func (i *Idempotency) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validKey(key) {
+			adapters.WriteError(w, r, server.ErrInvalidIdempotencyKey, http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, i.maxBodySize+1))
+		if err != nil {
+			adapters.WriteError(w, r, server.ErrInvalidRequestBody, http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) > i.maxBodySize {
+			adapters.WriteError(w, r, server.ErrRequestBodyTooLarge, http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := i.now()
+		record := model.IdempotencyRecord{
+			Scope:       scope(r.Context()),
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+			LockToken:   newLockToken(),
+			LockedUntil: now.Add(i.lockTimeout),
+			ExpiresAt:   now.Add(i.ttl),
+		}
+
+		existing, acquired, err := i.store.Acquire(r.Context(), record, now)
+		if err != nil {
+			i.log.ErrorContext(r.Context(), "acquire idempotency key", slog.String("error", err.Error()))
+			adapters.WriteError(w, r, server.ErrInternalError, http.StatusInternalServerError)
+			return
+		}
+		if !acquired {
+			i.replay(w, r, record, existing)
+			return
+		}
+
+		i.execute(w, r, next, record)
+	})
+}
+
+func (i *Idempotency) replay(w http.ResponseWriter, r *http.Request, record, existing model.IdempotencyRecord) {
+	switch {
+	case existing.Fingerprint != record.Fingerprint:
+		adapters.WriteError(w, r, server.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity)
+	case existing.Status == 0:
+		w.Header().Set("Retry-After", "1")
+		adapters.WriteError(w, r, server.ErrIdempotencyKeyInProgress, http.StatusConflict)
+	default:
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		w.Header().Set(HeaderReplayed, "true")
+		w.WriteHeader(existing.Status)
+		_, _ = w.Write(existing.Body)
+	}
+}
+
+func (i *Idempotency) execute(w http.ResponseWriter, r *http.Request, next http.Handler, record model.IdempotencyRecord) {
+	rec := &recorder{ResponseWriter: w}
+	// клиент мог уже отключиться, а ключ все равно нужно освободить или сохранить ответ
+	ctx := context.WithoutCancel(r.Context())
+
+	defer func() {
+		if p := recover(); p != nil {
+			i.release(ctx, record)
+			panic(p)
+		}
+	}()
+
+	next.ServeHTTP(rec, r)
+
+	status := rec.statusCode()
+	if status >= http.StatusInternalServerError {
+		i.release(ctx, record)
+		return
+	}
+
+	record.Status = status
+	record.ContentType = rec.Header().Get("Content-Type")
+	record.Body = rec.body.Bytes()
+	if err := i.store.Complete(ctx, record); err != nil {
+		// ответ клиенту уже отправлен, повтор с этим ключом получит 409, пока не истечет блокировка
+		i.log.ErrorContext(ctx, "save idempotent response", slog.String("key", record.Key), slog.String("error", err.Error()))
+	}
+}
+
+func (i *Idempotency) release(ctx context.Context, record model.IdempotencyRecord) {
+	if err := i.store.Release(ctx, record); err != nil {
+		i.log.ErrorContext(ctx, "release idempotency key", slog.String("key", record.Key), slog.String("error", err.Error()))
+	}
+}
+
+// Cleanup удаляет истекшие ключи, запускается планировщиком
+func (i *Idempotency) Cleanup(ctx context.Context) error {
+	deleted, err := i.store.DeleteExpired(ctx, i.now())
+	if err != nil {
+		return fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	i.log.DebugContext(ctx, "expired idempotency keys deleted", slog.Int("count", deleted))
+	return nil
+}
+
+// scope ключи разных клиентов не пересекаются. Без авторизации все запросы в одном scope
+func scope(ctx context.Context) string {
+	if p, ok := model.PrincipalFromContext(ctx); ok {
+		return p.String()
+	}
+	return "anonymous"
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validKey(key string) bool {
+	if len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newLockToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// recorder отдает ответ клиенту и одновременно запоминает его
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *recorder) statusCode() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
@@ -0,0 +1,222 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/model"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+// stubStore повторяет логику repository/postgres в памяти
+type stubStore struct {
+	mu      sync.Mutex
+	records map[[2]string]model.IdempotencyRecord
+	err     error
+}
+
+func newStubStore() *stubStore {
+	return &stubStore{records: map[[2]string]model.IdempotencyRecord{}}
+}
+
+func (s *stubStore) Acquire(_ context.Context, record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return model.IdempotencyRecord{}, false, s.err
+	}
+	k := [2]string{record.Scope, record.Key}
+	existing, ok := s.records[k]
+	stale := existing.Status == 0 && existing.LockedUntil.Before(now) && existing.Fingerprint == record.Fingerprint
+	if ok && !existing.ExpiresAt.Before(now) && !stale {
+		return existing, false, nil
+	}
+	s.records[k] = record
+	return record, true, nil
+}
+
+func (s *stubStore) Complete(_ context.Context, record model.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := [2]string{record.Scope, record.Key}
+	if existing, ok := s.records[k]; ok && existing.LockToken == record.LockToken && existing.Status == 0 {
+		s.records[k] = record
+	}
+	return nil
+}
+
+func (s *stubStore) Release(_ context.Context, record model.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := [2]string{record.Scope, record.Key}
+	if existing, ok := s.records[k]; ok && existing.LockToken == record.LockToken && existing.Status == 0 {
+		delete(s.records, k)
+	}
+	return nil
+}
+
+func (s *stubStore) DeleteExpired(context.Context, time.Time) (int, error) { return 0, nil }
+
+type testServer struct {
+	handler http.Handler
+	calls   int
+	status  int
+	clock   time.Time
+}
+
+func newTestServer(store *stubStore) *testServer {
+	ts := &testServer{status: http.StatusCreated, clock: time.Unix(1_700_000_000, 0)}
+	idem := NewIdempotency(store, time.Hour, time.Minute, 64, nopLogger{})
+	idem.now = func() time.Time { return ts.clock }
+	ts.handler = idem.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(ts.status)
+		_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(ts.calls) + `}`))
+	}))
+	return ts
+}
+
+func (ts *testServer) post(key, body string, p *model.Principal) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/delivery/assign", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(HeaderKey, key)
+	}
+	if p != nil {
+		r = r.WithContext(model.WithPrincipal(r.Context(), *p))
+	}
+	rec := httptest.NewRecorder()
+	ts.handler.ServeHTTP(rec, r)
+	return rec
+}
+
+func errorMessage(t *testing.T, rec *httptest.ResponseRecorder) string {
+	var body dto.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	return body.Detail
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	ts := newTestServer(newStubStore())
+
+	first := ts.post("k1", `{"order_id":"1"}`, nil)
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(HeaderReplayed))
+
+	second := ts.post("k1", `{"order_id":"1"}`, nil)
+	require.Equal(t, http.StatusCreated, second.Code)
+	require.Equal(t, "true", second.Header().Get(HeaderReplayed))
+	require.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	require.Equal(t, first.Body.String(), second.Body.String())
+	require.Equal(t, 1, ts.calls)
+
+	// без ключа запрос всегда выполняется
+	ts.post("", `{"order_id":"1"}`, nil)
+	ts.post("", `{"order_id":"1"}`, nil)
+	require.Equal(t, 3, ts.calls)
+
+	// у другого клиента свои ключи
+	ts.post("k1", `{"order_id":"1"}`, &model.Principal{Subject: "crm", Role: model.RoleService})
+	require.Equal(t, 4, ts.calls)
+
+	// после ttl ключ можно использовать заново
+	ts.clock = ts.clock.Add(time.Hour + time.Second)
+	rec := ts.post("k1", `{"order_id":"2"}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, 5, ts.calls)
+}
+
+func TestIdempotency_DifferentBody(t *testing.T) {
+	ts := newTestServer(newStubStore())
+
+	ts.post("k1", `{"order_id":"1"}`, nil)
+	rec := ts.post("k1", `{"order_id":"2"}`, nil)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.Equal(t, server.ErrIdempotencyKeyReused, errorMessage(t, rec))
+	require.Equal(t, 1, ts.calls)
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := newStubStore()
+	ts := newTestServer(store)
+
+	// первый запрос еще выполняется
+	_, acquired, err := store.Acquire(context.Background(), model.IdempotencyRecord{
+		Scope:       "anonymous",
+		Key:         "k1",
+		Fingerprint: fingerprint(httptest.NewRequest(http.MethodPost, "/delivery/assign", nil), []byte(`{}`)),
+		LockToken:   "other",
+		LockedUntil: ts.clock.Add(time.Minute),
+		ExpiresAt:   ts.clock.Add(time.Hour),
+	}, ts.clock)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	rec := ts.post("k1", `{}`, nil)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	require.Equal(t, server.ErrIdempotencyKeyInProgress, errorMessage(t, rec))
+	require.Equal(t, 0, ts.calls)
+
+	// брошенную блокировку можно перехватить
+	ts.clock = ts.clock.Add(2 * time.Minute)
+	rec = ts.post("k1", `{}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, 1, ts.calls)
+}
+
+func TestIdempotency_ServerErrorIsNotStored(t *testing.T) {
+	ts := newTestServer(newStubStore())
+	ts.status = http.StatusInternalServerError
+
+	ts.post("k1", `{}`, nil)
+	ts.status = http.StatusCreated
+	rec := ts.post("k1", `{}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Empty(t, rec.Header().Get(HeaderReplayed))
+	require.Equal(t, 2, ts.calls)
+}
+
+func TestIdempotency_Errors(t *testing.T) {
+	store := newStubStore()
+	ts := newTestServer(store)
+
+	rec := ts.post(strings.Repeat("k", maxKeyLength+1), `{}`, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, server.ErrInvalidIdempotencyKey, errorMessage(t, rec))
+
+	rec = ts.post("bad key", `{}`, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = ts.post("k1", strings.Repeat("x", 65), nil)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Equal(t, server.ErrRequestBodyTooLarge, errorMessage(t, rec))
+
+	store.err = errors.New("db is down")
+	rec = ts.post("k1", `{}`, nil)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Equal(t, 0, ts.calls)
+}
@@ -28,7 +28,7 @@ func WithLogging(log logger.LoggerAdapter) func(next http.Handler) http.Handler
 			start := time.Now()
 
 			defer func() {
-				entry.Info("request completed",
+				entry.InfoContext(r.Context(), "request completed",
 					slog.Int("status", ww.Status()),
 					slog.Int("bytes", ww.BytesWritten()),
 					slog.Duration("time", time.Since(start)),
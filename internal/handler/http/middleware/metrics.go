@@ -1,36 +1,61 @@
 package middleware
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 type MetricsObserverHTTP interface {
 	IncTotalRequests()
-	NewRequest(method, path, status string, durationSec float64)
+	IncInFlight()
+	DecInFlight()
+	NewRequest(method, route, status string, durationSec float64, requestSize, responseSize int64)
 	IncTotalRateLimitExceedances()
 }
 
+// WithMetrics пишет метрики запроса с меткой route - шаблоном chi (/courier/{id}), а не самим путем,
+// иначе каждый id создает новый ряд. Шаблон известен только после роутинга, для ненайденных роутов он пустой
 func WithMetrics(obs MetricsObserverHTTP) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 
 		fn := func(w http.ResponseWriter, r *http.Request) {
 
 			obs.IncTotalRequests()
+			obs.IncInFlight()
 
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			body := &countingBody{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
 
 			start := time.Now()
 			defer func() {
+				obs.DecInFlight()
 
-				if ww.Status() == http.StatusTooManyRequests {
+				status := ww.Status()
+				if status == 0 {
+					// хендлер ничего не записал, net/http ответит 200
+					status = http.StatusOK
+				}
+				if status == http.StatusTooManyRequests {
 					obs.IncTotalRateLimitExceedances()
 				}
 
-				obs.NewRequest(r.Method, r.URL.Path, strconv.Itoa(ww.Status()), time.Since(start).Seconds())
+				var route string
+				if rctx := chi.RouteContext(r.Context()); rctx != nil {
+					route = rctx.RoutePattern()
+				}
+
+				// хендлер мог не дочитать тело, тогда берем заявленный размер
+				requestSize := max(body.n, r.ContentLength)
+
+				obs.NewRequest(r.Method, route, strconv.Itoa(status), time.Since(start).Seconds(), requestSize, int64(ww.BytesWritten()))
 
 			}()
 
@@ -40,3 +65,14 @@ func WithMetrics(obs MetricsObserverHTTP) func(next http.Handler) http.Handler {
 		return http.HandlerFunc(fn)
 	}
 }
+
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
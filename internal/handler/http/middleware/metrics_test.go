@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	method, route, status string
+	requestSize           int64
+	responseSize          int64
+}
+
+type recordingMetrics struct {
+	inFlight    int
+	maxInFlight int
+	requests    []request
+	rateLimited int
+}
+
+func (m *recordingMetrics) IncTotalRequests() {}
+func (m *recordingMetrics) IncInFlight() {
+	m.inFlight++
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+}
+func (m *recordingMetrics) DecInFlight() { m.inFlight-- }
+func (m *recordingMetrics) NewRequest(method, route, status string, _ float64, requestSize, responseSize int64) {
+	m.requests = append(m.requests, request{method, route, status, requestSize, responseSize})
+}
+func (m *recordingMetrics) IncTotalRateLimitExceedances() { m.rateLimited++ }
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	router := chi.NewRouter()
+	router.Use(WithMetrics(metrics))
+	router.Route("/courier", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("courier"))
+		})
+		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/courier/1", nil),
+		httptest.NewRequest(http.MethodGet, "/courier/2", nil),
+		httptest.NewRequest(http.MethodPost, "/courier", strings.NewReader(`{"name":"Ivan"}`)),
+		httptest.NewRequest(http.MethodGet, "/nowhere/42", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Equal(t, []request{
+		{http.MethodGet, "/courier/{id}", "200", 0, 7},
+		{http.MethodGet, "/courier/{id}", "200", 0, 7},
+		{http.MethodPost, "/courier", "429", 15, 0},
+		{http.MethodGet, "", "404", 0, 19},
+	}, metrics.requests)
+	require.Equal(t, 1, metrics.rateLimited)
+	require.Equal(t, 0, metrics.inFlight)
+	require.Equal(t, 1, metrics.maxInFlight)
+}
@@ -0,0 +1,49 @@
+package rate_limiter
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AlgorithmTokenBucket   = "token_bucket"
+	AlgorithmSlidingLog    = "sliding_log"
+	AlgorithmSlidingWindow = "sliding_window"
+	AlgorithmGCRA          = "gcra"
+)
+
+// bucket состояние лимита одного ключа. now передается снаружи, чтобы алгоритмы не зависели от часов
+type bucket interface {
+	take(now time.Time) Decision
+}
+
+type bucketFactory func(p Policy, now time.Time) bucket
+
+// newBucketFactory выбирает алгоритм по значению из конфига:
+//   - token_bucket: Burst запросов сразу, дальше равномерно Limit за Window, токены копятся дробно
+//   - sliding_log: не больше Limit запросов в любом окне длиной Window, хранит время каждого запроса
+//   - sliding_window: приближение sliding_log по счетчикам текущего и прошлого окна, память O(1)
+//   - gcra: то же, что token_bucket, но хранит только одно время (theoretical arrival time)
+func newBucketFactory(algorithm string) (bucketFactory, error) {
+	switch algorithm {
+	case AlgorithmTokenBucket:
+		return func(p Policy, now time.Time) bucket { return newTokenBucket(p, now) }, nil
+	case AlgorithmSlidingLog:
+		return func(p Policy, _ time.Time) bucket { return newSlidingLog(p) }, nil
+	case AlgorithmSlidingWindow:
+		return func(p Policy, now time.Time) bucket { return newSlidingWindow(p, now) }, nil
+	case AlgorithmGCRA:
+		return func(p Policy, now time.Time) bucket { return newGCRA(p, now) }, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limiter algorithm %q", algorithm)
+	}
+}
+
+// emissionInterval промежуток между запросами при равномерном потоке Limit за Window
+func (p Policy) emissionInterval() time.Duration {
+	interval := p.Window / time.Duration(p.Limit)
+	if interval <= 0 {
+		return time.Nanosecond
+	}
+	return interval
+}
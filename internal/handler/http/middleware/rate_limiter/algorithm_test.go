@@ -0,0 +1,150 @@
+package rate_limiter
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var testStart = time.Unix(1_700_000_000, 0)
+
+type step struct {
+	at         time.Duration // от testStart
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+func runSteps(t *testing.T, b bucket, steps []step) {
+	t.Helper()
+	for i, s := range steps {
+		d := b.take(testStart.Add(s.at))
+		require.Equal(t, s.allowed, d.Allowed, "step %d at %s", i, s.at)
+		require.Equal(t, s.remaining, d.Remaining, "step %d at %s", i, s.at)
+		require.Equal(t, s.retryAfter, d.RetryAfter, "step %d at %s", i, s.at)
+	}
+}
+
+func TestNewBucketFactory(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow, AlgorithmGCRA} {
+		_, err := newBucketFactory(algorithm)
+		require.NoError(t, err, algorithm)
+	}
+	_, err := newBucketFactory("leaky_bucket")
+	require.Error(t, err)
+}
+
+func TestTokenBucket_FractionalRefill(t *testing.T) {
+	// 2 запроса за 5 секунд: токен появляется каждые 2.5s, а не раз в целую секунду
+	b := newTokenBucket(Policy{Limit: 2, Window: 5 * time.Second, Burst: 1}, testStart)
+
+	runSteps(t, b, []step{
+		{at: 0, allowed: true},
+		{at: time.Second, retryAfter: 1500 * time.Millisecond},
+		{at: 2499 * time.Millisecond, retryAfter: time.Millisecond},
+		{at: 2500 * time.Millisecond, allowed: true},
+		{at: 3750 * time.Millisecond, retryAfter: 1250 * time.Millisecond},
+		{at: 5 * time.Second, allowed: true},
+	})
+}
+
+func TestTokenBucket_Burst(t *testing.T) {
+	b := newTokenBucket(Policy{Limit: 10, Window: time.Second, Burst: 3}, testStart)
+
+	runSteps(t, b, []step{
+		{at: 0, allowed: true, remaining: 2},
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, retryAfter: 100 * time.Millisecond},
+		// за 250ms накопилось 2.5 токена
+		{at: 250 * time.Millisecond, allowed: true, remaining: 1},
+		{at: 250 * time.Millisecond, allowed: true, remaining: 0},
+		{at: 250 * time.Millisecond, retryAfter: 50 * time.Millisecond},
+		// полное ведро не растет больше capacity
+		{at: time.Hour, allowed: true, remaining: 2},
+	})
+}
+
+func TestSlidingLog(t *testing.T) {
+	b := newSlidingLog(Policy{Limit: 3, Window: time.Second})
+
+	runSteps(t, b, []step{
+		{at: 0, allowed: true, remaining: 2},
+		{at: 400 * time.Millisecond, allowed: true, remaining: 1},
+		{at: 800 * time.Millisecond, allowed: true, remaining: 0},
+		{at: 900 * time.Millisecond, retryAfter: 100 * time.Millisecond},
+		// запрос из 0 вышел из окна ровно через секунду
+		{at: time.Second, allowed: true, remaining: 0},
+		{at: 1300 * time.Millisecond, retryAfter: 100 * time.Millisecond},
+		{at: 1400 * time.Millisecond, allowed: true, remaining: 0},
+	})
+
+	d := b.take(testStart.Add(1500 * time.Millisecond))
+	require.Equal(t, 900*time.Millisecond, d.ResetAfter)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	b := newSlidingWindow(Policy{Limit: 4, Window: time.Second}, testStart)
+
+	runSteps(t, b, []step{
+		{at: 0, allowed: true, remaining: 3},
+		{at: 100 * time.Millisecond, allowed: true, remaining: 2},
+		{at: 200 * time.Millisecond, allowed: true, remaining: 1},
+		{at: 300 * time.Millisecond, allowed: true, remaining: 0},
+		// свободно станет только в следующем окне, когда 4 запроса затухнут до 3: через четверть окна
+		{at: 900 * time.Millisecond, retryAfter: 350 * time.Millisecond},
+		// в середине следующего окна прошлое весит 4*0.5 = 2
+		{at: 1500 * time.Millisecond, allowed: true, remaining: 1},
+		{at: 1500 * time.Millisecond, allowed: true, remaining: 0},
+		// 4*(1-t) + 2 <= 3 при t >= 0.75
+		{at: 1600 * time.Millisecond, retryAfter: 150 * time.Millisecond},
+		{at: 1750 * time.Millisecond, allowed: true, remaining: 0},
+		// прошло больше двух окон, счетчики обнулились
+		{at: 4 * time.Second, allowed: true, remaining: 3},
+	})
+}
+
+func TestGCRA(t *testing.T) {
+	b := newGCRA(Policy{Limit: 10, Window: time.Second, Burst: 3}, testStart)
+
+	runSteps(t, b, []step{
+		{at: 0, allowed: true, remaining: 2},
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, retryAfter: 100 * time.Millisecond},
+		{at: 50 * time.Millisecond, retryAfter: 50 * time.Millisecond},
+		{at: 100 * time.Millisecond, allowed: true, remaining: 0},
+		{at: 350 * time.Millisecond, allowed: true, remaining: 1},
+		{at: time.Hour, allowed: true, remaining: 2},
+	})
+
+	d := b.take(testStart.Add(time.Hour))
+	require.Equal(t, 200*time.Millisecond, d.ResetAfter)
+}
+
+// TestAlgorithms_SustainedRate при равномерном потоке чаще лимита все алгоритмы пропускают не больше
+// Burst + Limit запросов за Window
+func TestAlgorithms_SustainedRate(t *testing.T) {
+	policy := Policy{Limit: 5, Window: time.Second}
+
+	for _, algorithm := range []string{AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow, AlgorithmGCRA} {
+		t.Run(algorithm, func(t *testing.T) {
+			newBucket, err := newBucketFactory(algorithm)
+			require.NoError(t, err)
+			b := newBucket(policy, testStart)
+
+			allowed := 0
+			for at := time.Duration(0); at < 3*time.Second; at += 10 * time.Millisecond {
+				d := b.take(testStart.Add(at))
+				if d.Allowed {
+					allowed++
+					continue
+				}
+				require.Positive(t, d.RetryAfter)
+			}
+			// sliding_window оценивает прошлое окно с запасом и пропускает чуть меньше остальных
+			require.GreaterOrEqual(t, allowed, 2*policy.Limit)
+			require.LessOrEqual(t, allowed, 3*policy.Limit+policy.burst())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package rate_limiter
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var benchAlgorithms = []string{AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow, AlgorithmGCRA}
+
+// BenchmarkKeyedLimiter_SingleKey все горутины бьются в одно ведро
+func BenchmarkKeyedLimiter_SingleKey(b *testing.B) {
+	policy := Policy{Limit: 100, Window: time.Second}
+
+	for _, algorithm := range benchAlgorithms {
+		b.Run(algorithm, func(b *testing.B) {
+			kl, err := NewKeyedLimiter(algorithm, 10000, time.Minute)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					kl.Take("client", policy)
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkKeyedLimiter_ManyKeys запросы размазаны по множеству клиентов, часть ключей вытесняется из LRU
+func BenchmarkKeyedLimiter_ManyKeys(b *testing.B) {
+	policy := Policy{Limit: 100, Window: time.Second}
+
+	keys := make([]string, 20000)
+	for i := range keys {
+		keys[i] = "ip:10.0." + strconv.Itoa(i/256) + "." + strconv.Itoa(i%256)
+	}
+
+	for _, algorithm := range benchAlgorithms {
+		b.Run(algorithm, func(b *testing.B) {
+			kl, err := NewKeyedLimiter(algorithm, 10000, time.Minute)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			var seq atomic.Uint64
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					kl.Take(keys[seq.Add(1)%uint64(len(keys))], policy)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package rate_limiter
+
+import (
+	"context"
+	"log/slog"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/model"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// как часто удалять из базы закончившиеся окна
+const cleanupInterval = time.Minute
+
+// counterStore общие счетчики окон, реализация в repository/postgres
+type counterStore interface {
+	AddMany(ctx context.Context, counters []model.RateLimitCounter) ([]model.RateLimitCounter, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// DistributedLimiter общий лимит для всех реплик: счетчики фиксированных окон хранятся в базе.
+// Запросы считаются локально и раз в syncInterval отправляются в базу одной пачкой, в ответ приходят
+// суммы всех реплик. Между синхронизациями каждая реплика видит только свои новые запросы,
+// поэтому лимит может быть превышен на то, что другие реплики успели пропустить за syncInterval.
+// Пока база недоступна, решения принимает локальный лимитер, как будто реплика одна.
+// Burst политики здесь не учитывается, лимит - Limit запросов в окне
+type DistributedLimiter struct {
+	store        counterStore
+	local        *KeyedLimiter
+	syncInterval time.Duration
+	maxKeys      int
+	log          logger.LoggerAdapter
+	now          func() time.Time
+
+	mu          sync.Mutex
+	windows     map[windowKey]*windowCounter
+	degraded    atomic.Bool
+	lastCleanup time.Time
+}
+
+type windowKey struct {
+	key   string
+	start int64 // UnixNano начала окна
+}
+
+type windowCounter struct {
+	limit   int
+	start   time.Time
+	end     time.Time
+	synced  int // сумма всех реплик на момент последней синхронизации
+	pending int // запросы этой реплики, еще не отправленные в базу
+}
+
+// NewDistributedLimiter local используется, пока база недоступна, и для ключей сверх maxKeys
+func NewDistributedLimiter(store counterStore, local *KeyedLimiter, syncInterval time.Duration, maxKeys int, log logger.LoggerAdapter) *DistributedLimiter {
+	return &DistributedLimiter{
+		store:        store,
+		local:        local,
+		syncInterval: syncInterval,
+		maxKeys:      maxKeys,
+		log:          log.With(slog.String("component", "middleware/rate_limiter/distributed")),
+		now:          time.Now,
+		windows:      make(map[windowKey]*windowCounter),
+	}
+}
+
+func (dl *DistributedLimiter) Take(key string, p Policy) Decision {
+	now := dl.now()
+	// окна выровнены по времени, а не по первому запросу, чтобы у всех реплик они совпадали
+	start := now.Truncate(p.Window)
+	wk := windowKey{key: key, start: start.UnixNano()}
+
+	dl.mu.Lock()
+	c, ok := dl.windows[wk]
+	if !ok {
+		if dl.maxKeys > 0 && len(dl.windows) >= dl.maxKeys {
+			dl.mu.Unlock()
+			return dl.local.Take(key, p)
+		}
+		c = &windowCounter{limit: p.Limit, start: start, end: start.Add(p.Window)}
+		dl.windows[wk] = c
+	}
+
+	if dl.degraded.Load() {
+		d := dl.local.Take(key, p)
+		// запросы продолжаем считать, чтобы после восстановления базы они попали в общий счетчик
+		if d.Allowed {
+			c.pending++
+		}
+		dl.mu.Unlock()
+		return d
+	}
+
+	d := Decision{Limit: c.limit, ResetAfter: c.end.Sub(now)}
+	if c.synced+c.pending < c.limit {
+		c.pending++
+		d.Allowed = true
+	} else {
+		d.RetryAfter = d.ResetAfter
+	}
+	d.Remaining = max(0, c.limit-c.synced-c.pending)
+	dl.mu.Unlock()
+	return d
+}
+
+// Start синхронизирует счетчики с базой до отмены ctx
+func (dl *DistributedLimiter) Start(ctx context.Context) {
+	ticker := time.NewTicker(dl.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// последние запросы тоже должны попасть в общий счетчик
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dl.syncInterval)
+			dl.sync(flushCtx)
+			cancel()
+			dl.log.Info("distributed rate limiter gracefully stopped")
+			return
+		case <-ticker.C:
+			syncCtx, cancel := context.WithTimeout(ctx, dl.syncInterval)
+			dl.sync(syncCtx)
+			cancel()
+		}
+	}
+}
+
+func (dl *DistributedLimiter) sync(ctx context.Context) {
+	now := dl.now()
+
+	dl.mu.Lock()
+	deltas := make([]model.RateLimitCounter, 0, len(dl.windows))
+	for wk, c := range dl.windows {
+		if !c.end.After(now) && c.pending == 0 {
+			delete(dl.windows, wk)
+			continue
+		}
+		// окна без новых запросов тоже отправляем с нулем, чтобы узнать, сколько пропустили другие реплики
+		deltas = append(deltas, model.RateLimitCounter{Key: wk.key, WindowStart: c.start, ExpiresAt: c.end, Count: c.pending})
+		c.pending = 0
+	}
+	dl.mu.Unlock()
+
+	if len(deltas) == 0 {
+		return
+	}
+
+	totals, err := dl.store.AddMany(ctx, deltas)
+	if err != nil {
+		dl.restore(deltas, now)
+		if !dl.degraded.Swap(true) {
+			dl.log.Warn("rate limit storage is unavailable, falling back to local limiter", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if dl.degraded.Swap(false) {
+		dl.log.Info("rate limit storage is available again")
+	}
+
+	dl.mu.Lock()
+	for _, total := range totals {
+		if c, ok := dl.windows[windowKey{key: total.Key, start: total.WindowStart.UnixNano()}]; ok {
+			c.synced = total.Count
+		}
+	}
+	// закончившиеся окна уже отправлены, больше они не нужны
+	for wk, c := range dl.windows {
+		if !c.end.After(now) && c.pending == 0 {
+			delete(dl.windows, wk)
+		}
+	}
+	dl.mu.Unlock()
+
+	if now.Sub(dl.lastCleanup) >= cleanupInterval {
+		dl.lastCleanup = now
+		if _, err = dl.store.DeleteExpired(ctx, now); err != nil {
+			dl.log.Warn("delete expired rate limit counters", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// restore возвращает неотправленные запросы, чтобы отправить их при следующей синхронизации.
+// Закончившиеся окна на лимит уже не влияют, их просто забываем
+func (dl *DistributedLimiter) restore(deltas []model.RateLimitCounter, now time.Time) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	for _, delta := range deltas {
+		wk := windowKey{key: delta.Key, start: delta.WindowStart.UnixNano()}
+		c, ok := dl.windows[wk]
+		if !ok {
+			continue
+		}
+		if !c.end.After(now) {
+			delete(dl.windows, wk)
+			continue
+		}
+		c.pending += delta.Count
+	}
+}
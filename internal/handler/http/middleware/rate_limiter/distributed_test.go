@@ -0,0 +1,141 @@
+package rate_limiter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"service-order-avito/internal/domain/model"
+	"testing"
+	"time"
+)
+
+// stubStore эмулирует базу: общие счетчики, к которым могут прибавлять и другие реплики
+type stubStore struct {
+	counters map[windowKey]int
+	err      error
+	sent     [][]model.RateLimitCounter
+	deleted  int
+}
+
+func newStubStore() *stubStore {
+	return &stubStore{counters: map[windowKey]int{}}
+}
+
+func (s *stubStore) AddMany(_ context.Context, counters []model.RateLimitCounter) ([]model.RateLimitCounter, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.sent = append(s.sent, counters)
+	totals := make([]model.RateLimitCounter, 0, len(counters))
+	for _, c := range counters {
+		wk := windowKey{key: c.Key, start: c.WindowStart.UnixNano()}
+		s.counters[wk] += c.Count
+		c.Count = s.counters[wk]
+		totals = append(totals, c)
+	}
+	return totals, nil
+}
+
+func (s *stubStore) DeleteExpired(context.Context, time.Time) (int, error) {
+	s.deleted++
+	return 0, s.err
+}
+
+func newTestDistributedLimiter(t *testing.T, store *stubStore) (*DistributedLimiter, *fakeClock) {
+	local, clock := newTestKeyedLimiter(t, AlgorithmTokenBucket, 100, time.Hour)
+	dl := NewDistributedLimiter(store, local, time.Second, 100, nopLogger{})
+	dl.now = clock.now
+	return dl, clock
+}
+
+func TestDistributedLimiter_SharedCounter(t *testing.T) {
+	store := newStubStore()
+	dl, clock := newTestDistributedLimiter(t, store)
+	policy := Policy{Limit: 5, Window: time.Minute}
+
+	d := dl.Take("a", policy)
+	require.True(t, d.Allowed)
+	require.Equal(t, 4, d.Remaining)
+	require.Equal(t, time.Duration(0), d.ResetAfter%time.Second)
+
+	dl.Take("a", policy)
+	dl.sync(context.Background())
+	require.Len(t, store.sent, 1)
+	require.Equal(t, 2, store.sent[0][0].Count)
+
+	// другая реплика за это время пропустила еще 2 запроса
+	start := clock.now().Truncate(time.Minute)
+	store.counters[windowKey{key: "a", start: start.UnixNano()}] += 2
+
+	d = dl.Take("a", policy)
+	require.True(t, d.Allowed)
+	require.Equal(t, 2, d.Remaining, "replica doesn't know about others until sync")
+
+	dl.sync(context.Background())
+	require.Equal(t, 1, store.sent[1][0].Count)
+
+	d = dl.Take("a", policy)
+	require.False(t, d.Allowed)
+	require.Equal(t, 0, d.Remaining)
+	require.Equal(t, start.Add(time.Minute).Sub(clock.now()), d.RetryAfter)
+
+	// в следующем окне счет начинается заново
+	clock.add(time.Minute)
+	require.True(t, dl.Take("a", policy).Allowed)
+}
+
+func TestDistributedLimiter_DropsFinishedWindows(t *testing.T) {
+	store := newStubStore()
+	dl, clock := newTestDistributedLimiter(t, store)
+
+	dl.Take("a", Policy{Limit: 5, Window: time.Second})
+	clock.add(2 * time.Second)
+
+	// запросы закончившегося окна еще отправляются, после этого окно забывается
+	dl.sync(context.Background())
+	require.Len(t, store.sent, 1)
+	require.Empty(t, dl.windows)
+
+	dl.sync(context.Background())
+	require.Len(t, store.sent, 1)
+}
+
+func TestDistributedLimiter_FallbackToLocal(t *testing.T) {
+	store := newStubStore()
+	dl, clock := newTestDistributedLimiter(t, store)
+	policy := Policy{Limit: 2, Window: time.Minute}
+
+	dl.Take("a", policy)
+	store.err = errors.New("connection refused")
+	dl.sync(context.Background())
+	require.True(t, dl.degraded.Load())
+
+	// пока база недоступна, работает локальный token bucket со своими счетчиками
+	d := dl.Take("a", policy)
+	require.True(t, d.Allowed)
+	require.Equal(t, 1, d.Remaining)
+	require.True(t, dl.Take("a", policy).Allowed)
+	require.False(t, dl.Take("a", policy).Allowed)
+
+	// после восстановления в базу уходят и запросы, которые не удалось отправить, и сделанные без нее
+	store.err = nil
+	clock.add(time.Second)
+	dl.sync(context.Background())
+	require.False(t, dl.degraded.Load())
+	require.Equal(t, 3, store.sent[0][0].Count)
+
+	require.False(t, dl.Take("a", policy).Allowed)
+}
+
+func TestDistributedLimiter_MaxKeys(t *testing.T) {
+	store := newStubStore()
+	local, _ := newTestKeyedLimiter(t, AlgorithmTokenBucket, 100, time.Hour)
+	dl := NewDistributedLimiter(store, local, time.Second, 1, nopLogger{})
+
+	policy := Policy{Limit: 1, Window: time.Minute}
+	require.True(t, dl.Take("a", policy).Allowed)
+	// ключ сверх лимита считается локально
+	require.True(t, dl.Take("b", policy).Allowed)
+	require.Len(t, dl.windows, 1)
+	require.Equal(t, 1, local.Len())
+}
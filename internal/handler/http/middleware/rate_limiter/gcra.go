@@ -0,0 +1,48 @@
+package rate_limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// gcra generic cell rate algorithm. Хранит только theoretical arrival time (tat) - время,
+// когда ведро опустело бы при равномерном потоке. Запрос пропускается, если tat после него
+// уходит вперед не больше чем на burst интервалов
+type gcra struct {
+	interval time.Duration // между запросами при равномерном потоке
+	burst    int
+	tat      time.Time
+	mu       sync.Mutex
+}
+
+func newGCRA(p Policy, now time.Time) *gcra {
+	return &gcra{
+		interval: p.emissionInterval(),
+		burst:    p.burst(),
+		tat:      now,
+	}
+}
+
+func (g *gcra) take(now time.Time) Decision {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tat := g.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	capacity := time.Duration(g.burst) * g.interval
+
+	d := Decision{Limit: g.burst}
+	next := tat.Add(g.interval)
+	if allowAt := next.Add(-capacity); allowAt.After(now) {
+		d.RetryAfter = allowAt.Sub(now)
+	} else {
+		tat = next
+		g.tat = next
+		d.Allowed = true
+	}
+	d.Remaining = int((capacity - tat.Sub(now)) / g.interval)
+	d.ResetAfter = tat.Sub(now)
+	return d
+}
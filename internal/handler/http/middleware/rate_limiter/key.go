@@ -0,0 +1,145 @@
+package rate_limiter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	KeyIP     = "ip"
+	KeyAPIKey = "api_key"
+	KeyRoute  = "route"
+)
+
+// KeyFunc определяет, чье ведро расходует запрос
+type KeyFunc func(r *http.Request) string
+
+// NewKeyFunc выбирает способ определения клиента по значению из конфига.
+// knownAPIKey решает, какие API-ключи получают свое ведро, остальные считаются по адресу
+func NewKeyFunc(kind, apiKeyHeader string, trustedProxies []netip.Prefix, knownAPIKey func(string) bool) (KeyFunc, error) {
+	switch kind {
+	case KeyIP:
+		return KeyByIP(trustedProxies), nil
+	case KeyAPIKey:
+		return KeyByHeader(apiKeyHeader, knownAPIKey, KeyByIP(trustedProxies)), nil
+	case KeyRoute:
+		return KeyByRoute, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limiter key %q", kind)
+	}
+}
+
+// ParseTrustedProxies разбирает список CIDR, одиночный адрес считается сетью из одного адреса
+func ParseTrustedProxies(items []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", item, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", item, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// KeyByIP ключ по адресу клиента. X-Forwarded-For учитывается, только если запрос пришел
+// от доверенного прокси: цепочка читается справа налево до первого недоверенного адреса,
+// левее него значения мог подставить сам клиент
+func KeyByIP(trustedProxies []netip.Prefix) KeyFunc {
+	trusted := func(addr netip.Addr) bool {
+		for _, prefix := range trustedProxies {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(r *http.Request) string {
+		remote, ok := parseAddr(r.RemoteAddr)
+		if !ok {
+			return "ip:" + r.RemoteAddr
+		}
+		if !trusted(remote) {
+			return "ip:" + remote.String()
+		}
+
+		client := remote
+		forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(forwarded) - 1; i >= 0; i-- {
+			addr, ok := parseAddr(strings.TrimSpace(forwarded[i]))
+			if !ok {
+				break
+			}
+			client = addr
+			if !trusted(addr) {
+				break
+			}
+		}
+		return "ip:" + client.String()
+	}
+}
+
+// KeyByHeader ключ по значению заголовка (API-ключу). В памяти держим только хеш ключа.
+// Лимитер стоит до авторизации, поэтому свое ведро получают только известные ключи (known).
+// Запросы без заголовка и с непроверенным ключом делят лимит по fallback: иначе клиент со случайным
+// ключом в каждом запросе не ограничен, вытесняет настоящих клиентов и нагружает проверку ключей в базе
+func KeyByHeader(name string, known func(string) bool, fallback KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		value := strings.TrimSpace(r.Header.Get(name))
+		if value == "" || !known(value) {
+			return fallback(r)
+		}
+		sum := sha256.Sum256([]byte(value))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+}
+
+// KeyByRoute один общий лимит на роут для всех клиентов
+func KeyByRoute(r *http.Request) string {
+	return "route:" + routeKey(r.Method, routePattern(r))
+}
+
+// routePattern шаблон роута chi. Middleware выполняется до роутинга, поэтому шаблон
+// ищем по дереву роутера сами, без изменения контекста запроса
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return r.URL.Path
+	}
+	if pattern := rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path); pattern != "" {
+		return pattern
+	}
+	// неизвестные пути не плодят отдельных ключей
+	return "*"
+}
+
+func parseAddr(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,142 @@
+package rate_limiter
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestKeyByIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	key := KeyByIP(trusted)
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{name: "direct client", remote: "203.0.113.7:5123", want: "ip:203.0.113.7"},
+		{name: "untrusted proxy is ignored", remote: "203.0.113.7:5123", forwarded: []string{"1.1.1.1"}, want: "ip:203.0.113.7"},
+		{name: "trusted proxy", remote: "10.0.0.2:80", forwarded: []string{"198.51.100.4"}, want: "ip:198.51.100.4"},
+		{name: "spoofed left part", remote: "10.0.0.2:80", forwarded: []string{"1.1.1.1, 198.51.100.4, 192.168.1.1"}, want: "ip:198.51.100.4"},
+		{name: "several headers", remote: "10.0.0.2:80", forwarded: []string{"1.1.1.1", "198.51.100.4"}, want: "ip:198.51.100.4"},
+		{name: "garbage in chain", remote: "10.0.0.2:80", forwarded: []string{"1.1.1.1, nonsense"}, want: "ip:10.0.0.2"},
+		{name: "ipv6", remote: "[2001:db8::1]:443", want: "ip:2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			require.Equal(t, tt.want, key(r))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies([]string{" 10.1.2.3/8 ", "::1", ""})
+	require.NoError(t, err)
+	require.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}, prefixes)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+}
+
+func TestKeyByHeader(t *testing.T) {
+	known := func(key string) bool { return key == "secret" }
+	key := KeyByHeader("X-API-Key", known, KeyByIP(nil))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:5123"
+	require.Equal(t, "ip:203.0.113.7", key(r))
+
+	// непроверенные ключи не получают своих ведер
+	for _, unknown := range []string{"random-1", "random-2"} {
+		r.Header.Set("X-API-Key", unknown)
+		require.Equal(t, "ip:203.0.113.7", key(r))
+	}
+
+	r.Header.Set("X-API-Key", "secret")
+	k := key(r)
+	require.NotContains(t, k, "secret")
+
+	other := httptest.NewRequest(http.MethodGet, "/", nil)
+	other.RemoteAddr = "198.51.100.4:80"
+	other.Header.Set("X-API-Key", "secret")
+	require.Equal(t, k, key(other), "same key from different addresses shares a bucket")
+}
+
+func TestParseRoutePolicies(t *testing.T) {
+	policies, err := ParseRoutePolicies([]string{"post /couriers/import=2/1m", " GET /courier/{id} = 10/1s "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]Policy{
+		"POST /couriers/import": {Limit: 2, Window: time.Minute},
+		"GET /courier/{id}":     {Limit: 10, Window: time.Second},
+	}, policies)
+
+	for _, bad := range []string{"/couriers=1/1s", "GET /couriers", "GET /couriers=1", "GET /couriers=0/1s", "GET /couriers=1/soon"} {
+		_, err = ParseRoutePolicies([]string{bad})
+		require.Error(t, err, bad)
+	}
+}
+
+func TestRequestLimiter_RoutePolicies(t *testing.T) {
+	routes, err := ParseRoutePolicies([]string{"POST /couriers/import=1/1m"})
+	require.NoError(t, err)
+
+	kl, _ := newTestKeyedLimiter(t, AlgorithmTokenBucket, 10, time.Minute)
+	rl := NewRequestLimiter(kl, KeyByIP(nil), Policy{Limit: 5, Window: time.Second}, routes)
+
+	var got []Decision
+	router := chi.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, rl.Limit(r))
+		})
+	})
+	router.Route("/couriers", func(r chi.Router) {
+		r.Post("/import", func(http.ResponseWriter, *http.Request) {})
+		r.Get("/", func(http.ResponseWriter, *http.Request) {})
+	})
+	router.Get("/courier/{id}", func(http.ResponseWriter, *http.Request) {})
+
+	for _, target := range []string{"/couriers/import", "/couriers/import"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, target, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/couriers/", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/courier/7", nil))
+
+	require.Len(t, got, 4)
+	require.True(t, got[0].Allowed)
+	require.Equal(t, 1, got[0].Limit)
+	require.False(t, got[1].Allowed)
+	// остальные роуты делят общий лимит и не зависят от import
+	require.True(t, got[2].Allowed)
+	require.Equal(t, 4, got[2].Remaining)
+	require.Equal(t, 3, got[3].Remaining)
+}
+
+func TestKeyByRoute(t *testing.T) {
+	var keys []string
+	router := chi.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, KeyByRoute(r))
+		})
+	})
+	router.Get("/courier/{id}", func(http.ResponseWriter, *http.Request) {})
+
+	for _, target := range []string{"/courier/1", "/courier/2", "/unknown/1", "/unknown/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	require.Equal(t, []string{"route:GET /courier/{id}", "route:GET /courier/{id}", "route:GET *", "route:GET *"}, keys)
+}
@@ -0,0 +1,92 @@
+package rate_limiter
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// KeyedLimiter держит отдельное ведро на каждый ключ (клиент, роут).
+// Ведра хранятся в LRU: давно неактивные удаляются через idleTTL, а при переполнении
+// maxKeys вытесняется самое старое. Удаленное ведро при следующем запросе создается полным
+type KeyedLimiter struct {
+	newBucket bucketFactory
+	maxKeys   int
+	idleTTL   time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	lru     *list.List // спереди недавно использованные
+}
+
+type keyedBucket struct {
+	key      string
+	bucket   bucket
+	lastSeen time.Time
+}
+
+// NewKeyedLimiter algorithm - одно из Algorithm*, выбирает, как считается лимит каждого ключа
+func NewKeyedLimiter(algorithm string, maxKeys int, idleTTL time.Duration) (*KeyedLimiter, error) {
+	newBucket, err := newBucketFactory(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyedLimiter{
+		newBucket: newBucket,
+		maxKeys:   maxKeys,
+		idleTTL:   idleTTL,
+		now:       time.Now,
+		buckets:   make(map[string]*list.Element),
+		lru:       list.New(),
+	}, nil
+}
+
+// Take списывает один запрос из ведра ключа, ведро создается по политике при первом обращении
+func (kl *KeyedLimiter) Take(key string, p Policy) Decision {
+	now := kl.now()
+
+	kl.mu.Lock()
+	kl.evictIdle(now)
+
+	var entry *keyedBucket
+	if el, ok := kl.buckets[key]; ok {
+		kl.lru.MoveToFront(el)
+		entry = el.Value.(*keyedBucket)
+	} else {
+		if kl.maxKeys > 0 && kl.lru.Len() >= kl.maxKeys {
+			kl.remove(kl.lru.Back())
+		}
+		entry = &keyedBucket{key: key, bucket: kl.newBucket(p, now)}
+		kl.buckets[key] = kl.lru.PushFront(entry)
+	}
+	entry.lastSeen = now
+	kl.mu.Unlock()
+
+	return entry.bucket.take(now)
+}
+
+// Len количество ведер в памяти
+func (kl *KeyedLimiter) Len() int {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	return kl.lru.Len()
+}
+
+// evictIdle сзади списка лежат самые давно использованные ведра, поэтому идем с конца до первого живого
+func (kl *KeyedLimiter) evictIdle(now time.Time) {
+	if kl.idleTTL <= 0 {
+		return
+	}
+	for el := kl.lru.Back(); el != nil; el = kl.lru.Back() {
+		if now.Sub(el.Value.(*keyedBucket).lastSeen) < kl.idleTTL {
+			return
+		}
+		kl.remove(el)
+	}
+}
+
+func (kl *KeyedLimiter) remove(el *list.Element) {
+	kl.lru.Remove(el)
+	delete(kl.buckets, el.Value.(*keyedBucket).key)
+}
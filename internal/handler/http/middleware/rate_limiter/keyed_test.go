@@ -0,0 +1,97 @@
+package rate_limiter
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time      { return c.t }
+func (c *fakeClock) add(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestKeyedLimiter(t testing.TB, algorithm string, maxKeys int, idleTTL time.Duration) (*KeyedLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	kl, err := NewKeyedLimiter(algorithm, maxKeys, idleTTL)
+	require.NoError(t, err)
+	kl.now = clock.now
+	return kl, clock
+}
+
+func TestKeyedLimiter_Take(t *testing.T) {
+	kl, clock := newTestKeyedLimiter(t, AlgorithmTokenBucket, 10, time.Minute)
+	policy := Policy{Limit: 2, Window: time.Second}
+
+	d := kl.Take("a", policy)
+	require.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: 500 * time.Millisecond}, d)
+
+	d = kl.Take("a", policy)
+	require.True(t, d.Allowed)
+	require.Equal(t, 0, d.Remaining)
+	require.Equal(t, time.Second, d.ResetAfter)
+
+	d = kl.Take("a", policy)
+	require.False(t, d.Allowed)
+	require.Equal(t, 500*time.Millisecond, d.RetryAfter)
+
+	// у другого клиента свое ведро
+	require.True(t, kl.Take("b", policy).Allowed)
+
+	clock.add(300 * time.Millisecond)
+	d = kl.Take("a", policy)
+	require.False(t, d.Allowed)
+	require.Equal(t, 200*time.Millisecond, d.RetryAfter)
+
+	clock.add(200 * time.Millisecond)
+	d = kl.Take("a", policy)
+	require.True(t, d.Allowed)
+	require.Equal(t, 0, d.Remaining)
+}
+
+func TestKeyedLimiter_Burst(t *testing.T) {
+	kl, clock := newTestKeyedLimiter(t, AlgorithmTokenBucket, 10, time.Hour)
+	policy := Policy{Limit: 1, Window: time.Minute, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		require.True(t, kl.Take("a", policy).Allowed)
+	}
+	d := kl.Take("a", policy)
+	require.False(t, d.Allowed)
+	require.Equal(t, 3, d.Limit)
+	require.Equal(t, time.Minute, d.RetryAfter)
+	require.Equal(t, 3*time.Minute, d.ResetAfter)
+
+	clock.add(time.Minute)
+	require.True(t, kl.Take("a", policy).Allowed)
+	require.False(t, kl.Take("a", policy).Allowed)
+}
+
+func TestKeyedLimiter_EvictsLeastRecentlyUsed(t *testing.T) {
+	kl, _ := newTestKeyedLimiter(t, AlgorithmTokenBucket, 2, time.Minute)
+	policy := Policy{Limit: 1, Window: time.Hour}
+
+	require.True(t, kl.Take("a", policy).Allowed)
+	require.True(t, kl.Take("b", policy).Allowed)
+	// a использован позже b, поэтому при переполнении вытесняется b
+	require.False(t, kl.Take("a", policy).Allowed)
+	require.True(t, kl.Take("c", policy).Allowed)
+	require.Equal(t, 2, kl.Len())
+
+	require.False(t, kl.Take("a", policy).Allowed)
+	require.True(t, kl.Take("b", policy).Allowed, "evicted bucket starts full")
+}
+
+func TestKeyedLimiter_EvictsIdle(t *testing.T) {
+	kl, clock := newTestKeyedLimiter(t, AlgorithmTokenBucket, 10, time.Minute)
+	policy := Policy{Limit: 1, Window: time.Hour}
+
+	kl.Take("a", policy)
+	clock.add(30 * time.Second)
+	kl.Take("b", policy)
+	require.Equal(t, 2, kl.Len())
+
+	clock.add(45 * time.Second)
+	kl.Take("b", policy)
+	require.Equal(t, 1, kl.Len())
+}
@@ -0,0 +1,76 @@
+package rate_limiter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy лимит для одного ключа: Limit запросов за Window, из них до Burst можно сделать сразу.
+// Burst = 0 значит Burst = Limit
+type Policy struct {
+	Limit  int
+	Window time.Duration
+	Burst  int
+}
+
+func (p Policy) burst() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// Decision результат проверки лимита, из него строятся заголовки X-RateLimit-*
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // через сколько лимит восстановится полностью
+	RetryAfter time.Duration // через сколько можно повторить запрос, только для Allowed=false
+}
+
+// ParseRoutePolicies разбирает лимиты отдельных роутов из конфига.
+// Формат элемента: "METHOD /pattern=LIMIT/WINDOW", например "POST /couriers/import=2/1m".
+// Pattern - шаблон роута chi, как он объявлен в роутере
+func ParseRoutePolicies(items []string) (map[string]Policy, error) {
+	policies := make(map[string]Policy, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		route, limit, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("route policy %q: expected METHOD /pattern=LIMIT/WINDOW", item)
+		}
+
+		method, pattern, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("route policy %q: expected METHOD /pattern", item)
+		}
+
+		count, window, ok := strings.Cut(limit, "/")
+		if !ok {
+			return nil, fmt.Errorf("route policy %q: expected LIMIT/WINDOW", item)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("route policy %q: invalid limit", item)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(window))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("route policy %q: invalid window", item)
+		}
+
+		policies[routeKey(method, strings.TrimSpace(pattern))] = Policy{Limit: n, Window: d}
+	}
+	return policies, nil
+}
+
+func routeKey(method, pattern string) string {
+	return strings.ToUpper(method) + " " + pattern
+}
@@ -2,32 +2,40 @@ package rate_limiter
 
 import (
 	"log/slog"
+	"math"
 	"net/http"
+	"service-order-avito/internal/adapters"
 	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/errors/server"
+	"strconv"
+	"time"
 )
 
 type rateLimiter interface {
-	Allow() bool
+	Limit(r *http.Request) Decision
 }
 
+// WithRateLimiter выставляет X-RateLimit-* на каждый ответ, при превышении лимита отвечает 429 с Retry-After
 func WithRateLimiter(limiter rateLimiter, log logger.LoggerAdapter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		log = log.With(
 			slog.String("component", "middleware/rate_limiter"),
 		)
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !limiter.Allow() {
-				log.Info("rate limit exceeded",
+			d := limiter.Limit(r)
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(d.ResetAfter)))
+
+			if !d.Allowed {
+				log.InfoContext(r.Context(), "rate limit exceeded",
 					slog.String("method", r.Method),
 					slog.String("url", r.URL.String()),
 				)
 
-				w.Header().Set("X-RateLimit-Limit", "10")
-				w.Header().Set("X-RateLimit-Remaining", "0")
-				w.WriteHeader(http.StatusTooManyRequests)
-				if _, err := w.Write([]byte("rate limit exceeded")); err != nil {
-					// dummy
-				}
+				w.Header().Set("Retry-After", strconv.Itoa(max(ceilSeconds(d.RetryAfter), 1)))
+				adapters.WriteError(w, r, server.ErrRateLimitExceeded, http.StatusTooManyRequests)
 				return
 			}
 
@@ -35,3 +43,11 @@ func WithRateLimiter(limiter rateLimiter, log logger.LoggerAdapter) func(http.Ha
 		})
 	}
 }
+
+// ceilSeconds заголовки принимают целые секунды, округляем вверх, чтобы клиент не пришел раньше времени
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,63 @@
+package rate_limiter
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type stubLimiter struct{ d Decision }
+
+func (s stubLimiter) Limit(*http.Request) Decision { return s.d }
+
+func TestWithRateLimiter(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	t.Run("allowed", func(t *testing.T) {
+		limiter := stubLimiter{Decision{Allowed: true, Limit: 5, Remaining: 3, ResetAfter: 1500 * time.Millisecond}}
+		rec := httptest.NewRecorder()
+		WithRateLimiter(limiter, nopLogger{})(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, "5", rec.Header().Get("X-RateLimit-Limit"))
+		require.Equal(t, "3", rec.Header().Get("X-RateLimit-Remaining"))
+		require.Equal(t, "2", rec.Header().Get("X-RateLimit-Reset"))
+		require.Empty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		limiter := stubLimiter{Decision{Limit: 5, ResetAfter: 4 * time.Second, RetryAfter: 200 * time.Millisecond}}
+		rec := httptest.NewRecorder()
+		WithRateLimiter(limiter, nopLogger{})(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		require.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+		require.Equal(t, "4", rec.Header().Get("X-RateLimit-Reset"))
+		require.Equal(t, "1", rec.Header().Get("Retry-After"))
+		require.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+		var body dto.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+		require.Equal(t, server.ErrRateLimitExceeded, body.Detail)
+	})
+}
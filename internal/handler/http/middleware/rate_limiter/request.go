@@ -0,0 +1,39 @@
+package rate_limiter
+
+import "net/http"
+
+// ClientLimiter лимиты по ключам: KeyedLimiter в памяти реплики или DistributedLimiter, общий для всех реплик
+type ClientLimiter interface {
+	Take(key string, p Policy) Decision
+}
+
+// RequestLimiter выбирает для запроса политику и ведро: политика берется по роуту,
+// а ведро по паре (политика, клиент), поэтому у роута с отдельной политикой свой счетчик
+type RequestLimiter struct {
+	limiter  ClientLimiter
+	key      KeyFunc
+	fallback Policy
+	routes   map[string]Policy // "METHOD /pattern" -> политика
+}
+
+// NewRequestLimiter fallback применяется ко всем роутам, которых нет в routes
+func NewRequestLimiter(limiter ClientLimiter, key KeyFunc, fallback Policy, routes map[string]Policy) *RequestLimiter {
+	return &RequestLimiter{
+		limiter:  limiter,
+		key:      key,
+		fallback: fallback,
+		routes:   routes,
+	}
+}
+
+func (rl *RequestLimiter) Limit(r *http.Request) Decision {
+	name := "*"
+	policy := rl.fallback
+	if len(rl.routes) > 0 {
+		route := routeKey(r.Method, routePattern(r))
+		if p, ok := rl.routes[route]; ok {
+			name, policy = route, p
+		}
+	}
+	return rl.limiter.Take(name+"|"+rl.key(r), policy)
+}
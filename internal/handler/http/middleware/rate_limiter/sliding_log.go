@@ -0,0 +1,52 @@
+package rate_limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// slidingLog точный лимит: хранит время каждого пропущенного запроса за последнее окно.
+// Память O(Limit) на ключ, поэтому для больших лимитов лучше sliding_window
+type slidingLog struct {
+	limit  int
+	window time.Duration
+	log    []time.Time // по возрастанию, не длиннее limit
+	mu     sync.Mutex
+}
+
+func newSlidingLog(p Policy) *slidingLog {
+	return &slidingLog{
+		limit:  p.Limit,
+		window: p.Window,
+	}
+}
+
+func (sl *slidingLog) take(now time.Time) Decision {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	// параллельные запросы могут прийти со временем чуть раньше последнего, лог должен оставаться упорядоченным
+	if n := len(sl.log); n > 0 && now.Before(sl.log[n-1]) {
+		now = sl.log[n-1]
+	}
+
+	// выкидываем запросы, вышедшие из окна (now - window, now]
+	expired := 0
+	for expired < len(sl.log) && !sl.log[expired].After(now.Add(-sl.window)) {
+		expired++
+	}
+	sl.log = append(sl.log[:0], sl.log[expired:]...)
+
+	d := Decision{Limit: sl.limit}
+	if len(sl.log) < sl.limit {
+		sl.log = append(sl.log, now)
+		d.Allowed = true
+	} else {
+		d.RetryAfter = sl.log[0].Add(sl.window).Sub(now)
+	}
+	d.Remaining = sl.limit - len(sl.log)
+	if len(sl.log) > 0 {
+		d.ResetAfter = sl.log[len(sl.log)-1].Add(sl.window).Sub(now)
+	}
+	return d
+}
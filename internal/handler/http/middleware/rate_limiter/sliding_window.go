@@ -0,0 +1,82 @@
+package rate_limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// slidingWindow приближение скользящего окна по двум счетчикам: запросы прошлого окна
+// учитываются с весом той части, что еще попадает в скользящее окно
+type slidingWindow struct {
+	limit  float64
+	window time.Duration
+	start  time.Time // начало текущего окна
+	prev   float64
+	curr   float64
+	mu     sync.Mutex
+}
+
+func newSlidingWindow(p Policy, now time.Time) *slidingWindow {
+	return &slidingWindow{
+		limit:  float64(p.Limit),
+		window: p.Window,
+		start:  now,
+	}
+}
+
+func (sw *slidingWindow) take(now time.Time) Decision {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.advance(now)
+
+	d := Decision{Limit: int(sw.limit)}
+	if sw.estimate(now)+1 <= sw.limit {
+		sw.curr++
+		d.Allowed = true
+	} else {
+		d.RetryAfter = sw.retryAfter(now)
+	}
+	d.Remaining = int(math.Max(0, math.Floor(sw.limit-sw.estimate(now))))
+
+	// текущее окно перестает влиять на оценку через окно после своего конца, прошлое - в конце текущего
+	switch {
+	case sw.curr > 0:
+		d.ResetAfter = sw.start.Add(2 * sw.window).Sub(now)
+	case sw.prev > 0:
+		d.ResetAfter = sw.start.Add(sw.window).Sub(now)
+	}
+	return d
+}
+
+// advance сдвигает окна, границы окон кратны window от создания ключа
+func (sw *slidingWindow) advance(now time.Time) {
+	passed := now.Sub(sw.start) / sw.window
+	switch {
+	case passed <= 0:
+		return
+	case passed == 1:
+		sw.prev, sw.curr = sw.curr, 0
+	default:
+		sw.prev, sw.curr = 0, 0
+	}
+	sw.start = sw.start.Add(passed * sw.window)
+}
+
+func (sw *slidingWindow) estimate(now time.Time) float64 {
+	weight := 1 - float64(now.Sub(sw.start))/float64(sw.window)
+	return sw.prev*weight + sw.curr
+}
+
+// retryAfter когда оценка опустится до limit-1. Если не хватает затухания прошлого окна,
+// ждем следующего окна, где текущий счетчик станет прошлым
+func (sw *slidingWindow) retryAfter(now time.Time) time.Duration {
+	free := sw.limit - 1
+	if sw.curr <= free && sw.prev > 0 {
+		at := sw.start.Add(time.Duration(math.Ceil(float64(sw.window) * (1 - (free-sw.curr)/sw.prev))))
+		return at.Sub(now)
+	}
+	at := sw.start.Add(sw.window + time.Duration(math.Ceil(float64(sw.window)*(1-free/sw.curr))))
+	return at.Sub(now)
+}
@@ -1,51 +1,67 @@
 package rate_limiter
 
 import (
+	"math"
 	"sync"
 	"time"
 )
 
+// TokenBucket ведро на capacity токенов, пополняется со скоростью rate токенов в наносекунду.
+// Токены копятся дробно, поэтому пополнение равномерное и работают скорости меньше токена в секунду
 type TokenBucket struct {
-	capacity   int
-	tokens     int
-	refillRate int
+	capacity   float64
+	tokens     float64
+	rate       float64
 	lastRefill time.Time
 	mu         sync.Mutex
 }
 
 func NewTokenBucket(capacity, refillRate int) *TokenBucket {
+	return newTokenBucket(Policy{Limit: refillRate, Window: time.Second, Burst: capacity}, time.Now())
+}
+
+// newTokenBucket ведро для политики: Burst токенов сразу, дальше Limit токенов за Window
+func newTokenBucket(p Policy, now time.Time) *TokenBucket {
 	return &TokenBucket{
-		capacity:   capacity,
-		tokens:     capacity,
-		refillRate: refillRate,
-		lastRefill: time.Now(),
+		capacity:   float64(p.burst()),
+		tokens:     float64(p.burst()),
+		rate:       float64(p.Limit) / float64(p.Window),
+		lastRefill: now,
 	}
 }
 
 func (tb *TokenBucket) Allow() bool {
+	return tb.take(time.Now()).Allowed
+}
+
+func (tb *TokenBucket) take(now time.Time) Decision {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
-	tb.refill()
+	tb.refill(now)
 
-	if tb.tokens > 0 {
+	d := Decision{Limit: int(tb.capacity)}
+	if tb.tokens >= 1 {
 		tb.tokens--
-		return true
+		d.Allowed = true
+	} else {
+		d.RetryAfter = tb.durationFor(1 - tb.tokens)
 	}
-
-	return false
+	d.Remaining = int(tb.tokens)
+	d.ResetAfter = tb.durationFor(tb.capacity - tb.tokens)
+	return d
 }
 
-func (tb *TokenBucket) refill() {
-	now := time.Now()
+func (tb *TokenBucket) refill(now time.Time) {
 	elapsed := now.Sub(tb.lastRefill)
-
-	tokensToAdd := int(elapsed.Seconds()) * tb.refillRate
-	if tokensToAdd > 0 {
-		tb.tokens += tokensToAdd
-		if tb.tokens > tb.capacity {
-			tb.tokens = tb.capacity
-		}
-		tb.lastRefill = now
+	if elapsed <= 0 {
+		return
 	}
+	tb.tokens = math.Min(tb.capacity, tb.tokens+float64(elapsed)*tb.rate)
+	tb.lastRefill = now
+}
+
+// durationFor время, за которое накопится tokens токенов
+func (tb *TokenBucket) durationFor(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / tb.rate))
 }
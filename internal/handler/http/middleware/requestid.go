@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"service-order-avito/internal/observability/requestid"
+)
+
+// WithRequestID берет request id из заголовка X-Request-Id или генерирует новый, кладет его в контекст
+// и возвращает в ответе. Дальше id попадает в логи (*Context методы логгера), problem ответы,
+// события в Kafka и метаданные запросов в order-service. Некорректный id от клиента заменяется новым
+func WithRequestID() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.New()
+			}
+			w.Header().Set(requestid.Header, id)
+			next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/observability/requestid"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestID(t *testing.T) {
+	var got string
+	handler := WithRequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = requestid.FromContext(r.Context())
+	}))
+
+	t.Run("incoming id is kept", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(requestid.Header, "gateway-42")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+
+		require.Equal(t, "gateway-42", got)
+		require.Equal(t, "gateway-42", rec.Header().Get(requestid.Header))
+	})
+
+	t.Run("missing or invalid id is generated", func(t *testing.T) {
+		for _, header := range []string{"", "bad id\r\n"} {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(requestid.Header, header)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			require.Len(t, got, 32)
+			require.Equal(t, got, rec.Header().Get(requestid.Header))
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// тела больше этого размера не проверяются схемой, JSON запросы у нас маленькие
+const maxValidatedBodySize = 1 << 20 // 1 MiB
+
+// WithValidation проверяет параметры и JSON тело запроса по спецификации до вызова хендлера.
+// Middleware стоит на корневом роутере, поэтому роут ищется заново, как в rate limiter.
+// Неизвестные роуты пропускаются, на них ответит chi
+func WithValidation(doc *Document) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.RouteContext(r.Context())
+			if rctx == nil || rctx.Routes == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tctx := chi.NewRouteContext()
+			op := doc.Operation(r.Method, rctx.Routes.Find(tctx, r.Method, r.URL.Path))
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := op.validateParameters(r, tctx.URLParam); err != nil {
+				writeValidationError(w, r, err)
+				return
+			}
+
+			if op.RequestBody != nil && r.Body != nil {
+				if _, ok := op.RequestBody.Content[mediaTypeJSON]; ok {
+					body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBodySize+1))
+					if err != nil {
+						adapters.WriteError(w, r, server.ErrInvalidRequestBody, http.StatusBadRequest)
+						return
+					}
+					if len(body) > maxValidatedBodySize {
+						adapters.WriteError(w, r, server.ErrRequestBodyTooLarge, http.StatusRequestEntityTooLarge)
+						return
+					}
+					r.Body = io.NopCloser(bytes.NewReader(body))
+
+					if err = op.validateBody(body); err != nil {
+						if errors.Is(err, errInvalidJSON) {
+							adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
+							return
+						}
+						writeValidationError(w, r, err)
+						return
+					}
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeValidationError отдает все нарушения схемы списком errors, field в нем - путь вида body.name
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		adapters.WriteError(w, r, server.ErrInvalidRequest+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields := make([]dto.ProblemFieldError, len(verr.Violations))
+	for i, v := range verr.Violations {
+		fields[i] = dto.ProblemFieldError{Field: v.Path, Code: v.Code, Message: v.Message}
+	}
+	adapters.WriteValidationError(w, r, server.ErrInvalidRequest+": "+verr.Error(), fields)
+}
+
+// Handler отдает спецификацию
+func Handler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(spec)
+}
@@ -0,0 +1,320 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// спецификация API. При добавлении роута его нужно описать здесь, иначе упадет тест в server
+//
+//go:embed openapi.json
+var spec []byte
+
+// Document разобранная спецификация. Описывается только то, что используется при проверке запросов и ответов
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas"`
+	Responses map[string]*Response `json:"responses"`
+}
+
+// PathItem операции пути по методу в нижнем регистре
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationId string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Parameters  []Parameter          `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // path или query
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]MediaType `json:"content"`
+}
+
+const (
+	mediaTypeJSON    = "application/json"
+	mediaTypeNDJSON  = "application/x-ndjson"
+	mediaTypeProblem = "application/problem+json"
+)
+
+// Load разбирает встроенную спецификацию и проверяет, что все ссылки в ней существуют
+func Load() (*Document, error) {
+	return Parse(spec)
+}
+
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version %q", doc.OpenAPI)
+	}
+
+	schemas := doc.Components.Schemas
+	for name, s := range schemas {
+		if err := s.resolve(schemas); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	for name, r := range doc.Components.Responses {
+		if err := resolveContent(r.Content, schemas); err != nil {
+			return nil, fmt.Errorf("response %s: %w", name, err)
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			where := strings.ToUpper(method) + " " + path
+			for _, p := range op.Parameters {
+				if err := p.Schema.resolve(schemas); err != nil {
+					return nil, fmt.Errorf("%s parameter %s: %w", where, p.Name, err)
+				}
+			}
+			if op.RequestBody != nil {
+				if err := resolveContent(op.RequestBody.Content, schemas); err != nil {
+					return nil, fmt.Errorf("%s request body: %w", where, err)
+				}
+			}
+			for status, r := range op.Responses {
+				if r.Ref != "" {
+					name, _ := strings.CutPrefix(r.Ref, "#/components/responses/")
+					shared, ok := doc.Components.Responses[name]
+					if !ok {
+						return nil, fmt.Errorf("%s response %s: unknown reference %q", where, status, r.Ref)
+					}
+					op.Responses[status] = shared
+					continue
+				}
+				if err := resolveContent(r.Content, schemas); err != nil {
+					return nil, fmt.Errorf("%s response %s: %w", where, status, err)
+				}
+			}
+		}
+	}
+	return &doc, nil
+}
+
+func resolveContent(content map[string]MediaType, schemas map[string]*Schema) error {
+	for _, m := range content {
+		if err := m.Schema.resolve(schemas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Operation операция по методу и шаблону пути chi. Слеш в конце шаблона не учитывается:
+// r.Get("/") внутри router.Route("/couriers") отвечает и на /couriers
+func (d *Document) Operation(method, pattern string) *Operation {
+	item, ok := d.Paths[normalizePattern(pattern)]
+	if !ok {
+		return nil
+	}
+	return item[strings.ToLower(method)]
+}
+
+// Operations все описанные операции в виде "METHOD /path"
+func (d *Document) Operations() []string {
+	var ops []string
+	for path, item := range d.Paths {
+		for method := range item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+func normalizePattern(pattern string) string {
+	if len(pattern) > 1 {
+		return strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+// validateParameters проверяет path и query параметры. Значения приводятся к типу схемы, как их разобрал бы хендлер
+func (op *Operation) validateParameters(r *http.Request, pathParam func(string) string) error {
+	var verr ValidationError
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var raw string
+		var present bool
+		switch p.In {
+		case "path":
+			raw = pathParam(p.Name)
+			present = raw != ""
+		case "query":
+			present = query.Has(p.Name)
+			raw = query.Get(p.Name)
+		default:
+			continue
+		}
+
+		where := p.In + "." + p.Name
+		if !present {
+			if p.Required {
+				verr.add(where, "required", "is required")
+			}
+			continue
+		}
+		if p.Schema != nil {
+			p.Schema.validate(where, coerce(p.Schema, raw), &verr)
+		}
+	}
+	return verr.err()
+}
+
+// coerce переводит строку из пути или query в значение того типа, который ждет схема.
+// Неподходящая строка остается строкой и не пройдет проверку типа
+func coerce(s *Schema, raw string) any {
+	for s.target != nil {
+		s = s.target
+	}
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// validateBody проверяет JSON тело запроса. Тела других типов (CSV, multipart) разбирают сами хендлеры
+func (op *Operation) validateBody(body []byte) error {
+	if op.RequestBody == nil {
+		return nil
+	}
+	media, ok := op.RequestBody.Content[mediaTypeJSON]
+	if !ok {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			verr := &ValidationError{}
+			verr.add("body", "required", "is required")
+			return verr
+		}
+		return nil
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return errInvalidJSON
+	}
+	if media.Schema == nil {
+		return nil
+	}
+	return media.Schema.Validate("body", value)
+}
+
+var errInvalidJSON = errors.New("invalid JSON")
+
+// ValidateResponse проверяет ответ хендлера по спецификации: статус должен быть описан, тип содержимого
+// и JSON (или каждая строка NDJSON) должны соответствовать схеме
+func (d *Document) ValidateResponse(method, pattern string, status int, header http.Header, body []byte) error {
+	op := d.Operation(method, pattern)
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", method, pattern)
+	}
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if resp, ok = op.Responses["default"]; !ok {
+			return fmt.Errorf("%s %s: status %d is not documented", method, pattern, status)
+		}
+	}
+
+	if len(resp.Content) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("%s %s: status %d must have no body", method, pattern, status)
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("%s %s: invalid content type %q", method, pattern, header.Get("Content-Type"))
+	}
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("%s %s: content type %s is not documented for status %d", method, pattern, mediaType, status)
+	}
+	if media.Schema == nil {
+		return nil
+	}
+
+	switch mediaType {
+	case mediaTypeJSON, mediaTypeProblem:
+		value, err := decodeJSON(body)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", method, pattern, err)
+		}
+		return media.Schema.Validate("body", value)
+	case mediaTypeNDJSON:
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			value, err := decodeJSON(scanner.Bytes())
+			if err != nil {
+				return fmt.Errorf("%s %s: line %d: %w", method, pattern, line, err)
+			}
+			if err = media.Schema.Validate(fmt.Sprintf("line[%d]", line), value); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+	return nil
+}
+
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	// после значения не должно быть ничего, кроме пробелов
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return value, nil
+}
@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	require.NotNil(t, doc.Operation(http.MethodPost, "/delivery/assign"))
+	require.NotNil(t, doc.Operation(http.MethodPost, "/courier/"), "trailing slash of chi pattern is ignored")
+	require.Nil(t, doc.Operation(http.MethodPatch, "/courier"))
+
+	_, err = Parse([]byte(`{"openapi":"3.0.3","paths":{},"components":{"schemas":{"A":{"$ref":"#/components/schemas/B"}}}}`))
+	require.ErrorContains(t, err, "unknown schema reference")
+}
+
+func TestSchema_Validate(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	feedback := doc.Components.Schemas["LeaveFeedbackRequest"]
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "valid", body: `{"rating":5,"tags":["late"],"comment":"ok"}`},
+		{name: "null tags", body: `{"rating":5,"tags":null}`},
+		{name: "unknown fields are allowed", body: `{"rating":1,"extra":true}`},
+		{name: "missing rating", body: `{"comment":"ok"}`, wantErr: "body.rating: is required"},
+		{name: "rating is not integer", body: `{"rating":4.5}`, wantErr: "body.rating: must be integer"},
+		{name: "rating out of range", body: `{"rating":6}`, wantErr: "body.rating: must be <= 5"},
+		{name: "unknown tag", body: `{"rating":5,"tags":["late","slow"]}`, wantErr: "body.tags[1]: must be one of late, rude, damaged"},
+		{name: "comment too long", body: `{"rating":5,"comment":"` + strings.Repeat("я", 1001) + `"}`, wantErr: "body.comment: must be at most 1000 characters"},
+		{name: "not an object", body: `[]`, wantErr: "body: must be object"},
+		{name: "all errors are reported", body: `{"rating":0,"tags":["slow"],"comment":1}`,
+			wantErr: "body.comment: must be string; body.rating: must be >= 1; body.tags[0]: must be one of late, rude, damaged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := decodeJSON([]byte(tt.body))
+			require.NoError(t, err)
+			err = feedback.Validate("body", value)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestWithValidation(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+
+	var gotBody string
+	router := chi.NewRouter()
+	router.Use(WithValidation(doc))
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	router.Route("/courier", func(r chi.Router) {
+		r.Get("/{id}", ok)
+		r.Get("/{id}/stats", ok)
+		r.Post("/", ok)
+	})
+	router.Post("/undocumented", ok)
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "valid body", method: http.MethodPost, url: "/courier", body: `{"name":"Ivan","phone":"+79991234567","status":"available"}`, wantStatus: http.StatusNoContent},
+		{name: "missing field", method: http.MethodPost, url: "/courier", body: `{"name":"Ivan","phone":"+79991234567"}`, wantStatus: http.StatusBadRequest, wantError: server.ErrInvalidRequest + ": body.status: is required"},
+		{name: "invalid enum", method: http.MethodPost, url: "/courier", body: `{"name":"Ivan","phone":"1","status":"sleeping"}`, wantStatus: http.StatusBadRequest, wantError: server.ErrInvalidRequest + ": body.status: must be one of available, busy, paused"},
+		{name: "broken json", method: http.MethodPost, url: "/courier", body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: server.ErrInvalidJSON},
+		{name: "empty body", method: http.MethodPost, url: "/courier", wantStatus: http.StatusBadRequest, wantError: server.ErrInvalidRequest + ": body: is required"},
+		{name: "valid path param", method: http.MethodGet, url: "/courier/7", wantStatus: http.StatusNoContent},
+		{name: "path param is not integer", method: http.MethodGet, url: "/courier/abc", wantStatus: http.StatusBadRequest, wantError: server.ErrInvalidRequest + ": path.id: must be integer"},
+		{name: "path param below minimum", method: http.MethodGet, url: "/courier/0/stats", wantStatus: http.StatusBadRequest, wantError: server.ErrInvalidRequest + ": path.id: must be >= 1"},
+		{name: "undocumented route", method: http.MethodPost, url: "/undocumented", body: `garbage`, wantStatus: http.StatusNoContent},
+		{name: "unknown route", method: http.MethodGet, url: "/nowhere", wantStatus: http.StatusNotFound},
+	}
+
+	t.Run("field errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/courier", strings.NewReader(`{"name":"","status":"sleeping"}`)))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+		var body dto.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+		require.Equal(t, "validation_error", body.Code)
+		require.Equal(t, "/courier", body.Instance)
+		require.Equal(t, []dto.ProblemFieldError{
+			{Field: "body.phone", Code: "required", Message: "is required"},
+			{Field: "body.name", Code: "min_length", Message: "must be at least 1 characters"},
+			{Field: "body.status", Code: "enum", Message: "must be one of available, busy, paused"},
+		}, body.Errors)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = ""
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantError != "" {
+				var body dto.Problem
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+				require.Equal(t, tt.wantError, body.Detail)
+			}
+			if tt.wantStatus == http.StatusNoContent {
+				require.Equal(t, tt.body, gotBody, "handler must still be able to read the body")
+			}
+		})
+	}
+}
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	require.NoError(t, doc.ValidateResponse(http.MethodGet, "/ping", http.StatusOK, jsonHeader, []byte(`{"message":"pong"}`)))
+	require.NoError(t, doc.ValidateResponse(http.MethodHead, "/healthcheck", http.StatusNoContent, http.Header{}, nil))
+	problemHeader := http.Header{"Content-Type": {"application/problem+json"}}
+	require.NoError(t, doc.ValidateResponse(http.MethodGet, "/courier/{id}", http.StatusNotFound, problemHeader,
+		[]byte(`{"type":"/problems/courier_not_found","title":"Not Found","status":404,"detail":"courier not found","code":"courier_not_found"}`)))
+	require.ErrorContains(t, doc.ValidateResponse(http.MethodGet, "/courier/{id}", http.StatusNotFound, problemHeader,
+		[]byte(`{"error":{"message":"courier not found"}}`)), "body.status: is required")
+
+	require.ErrorContains(t, doc.ValidateResponse(http.MethodGet, "/ping", http.StatusOK, jsonHeader, []byte(`{}`)), "body.message: is required")
+	require.ErrorContains(t, doc.ValidateResponse(http.MethodGet, "/ping", http.StatusOK, http.Header{"Content-Type": {"text/plain"}}, []byte(`pong`)), "content type text/plain is not documented")
+	require.ErrorContains(t, doc.ValidateResponse(http.MethodGet, "/courier/{id}", http.StatusOK, jsonHeader,
+		[]byte(`{"id":1,"name":"a","phone":"+7","status":"available","transport-type":"car","rating":0,"rating_count":0}`)), "body.transport_type: is required")
+
+	ndjson := http.Header{"Content-Type": {"application/x-ndjson"}}
+	line := `{"id":1,"name":"a","phone":"+7","status":"available","transport_type":"car","total_deliveries":0,"created_at":"2025-01-01T00:00:00Z"}`
+	require.NoError(t, doc.ValidateResponse(http.MethodGet, "/couriers/export", http.StatusOK, ndjson, []byte(line+"\n"+line+"\n")))
+	require.ErrorContains(t, doc.ValidateResponse(http.MethodGet, "/couriers/export", http.StatusOK, ndjson, []byte(line+"\n{}\n")), "line[2].id: is required")
+}
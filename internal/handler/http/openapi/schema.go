@@ -0,0 +1,275 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schema подмножество JSON Schema из OpenAPI 3.0, которого хватает для нашего API:
+// типы, nullable, enum, границы чисел, длины строк и массивов, объекты с required и additionalProperties.
+// Ссылки поддерживаются только на #/components/schemas
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties,omitempty"`
+
+	// заполняются в resolve
+	target     *Schema
+	additional *Schema
+	closed     bool // additionalProperties: false
+}
+
+// resolve подставляет ссылки и разбирает additionalProperties
+func (s *Schema) resolve(components map[string]*Schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		target, found := components[name]
+		if !ok || !found {
+			return fmt.Errorf("unknown schema reference %q", s.Ref)
+		}
+		s.target = target
+		return nil
+	}
+
+	if len(s.AdditionalProperties) > 0 {
+		var closed bool
+		if err := json.Unmarshal(s.AdditionalProperties, &closed); err == nil {
+			s.closed = !closed
+		} else {
+			s.additional = &Schema{}
+			if err = json.Unmarshal(s.AdditionalProperties, s.additional); err != nil {
+				return fmt.Errorf("invalid additionalProperties: %w", err)
+			}
+		}
+	}
+
+	children := []*Schema{s.Items, s.additional}
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	for _, child := range children {
+		if err := child.resolve(components); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Violation нарушение схемы в одном месте значения. Code - короткое имя правила (required, type, enum, ...)
+type Violation struct {
+	Path    string
+	Code    string
+	Message string
+}
+
+// ValidationError все нарушения схемы, найденные в значении
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Path + ": " + v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) add(path, code, format string, args ...any) {
+	e.Violations = append(e.Violations, Violation{Path: path, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *ValidationError) err() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate проверяет значение, декодированное из JSON с UseNumber, и возвращает *ValidationError со всеми нарушениями.
+// path попадает в текст ошибки
+func (s *Schema) Validate(path string, value any) error {
+	var verr ValidationError
+	s.validate(path, value, &verr)
+	return verr.err()
+}
+
+func (s *Schema) validate(path string, value any, verr *ValidationError) {
+	if s.target != nil {
+		s.target.validate(path, value, verr)
+		return
+	}
+
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			verr.add(path, "null", "must not be null")
+		}
+		return
+	}
+
+	if !s.validateType(path, value, verr) {
+		return
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		verr.add(path, "enum", "must be one of %s", s.enumString())
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(path, v, verr)
+	case json.Number:
+		s.validateNumber(path, v, verr)
+	case []any:
+		s.validateArray(path, v, verr)
+	case map[string]any:
+		s.validateObject(path, v, verr)
+	}
+}
+
+// validateType false, если тип не совпал и дальше значение проверять бессмысленно
+func (s *Schema) validateType(path string, value any, verr *ValidationError) bool {
+	ok := true
+	switch s.Type {
+	case "":
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = value.(json.Number)
+	case "integer":
+		var n json.Number
+		if n, ok = value.(json.Number); ok {
+			_, err := n.Int64()
+			ok = err == nil
+		}
+	case "array":
+		_, ok = value.([]any)
+	case "object":
+		_, ok = value.(map[string]any)
+	default:
+		verr.add(path, "type", "unsupported schema type %q", s.Type)
+		return false
+	}
+	if !ok {
+		verr.add(path, "type", "must be %s", s.Type)
+	}
+	return ok
+}
+
+func (s *Schema) validateString(path, v string, verr *ValidationError) {
+	length := len([]rune(v))
+	if s.MinLength != nil && length < *s.MinLength {
+		verr.add(path, "min_length", "must be at least %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		verr.add(path, "max_length", "must be at most %d characters", *s.MaxLength)
+	}
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			verr.add(path, "format", "must be RFC 3339 date-time")
+		}
+	}
+}
+
+func (s *Schema) validateNumber(path string, v json.Number, verr *ValidationError) {
+	f, err := v.Float64()
+	if err != nil || math.IsInf(f, 0) {
+		verr.add(path, "type", "must be %s", s.Type)
+		return
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		verr.add(path, "minimum", "must be >= %v", *s.Minimum)
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		verr.add(path, "maximum", "must be <= %v", *s.Maximum)
+	}
+}
+
+func (s *Schema) validateArray(path string, v []any, verr *ValidationError) {
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		verr.add(path, "max_items", "must have at most %d items", *s.MaxItems)
+	}
+	if s.Items == nil {
+		return
+	}
+	for i, item := range v {
+		s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, verr)
+	}
+}
+
+func (s *Schema) validateObject(path string, v map[string]any, verr *ValidationError) {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			verr.add(path+"."+name, "required", "is required")
+		}
+	}
+
+	// порядок обхода фиксирован, чтобы ошибки для одного и того же тела шли в одном порядке
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		switch {
+		case ok:
+		case s.additional != nil:
+			prop = s.additional
+		case s.closed:
+			verr.add(path+"."+name, "unknown_field", "unknown field")
+			continue
+		default:
+			continue
+		}
+		prop.validate(path+"."+name, v[name], verr)
+	}
+}
+
+func (s *Schema) inEnum(value any) bool {
+	for _, e := range s.Enum {
+		// enum из спецификации декодирован без UseNumber, числа сравниваем как float64
+		if n, ok := value.(json.Number); ok {
+			f, _ := n.Float64()
+			if ef, ok := e.(float64); ok && ef == f {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) enumString() string {
+	values := make([]string, len(s.Enum))
+	for i, e := range s.Enum {
+		values[i] = fmt.Sprint(e)
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// mockgen -source="internal/handler/http/server/handler/admin/admin.go" -destination="internal/handler/http/server/handler/admin/mocks/mock_admin.go"
+type logLevels interface {
+	Global() slog.Level
+	SetGlobal(slog.Level)
+	Components() map[string]slog.Level
+	SetComponent(component string, level *slog.Level)
+}
+
+type jobScheduler interface {
+	Status() dto.JobsResponse
+	Trigger(name string) error
+}
+
+type adminHandler struct {
+	log    logger.LoggerAdapter
+	levels logLevels
+	jobs   jobScheduler
+}
+
+func NewAdminHandler(log logger.LoggerAdapter, levels logLevels, jobs jobScheduler) *adminHandler {
+	return &adminHandler{log: log, levels: levels, jobs: jobs}
+}
+
+func (ah *adminHandler) GetLogLevel(w http.ResponseWriter, _ *http.Request) {
+	ah.writeLevels(w)
+}
+
+// PutLogLevel меняет уровни без перезапуска. Значения проверяются до применения,
+// поэтому при ошибке не меняется ничего. Изменения живут до рестарта или SIGHUP
+func (ah *adminHandler) PutLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateLogLevelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
+		return
+	}
+
+	var global *slog.Level
+	if req.Level != "" {
+		level, ok := parseLevel(req.Level)
+		if !ok {
+			adapters.WriteError(w, r, server.ErrInvalidLogLevel, http.StatusBadRequest)
+			return
+		}
+		global = &level
+	}
+	components := make(map[string]*slog.Level, len(req.Components))
+	for component, value := range req.Components {
+		if value == "" {
+			components[component] = nil
+			continue
+		}
+		level, ok := parseLevel(value)
+		if !ok {
+			adapters.WriteError(w, r, server.ErrInvalidLogLevel, http.StatusBadRequest)
+			return
+		}
+		components[component] = &level
+	}
+
+	if global != nil {
+		ah.levels.SetGlobal(*global)
+	}
+	for component, level := range components {
+		ah.levels.SetComponent(component, level)
+	}
+	ah.log.InfoContext(r.Context(), "log levels changed", "level", req.Level, "components", req.Components)
+
+	ah.writeLevels(w)
+}
+
+func (ah *adminHandler) GetJobs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(ah.jobs.Status())
+}
+
+// PostJobRun запускает задачу вне расписания и сразу отвечает 202, результат запуска виден в GET /admin/jobs.
+// Планировщик работает только на лидере, на остальных репликах запрос получает 409
+func (ah *adminHandler) PostJobRun(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := ah.jobs.Trigger(name); err != nil {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+	ah.log.InfoContext(r.Context(), "job is triggered manually", "job", name)
+
+	res := dto.JobResponse{Name: name}
+	for _, job := range ah.jobs.Status().Jobs {
+		if job.Name == name {
+			res = job
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (ah *adminHandler) writeLevels(w http.ResponseWriter) {
+	res := dto.LogLevelsResponse{
+		Level:      formatLevel(ah.levels.Global()),
+		Components: map[string]string{},
+	}
+	for component, level := range ah.levels.Components() {
+		res.Components[component] = formatLevel(level)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func parseLevel(s string) (slog.Level, bool) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, false
+	}
+	return level, true
+}
+
+func formatLevel(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
@@ -0,0 +1,132 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/handler/http/server/handler/admin/mocks"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+func TestAdminHandler_PutLogLevel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	levels := mock_admin.NewMocklogLevels(ctrl)
+	handler := NewAdminHandler(nopLogger{}, levels, mock_admin.NewMockjobScheduler(ctrl))
+
+	warn := slog.LevelWarn
+	levels.EXPECT().SetGlobal(slog.LevelDebug)
+	levels.EXPECT().SetComponent("worker/delivery", &warn)
+	levels.EXPECT().SetComponent("middleware/auth", nil)
+	levels.EXPECT().Global().Return(slog.LevelDebug)
+	levels.EXPECT().Components().Return(map[string]slog.Level{"worker/delivery": slog.LevelWarn})
+
+	r := httptest.NewRequest(http.MethodPut, "/admin/loglevel",
+		strings.NewReader(`{"level":"DEBUG","components":{"worker/delivery":"warn","middleware/auth":""}}`))
+	w := httptest.NewRecorder()
+	handler.PutLogLevel(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var res dto.LogLevelsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	require.Equal(t, dto.LogLevelsResponse{Level: "debug", Components: map[string]string{"worker/delivery": "warn"}}, res)
+}
+
+func TestAdminHandler_PutLogLevel_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErrMsg string
+	}{
+		{name: "invalid json", body: `{"level":`, wantErrMsg: server.ErrInvalidJSON},
+		{name: "invalid global level", body: `{"level":"verbose"}`, wantErrMsg: server.ErrInvalidLogLevel},
+		// общий уровень валиден, но из-за компонента не применяется ничего
+		{name: "invalid component level", body: `{"level":"debug","components":{"worker/delivery":"loud"}}`, wantErrMsg: server.ErrInvalidLogLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			handler := NewAdminHandler(nopLogger{}, mock_admin.NewMocklogLevels(ctrl), mock_admin.NewMockjobScheduler(ctrl))
+
+			w := httptest.NewRecorder()
+			handler.PutLogLevel(w, httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(tt.body)))
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var problem dto.Problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			require.Equal(t, tt.wantErrMsg, problem.Detail)
+		})
+	}
+}
+
+func TestAdminHandler_PostJobRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		triggerErr error
+		wantStatus int
+		wantErrMsg string
+	}{
+		{name: "accepted", wantStatus: http.StatusAccepted},
+		{name: "unknown job", triggerErr: service.ErrJobNotFound, wantStatus: http.StatusNotFound, wantErrMsg: server.ErrJobNotFound},
+		{name: "already running", triggerErr: service.ErrJobAlreadyRunning, wantStatus: http.StatusConflict, wantErrMsg: server.ErrJobAlreadyRunning},
+		{name: "follower", triggerErr: service.ErrSchedulerInactive, wantStatus: http.StatusConflict, wantErrMsg: server.ErrSchedulerInactive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			jobs := mock_admin.NewMockjobScheduler(ctrl)
+			handler := NewAdminHandler(nopLogger{}, mock_admin.NewMocklogLevels(ctrl), jobs)
+
+			jobs.EXPECT().Trigger("delivery_monitor").Return(tt.triggerErr)
+			if tt.triggerErr == nil {
+				jobs.EXPECT().Status().Return(dto.JobsResponse{Active: true, Jobs: []dto.JobResponse{
+					{Name: "idempotency_keys_cleanup", Schedule: "@every 10m"},
+					{Name: "delivery_monitor", Schedule: "@every 5s", Running: true},
+				}})
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/admin/jobs/delivery_monitor/run", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("name", "delivery_monitor")
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			handler.PostJobRun(w, r)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.triggerErr != nil {
+				var problem dto.Problem
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+				require.Equal(t, tt.wantErrMsg, problem.Detail)
+				return
+			}
+			var res dto.JobResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+			require.Equal(t, dto.JobResponse{Name: "delivery_monitor", Schedule: "@every 5s", Running: true}, res)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/http/server/handler/admin/admin.go
+
+// Package mock_admin is a generated GoMock package.
+package mock_admin
+
+import (
+	slog "log/slog"
+	reflect "reflect"
+	dto "service-order-avito/internal/domain/dto"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocklogLevels is a mock of logLevels interface.
+type MocklogLevels struct {
+	ctrl     *gomock.Controller
+	recorder *MocklogLevelsMockRecorder
+}
+
+// MocklogLevelsMockRecorder is the mock recorder for MocklogLevels.
+type MocklogLevelsMockRecorder struct {
+	mock *MocklogLevels
+}
+
+// NewMocklogLevels creates a new mock instance.
+func NewMocklogLevels(ctrl *gomock.Controller) *MocklogLevels {
+	mock := &MocklogLevels{ctrl: ctrl}
+	mock.recorder = &MocklogLevelsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocklogLevels) EXPECT() *MocklogLevelsMockRecorder {
+	return m.recorder
+}
+
+// Components mocks base method.
+func (m *MocklogLevels) Components() map[string]slog.Level {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Components")
+	ret0, _ := ret[0].(map[string]slog.Level)
+	return ret0
+}
+
+// Components indicates an expected call of Components.
+func (mr *MocklogLevelsMockRecorder) Components() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Components", reflect.TypeOf((*MocklogLevels)(nil).Components))
+}
+
+// Global mocks base method.
+func (m *MocklogLevels) Global() slog.Level {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Global")
+	ret0, _ := ret[0].(slog.Level)
+	return ret0
+}
+
+// Global indicates an expected call of Global.
+func (mr *MocklogLevelsMockRecorder) Global() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Global", reflect.TypeOf((*MocklogLevels)(nil).Global))
+}
+
+// SetComponent mocks base method.
+func (m *MocklogLevels) SetComponent(component string, level *slog.Level) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetComponent", component, level)
+}
+
+// SetComponent indicates an expected call of SetComponent.
+func (mr *MocklogLevelsMockRecorder) SetComponent(component, level interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetComponent", reflect.TypeOf((*MocklogLevels)(nil).SetComponent), component, level)
+}
+
+// SetGlobal mocks base method.
+func (m *MocklogLevels) SetGlobal(arg0 slog.Level) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetGlobal", arg0)
+}
+
+// SetGlobal indicates an expected call of SetGlobal.
+func (mr *MocklogLevelsMockRecorder) SetGlobal(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGlobal", reflect.TypeOf((*MocklogLevels)(nil).SetGlobal), arg0)
+}
+
+// MockjobScheduler is a mock of jobScheduler interface.
+type MockjobScheduler struct {
+	ctrl     *gomock.Controller
+	recorder *MockjobSchedulerMockRecorder
+}
+
+// MockjobSchedulerMockRecorder is the mock recorder for MockjobScheduler.
+type MockjobSchedulerMockRecorder struct {
+	mock *MockjobScheduler
+}
+
+// NewMockjobScheduler creates a new mock instance.
+func NewMockjobScheduler(ctrl *gomock.Controller) *MockjobScheduler {
+	mock := &MockjobScheduler{ctrl: ctrl}
+	mock.recorder = &MockjobSchedulerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockjobScheduler) EXPECT() *MockjobSchedulerMockRecorder {
+	return m.recorder
+}
+
+// Status mocks base method.
+func (m *MockjobScheduler) Status() dto.JobsResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(dto.JobsResponse)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockjobSchedulerMockRecorder) Status() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockjobScheduler)(nil).Status))
+}
+
+// Trigger mocks base method.
+func (m *MockjobScheduler) Trigger(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trigger", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Trigger indicates an expected call of Trigger.
+func (mr *MockjobSchedulerMockRecorder) Trigger(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trigger", reflect.TypeOf((*MockjobScheduler)(nil).Trigger), name)
+}
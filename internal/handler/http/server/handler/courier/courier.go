@@ -6,37 +6,41 @@ import (
 	"github.com/go-chi/chi/v5"
 	"net/http"
 	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/adapters/logger"
 	"service-order-avito/internal/domain/dto"
 	"service-order-avito/internal/domain/errors/server"
 	"strconv"
 )
 
-// mockgen -source="internal/http/server/handler/courier/courier.go" -destination="internal/http/server/handler/courier/mocks/mock_courier_service.go"
+// mockgen -source="internal/handler/http/server/handler/courier/courier.go" -destination="internal/handler/http/server/handler/courier/mocks/mock_courier_service.go"
 type сourierService interface {
 	CreateCourier(context.Context, *dto.CreateCourierRequest) (*dto.CreateCourierResponse, error)
 	GetCourier(context.Context, *dto.GetCourierRequest) (*dto.GetCourierResponse, error)
 	GetAllCouriers(context.Context) ([]dto.GetCourierResponse, error)
 	UpdateCourier(context.Context, *dto.UpdateCourierRequest) error
 	DeleteCourier(context.Context, *dto.DeleteCourierRequest) error
+	ImportCouriers(context.Context, *dto.ImportCouriersRequest) (*dto.ImportCouriersResponse, error)
+	ExportCouriers(context.Context, func(dto.ExportCourier) error) error
 }
 
 type courierHandler struct {
+	log     logger.LoggerAdapter
 	service сourierService
 }
 
-func NewCourierHandler(service сourierService) *courierHandler {
-	return &courierHandler{service: service}
+func NewCourierHandler(log logger.LoggerAdapter, service сourierService) *courierHandler {
+	return &courierHandler{log: log, service: service}
 }
 
 func (ch *courierHandler) Post(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateCourierRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		adapters.WriteError(w, server.ErrInvalidJSON, http.StatusBadRequest)
+		adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
 		return
 	}
 	res, err := ch.service.CreateCourier(r.Context(), &req)
 	if err != nil {
-		adapters.WriteServiceError(w, err)
+		adapters.WriteServiceError(w, r, err)
 		return
 	}
 	res.Message = "courier's profile created successfully"
@@ -50,7 +54,7 @@ func (ch *courierHandler) Get(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		adapters.WriteError(w, server.ErrInvalidCourierId, http.StatusBadRequest)
+		adapters.WriteError(w, r, server.ErrInvalidCourierId, http.StatusBadRequest)
 		return
 	}
 
@@ -60,7 +64,7 @@ func (ch *courierHandler) Get(w http.ResponseWriter, r *http.Request) {
 	courier, err := ch.service.GetCourier(r.Context(), courierReq)
 
 	if err != nil {
-		adapters.WriteServiceError(w, err)
+		adapters.WriteServiceError(w, r, err)
 		return
 	}
 
@@ -72,7 +76,7 @@ func (ch *courierHandler) Get(w http.ResponseWriter, r *http.Request) {
 func (ch *courierHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	couriers, err := ch.service.GetAllCouriers(r.Context())
 	if err != nil {
-		adapters.WriteServiceError(w, err)
+		adapters.WriteServiceError(w, r, err)
 		return
 	}
 
@@ -84,12 +88,12 @@ func (ch *courierHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 func (ch *courierHandler) Put(w http.ResponseWriter, r *http.Request) {
 	var req dto.UpdateCourierRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		adapters.WriteError(w, server.ErrInvalidJSON, http.StatusBadRequest)
+		adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
 		return
 	}
 	err := ch.service.UpdateCourier(r.Context(), &req)
 	if err != nil {
-		adapters.WriteServiceError(w, err)
+		adapters.WriteServiceError(w, r, err)
 		return
 	}
 	res := dto.UpdateCourierResponse{
@@ -104,7 +108,7 @@ func (ch *courierHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		adapters.WriteError(w, server.ErrInvalidCourierId, http.StatusBadRequest)
+		adapters.WriteError(w, r, server.ErrInvalidCourierId, http.StatusBadRequest)
 		return
 	}
 
@@ -112,7 +116,7 @@ func (ch *courierHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	err = ch.service.DeleteCourier(r.Context(), courierReq)
 
 	if err != nil {
-		adapters.WriteServiceError(w, err)
+		adapters.WriteServiceError(w, r, err)
 		return
 	}
 	res := dto.DeleteCourierResponse{
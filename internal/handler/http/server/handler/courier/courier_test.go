@@ -2,12 +2,14 @@ package courier
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
+	"service-order-avito/internal/adapters/logger"
 	"service-order-avito/internal/domain/dto"
 	"service-order-avito/internal/domain/errors/server"
 	"service-order-avito/internal/domain/errors/service"
@@ -15,6 +17,19 @@ import (
 	"testing"
 )
 
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
 func TestCourierHandler_Post_Success(t *testing.T) {
 	t.Parallel()
 
@@ -22,7 +37,7 @@ func TestCourierHandler_Post_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	reqBody := dto.CreateCourierRequest{
 		Name:          "John",
@@ -66,7 +81,7 @@ func TestCourierHandler_Post_InvalidJSON(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	body := []byte(`invalid json`)
 
@@ -80,11 +95,11 @@ func TestCourierHandler_Post_InvalidJSON(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrInvalidJSON, decoded.Error.Message)
+	require.Equal(t, server.ErrInvalidJSON, decoded.Detail)
 }
 
 func TestCourierHandler_Post_ServiceErrors(t *testing.T) {
@@ -156,7 +171,7 @@ func TestCourierHandler_Post_ServiceErrors(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockService := mock_courier.NewMockсourierService(ctrl)
-			handler := NewCourierHandler(mockService)
+			handler := NewCourierHandler(nopLogger{}, mockService)
 
 			bodyBytes, _ := json.Marshal(tt.req)
 
@@ -175,11 +190,11 @@ func TestCourierHandler_Post_ServiceErrors(t *testing.T) {
 
 			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
 
-			var decoded dto.ErrorResponse
+			var decoded dto.Problem
 			err := json.NewDecoder(resp.Body).Decode(&decoded)
 			require.NoError(t, err)
 
-			require.Equal(t, tt.wantErrMsg, decoded.Error.Message)
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
 		})
 	}
 }
@@ -191,7 +206,7 @@ func TestCourierHandler_Get_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Get("/courier/{id}", handler.Get)
@@ -234,6 +249,43 @@ func TestCourierHandler_Get_Success(t *testing.T) {
 	require.Equal(t, "on_foot", decoded.TransportType)
 }
 
+// старые клиенты читают transport-type, на переходный релиз отдаем оба имени
+func TestCourierHandler_Get_LegacyTransportType(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_courier.NewMockсourierService(ctrl)
+	handler := NewCourierHandler(nopLogger{}, mockService)
+
+	router := chi.NewRouter()
+	router.Get("/courier/{id}", handler.Get)
+	router.Get("/couriers", handler.GetAll)
+
+	courier := dto.GetCourierResponse{Id: 10, Name: "John", Phone: "+79779779779", Status: "available", TransportType: "car"}
+	mockService.EXPECT().GetCourier(gomock.Any(), &dto.GetCourierRequest{Id: 10}).Return(&courier, nil)
+	mockService.EXPECT().GetAllCouriers(gomock.Any()).Return([]dto.GetCourierResponse{courier}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courier/10", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var one map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &one))
+	require.Equal(t, "car", one["transport_type"])
+	require.Equal(t, "car", one["transport-type"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/couriers", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var all []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &all))
+	require.Len(t, all, 1)
+	require.Equal(t, "car", all[0]["transport-type"])
+}
+
 func TestCourierHandler_Get_InvalidId(t *testing.T) {
 	t.Parallel()
 
@@ -241,7 +293,7 @@ func TestCourierHandler_Get_InvalidId(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Get("/courier/{id}", handler.Get)
@@ -256,11 +308,11 @@ func TestCourierHandler_Get_InvalidId(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrInvalidCourierId, decoded.Error.Message)
+	require.Equal(t, server.ErrInvalidCourierId, decoded.Detail)
 }
 
 func TestCourierHandler_Get_NotFoundError(t *testing.T) {
@@ -270,7 +322,7 @@ func TestCourierHandler_Get_NotFoundError(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Get("/courier/{id}", handler.Get)
@@ -294,11 +346,11 @@ func TestCourierHandler_Get_NotFoundError(t *testing.T) {
 
 	require.Equal(t, http.StatusNotFound, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrCourierNotFound, decoded.Error.Message)
+	require.Equal(t, server.ErrCourierNotFound, decoded.Detail)
 }
 
 func TestCourierHandler_GetAll_Success(t *testing.T) {
@@ -308,7 +360,7 @@ func TestCourierHandler_GetAll_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Get("/couriers", handler.GetAll)
@@ -365,7 +417,7 @@ func TestCourierHandler_GetAll_InternalError(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Get("/couriers", handler.GetAll)
@@ -385,11 +437,11 @@ func TestCourierHandler_GetAll_InternalError(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrInternalError, decoded.Error.Message)
+	require.Equal(t, server.ErrInternalError, decoded.Detail)
 }
 
 func TestCourierHandler_Put_Success(t *testing.T) {
@@ -399,7 +451,7 @@ func TestCourierHandler_Put_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	reqBody := dto.UpdateCourierRequest{
 		Name:          "John",
@@ -438,7 +490,7 @@ func TestCourierHandler_Put_InvalidJSON(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	body := []byte(`invalid json`)
 
@@ -452,11 +504,11 @@ func TestCourierHandler_Put_InvalidJSON(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrInvalidJSON, decoded.Error.Message)
+	require.Equal(t, server.ErrInvalidJSON, decoded.Detail)
 }
 
 func TestCourierHandler_Put_ServiceErrors(t *testing.T) {
@@ -534,7 +586,7 @@ func TestCourierHandler_Put_ServiceErrors(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockService := mock_courier.NewMockсourierService(ctrl)
-			handler := NewCourierHandler(mockService)
+			handler := NewCourierHandler(nopLogger{}, mockService)
 
 			bodyBytes, _ := json.Marshal(tt.req)
 
@@ -553,11 +605,11 @@ func TestCourierHandler_Put_ServiceErrors(t *testing.T) {
 
 			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
 
-			var decoded dto.ErrorResponse
+			var decoded dto.Problem
 			err := json.NewDecoder(resp.Body).Decode(&decoded)
 			require.NoError(t, err)
 
-			require.Equal(t, tt.wantErrMsg, decoded.Error.Message)
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
 		})
 	}
 }
@@ -569,7 +621,7 @@ func TestCourierHandler_Delete_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Delete("/courier/{id}", handler.Delete)
@@ -607,7 +659,7 @@ func TestCourierHandler_Delete_InvalidId(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Delete("/courier/{id}", handler.Delete)
@@ -622,11 +674,11 @@ func TestCourierHandler_Delete_InvalidId(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrInvalidCourierId, decoded.Error.Message)
+	require.Equal(t, server.ErrInvalidCourierId, decoded.Detail)
 }
 
 func TestCourierHandler_Delete_NotFoundError(t *testing.T) {
@@ -636,7 +688,7 @@ func TestCourierHandler_Delete_NotFoundError(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_courier.NewMockсourierService(ctrl)
-	handler := NewCourierHandler(mockService)
+	handler := NewCourierHandler(nopLogger{}, mockService)
 
 	router := chi.NewRouter()
 	router.Delete("/courier/{id}", handler.Delete)
@@ -660,9 +712,9 @@ func TestCourierHandler_Delete_NotFoundError(t *testing.T) {
 
 	require.Equal(t, http.StatusNotFound, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrCourierNotFound, decoded.Error.Message)
+	require.Equal(t, server.ErrCourierNotFound, decoded.Detail)
 }
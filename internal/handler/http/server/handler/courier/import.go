@@ -0,0 +1,285 @@
+package courier
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	maxImportBodySize = 10 << 20 // 10 MiB
+	maxImportRows     = 10000
+
+	// exportFlushEvery через сколько строк выгрузки принудительно отдавать данные клиенту
+	exportFlushEvery = 100
+)
+
+var (
+	errImportTooManyRows = errors.New("too many rows")
+	errImportBadHeader   = errors.New("csv header must contain name and phone columns")
+)
+
+var exportCSVHeader = []string{"id", "name", "phone", "status", "transport_type", "total_deliveries", "created_at"}
+
+// Import принимает CSV (с заголовком) или NDJSON со списком курьеров и возвращает построчный отчет.
+// Формат берется из параметра ?format=, если его нет - из Content-Type. ?dry_run=true только проверяет файл
+func (ch *courierHandler) Import(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatFromContentType(r.Header.Get("Content-Type"))
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			adapters.WriteError(w, r, server.ErrInvalidImportFile, http.StatusBadRequest)
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+
+	var couriers []dto.CreateCourierRequest
+	var err error
+	switch format {
+	case formatCSV:
+		couriers, err = decodeCSV(body)
+	case formatNDJSON:
+		couriers, err = decodeNDJSON(body)
+	default:
+		adapters.WriteError(w, r, server.ErrUnsupportedFormat, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || errors.Is(err, errImportTooManyRows) {
+			adapters.WriteError(w, r, server.ErrImportTooLarge, http.StatusRequestEntityTooLarge)
+			return
+		}
+		adapters.WriteError(w, r, server.ErrInvalidImportFile+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := ch.service.ImportCouriers(r.Context(), &dto.ImportCouriersRequest{Couriers: couriers, DryRun: dryRun})
+	if err != nil {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// Export потоково отдает всех курьеров в CSV (по умолчанию) или NDJSON.
+// Заголовки ответа пишутся только при получении первой строки, чтобы ошибку до начала выгрузки
+// можно было вернуть обычным JSON'ом. Ошибку посреди выгрузки клиенту уже не отдать, поэтому соединение обрывается
+// через http.ErrAbortHandler: клиент получит оборванный ответ, а не обрезанный файл со статусом 200
+func (ch *courierHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatCSV
+	}
+
+	var contentType string
+	switch format {
+	case formatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case formatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		adapters.WriteError(w, r, server.ErrUnsupportedFormat, http.StatusBadRequest)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	bw := bufio.NewWriter(w)
+	csvWriter := csv.NewWriter(bw)
+	jsonEncoder := json.NewEncoder(bw)
+
+	written := 0
+	writeHeaders := func() error {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="couriers.`+format+`"`)
+		w.WriteHeader(http.StatusOK)
+		if format == formatCSV {
+			return csvWriter.Write(exportCSVHeader)
+		}
+		return nil
+	}
+	flush := func() error {
+		if format == formatCSV {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	err := ch.service.ExportCouriers(r.Context(), func(c dto.ExportCourier) error {
+		if written == 0 {
+			if err := writeHeaders(); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if format == formatCSV {
+			err = csvWriter.Write([]string{
+				strconv.Itoa(c.Id),
+				c.Name,
+				c.Phone,
+				c.Status,
+				c.TransportType,
+				strconv.Itoa(c.TotalDeliveries),
+				c.CreatedAt.Format(time.RFC3339),
+			})
+		} else {
+			err = jsonEncoder.Encode(c)
+		}
+		if err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
+
+	if err != nil && written == 0 {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+	if err != nil {
+		ch.log.ErrorContext(r.Context(), "couriers export interrupted", "written", written, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+	if written == 0 {
+		// пустая таблица - отдаем пустую выгрузку (для CSV только заголовок)
+		_ = writeHeaders()
+	}
+	_ = flush()
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/csv":
+		return formatCSV
+	case "application/x-ndjson", "application/jsonl", "application/jsonlines":
+		return formatNDJSON
+	default:
+		return ""
+	}
+}
+
+// decodeCSV читает CSV с заголовком. Порядок колонок произвольный, лишние колонки игнорируются,
+// поэтому файл выгрузки можно загрузить обратно без изменений
+func decodeCSV(r io.Reader) ([]dto.CreateCourierRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errImportBadHeader
+	}
+	if _, ok := columns["phone"]; !ok {
+		return nil, errImportBadHeader
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	couriers := make([]dto.CreateCourierRequest, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(couriers) == maxImportRows {
+			return nil, errImportTooManyRows
+		}
+
+		couriers = append(couriers, dto.CreateCourierRequest{
+			Name:          field(record, "name"),
+			Phone:         field(record, "phone"),
+			Status:        field(record, "status"),
+			TransportType: field(record, "transport_type"),
+		})
+	}
+
+	return couriers, nil
+}
+
+// decodeNDJSON читает по одному JSON-объекту на строку. Пустые строки пропускаются
+func decodeNDJSON(r io.Reader) ([]dto.CreateCourierRequest, error) {
+	scanner := bufio.NewScanner(r)
+
+	couriers := make([]dto.CreateCourierRequest, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		if len(couriers) == maxImportRows {
+			return nil, errImportTooManyRows
+		}
+
+		var courier dto.CreateCourierRequest
+		if err := json.Unmarshal([]byte(raw), &courier); err != nil {
+			return nil, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+		}
+		couriers = append(couriers, courier)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return couriers, nil
+}
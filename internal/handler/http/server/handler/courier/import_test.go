@@ -0,0 +1,311 @@
+package courier
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/handler/http/server/handler/courier/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCourierHandler_Import_CSV(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_courier.NewMockсourierService(ctrl)
+	handler := NewCourierHandler(nopLogger{}, mockService)
+
+	// порядок колонок произвольный, лишние колонки игнорируются
+	body := "phone,name,id,transport_type\n" +
+		"+79990000001,John,1,car\n" +
+		"+79990000002, Ivan ,2,\n"
+
+	expectedReq := &dto.ImportCouriersRequest{
+		Couriers: []dto.CreateCourierRequest{
+			{Name: "John", Phone: "+79990000001", TransportType: "car"},
+			{Name: "Ivan", Phone: "+79990000002"},
+		},
+		DryRun: true,
+	}
+	expectedResp := &dto.ImportCouriersResponse{Total: 2, DryRun: true}
+
+	mockService.
+		EXPECT().
+		ImportCouriers(gomock.Any(), expectedReq).
+		Return(expectedResp, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/couriers/import?dry_run=true", strings.NewReader(body))
+	r.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+
+	handler.Import(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var decoded dto.ImportCouriersResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, 2, decoded.Total)
+	require.True(t, decoded.DryRun)
+}
+
+func TestCourierHandler_Import_NDJSON(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_courier.NewMockсourierService(ctrl)
+	handler := NewCourierHandler(nopLogger{}, mockService)
+
+	body := `{"name":"John","phone":"+79990000001","status":"available","transport_type":"car"}` + "\n\n" +
+		`{"name":"Ivan","phone":"+79990000002"}` + "\n"
+
+	expectedReq := &dto.ImportCouriersRequest{
+		Couriers: []dto.CreateCourierRequest{
+			{Name: "John", Phone: "+79990000001", Status: "available", TransportType: "car"},
+			{Name: "Ivan", Phone: "+79990000002"},
+		},
+	}
+
+	mockService.
+		EXPECT().
+		ImportCouriers(gomock.Any(), expectedReq).
+		Return(&dto.ImportCouriersResponse{Total: 2, Created: 2}, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/couriers/import?format=ndjson", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Import(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestCourierHandler_Import_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		contentType    string
+		body           string
+		wantStatusCode int
+		wantErrMsg     string
+	}{
+		{
+			name:           "unsupported format",
+			url:            "/couriers/import",
+			contentType:    "application/xml",
+			body:           "<couriers/>",
+			wantStatusCode: http.StatusUnsupportedMediaType,
+			wantErrMsg:     server.ErrUnsupportedFormat,
+		},
+		{
+			name:           "csv without phone column",
+			url:            "/couriers/import?format=csv",
+			body:           "name\nJohn\n",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidImportFile + ": " + errImportBadHeader.Error(),
+		},
+		{
+			name:           "broken ndjson line",
+			url:            "/couriers/import?format=ndjson",
+			body:           `{"name":"John"}` + "\n" + `{"name":`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid dry_run",
+			url:            "/couriers/import?format=csv&dry_run=maybe",
+			body:           "name,phone\n",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidImportFile,
+		},
+		{
+			name:           "too many rows",
+			url:            "/couriers/import?format=csv",
+			body:           "name,phone\n" + strings.Repeat("John,+79990000001\n", maxImportRows+1),
+			wantStatusCode: http.StatusRequestEntityTooLarge,
+			wantErrMsg:     server.ErrImportTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_courier.NewMockсourierService(ctrl)
+			handler := NewCourierHandler(nopLogger{}, mockService)
+
+			r := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			handler.Import(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
+
+			var decoded dto.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+			if tt.wantErrMsg != "" {
+				require.Equal(t, tt.wantErrMsg, decoded.Detail)
+			}
+		})
+	}
+}
+
+func TestCourierHandler_Export(t *testing.T) {
+	createdAt := time.Date(2025, time.November, 1, 12, 0, 0, 0, time.UTC)
+	couriers := []dto.ExportCourier{
+		{Id: 1, Name: "John", Phone: "+79990000001", Status: "available", TransportType: "car", TotalDeliveries: 3, CreatedAt: createdAt},
+		{Id: 2, Name: "Ivan", Phone: "+79990000002", Status: "busy", TransportType: "on_foot", CreatedAt: createdAt},
+	}
+
+	tests := []struct {
+		name            string
+		url             string
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "csv by default",
+			url:             "/couriers/export",
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody: "id,name,phone,status,transport_type,total_deliveries,created_at\n" +
+				"1,John,+79990000001,available,car,3,2025-11-01T12:00:00Z\n" +
+				"2,Ivan,+79990000002,busy,on_foot,0,2025-11-01T12:00:00Z\n",
+		},
+		{
+			name:            "ndjson",
+			url:             "/couriers/export?format=ndjson",
+			wantContentType: "application/x-ndjson",
+			wantBody: `{"id":1,"name":"John","phone":"+79990000001","status":"available","transport_type":"car","total_deliveries":3,"created_at":"2025-11-01T12:00:00Z"}` + "\n" +
+				`{"id":2,"name":"Ivan","phone":"+79990000002","status":"busy","transport_type":"on_foot","total_deliveries":0,"created_at":"2025-11-01T12:00:00Z"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_courier.NewMockсourierService(ctrl)
+			handler := NewCourierHandler(nopLogger{}, mockService)
+
+			mockService.
+				EXPECT().
+				ExportCouriers(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, fn func(dto.ExportCourier) error) error {
+					for _, c := range couriers {
+						if err := fn(c); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			handler.Export(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tt.wantContentType, resp.Header.Get("Content-Type"))
+			require.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestCourierHandler_Export_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// выгрузка в CSV должна читаться импортом без изменений
+	body := "id,name,phone,status,transport_type,total_deliveries,created_at\n" +
+		"1,John,+79990000001,available,car,3,2025-11-01T12:00:00Z\n"
+
+	couriers, err := decodeCSV(strings.NewReader(body))
+	require.NoError(t, err)
+	require.Equal(t, []dto.CreateCourierRequest{
+		{Name: "John", Phone: "+79990000001", Status: "available", TransportType: "car"},
+	}, couriers)
+}
+
+func TestCourierHandler_Export_ServiceError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_courier.NewMockсourierService(ctrl)
+	handler := NewCourierHandler(nopLogger{}, mockService)
+
+	mockService.
+		EXPECT().
+		ExportCouriers(gomock.Any(), gomock.Any()).
+		Return(service.ErrInternalError)
+
+	r := httptest.NewRequest(http.MethodGet, "/couriers/export", nil)
+	w := httptest.NewRecorder()
+
+	handler.Export(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	var decoded dto.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, server.ErrInternalError, decoded.Detail)
+}
+
+func TestCourierHandler_Export_ErrorAfterFirstRow(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_courier.NewMockсourierService(ctrl)
+	handler := NewCourierHandler(nopLogger{}, mockService)
+
+	mockService.
+		EXPECT().
+		ExportCouriers(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(dto.ExportCourier) error) error {
+			require.NoError(t, fn(dto.ExportCourier{Id: 1, Name: "John", Phone: "+79990000001"}))
+			return service.ErrInternalError
+		})
+
+	r := httptest.NewRequest(http.MethodGet, "/couriers/export", nil)
+	w := httptest.NewRecorder()
+
+	// статус 200 уже ушел, обрезанный файл не должен выглядеть как успешная выгрузка
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() { handler.Export(w, r) })
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/http/server/handler/courier/courier.go
+// Source: internal/handler/http/server/handler/courier/courier.go
 
 // Package mock_courier is a generated GoMock package.
 package mock_courier
@@ -64,6 +64,20 @@ func (mr *MockсourierServiceMockRecorder) DeleteCourier(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCourier", reflect.TypeOf((*MockсourierService)(nil).DeleteCourier), arg0, arg1)
 }
 
+// ExportCouriers mocks base method.
+func (m *MockсourierService) ExportCouriers(arg0 context.Context, arg1 func(dto.ExportCourier) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportCouriers", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportCouriers indicates an expected call of ExportCouriers.
+func (mr *MockсourierServiceMockRecorder) ExportCouriers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportCouriers", reflect.TypeOf((*MockсourierService)(nil).ExportCouriers), arg0, arg1)
+}
+
 // GetAllCouriers mocks base method.
 func (m *MockсourierService) GetAllCouriers(arg0 context.Context) ([]dto.GetCourierResponse, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +108,21 @@ func (mr *MockсourierServiceMockRecorder) GetCourier(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCourier", reflect.TypeOf((*MockсourierService)(nil).GetCourier), arg0, arg1)
 }
 
+// ImportCouriers mocks base method.
+func (m *MockсourierService) ImportCouriers(arg0 context.Context, arg1 *dto.ImportCouriersRequest) (*dto.ImportCouriersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCouriers", arg0, arg1)
+	ret0, _ := ret[0].(*dto.ImportCouriersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportCouriers indicates an expected call of ImportCouriers.
+func (mr *MockсourierServiceMockRecorder) ImportCouriers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCouriers", reflect.TypeOf((*MockсourierService)(nil).ImportCouriers), arg0, arg1)
+}
+
 // UpdateCourier mocks base method.
 func (m *MockсourierService) UpdateCourier(arg0 context.Context, arg1 *dto.UpdateCourierRequest) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,39 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"service-order-avito/internal/domain/dto"
+)
+
+// mockgen -source="internal/handler/http/server/handler/debug/debug.go" -destination="internal/handler/http/server/handler/debug/mocks/mock_debug_source.go"
+type kafkaConsumerState interface {
+	Snapshot() dto.KafkaConsumerResponse
+}
+
+type leaderState interface {
+	Status() dto.LeaderResponse
+}
+
+type debugHandler struct {
+	kafka  kafkaConsumerState
+	leader leaderState
+}
+
+func NewDebugHandler(kafka kafkaConsumerState, leader leaderState) *debugHandler {
+	return &debugHandler{kafka: kafka, leader: leader}
+}
+
+// GetKafka текущая сессия консьюмера, его партиции и оффсеты
+func (dh *debugHandler) GetKafka(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(dh.kafka.Snapshot())
+}
+
+// GetLeader какая реплика сейчас лидер и какие синглтон воркеры на ней работают
+func (dh *debugHandler) GetLeader(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(dh.leader.Status())
+}
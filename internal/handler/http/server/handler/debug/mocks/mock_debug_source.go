@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/http/server/handler/debug/debug.go
+
+// Package mock_debug is a generated GoMock package.
+package mock_debug
+
+import (
+	reflect "reflect"
+	dto "service-order-avito/internal/domain/dto"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockkafkaConsumerState is a mock of kafkaConsumerState interface.
+type MockkafkaConsumerState struct {
+	ctrl     *gomock.Controller
+	recorder *MockkafkaConsumerStateMockRecorder
+}
+
+// MockkafkaConsumerStateMockRecorder is the mock recorder for MockkafkaConsumerState.
+type MockkafkaConsumerStateMockRecorder struct {
+	mock *MockkafkaConsumerState
+}
+
+// NewMockkafkaConsumerState creates a new mock instance.
+func NewMockkafkaConsumerState(ctrl *gomock.Controller) *MockkafkaConsumerState {
+	mock := &MockkafkaConsumerState{ctrl: ctrl}
+	mock.recorder = &MockkafkaConsumerStateMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkafkaConsumerState) EXPECT() *MockkafkaConsumerStateMockRecorder {
+	return m.recorder
+}
+
+// Snapshot mocks base method.
+func (m *MockkafkaConsumerState) Snapshot() dto.KafkaConsumerResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(dto.KafkaConsumerResponse)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockkafkaConsumerStateMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockkafkaConsumerState)(nil).Snapshot))
+}
+
+// MockleaderState is a mock of leaderState interface.
+type MockleaderState struct {
+	ctrl     *gomock.Controller
+	recorder *MockleaderStateMockRecorder
+}
+
+// MockleaderStateMockRecorder is the mock recorder for MockleaderState.
+type MockleaderStateMockRecorder struct {
+	mock *MockleaderState
+}
+
+// NewMockleaderState creates a new mock instance.
+func NewMockleaderState(ctrl *gomock.Controller) *MockleaderState {
+	mock := &MockleaderState{ctrl: ctrl}
+	mock.recorder = &MockleaderStateMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockleaderState) EXPECT() *MockleaderStateMockRecorder {
+	return m.recorder
+}
+
+// Status mocks base method.
+func (m *MockleaderState) Status() dto.LeaderResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(dto.LeaderResponse)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockleaderStateMockRecorder) Status() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockleaderState)(nil).Status))
+}
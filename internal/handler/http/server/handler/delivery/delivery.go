@@ -7,12 +7,17 @@ import (
 	"service-order-avito/internal/adapters"
 	"service-order-avito/internal/domain/dto"
 	"service-order-avito/internal/domain/errors/server"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
 )
 
-// mockgen -source="internal/http/server/handler/delivery/delivery.go" -destination="internal/http/server/handler/delivery/mocks/mock_delivery_service.go"
+// mockgen -source="internal/handler/http/server/handler/delivery/delivery.go" -destination="internal/handler/http/server/handler/delivery/mocks/mock_delivery_service.go"
 type deliveryService interface {
 	Assign(context.Context, *dto.AssignDeliveryRequest) (*dto.AssignDeliveryResponse, error)
 	Unassign(context.Context, *dto.UnassignDeliveryRequest) (*dto.UnassignDeliveryResponse, error)
+	AddEvent(context.Context, *dto.AddDeliveryEventRequest) (*dto.AddDeliveryEventResponse, error)
+	GetTimeline(context.Context, *dto.GetDeliveryTimelineRequest) (*dto.DeliveryTimelineResponse, error)
 }
 
 type deliveryHandler struct {
@@ -26,12 +31,12 @@ func NewDeliveryHandler(service deliveryService) *deliveryHandler {
 func (dh *deliveryHandler) PostAssign(w http.ResponseWriter, r *http.Request) {
 	var req dto.AssignDeliveryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		adapters.WriteError(w, server.ErrInvalidJSON, http.StatusBadRequest)
+		adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
 		return
 	}
 	res, err := dh.service.Assign(r.Context(), &req)
 	if err != nil {
-		adapters.WriteServiceError(w, err)
+		adapters.WriteServiceError(w, r, err)
 		return
 	}
 
@@ -43,12 +48,55 @@ func (dh *deliveryHandler) PostAssign(w http.ResponseWriter, r *http.Request) {
 func (dh *deliveryHandler) PostUnassign(w http.ResponseWriter, r *http.Request) {
 	var req dto.UnassignDeliveryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		adapters.WriteError(w, server.ErrInvalidJSON, http.StatusBadRequest)
+		adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
 		return
 	}
 	res, err := dh.service.Unassign(r.Context(), &req)
 	if err != nil {
-		adapters.WriteServiceError(w, err)
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (dh *deliveryHandler) PostEvent(w http.ResponseWriter, r *http.Request) {
+	orderId := strings.TrimSpace(chi.URLParam(r, "order_id"))
+	if orderId == "" {
+		adapters.WriteError(w, r, server.ErrInvalidOrderId, http.StatusBadRequest)
+		return
+	}
+
+	var req dto.AddDeliveryEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
+		return
+	}
+	req.OrderId = orderId
+
+	res, err := dh.service.AddEvent(r.Context(), &req)
+	if err != nil {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (dh *deliveryHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	orderId := strings.TrimSpace(chi.URLParam(r, "order_id"))
+	if orderId == "" {
+		adapters.WriteError(w, r, server.ErrInvalidOrderId, http.StatusBadRequest)
+		return
+	}
+
+	res, err := dh.service.GetTimeline(r.Context(), &dto.GetDeliveryTimelineRequest{OrderId: orderId})
+	if err != nil {
+		adapters.WriteServiceError(w, r, err)
 		return
 	}
 
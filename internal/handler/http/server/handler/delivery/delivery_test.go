@@ -2,7 +2,9 @@ package delivery
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -11,6 +13,7 @@ import (
 	"service-order-avito/internal/domain/errors/server"
 	"service-order-avito/internal/domain/errors/service"
 	"service-order-avito/internal/handler/http/server/handler/delivery/mocks"
+	"strings"
 	"testing"
 	"time"
 )
@@ -38,7 +41,7 @@ func TestCourierHandler_PostAssign_Success(t *testing.T) {
 
 	mockService.
 		EXPECT().
-		AssignDelivery(gomock.Any(), &reqBody).
+		Assign(gomock.Any(), &reqBody).
 		Return(expectedResp, nil)
 
 	r := httptest.NewRequest(http.MethodPost, "/delivery/assign", bytes.NewReader(bodyBytes))
@@ -82,11 +85,11 @@ func TestCourierHandler_PostAssign_InvalidJSON(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrInvalidJSON, decoded.Error.Message)
+	require.Equal(t, server.ErrInvalidJSON, decoded.Detail)
 }
 
 func TestCourierHandler_PostAssign_ServiceErrors(t *testing.T) {
@@ -144,7 +147,7 @@ func TestCourierHandler_PostAssign_ServiceErrors(t *testing.T) {
 
 			mockService.
 				EXPECT().
-				AssignDelivery(gomock.Any(), &tt.req).
+				Assign(gomock.Any(), &tt.req).
 				Return(tt.mockResp, tt.mockErr)
 
 			r := httptest.NewRequest(http.MethodPost, "/delivery/assign", bytes.NewReader(bodyBytes))
@@ -157,11 +160,11 @@ func TestCourierHandler_PostAssign_ServiceErrors(t *testing.T) {
 
 			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
 
-			var decoded dto.ErrorResponse
+			var decoded dto.Problem
 			err := json.NewDecoder(resp.Body).Decode(&decoded)
 			require.NoError(t, err)
 
-			require.Equal(t, tt.wantErrMsg, decoded.Error.Message)
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
 		})
 	}
 }
@@ -188,7 +191,7 @@ func TestCourierHandler_PostUnassign_Success(t *testing.T) {
 
 	mockService.
 		EXPECT().
-		UnassignDelivery(gomock.Any(), &reqBody).
+		Unassign(gomock.Any(), &reqBody).
 		Return(expectedResp, nil)
 
 	r := httptest.NewRequest(http.MethodPost, "/delivery/unassign", bytes.NewReader(bodyBytes))
@@ -231,11 +234,11 @@ func TestCourierHandler_PostUnassign_InvalidJSON(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var decoded dto.ErrorResponse
+	var decoded dto.Problem
 	err := json.NewDecoder(resp.Body).Decode(&decoded)
 	require.NoError(t, err)
 
-	require.Equal(t, server.ErrInvalidJSON, decoded.Error.Message)
+	require.Equal(t, server.ErrInvalidJSON, decoded.Detail)
 }
 
 func TestCourierHandler_PostUnassign_ServiceErrors(t *testing.T) {
@@ -283,7 +286,7 @@ func TestCourierHandler_PostUnassign_ServiceErrors(t *testing.T) {
 
 			mockService.
 				EXPECT().
-				UnassignDelivery(gomock.Any(), &tt.req).
+				Unassign(gomock.Any(), &tt.req).
 				Return(tt.mockResp, tt.mockErr)
 
 			r := httptest.NewRequest(http.MethodPost, "/delivery/unassign", bytes.NewReader(bodyBytes))
@@ -296,11 +299,171 @@ func TestCourierHandler_PostUnassign_ServiceErrors(t *testing.T) {
 
 			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
 
-			var decoded dto.ErrorResponse
+			var decoded dto.Problem
 			err := json.NewDecoder(resp.Body).Decode(&decoded)
 			require.NoError(t, err)
 
-			require.Equal(t, tt.wantErrMsg, decoded.Error.Message)
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
 		})
 	}
 }
+
+func withOrderId(r *http.Request, orderId string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("order_id", orderId)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestDeliveryHandler_PostEvent_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_delivery.NewMockdeliveryService(ctrl)
+	handler := NewDeliveryHandler(mockService)
+
+	at := time.Date(2025, time.December, 8, 12, 0, 0, 0, time.UTC)
+	mockService.
+		EXPECT().
+		AddEvent(gomock.Any(), &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: "picked_up"}).
+		Return(&dto.AddDeliveryEventResponse{OrderId: "ORDER-1", CourierId: 7, Status: "picked_up", OccurredAt: at}, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/delivery/ORDER-1/events", strings.NewReader(`{"status":"picked_up"}`))
+	w := httptest.NewRecorder()
+
+	handler.PostEvent(w, withOrderId(r, "ORDER-1"))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var decoded dto.AddDeliveryEventResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, 7, decoded.CourierId)
+	require.Equal(t, at, decoded.OccurredAt)
+}
+
+func TestDeliveryHandler_PostEvent_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		wantStatusCode int
+		wantErrMsg     string
+	}{
+		{
+			name:           "invalid json",
+			body:           `{"status":`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidJSON,
+		},
+		{
+			name:           "unknown status",
+			body:           `{"status":"teleported"}`,
+			serviceErr:     service.ErrInvalidDeliveryStatus,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidDeliveryStatus,
+		},
+		{
+			name:           "out of order",
+			body:           `{"status":"arrived"}`,
+			serviceErr:     service.ErrInvalidStatusTransition,
+			wantStatusCode: http.StatusConflict,
+			wantErrMsg:     server.ErrInvalidStatusTransition,
+		},
+		{
+			name:           "wrong handoff code",
+			body:           `{"status":"delivered","handoff_code":"1111"}`,
+			serviceErr:     service.ErrInvalidHandoffCode,
+			wantStatusCode: http.StatusForbidden,
+			wantErrMsg:     server.ErrInvalidHandoffCode,
+		},
+		{
+			name:           "handoff locked",
+			body:           `{"status":"delivered","handoff_code":"1111"}`,
+			serviceErr:     service.ErrHandoffLocked,
+			wantStatusCode: http.StatusTooManyRequests,
+			wantErrMsg:     server.ErrHandoffLocked,
+		},
+		{
+			name:           "not found",
+			body:           `{"status":"accepted"}`,
+			serviceErr:     service.ErrDeliveryNotFound,
+			wantStatusCode: http.StatusNotFound,
+			wantErrMsg:     server.ErrDeliveryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_delivery.NewMockdeliveryService(ctrl)
+			handler := NewDeliveryHandler(mockService)
+
+			if tt.serviceErr != nil {
+				mockService.
+					EXPECT().
+					AddEvent(gomock.Any(), gomock.Any()).
+					Return(nil, tt.serviceErr)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/delivery/ORDER-1/events", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.PostEvent(w, withOrderId(r, "ORDER-1"))
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
+
+			var decoded dto.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
+		})
+	}
+}
+
+func TestDeliveryHandler_GetTimeline_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_delivery.NewMockdeliveryService(ctrl)
+	handler := NewDeliveryHandler(mockService)
+
+	mockService.
+		EXPECT().
+		GetTimeline(gomock.Any(), &dto.GetDeliveryTimelineRequest{OrderId: "ORDER-1"}).
+		Return(&dto.DeliveryTimelineResponse{
+			OrderId: "ORDER-1",
+			Status:  "accepted",
+			Events: []dto.DeliveryTimelineEvent{
+				{Status: "assigned", CourierId: 7},
+				{Status: "accepted", CourierId: 7},
+			},
+		}, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/delivery/ORDER-1/timeline", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetTimeline(w, withOrderId(r, "ORDER-1"))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var decoded dto.DeliveryTimelineResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, "accepted", decoded.Status)
+	require.Len(t, decoded.Events, 2)
+}
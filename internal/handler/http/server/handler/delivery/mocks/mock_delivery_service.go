@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/http/server/handler/delivery/delivery.go
+// Source: internal/handler/http/server/handler/delivery/delivery.go
 
 // Package mock_delivery is a generated GoMock package.
 package mock_delivery
@@ -35,7 +35,22 @@ func (m *MockdeliveryService) EXPECT() *MockdeliveryServiceMockRecorder {
 	return m.recorder
 }
 
-// AssignDelivery mocks base method.
+// AddEvent mocks base method.
+func (m *MockdeliveryService) AddEvent(arg0 context.Context, arg1 *dto.AddDeliveryEventRequest) (*dto.AddDeliveryEventResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvent", arg0, arg1)
+	ret0, _ := ret[0].(*dto.AddDeliveryEventResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddEvent indicates an expected call of AddEvent.
+func (mr *MockdeliveryServiceMockRecorder) AddEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvent", reflect.TypeOf((*MockdeliveryService)(nil).AddEvent), arg0, arg1)
+}
+
+// Assign mocks base method.
 func (m *MockdeliveryService) Assign(arg0 context.Context, arg1 *dto.AssignDeliveryRequest) (*dto.AssignDeliveryResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Assign", arg0, arg1)
@@ -44,13 +59,28 @@ func (m *MockdeliveryService) Assign(arg0 context.Context, arg1 *dto.AssignDeliv
 	return ret0, ret1
 }
 
-// AssignDelivery indicates an expected call of AssignDelivery.
-func (mr *MockdeliveryServiceMockRecorder) AssignDelivery(arg0, arg1 interface{}) *gomock.Call {
+// Assign indicates an expected call of Assign.
+func (mr *MockdeliveryServiceMockRecorder) Assign(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assign", reflect.TypeOf((*MockdeliveryService)(nil).Assign), arg0, arg1)
 }
 
-// UnassignDelivery mocks base method.
+// GetTimeline mocks base method.
+func (m *MockdeliveryService) GetTimeline(arg0 context.Context, arg1 *dto.GetDeliveryTimelineRequest) (*dto.DeliveryTimelineResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeline", arg0, arg1)
+	ret0, _ := ret[0].(*dto.DeliveryTimelineResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeline indicates an expected call of GetTimeline.
+func (mr *MockdeliveryServiceMockRecorder) GetTimeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeline", reflect.TypeOf((*MockdeliveryService)(nil).GetTimeline), arg0, arg1)
+}
+
+// Unassign mocks base method.
 func (m *MockdeliveryService) Unassign(arg0 context.Context, arg1 *dto.UnassignDeliveryRequest) (*dto.UnassignDeliveryResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Unassign", arg0, arg1)
@@ -59,8 +89,8 @@ func (m *MockdeliveryService) Unassign(arg0 context.Context, arg1 *dto.UnassignD
 	return ret0, ret1
 }
 
-// UnassignDelivery indicates an expected call of UnassignDelivery.
-func (mr *MockdeliveryServiceMockRecorder) UnassignDelivery(arg0, arg1 interface{}) *gomock.Call {
+// Unassign indicates an expected call of Unassign.
+func (mr *MockdeliveryServiceMockRecorder) Unassign(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unassign", reflect.TypeOf((*MockdeliveryService)(nil).Unassign), arg0, arg1)
 }
@@ -0,0 +1,50 @@
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"strings"
+)
+
+// mockgen -source="internal/handler/http/server/handler/feedback/feedback.go" -destination="internal/handler/http/server/handler/feedback/mocks/mock_feedback_service.go"
+type feedbackService interface {
+	LeaveFeedback(context.Context, *dto.LeaveFeedbackRequest) (*dto.LeaveFeedbackResponse, error)
+}
+
+type feedbackHandler struct {
+	service feedbackService
+}
+
+func NewFeedbackHandler(service feedbackService) *feedbackHandler {
+	return &feedbackHandler{service: service}
+}
+
+func (fh *feedbackHandler) Post(w http.ResponseWriter, r *http.Request) {
+	orderId := strings.TrimSpace(chi.URLParam(r, "order_id"))
+	if orderId == "" {
+		adapters.WriteError(w, r, server.ErrInvalidOrderId, http.StatusBadRequest)
+		return
+	}
+
+	var req dto.LeaveFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidJSON, http.StatusBadRequest)
+		return
+	}
+	req.OrderId = orderId
+
+	res, err := fh.service.LeaveFeedback(r.Context(), &req)
+	if err != nil {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,140 @@
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/handler/http/server/handler/feedback/mocks"
+	"strings"
+	"testing"
+)
+
+func withOrderId(r *http.Request, orderId string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("order_id", orderId)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestFeedbackHandler_Post_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_feedback.NewMockfeedbackService(ctrl)
+	handler := NewFeedbackHandler(mockService)
+
+	body, _ := json.Marshal(dto.LeaveFeedbackRequest{Rating: 2, Tags: []string{"late"}, Comment: "cold food"})
+
+	mockService.
+		EXPECT().
+		LeaveFeedback(gomock.Any(), &dto.LeaveFeedbackRequest{
+			OrderId: "ORDER-1",
+			Rating:  2,
+			Tags:    []string{"late"},
+			Comment: "cold food",
+		}).
+		Return(&dto.LeaveFeedbackResponse{OrderId: "ORDER-1", CourierId: 7, Rating: 2, CourierRating: 4.1, CourierRatingCount: 12}, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/delivery/ORDER-1/feedback", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Post(w, withOrderId(r, "ORDER-1"))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var decoded dto.LeaveFeedbackResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, 7, decoded.CourierId)
+	require.Equal(t, 4.1, decoded.CourierRating)
+}
+
+func TestFeedbackHandler_Post_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		wantStatusCode int
+		wantErrMsg     string
+	}{
+		{
+			name:           "invalid json",
+			body:           `{"rating":`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidJSON,
+		},
+		{
+			name:           "invalid rating",
+			body:           `{"rating":7}`,
+			serviceErr:     service.ErrInvalidRating,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidRating,
+		},
+		{
+			name:           "already rated",
+			body:           `{"rating":5}`,
+			serviceErr:     service.ErrFeedbackExists,
+			wantStatusCode: http.StatusConflict,
+			wantErrMsg:     server.ErrFeedbackExists,
+		},
+		{
+			name:           "not completed",
+			body:           `{"rating":5}`,
+			serviceErr:     service.ErrDeliveryNotCompleted,
+			wantStatusCode: http.StatusConflict,
+			wantErrMsg:     server.ErrDeliveryNotCompleted,
+		},
+		{
+			name:           "not found",
+			body:           `{"rating":5}`,
+			serviceErr:     service.ErrDeliveryNotFound,
+			wantStatusCode: http.StatusNotFound,
+			wantErrMsg:     server.ErrDeliveryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_feedback.NewMockfeedbackService(ctrl)
+			handler := NewFeedbackHandler(mockService)
+
+			if tt.serviceErr != nil {
+				mockService.
+					EXPECT().
+					LeaveFeedback(gomock.Any(), gomock.Any()).
+					Return(nil, tt.serviceErr)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/delivery/ORDER-1/feedback", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Post(w, withOrderId(r, "ORDER-1"))
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
+
+			var decoded dto.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
+		})
+	}
+}
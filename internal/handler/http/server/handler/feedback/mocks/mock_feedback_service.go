@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/http/server/handler/feedback/feedback.go
+
+// Package mock_feedback is a generated GoMock package.
+package mock_feedback
+
+import (
+	context "context"
+	reflect "reflect"
+	dto "service-order-avito/internal/domain/dto"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockfeedbackService is a mock of feedbackService interface.
+type MockfeedbackService struct {
+	ctrl     *gomock.Controller
+	recorder *MockfeedbackServiceMockRecorder
+}
+
+// MockfeedbackServiceMockRecorder is the mock recorder for MockfeedbackService.
+type MockfeedbackServiceMockRecorder struct {
+	mock *MockfeedbackService
+}
+
+// NewMockfeedbackService creates a new mock instance.
+func NewMockfeedbackService(ctrl *gomock.Controller) *MockfeedbackService {
+	mock := &MockfeedbackService{ctrl: ctrl}
+	mock.recorder = &MockfeedbackServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockfeedbackService) EXPECT() *MockfeedbackServiceMockRecorder {
+	return m.recorder
+}
+
+// LeaveFeedback mocks base method.
+func (m *MockfeedbackService) LeaveFeedback(arg0 context.Context, arg1 *dto.LeaveFeedbackRequest) (*dto.LeaveFeedbackResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaveFeedback", arg0, arg1)
+	ret0, _ := ret[0].(*dto.LeaveFeedbackResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaveFeedback indicates an expected call of LeaveFeedback.
+func (mr *MockfeedbackServiceMockRecorder) LeaveFeedback(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveFeedback", reflect.TypeOf((*MockfeedbackService)(nil).LeaveFeedback), arg0, arg1)
+}
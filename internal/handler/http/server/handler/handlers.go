@@ -25,14 +25,3 @@ func PingGetHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
-
-func HealthcheckHeadHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	select {
-	case <-ctx.Done():
-		http.Error(w, server.ErrRequestCanceled, http.StatusRequestTimeout)
-	default:
-		w.WriteHeader(http.StatusNoContent)
-	}
-}
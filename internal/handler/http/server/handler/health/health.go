@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"service-order-avito/internal/domain/dto"
+)
+
+// mockgen -source="internal/handler/http/server/handler/health/health.go" -destination="internal/handler/http/server/handler/health/mocks/mock_health_probes.go"
+type probes interface {
+	Live(context.Context) dto.HealthResponse
+	Ready(context.Context) dto.HealthResponse
+}
+
+type healthHandler struct {
+	probes probes
+}
+
+func NewHealthHandler(probes probes) *healthHandler {
+	return &healthHandler{probes: probes}
+}
+
+func (hh *healthHandler) GetLivez(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, hh.probes.Live(r.Context()))
+}
+
+func (hh *healthHandler) GetReadyz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, hh.probes.Ready(r.Context()))
+}
+
+// HeadHealthcheck старая проба балансировщика, теперь отвечает по readiness
+func (hh *healthHandler) HeadHealthcheck(w http.ResponseWriter, r *http.Request) {
+	if hh.probes.Ready(r.Context()).Status != dto.HealthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeReport(w http.ResponseWriter, report dto.HealthResponse) {
+	status := http.StatusOK
+	if report.Status != dto.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/http/server/handler/health/health.go
+
+// Package mock_health is a generated GoMock package.
+package mock_health
+
+import (
+	context "context"
+	reflect "reflect"
+	dto "service-order-avito/internal/domain/dto"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockprobes is a mock of probes interface.
+type Mockprobes struct {
+	ctrl     *gomock.Controller
+	recorder *MockprobesMockRecorder
+}
+
+// MockprobesMockRecorder is the mock recorder for Mockprobes.
+type MockprobesMockRecorder struct {
+	mock *Mockprobes
+}
+
+// NewMockprobes creates a new mock instance.
+func NewMockprobes(ctrl *gomock.Controller) *Mockprobes {
+	mock := &Mockprobes{ctrl: ctrl}
+	mock.recorder = &MockprobesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockprobes) EXPECT() *MockprobesMockRecorder {
+	return m.recorder
+}
+
+// Live mocks base method.
+func (m *Mockprobes) Live(arg0 context.Context) dto.HealthResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Live", arg0)
+	ret0, _ := ret[0].(dto.HealthResponse)
+	return ret0
+}
+
+// Live indicates an expected call of Live.
+func (mr *MockprobesMockRecorder) Live(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Live", reflect.TypeOf((*Mockprobes)(nil).Live), arg0)
+}
+
+// Ready mocks base method.
+func (m *Mockprobes) Ready(arg0 context.Context) dto.HealthResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", arg0)
+	ret0, _ := ret[0].(dto.HealthResponse)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockprobesMockRecorder) Ready(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*Mockprobes)(nil).Ready), arg0)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/http/server/handler/proof/proof.go
+
+// Package mock_proof is a generated GoMock package.
+package mock_proof
+
+import (
+	context "context"
+	reflect "reflect"
+	dto "service-order-avito/internal/domain/dto"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockproofService is a mock of proofService interface.
+type MockproofService struct {
+	ctrl     *gomock.Controller
+	recorder *MockproofServiceMockRecorder
+}
+
+// MockproofServiceMockRecorder is the mock recorder for MockproofService.
+type MockproofServiceMockRecorder struct {
+	mock *MockproofService
+}
+
+// NewMockproofService creates a new mock instance.
+func NewMockproofService(ctrl *gomock.Controller) *MockproofService {
+	mock := &MockproofService{ctrl: ctrl}
+	mock.recorder = &MockproofServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockproofService) EXPECT() *MockproofServiceMockRecorder {
+	return m.recorder
+}
+
+// Upload mocks base method.
+func (m *MockproofService) Upload(arg0 context.Context, arg1 *dto.UploadProofRequest) (*dto.UploadProofResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", arg0, arg1)
+	ret0, _ := ret[0].(*dto.UploadProofResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockproofServiceMockRecorder) Upload(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockproofService)(nil).Upload), arg0, arg1)
+}
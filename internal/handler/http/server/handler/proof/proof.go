@@ -0,0 +1,97 @@
+package proof
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"strconv"
+	"strings"
+)
+
+// части формы больше этого размера multipart пишет во временные файлы, а не держит в памяти
+const maxFormMemory = 1 << 20 // 1 MiB
+
+// mockgen -source="internal/handler/http/server/handler/proof/proof.go" -destination="internal/handler/http/server/handler/proof/mocks/mock_proof_service.go"
+type proofService interface {
+	Upload(context.Context, *dto.UploadProofRequest) (*dto.UploadProofResponse, error)
+}
+
+type proofHandler struct {
+	service     proofService
+	maxBodySize int64
+}
+
+// NewProofHandler maxBodySize ограничивает всю форму целиком: фото, подпись и поля
+func NewProofHandler(service proofService, maxBodySize int64) *proofHandler {
+	return &proofHandler{service: service, maxBodySize: maxBodySize}
+}
+
+// Post принимает multipart/form-data: photo (файл), signature (файл, необязательно), pin, lat, lon
+func (ph *proofHandler) Post(w http.ResponseWriter, r *http.Request) {
+	orderId := strings.TrimSpace(chi.URLParam(r, "order_id"))
+	if orderId == "" {
+		adapters.WriteError(w, r, server.ErrInvalidOrderId, http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, ph.maxBodySize)
+	if err := r.ParseMultipartForm(maxFormMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			adapters.WriteError(w, r, server.ErrProofFileTooLarge, http.StatusRequestEntityTooLarge)
+			return
+		}
+		adapters.WriteError(w, r, server.ErrInvalidProofForm, http.StatusBadRequest)
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	photo, photoHeader, err := r.FormFile("photo")
+	if err != nil {
+		adapters.WriteError(w, r, server.ErrProofPhotoRequired, http.StatusBadRequest)
+		return
+	}
+	defer photo.Close()
+
+	req := dto.UploadProofRequest{
+		OrderId: orderId,
+		Photo:   dto.ProofFile{Content: photo, Size: photoHeader.Size},
+		PIN:     strings.TrimSpace(r.FormValue("pin")),
+	}
+
+	signature, signatureHeader, err := r.FormFile("signature")
+	switch {
+	case err == nil:
+		defer signature.Close()
+		req.Signature = &dto.ProofFile{Content: signature, Size: signatureHeader.Size}
+	case !errors.Is(err, http.ErrMissingFile):
+		adapters.WriteError(w, r, server.ErrInvalidProofForm, http.StatusBadRequest)
+		return
+	}
+
+	req.Latitude, err = strconv.ParseFloat(strings.TrimSpace(r.FormValue("lat")), 64)
+	if err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidCoordinates, http.StatusBadRequest)
+		return
+	}
+	req.Longitude, err = strconv.ParseFloat(strings.TrimSpace(r.FormValue("lon")), 64)
+	if err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidCoordinates, http.StatusBadRequest)
+		return
+	}
+
+	res, err := ph.service.Upload(r.Context(), &req)
+	if err != nil {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(res)
+}
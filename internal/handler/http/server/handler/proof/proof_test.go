@@ -0,0 +1,187 @@
+package proof
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/handler/http/server/handler/proof/mocks"
+	"testing"
+)
+
+func withOrderId(r *http.Request, orderId string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("order_id", orderId)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+// newForm собирает multipart-форму. Файлы передаются как имя поля -> содержимое
+func newForm(t *testing.T, fields map[string]string, files map[string][]byte) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, mw.WriteField(name, value))
+	}
+	for name, content := range files {
+		fw, err := mw.CreateFormFile(name, name+".png")
+		require.NoError(t, err)
+		_, err = fw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	return &body, mw.FormDataContentType()
+}
+
+func TestProofHandler_Post_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_proof.NewMockproofService(ctrl)
+	handler := NewProofHandler(mockService, 1<<20)
+
+	body, contentType := newForm(t,
+		map[string]string{"pin": "0421", "lat": "55.75", "lon": "37.61"},
+		map[string][]byte{"photo": []byte("photo-bytes"), "signature": []byte("sig")},
+	)
+
+	mockService.
+		EXPECT().
+		Upload(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *dto.UploadProofRequest) (*dto.UploadProofResponse, error) {
+			require.Equal(t, "ORDER-1", req.OrderId)
+			require.Equal(t, "0421", req.PIN)
+			require.Equal(t, 55.75, req.Latitude)
+			require.Equal(t, 37.61, req.Longitude)
+
+			photo, err := io.ReadAll(req.Photo.Content)
+			require.NoError(t, err)
+			require.Equal(t, "photo-bytes", string(photo))
+			require.Equal(t, int64(len("photo-bytes")), req.Photo.Size)
+			require.NotNil(t, req.Signature)
+
+			return &dto.UploadProofResponse{OrderId: "ORDER-1", CourierId: 7}, nil
+		})
+
+	r := httptest.NewRequest(http.MethodPost, "/delivery/ORDER-1/proof", body)
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+
+	handler.Post(w, withOrderId(r, "ORDER-1"))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var decoded dto.UploadProofResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, 7, decoded.CourierId)
+}
+
+func TestProofHandler_Post_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		fields         map[string]string
+		files          map[string][]byte
+		rawBody        string
+		serviceErr     error
+		wantStatusCode int
+		wantErrMsg     string
+	}{
+		{
+			name:           "not multipart",
+			rawBody:        `{"pin":"1234"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidProofForm,
+		},
+		{
+			name:           "photo missing",
+			fields:         map[string]string{"pin": "1234", "lat": "1", "lon": "1"},
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrProofPhotoRequired,
+		},
+		{
+			name:           "bad coordinates",
+			fields:         map[string]string{"pin": "1234", "lat": "north", "lon": "1"},
+			files:          map[string][]byte{"photo": []byte("x")},
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidCoordinates,
+		},
+		{
+			name:           "body too large",
+			fields:         map[string]string{"pin": "1234", "lat": "1", "lon": "1"},
+			files:          map[string][]byte{"photo": bytes.Repeat([]byte("x"), 4096)},
+			wantStatusCode: http.StatusRequestEntityTooLarge,
+			wantErrMsg:     server.ErrProofFileTooLarge,
+		},
+		{
+			name:           "unsupported content",
+			fields:         map[string]string{"pin": "1234", "lat": "1", "lon": "1"},
+			files:          map[string][]byte{"photo": []byte("x")},
+			serviceErr:     service.ErrProofContentType,
+			wantStatusCode: http.StatusUnsupportedMediaType,
+			wantErrMsg:     server.ErrProofContentType,
+		},
+		{
+			name:           "proof exists",
+			fields:         map[string]string{"pin": "1234", "lat": "1", "lon": "1"},
+			files:          map[string][]byte{"photo": []byte("x")},
+			serviceErr:     service.ErrProofExists,
+			wantStatusCode: http.StatusConflict,
+			wantErrMsg:     server.ErrProofExists,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_proof.NewMockproofService(ctrl)
+			handler := NewProofHandler(mockService, 2048)
+
+			if tt.serviceErr != nil {
+				mockService.
+					EXPECT().
+					Upload(gomock.Any(), gomock.Any()).
+					Return(nil, tt.serviceErr)
+			}
+
+			var r *http.Request
+			if tt.rawBody != "" {
+				r = httptest.NewRequest(http.MethodPost, "/delivery/ORDER-1/proof", bytes.NewBufferString(tt.rawBody))
+				r.Header.Set("Content-Type", "application/json")
+			} else {
+				body, contentType := newForm(t, tt.fields, tt.files)
+				r = httptest.NewRequest(http.MethodPost, "/delivery/ORDER-1/proof", body)
+				r.Header.Set("Content-Type", contentType)
+			}
+			w := httptest.NewRecorder()
+
+			handler.Post(w, withOrderId(r, "ORDER-1"))
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
+
+			var decoded dto.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
+		})
+	}
+}
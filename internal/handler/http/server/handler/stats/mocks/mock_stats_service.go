@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/http/server/handler/stats/stats.go
+
+// Package mock_stats is a generated GoMock package.
+package mock_stats
+
+import (
+	context "context"
+	reflect "reflect"
+	dto "service-order-avito/internal/domain/dto"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockstatsService is a mock of statsService interface.
+type MockstatsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockstatsServiceMockRecorder
+}
+
+// MockstatsServiceMockRecorder is the mock recorder for MockstatsService.
+type MockstatsServiceMockRecorder struct {
+	mock *MockstatsService
+}
+
+// NewMockstatsService creates a new mock instance.
+func NewMockstatsService(ctrl *gomock.Controller) *MockstatsService {
+	mock := &MockstatsService{ctrl: ctrl}
+	mock.recorder = &MockstatsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockstatsService) EXPECT() *MockstatsServiceMockRecorder {
+	return m.recorder
+}
+
+// GetCourierStats mocks base method.
+func (m *MockstatsService) GetCourierStats(arg0 context.Context, arg1 *dto.GetCourierStatsRequest) (*dto.CourierStatsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCourierStats", arg0, arg1)
+	ret0, _ := ret[0].(*dto.CourierStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCourierStats indicates an expected call of GetCourierStats.
+func (mr *MockstatsServiceMockRecorder) GetCourierStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCourierStats", reflect.TypeOf((*MockstatsService)(nil).GetCourierStats), arg0, arg1)
+}
+
+// GetFleetStats mocks base method.
+func (m *MockstatsService) GetFleetStats(arg0 context.Context, arg1 *dto.GetFleetStatsRequest) (*dto.FleetStatsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFleetStats", arg0, arg1)
+	ret0, _ := ret[0].(*dto.FleetStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFleetStats indicates an expected call of GetFleetStats.
+func (mr *MockstatsServiceMockRecorder) GetFleetStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFleetStats", reflect.TypeOf((*MockstatsService)(nil).GetFleetStats), arg0, arg1)
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"strconv"
+	"time"
+)
+
+// mockgen -source="internal/handler/http/server/handler/stats/stats.go" -destination="internal/handler/http/server/handler/stats/mocks/mock_stats_service.go"
+type statsService interface {
+	GetCourierStats(context.Context, *dto.GetCourierStatsRequest) (*dto.CourierStatsResponse, error)
+	GetFleetStats(context.Context, *dto.GetFleetStatsRequest) (*dto.FleetStatsResponse, error)
+}
+
+type statsHandler struct {
+	service statsService
+}
+
+func NewStatsHandler(service statsService) *statsHandler {
+	return &statsHandler{service: service}
+}
+
+func (sh *statsHandler) GetCourier(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidCourierId, http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidStatsPeriod, http.StatusBadRequest)
+		return
+	}
+
+	res, err := sh.service.GetCourierStats(r.Context(), &dto.GetCourierStatsRequest{CourierId: id, From: from, To: to})
+	if err != nil {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (sh *statsHandler) GetFleet(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		adapters.WriteError(w, r, server.ErrInvalidStatsPeriod, http.StatusBadRequest)
+		return
+	}
+
+	res, err := sh.service.GetFleetStats(r.Context(), &dto.GetFleetStatsRequest{From: from, To: to})
+	if err != nil {
+		adapters.WriteServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// parsePeriod читает from/to из query. Пустые значения остаются нулевыми, их заполняет сервис
+func parsePeriod(r *http.Request) (from, to time.Time, err error) {
+	if from, err = parseTime(r.URL.Query().Get("from")); err != nil {
+		return
+	}
+	to, err = parseTime(r.URL.Query().Get("to"))
+	return
+}
+
+// parseTime принимает RFC3339 или просто дату (полночь UTC)
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
@@ -0,0 +1,169 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/server"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/handler/http/server/handler/stats/mocks"
+	"testing"
+	"time"
+)
+
+func withCourierId(r *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestStatsHandler_GetCourier_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_stats.NewMockstatsService(ctrl)
+	handler := NewStatsHandler(mockService)
+
+	expectedReq := &dto.GetCourierStatsRequest{
+		CourierId: 1,
+		From:      time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2025, time.December, 8, 12, 0, 0, 0, time.UTC),
+	}
+
+	mockService.
+		EXPECT().
+		GetCourierStats(gomock.Any(), expectedReq).
+		Return(&dto.CourierStatsResponse{CourierId: 1, Completed: 5, OnTimeRate: 0.8}, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/courier/1/stats?from=2025-12-01&to=2025-12-08T12:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetCourier(w, withCourierId(r, "1"))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var decoded dto.CourierStatsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, 5, decoded.Completed)
+	require.Equal(t, 0.8, decoded.OnTimeRate)
+}
+
+func TestStatsHandler_GetCourier_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		url            string
+		serviceErr     error
+		wantStatusCode int
+		wantErrMsg     string
+	}{
+		{
+			name:           "invalid id",
+			id:             "abc",
+			url:            "/courier/abc/stats",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidCourierId,
+		},
+		{
+			name:           "invalid from",
+			id:             "1",
+			url:            "/courier/1/stats?from=yesterday",
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidStatsPeriod,
+		},
+		{
+			name:           "courier not found",
+			id:             "1",
+			url:            "/courier/1/stats",
+			serviceErr:     service.ErrCourierNotFound,
+			wantStatusCode: http.StatusNotFound,
+			wantErrMsg:     server.ErrCourierNotFound,
+		},
+		{
+			name:           "invalid period",
+			id:             "1",
+			url:            "/courier/1/stats?from=2025-12-08&to=2025-12-01",
+			serviceErr:     service.ErrInvalidStatsPeriod,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrMsg:     server.ErrInvalidStatsPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock_stats.NewMockstatsService(ctrl)
+			handler := NewStatsHandler(mockService)
+
+			if tt.serviceErr != nil {
+				mockService.
+					EXPECT().
+					GetCourierStats(gomock.Any(), gomock.Any()).
+					Return(nil, tt.serviceErr)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetCourier(w, withCourierId(r, tt.id))
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatusCode, resp.StatusCode)
+
+			var decoded dto.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+			require.Equal(t, tt.wantErrMsg, decoded.Detail)
+		})
+	}
+}
+
+func TestStatsHandler_GetFleet_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_stats.NewMockstatsService(ctrl)
+	handler := NewStatsHandler(mockService)
+
+	// без параметров период выбирает сервис
+	mockService.
+		EXPECT().
+		GetFleetStats(gomock.Any(), &dto.GetFleetStatsRequest{}).
+		Return(&dto.FleetStatsResponse{
+			Couriers:         []dto.FleetCourierGroup{{Status: "available", TransportType: "car", Count: 3}},
+			ActiveDeliveries: 1,
+		}, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/couriers/stats", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetFleet(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var decoded dto.FleetStatsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	require.Equal(t, 1, decoded.ActiveDeliveries)
+	require.Len(t, decoded.Couriers, 1)
+}
@@ -3,12 +3,14 @@ package server
 import (
 	"net/http"
 	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/model"
 	"service-order-avito/internal/handler/http/middleware"
+	"service-order-avito/internal/handler/http/middleware/auth"
 	"service-order-avito/internal/handler/http/middleware/rate_limiter"
+	"service-order-avito/internal/handler/http/openapi"
 	"service-order-avito/internal/handler/http/server/handler"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type courierHandler interface {
@@ -17,48 +19,154 @@ type courierHandler interface {
 	GetAll(http.ResponseWriter, *http.Request)
 	Put(http.ResponseWriter, *http.Request)
 	Delete(http.ResponseWriter, *http.Request)
+	Import(http.ResponseWriter, *http.Request)
+	Export(http.ResponseWriter, *http.Request)
 }
 
 type deliveryHandler interface {
 	PostAssign(http.ResponseWriter, *http.Request)
 	PostUnassign(http.ResponseWriter, *http.Request)
+	PostEvent(http.ResponseWriter, *http.Request)
+	GetTimeline(http.ResponseWriter, *http.Request)
+}
+
+type feedbackHandler interface {
+	Post(http.ResponseWriter, *http.Request)
+}
+
+type proofHandler interface {
+	Post(http.ResponseWriter, *http.Request)
+}
+
+type statsHandler interface {
+	GetCourier(http.ResponseWriter, *http.Request)
+	GetFleet(http.ResponseWriter, *http.Request)
+}
+
+type healthHandler interface {
+	GetLivez(http.ResponseWriter, *http.Request)
+	GetReadyz(http.ResponseWriter, *http.Request)
+	HeadHealthcheck(http.ResponseWriter, *http.Request)
+}
+
+type debugHandler interface {
+	GetKafka(http.ResponseWriter, *http.Request)
+	GetLeader(http.ResponseWriter, *http.Request)
+}
+
+type adminHandler interface {
+	GetLogLevel(http.ResponseWriter, *http.Request)
+	PutLogLevel(http.ResponseWriter, *http.Request)
+	GetJobs(http.ResponseWriter, *http.Request)
+	PostJobRun(http.ResponseWriter, *http.Request)
 }
 
 type rateLimiter interface {
-	Allow() bool
+	Limit(*http.Request) rate_limiter.Decision
+}
+
+type authenticator interface {
+	Authenticate(*http.Request) (model.Principal, error)
+}
+
+type idempotency interface {
+	Handler(http.Handler) http.Handler
 }
 
 func InitRouter(log logger.LoggerAdapter,
 	courierHandler courierHandler,
 	deliveryHandler deliveryHandler,
+	feedbackHandler feedbackHandler,
+	statsHandler statsHandler,
+	proofHandler proofHandler,
+	healthHandler healthHandler,
+	debugHandler debugHandler,
+	adminHandler adminHandler,
 	metricObserver middleware.MetricsObserverHTTP,
-	rateLimiter rateLimiter) chi.Router {
+	metricsHandler http.Handler,
+	rateLimiter rateLimiter,
+	authenticator authenticator,
+	idempotency idempotency,
+	spec *openapi.Document) chi.Router {
 
 	router := chi.NewRouter()
 
 	router.Use(
+		middleware.WithTracing(),
+		middleware.WithRequestID(),
 		middleware.WithLogging(log),
 		middleware.WithMetrics(metricObserver),
 		rate_limiter.WithRateLimiter(rateLimiter, log),
+		auth.WithAuthentication(authenticator, log),
 	)
 
-	router.Handle("/metrics", promhttp.Handler())
+	// схема проверяется после роли: без доступа клиент получает 401/403, а не подробности валидации
+	validate := openapi.WithValidation(spec)
+	authorize := func(roles ...string) func(http.Handler) http.Handler {
+		require := auth.RequireRoles(roles...)
+		return func(next http.Handler) http.Handler {
+			return require(validate(next))
+		}
+	}
 
-	router.Get("/ping", handler.PingGetHandler)
-	router.Head("/healthcheck", handler.HealthcheckHeadHandler)
+	// admin доступно все, поэтому в списках ролей он не указывается.
+	// Курьер ограничен своим профилем и своими доставками, это проверяют сервисы
+	var (
+		adminOnly  = authorize()
+		staff      = authorize(model.RoleDispatcher)
+		system     = authorize(model.RoleService)
+		dispatch   = authorize(model.RoleDispatcher, model.RoleService)
+		courierApp = authorize(model.RoleDispatcher, model.RoleCourier)
+	)
+	// Idempotency-Key работает после авторизации, ключи хранятся отдельно для каждого клиента.
+	// Импорт и загрузка подтверждений не подключены: тела большие, а повтор и так дает конфликт
+	idempotent := idempotency.Handler
+
+	router.With(system).Method(http.MethodGet, "/metrics", metricsHandler)
 
-	router.Get("/couriers", courierHandler.GetAll)
+	router.Group(func(r chi.Router) {
+		r.Use(validate)
+		r.Get("/ping", handler.PingGetHandler)
+		r.Head("/healthcheck", healthHandler.HeadHealthcheck)
+		r.Get("/livez", healthHandler.GetLivez)
+		r.Get("/readyz", healthHandler.GetReadyz)
+		r.Get("/openapi.json", openapi.Handler)
+	})
+
+	router.Route("/debug", func(r chi.Router) {
+		r.With(adminOnly).Get("/kafka", debugHandler.GetKafka)
+		r.With(adminOnly).Get("/leader", debugHandler.GetLeader)
+	})
+
+	router.Route("/admin", func(r chi.Router) {
+		r.With(adminOnly).Get("/loglevel", adminHandler.GetLogLevel)
+		r.With(adminOnly).Put("/loglevel", adminHandler.PutLogLevel)
+		r.With(adminOnly).Get("/jobs", adminHandler.GetJobs)
+		r.With(adminOnly).Post("/jobs/{name}/run", adminHandler.PostJobRun)
+	})
+
+	router.Route("/couriers", func(r chi.Router) {
+		r.With(staff).Get("/", courierHandler.GetAll)
+		r.With(adminOnly).Post("/import", courierHandler.Import)
+		r.With(staff).Get("/export", courierHandler.Export)
+		r.With(staff).Get("/stats", statsHandler.GetFleet)
+	})
 
 	router.Route("/courier", func(r chi.Router) {
-		r.Get("/{id}", courierHandler.Get)
-		r.Post("/", courierHandler.Post)
-		r.Put("/", courierHandler.Put)
-		r.Delete("/{id}", courierHandler.Delete)
+		r.With(courierApp).Get("/{id}", courierHandler.Get)
+		r.With(courierApp).Get("/{id}/stats", statsHandler.GetCourier)
+		r.With(staff, idempotent).Post("/", courierHandler.Post)
+		r.With(courierApp).Put("/", courierHandler.Put)
+		r.With(adminOnly).Delete("/{id}", courierHandler.Delete)
 	})
 
 	router.Route("/delivery", func(r chi.Router) {
-		r.Post("/assign", deliveryHandler.PostAssign)
-		r.Post("/unassign", deliveryHandler.PostUnassign)
+		r.With(dispatch, idempotent).Post("/assign", deliveryHandler.PostAssign)
+		r.With(dispatch, idempotent).Post("/unassign", deliveryHandler.PostUnassign)
+		r.With(system, idempotent).Post("/{order_id}/feedback", feedbackHandler.Post)
+		r.With(courierApp, idempotent).Post("/{order_id}/events", deliveryHandler.PostEvent)
+		r.With(authorize(model.RoleDispatcher, model.RoleCourier, model.RoleService)).Get("/{order_id}/timeline", deliveryHandler.GetTimeline)
+		r.With(authorize(model.RoleCourier)).Post("/{order_id}/proof", proofHandler.Post)
 	})
 	return router
 }
@@ -0,0 +1,462 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/handler/http/middleware/auth"
+	"service-order-avito/internal/handler/http/middleware/rate_limiter"
+	"service-order-avito/internal/handler/http/openapi"
+	"service-order-avito/internal/handler/http/server/handler/admin"
+	mock_admin "service-order-avito/internal/handler/http/server/handler/admin/mocks"
+	"service-order-avito/internal/handler/http/server/handler/courier"
+	mock_courier "service-order-avito/internal/handler/http/server/handler/courier/mocks"
+	"service-order-avito/internal/handler/http/server/handler/debug"
+	mock_debug "service-order-avito/internal/handler/http/server/handler/debug/mocks"
+	"service-order-avito/internal/handler/http/server/handler/delivery"
+	mock_delivery "service-order-avito/internal/handler/http/server/handler/delivery/mocks"
+	"service-order-avito/internal/handler/http/server/handler/feedback"
+	mock_feedback "service-order-avito/internal/handler/http/server/handler/feedback/mocks"
+	"service-order-avito/internal/handler/http/server/handler/health"
+	mock_health "service-order-avito/internal/handler/http/server/handler/health/mocks"
+	"service-order-avito/internal/handler/http/server/handler/proof"
+	mock_proof "service-order-avito/internal/handler/http/server/handler/proof/mocks"
+	"service-order-avito/internal/handler/http/server/handler/stats"
+	mock_stats "service-order-avito/internal/handler/http/server/handler/stats/mocks"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/require"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type nopMetrics struct{}
+
+func (nopMetrics) IncTotalRequests()                                        {}
+func (nopMetrics) IncInFlight()                                             {}
+func (nopMetrics) DecInFlight()                                             {}
+func (nopMetrics) NewRequest(string, string, string, float64, int64, int64) {}
+func (nopMetrics) IncTotalRateLimitExceedances()                            {}
+
+type unlimited struct{}
+
+func (unlimited) Limit(*http.Request) rate_limiter.Decision {
+	return rate_limiter.Decision{Allowed: true, Limit: 100, Remaining: 100}
+}
+
+type passIdempotency struct{}
+
+func (passIdempotency) Handler(next http.Handler) http.Handler { return next }
+
+type services struct {
+	courier  *mock_courier.MockсourierService
+	delivery *mock_delivery.MockdeliveryService
+	feedback *mock_feedback.MockfeedbackService
+	stats    *mock_stats.MockstatsService
+	proof    *mock_proof.MockproofService
+	kafka    *mock_debug.MockkafkaConsumerState
+	leader   *mock_debug.MockleaderState
+	health   *mock_health.Mockprobes
+	levels   *mock_admin.MocklogLevels
+	jobs     *mock_admin.MockjobScheduler
+}
+
+func newTestRouter(t *testing.T) (chi.Router, *openapi.Document, services) {
+	return newTestRouterWithAuth(t, auth.NewDisabledAuthenticator())
+}
+
+func newTestRouterWithAuth(t *testing.T, authn authenticator) (chi.Router, *openapi.Document, services) {
+	ctrl := gomock.NewController(t)
+	s := services{
+		courier:  mock_courier.NewMockсourierService(ctrl),
+		delivery: mock_delivery.NewMockdeliveryService(ctrl),
+		feedback: mock_feedback.NewMockfeedbackService(ctrl),
+		stats:    mock_stats.NewMockstatsService(ctrl),
+		proof:    mock_proof.NewMockproofService(ctrl),
+		kafka:    mock_debug.NewMockkafkaConsumerState(ctrl),
+		leader:   mock_debug.NewMockleaderState(ctrl),
+		health:   mock_health.NewMockprobes(ctrl),
+		levels:   mock_admin.NewMocklogLevels(ctrl),
+		jobs:     mock_admin.NewMockjobScheduler(ctrl),
+	}
+
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	router := InitRouter(nopLogger{},
+		courier.NewCourierHandler(nopLogger{}, s.courier),
+		delivery.NewDeliveryHandler(s.delivery),
+		feedback.NewFeedbackHandler(s.feedback),
+		stats.NewStatsHandler(s.stats),
+		proof.NewProofHandler(s.proof, 1<<20),
+		health.NewHealthHandler(s.health),
+		debug.NewDebugHandler(s.kafka, s.leader),
+		admin.NewAdminHandler(nopLogger{}, s.levels, s.jobs),
+		nopMetrics{},
+		promhttp.HandlerFor(prometheus.NewRegistry(), promhttp.HandlerOpts{}),
+		unlimited{},
+		authn,
+		passIdempotency{},
+		doc,
+	)
+	return router, doc, s
+}
+
+func routes(t *testing.T, router chi.Router) []string {
+	var all []string
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		all = append(all, method+" "+route)
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(all)
+	return all
+}
+
+func TestRouter_AllRoutesDocumented(t *testing.T) {
+	router, doc, _ := newTestRouter(t)
+	require.Equal(t, routes(t, router), doc.Operations())
+}
+
+func TestRouter_ResponsesMatchSpec(t *testing.T) {
+	router, doc, s := newTestRouter(t)
+
+	now := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+	courierResp := dto.GetCourierResponse{Id: 7, Name: "Ivan", Phone: "+79991234567", Status: "available", TransportType: "car", Rating: 4.5, RatingCount: 2}
+	exportRow := dto.ExportCourier{Id: 7, Name: "Ivan", Phone: "+79991234567", Status: "available", TransportType: "car", CreatedAt: now}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	photo, err := mw.CreateFormFile("photo", "photo.png")
+	require.NoError(t, err)
+	_, _ = photo.Write([]byte("png"))
+	require.NoError(t, mw.WriteField("pin", "1234"))
+	require.NoError(t, mw.WriteField("lat", "55.75"))
+	require.NoError(t, mw.WriteField("lon", "37.61"))
+	require.NoError(t, mw.Close())
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		pattern     string
+		body        string
+		contentType string
+		setup       func()
+		wantStatus  int
+	}{
+		{name: "ping", method: http.MethodGet, url: "/ping", pattern: "/ping", wantStatus: http.StatusOK},
+		{
+			name: "healthcheck", method: http.MethodHead, url: "/healthcheck", pattern: "/healthcheck", wantStatus: http.StatusNoContent,
+			setup: func() {
+				s.health.EXPECT().Ready(gomock.Any()).Return(dto.HealthResponse{Status: dto.HealthStatusOK, Checks: []dto.HealthCheckResponse{}})
+			},
+		},
+		{
+			name: "healthcheck not ready", method: http.MethodHead, url: "/healthcheck", pattern: "/healthcheck", wantStatus: http.StatusServiceUnavailable,
+			setup: func() {
+				s.health.EXPECT().Ready(gomock.Any()).Return(dto.HealthResponse{Status: dto.HealthStatusFail, Checks: []dto.HealthCheckResponse{}})
+			},
+		},
+		{
+			name: "livez", method: http.MethodGet, url: "/livez", pattern: "/livez", wantStatus: http.StatusOK,
+			setup: func() {
+				s.health.EXPECT().Live(gomock.Any()).Return(dto.HealthResponse{Status: dto.HealthStatusOK, Checks: []dto.HealthCheckResponse{
+					{Name: "delivery_monitor_worker", Status: dto.HealthStatusOK, DurationMs: 0.01, Details: map[string]any{"last_beat": now}},
+				}})
+			},
+		},
+		{
+			name: "readyz failed", method: http.MethodGet, url: "/readyz", pattern: "/readyz", wantStatus: http.StatusServiceUnavailable,
+			setup: func() {
+				s.health.EXPECT().Ready(gomock.Any()).Return(dto.HealthResponse{Status: dto.HealthStatusFail, Checks: []dto.HealthCheckResponse{
+					{Name: "postgres", Status: dto.HealthStatusOK, DurationMs: 1.5, Details: map[string]any{"total_conns": 2}},
+					{Name: "order_service", Status: dto.HealthStatusFail, Error: "connection is TRANSIENT_FAILURE", DurationMs: 0.01},
+				}})
+			},
+		},
+		{name: "openapi", method: http.MethodGet, url: "/openapi.json", pattern: "/openapi.json", wantStatus: http.StatusOK},
+		{name: "metrics", method: http.MethodGet, url: "/metrics", pattern: "/metrics", wantStatus: http.StatusOK},
+		{
+			name: "kafka consumer", method: http.MethodGet, url: "/debug/kafka", pattern: "/debug/kafka", wantStatus: http.StatusOK,
+			setup: func() {
+				lastMessageAt := now.Add(time.Minute)
+				s.kafka.EXPECT().Snapshot().Return(dto.KafkaConsumerResponse{
+					Active: true, MemberId: "member-1", GenerationId: 3, SessionStartedAt: &now, Rebalances: 3,
+					Claims: []dto.KafkaClaimResponse{{
+						Topic: "order.changed", Partition: 0, InitialOffset: 10, LastOffset: 12, MarkedOffset: 13,
+						HighWaterMark: 20, Lag: 7, Messages: 3, ClaimedAt: now, LastMessageAt: &lastMessageAt,
+					}},
+				})
+			},
+		},
+		{
+			name: "leader", method: http.MethodGet, url: "/debug/leader", pattern: "/debug/leader", wantStatus: http.StatusOK,
+			setup: func() {
+				expiresAt := now.Add(15 * time.Second)
+				s.leader.EXPECT().Status().Return(dto.LeaderResponse{
+					Lease: "singleton_workers", InstanceId: "pod-1", Leader: true, Holder: "pod-1",
+					AcquiredAt: &now, ExpiresAt: &expiresAt, Jobs: []string{"delivery_monitor_worker"},
+				})
+			},
+		},
+		{
+			name: "get log level", method: http.MethodGet, url: "/admin/loglevel", pattern: "/admin/loglevel", wantStatus: http.StatusOK,
+			setup: func() {
+				s.levels.EXPECT().Global().Return(slog.LevelInfo)
+				s.levels.EXPECT().Components().Return(map[string]slog.Level{"middleware/auth": slog.LevelDebug})
+			},
+		},
+		{
+			name: "put invalid log level", method: http.MethodPut, url: "/admin/loglevel", pattern: "/admin/loglevel",
+			body: `{"level":"verbose"}`, wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "list jobs", method: http.MethodGet, url: "/admin/jobs", pattern: "/admin/jobs", wantStatus: http.StatusOK,
+			setup: func() {
+				nextRun := now.Add(5 * time.Second)
+				s.jobs.EXPECT().Status().Return(dto.JobsResponse{InstanceId: "pod-1", Active: true, Jobs: []dto.JobResponse{{
+					Name: "delivery_monitor", Schedule: "@every 5s", NextRun: &nextRun, LastSuccessAt: &now,
+					LastRun: &dto.JobRunResponse{Trigger: "schedule", StartedAt: now, DurationMs: 12, Outcome: "success", Attempts: 1},
+				}}})
+			},
+		},
+		{
+			name: "run job", method: http.MethodPost, url: "/admin/jobs/delivery_monitor/run", pattern: "/admin/jobs/{name}/run",
+			wantStatus: http.StatusAccepted,
+			setup: func() {
+				s.jobs.EXPECT().Trigger("delivery_monitor").Return(nil)
+				s.jobs.EXPECT().Status().Return(dto.JobsResponse{InstanceId: "pod-1", Active: true, Jobs: []dto.JobResponse{{
+					Name: "delivery_monitor", Schedule: "@every 5s", Running: true, RunningSince: &now,
+				}}})
+			},
+		},
+		{
+			name: "run job on follower", method: http.MethodPost, url: "/admin/jobs/delivery_monitor/run", pattern: "/admin/jobs/{name}/run",
+			wantStatus: http.StatusConflict,
+			setup: func() {
+				s.jobs.EXPECT().Trigger("delivery_monitor").Return(service.ErrSchedulerInactive)
+			},
+		},
+		{
+			name: "list couriers", method: http.MethodGet, url: "/couriers", pattern: "/couriers", wantStatus: http.StatusOK,
+			setup: func() {
+				s.courier.EXPECT().GetAllCouriers(gomock.Any()).Return([]dto.GetCourierResponse{courierResp}, nil)
+			},
+		},
+		{
+			name: "import couriers", method: http.MethodPost, url: "/couriers/import?dry_run=true", pattern: "/couriers/import",
+			body: `{"name":"Ivan","phone":"+79991234567","status":"available"}` + "\n", contentType: "application/x-ndjson", wantStatus: http.StatusOK,
+			setup: func() {
+				s.courier.EXPECT().ImportCouriers(gomock.Any(), gomock.Any()).Return(&dto.ImportCouriersResponse{
+					Total: 1, DryRun: true, Rows: []dto.ImportCourierRowResult{{Row: 1, Phone: "+79991234567", Status: dto.ImportRowStatusValid}},
+				}, nil)
+			},
+		},
+		{
+			name: "export couriers", method: http.MethodGet, url: "/couriers/export?format=ndjson", pattern: "/couriers/export", wantStatus: http.StatusOK,
+			setup: func() {
+				s.courier.EXPECT().ExportCouriers(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(dto.ExportCourier) error) error {
+					return fn(exportRow)
+				})
+			},
+		},
+		{
+			name: "fleet stats", method: http.MethodGet, url: "/couriers/stats?from=2025-12-01", pattern: "/couriers/stats", wantStatus: http.StatusOK,
+			setup: func() {
+				s.stats.EXPECT().GetFleetStats(gomock.Any(), gomock.Any()).Return(&dto.FleetStatsResponse{
+					From: now, To: now.Add(time.Hour),
+					Couriers:   []dto.FleetCourierGroup{{Status: "available", TransportType: "car", Count: 3}},
+					Deliveries: []dto.FleetDeliveryGroup{{TransportType: "car", Completed: 2, OnTimeRate: 1, AvgDeliveryDurationSec: 600}},
+				}, nil)
+			},
+		},
+		{
+			name: "get courier", method: http.MethodGet, url: "/courier/7", pattern: "/courier/{id}", wantStatus: http.StatusOK,
+			setup: func() {
+				s.courier.EXPECT().GetCourier(gomock.Any(), &dto.GetCourierRequest{Id: 7}).Return(&courierResp, nil)
+			},
+		},
+		{
+			name: "courier not found", method: http.MethodGet, url: "/courier/8", pattern: "/courier/{id}", wantStatus: http.StatusNotFound,
+			setup: func() {
+				s.courier.EXPECT().GetCourier(gomock.Any(), &dto.GetCourierRequest{Id: 8}).Return(nil, service.ErrCourierNotFound)
+			},
+		},
+		{
+			name: "courier stats", method: http.MethodGet, url: "/courier/7/stats", pattern: "/courier/{id}/stats", wantStatus: http.StatusOK,
+			setup: func() {
+				s.stats.EXPECT().GetCourierStats(gomock.Any(), gomock.Any()).Return(&dto.CourierStatsResponse{
+					CourierId: 7, From: now, To: now.Add(time.Hour), Completed: 1, OnTimeRate: 1,
+					AvgDeliveryDurationSec: map[string]float64{"car": 600}, ActiveHours: 0.5, Utilisation: 0.5,
+				}, nil)
+			},
+		},
+		{
+			name: "create courier", method: http.MethodPost, url: "/courier", pattern: "/courier",
+			body: `{"name":"Ivan","phone":"+79991234567","status":"available","transport_type":"car"}`, wantStatus: http.StatusCreated,
+			setup: func() {
+				s.courier.EXPECT().CreateCourier(gomock.Any(), gomock.Any()).Return(&dto.CreateCourierResponse{Id: 7}, nil)
+			},
+		},
+		{
+			name: "create courier with invalid body", method: http.MethodPost, url: "/courier", pattern: "/courier",
+			body: `{"name":"Ivan"}`, wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update courier", method: http.MethodPut, url: "/courier", pattern: "/courier",
+			body: `{"id":7,"status":"paused"}`, wantStatus: http.StatusOK,
+			setup: func() {
+				s.courier.EXPECT().UpdateCourier(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "delete courier", method: http.MethodDelete, url: "/courier/7", pattern: "/courier/{id}", wantStatus: http.StatusOK,
+			setup: func() {
+				s.courier.EXPECT().DeleteCourier(gomock.Any(), &dto.DeleteCourierRequest{Id: 7}).Return(nil)
+			},
+		},
+		{
+			name: "assign delivery", method: http.MethodPost, url: "/delivery/assign", pattern: "/delivery/assign",
+			body: `{"order_id":"ORDER-1","total_price":5000}`, wantStatus: http.StatusCreated,
+			setup: func() {
+				s.delivery.EXPECT().Assign(gomock.Any(), gomock.Any()).Return(&dto.AssignDeliveryResponse{
+					CourierId: 7, OrderId: "ORDER-1", TransportType: "car", DeliveryDeadline: now, HandoffCode: "1234",
+				}, nil)
+			},
+		},
+		{
+			name: "unassign delivery", method: http.MethodPost, url: "/delivery/unassign", pattern: "/delivery/unassign",
+			body: `{"order_id":"ORDER-1"}`, wantStatus: http.StatusOK,
+			setup: func() {
+				s.delivery.EXPECT().Unassign(gomock.Any(), gomock.Any()).Return(&dto.UnassignDeliveryResponse{
+					OrderId: "ORDER-1", Status: "unassigned", CourierId: 7,
+				}, nil)
+			},
+		},
+		{
+			name: "feedback", method: http.MethodPost, url: "/delivery/ORDER-1/feedback", pattern: "/delivery/{order_id}/feedback",
+			body: `{"rating":5,"tags":["late"]}`, wantStatus: http.StatusCreated,
+			setup: func() {
+				s.feedback.EXPECT().LeaveFeedback(gomock.Any(), gomock.Any()).Return(&dto.LeaveFeedbackResponse{
+					OrderId: "ORDER-1", CourierId: 7, Rating: 5, Tags: []string{"late"}, CourierRating: 4.5, CourierRatingCount: 2,
+				}, nil)
+			},
+		},
+		{
+			name: "delivery event", method: http.MethodPost, url: "/delivery/ORDER-1/events", pattern: "/delivery/{order_id}/events",
+			body: `{"status":"picked_up"}`, wantStatus: http.StatusCreated,
+			setup: func() {
+				s.delivery.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(&dto.AddDeliveryEventResponse{
+					OrderId: "ORDER-1", CourierId: 7, Status: "picked_up", OccurredAt: now,
+				}, nil)
+			},
+		},
+		{
+			name: "timeline", method: http.MethodGet, url: "/delivery/ORDER-1/timeline", pattern: "/delivery/{order_id}/timeline", wantStatus: http.StatusOK,
+			setup: func() {
+				s.delivery.EXPECT().GetTimeline(gomock.Any(), gomock.Any()).Return(&dto.DeliveryTimelineResponse{
+					OrderId: "ORDER-1", Status: "picked_up",
+					Events: []dto.DeliveryTimelineEvent{
+						{Status: "assigned", CourierId: 7, OccurredAt: now},
+						{Status: "picked_up", CourierId: 7, Actor: "courier:7", OccurredAt: now.Add(time.Minute)},
+					},
+				}, nil)
+			},
+		},
+		{
+			name: "proof", method: http.MethodPost, url: "/delivery/ORDER-1/proof", pattern: "/delivery/{order_id}/proof",
+			body: form.String(), contentType: mw.FormDataContentType(), wantStatus: http.StatusCreated,
+			setup: func() {
+				s.proof.EXPECT().Upload(gomock.Any(), gomock.Any()).Return(&dto.UploadProofResponse{
+					OrderId: "ORDER-1", CourierId: 7,
+					Photo:    dto.ProofFileInfo{Key: "ORDER-1/photo.png", ContentType: "image/png", Size: 3},
+					Latitude: 55.75, Longitude: 37.61, CreatedAt: now,
+				}, nil)
+			},
+		},
+	}
+
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			require.NoError(t, doc.ValidateResponse(tt.method, tt.pattern, rec.Code, rec.Header(), rec.Body.Bytes()))
+			covered[tt.method+" "+tt.pattern] = true
+		})
+	}
+
+	for _, route := range routes(t, router) {
+		require.True(t, covered[route], "no response check for %s", route)
+	}
+}
+
+// невалидное тело без доступа к роуту получает 401/403, а не подробности валидации схемы
+func TestRouter_AuthorizationBeforeValidation(t *testing.T) {
+	authn := auth.NewAuthenticator("X-API-Key", []model.APIKey{
+		{Name: "courier-app-7", KeyHash: auth.HashAPIKey("courier-key"), Role: model.RoleCourier, CourierId: 7},
+		{Name: "ops", KeyHash: auth.HashAPIKey("dispatcher-key"), Role: model.RoleDispatcher},
+	}, nil, nil)
+	router, _, _ := newTestRouterWithAuth(t, authn)
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		wantStatus int
+	}{
+		{name: "no credentials", wantStatus: http.StatusUnauthorized},
+		{name: "wrong role", apiKey: "courier-key", wantStatus: http.StatusForbidden},
+		{name: "allowed role", apiKey: "dispatcher-key", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/courier", strings.NewReader(`{"name":"Ivan"}`))
+			if tt.apiKey != "" {
+				r.Header.Set("X-API-Key", tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
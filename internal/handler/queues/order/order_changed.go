@@ -8,9 +8,18 @@ import (
 	"service-order-avito/internal/adapters/logger"
 	"service-order-avito/internal/domain/dto/kafka/order"
 	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/observability/requestid"
+	"service-order-avito/internal/observability/tracing"
+	"strconv"
 	"time"
 
 	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -18,6 +27,16 @@ const (
 	MAX_RETRIES = 3
 )
 
+// результаты обработки сообщения для метрик
+const (
+	OutcomeProcessed     = "processed"
+	OutcomeDuplicate     = "duplicate"      // доставка по заказу уже есть, сообщение пришло повторно
+	OutcomeStaleStatus   = "stale_status"   // статус в order-service уже другой
+	OutcomeUnknownStatus = "unknown_status" // для статуса нет стратегии
+	OutcomeFailed        = "failed"
+	OutcomeBadJSON       = "bad_json"
+)
+
 type usecase interface {
 	Process(context.Context, *order.Event) (*order.ProcessedEvent, error)
 }
@@ -30,88 +49,179 @@ type totalGatewayRetriesCounter interface {
 	IncTotalGatewayRetries()
 }
 
+type consumerMetrics interface {
+	ObserveMessage(topic string, partition int32, outcome string, duration time.Duration)
+	SetLag(topic string, partition int32, lag int64)
+	DeleteLag(topic string, partition int32)
+	IncRebalances()
+	ObserveSession(time.Duration)
+}
+
+// consumerState состояние сессии и партиций для /debug/kafka
+type consumerState interface {
+	SessionStarted(memberId string, generationId int32)
+	SessionEnded()
+	ClaimStarted(topic string, partition int32, initialOffset, highWaterMark int64)
+	ClaimEnded(topic string, partition int32)
+	MessageConsumed(topic string, partition int32, offset, highWaterMark int64)
+	MessageMarked(topic string, partition int32, offset int64)
+}
+
 type handler struct {
 	l           logger.LoggerAdapter
 	og          orderServiceGRPCGateway
 	uc          usecase
 	retrCounter totalGatewayRetriesCounter
+	metrics     consumerMetrics
+	state       consumerState
+
+	// Setup и Cleanup одной сессии sarama вызывает последовательно, поэтому без блокировки
+	sessionStartedAt time.Time
 }
 
-func NewOrderChangedHandler(l logger.LoggerAdapter, og orderServiceGRPCGateway, uc usecase, retrCounter totalGatewayRetriesCounter) *handler {
-	return &handler{l: l, og: og, uc: uc, retrCounter: retrCounter}
+func NewOrderChangedHandler(l logger.LoggerAdapter, og orderServiceGRPCGateway, uc usecase, retrCounter totalGatewayRetriesCounter, metrics consumerMetrics, state consumerState) *handler {
+	return &handler{l: l, og: og, uc: uc, retrCounter: retrCounter, metrics: metrics, state: state}
 }
 
+// Setup вызывается в начале каждой сессии группы, то есть после каждой ребалансировки
 func (h *handler) Setup(session sarama.ConsumerGroupSession) error {
+	h.sessionStartedAt = time.Now()
+	h.metrics.IncRebalances()
+	h.state.SessionStarted(session.MemberID(), session.GenerationID())
+	h.l.Info("order.changed handler: session started",
+		"member_id", session.MemberID(),
+		"generation_id", session.GenerationID(),
+		"claims", session.Claims(),
+	)
 	return nil
 }
 
 func (h *handler) Cleanup(session sarama.ConsumerGroupSession) error {
+	h.metrics.ObserveSession(time.Since(h.sessionStartedAt))
+	h.state.SessionEnded()
 	return nil
 }
 
 func (h *handler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	op := "order.changed.handler: "
-CLAIM_MSSG_LOOP:
+	topic, partition := claim.Topic(), claim.Partition()
+	h.state.ClaimStarted(topic, partition, claim.InitialOffset(), claim.HighWaterMarkOffset())
+	defer func() {
+		h.state.ClaimEnded(topic, partition)
+		h.metrics.DeleteLag(topic, partition)
+	}()
+
 	for dtoMsg := range claim.Messages() {
-		ctx := sess.Context()
-		h.l.Info("order.changed handler: received message",
-			"key", string(dtoMsg.Key),
-			"value", string(dtoMsg.Value),
-			"partition", int(dtoMsg.Partition),
-			"offset", dtoMsg.Offset,
-		)
+		// high water mark - оффсет следующего сообщения, которое будет записано в партицию
+		highWaterMark := claim.HighWaterMarkOffset()
+		h.state.MessageConsumed(topic, partition, dtoMsg.Offset, highWaterMark)
+		h.metrics.SetLag(topic, partition, max(highWaterMark-dtoMsg.Offset-1, 0))
+
+		start := time.Now()
+		outcome := h.handleMessage(sess, dtoMsg)
+		h.metrics.ObserveMessage(topic, partition, outcome, time.Since(start))
+	}
+	return nil
+}
 
-		var event order.Event
-		err := json.Unmarshal(dtoMsg.Value, &event)
-		if err != nil {
-			h.l.Error(op+"received bad message",
-				"error", err.Error(),
-			)
-			sess.MarkMessage(dtoMsg, "")
-			continue
-		}
+func (h *handler) markMessage(sess sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) {
+	sess.MarkMessage(msg, "")
+	h.state.MessageMarked(msg.Topic, msg.Partition, msg.Offset)
+}
 
-		// check if order status is still actual
-		for i := range MAX_RETRIES {
-
-			actualStatus, err := h.og.GetOrderStatusById(ctx, event.OrderID)
-			if err != nil {
-				h.retrCounter.IncTotalGatewayRetries()
-				// exponential backoff + jitter
-				if i != MAX_RETRIES-1 {
-					delay := i*i*BASE_DELAY + rand.Intn(20)
-					time.Sleep(time.Duration(delay) * time.Millisecond)
-				}
-
-			} else if actualStatus != event.Status {
-				h.l.Info("order.changed handler: order's status changed",
-					"id", event.OrderID,
-					"prev_status", event.Status,
-					"actual_status", actualStatus,
-				)
-				continue CLAIM_MSSG_LOOP
-			}
-		}
+// handleMessage обрабатывает одно сообщение в своем спане. Трейс продолжается из заголовков сообщения,
+// поэтому в нем видны и gRPC запрос в order-service, и SQL из Assign. Возвращает результат для метрик
+func (h *handler) handleMessage(sess sarama.ConsumerGroupSession, dtoMsg *sarama.ConsumerMessage) string {
+	op := "order.changed.handler: "
+
+	carrier := propagation.MapCarrier{}
+	for _, header := range dtoMsg.Headers {
+		carrier[string(header.Key)] = string(header.Value)
+	}
+	// контекст сессии отменяется при остановке и ребалансировке, а начатое сообщение нужно дообработать,
+	// иначе оно уйдет в failed и придет повторно
+	ctx := otel.GetTextMapPropagator().Extract(context.WithoutCancel(sess.Context()), carrier)
+	// request id продюсера, если он его передал, иначе у каждого сообщения свой
+	reqId := carrier.Get(requestid.MetadataKey)
+	if !requestid.Valid(reqId) {
+		reqId = requestid.New()
+	}
+	ctx = requestid.NewContext(ctx, reqId)
+	ctx, span := tracing.Tracer().Start(ctx, dtoMsg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingDestinationName(dtoMsg.Topic),
+			semconv.MessagingKafkaMessageKey(string(dtoMsg.Key)),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(int(dtoMsg.Partition))),
+			semconv.MessagingKafkaOffset(int(dtoMsg.Offset)),
+		),
+	)
+	defer span.End()
+
+	h.l.InfoContext(ctx, "order.changed handler: received message",
+		"key", string(dtoMsg.Key),
+		"value", string(dtoMsg.Value),
+		"partition", int(dtoMsg.Partition),
+		"offset", dtoMsg.Offset,
+	)
+
+	var event order.Event
+	err := json.Unmarshal(dtoMsg.Value, &event)
+	if err != nil {
+		h.l.ErrorContext(ctx, op+"received bad message",
+			"error", err.Error(),
+		)
+		span.SetStatus(codes.Error, "bad message")
+		h.markMessage(sess, dtoMsg)
+		return OutcomeBadJSON
+	}
+	span.SetAttributes(attribute.String("order.id", event.OrderID), attribute.String("order.status", event.Status))
 
-		res, err := h.uc.Process(ctx, &event)
+	// check if order status is still actual
+	for i := range MAX_RETRIES {
+
+		actualStatus, err := h.og.GetOrderStatusById(ctx, event.OrderID)
 		if err != nil {
-			if errors.Is(err, service.ErrUnknownOrderStatus) {
-				continue
+			h.retrCounter.IncTotalGatewayRetries()
+			// exponential backoff + jitter
+			if i != MAX_RETRIES-1 {
+				delay := i*i*BASE_DELAY + rand.Intn(20)
+				time.Sleep(time.Duration(delay) * time.Millisecond)
 			}
-			h.l.Error(op+"failed process order",
-				"error", err.Error(),
+
+		} else if actualStatus != event.Status {
+			h.l.InfoContext(ctx, "order.changed handler: order's status changed",
+				"id", event.OrderID,
+				"prev_status", event.Status,
+				"actual_status", actualStatus,
 			)
-			continue
+			return OutcomeStaleStatus
 		}
+	}
 
-		h.l.Info(op+" message processed",
-			"order_id", res.OrderId,
-			"status", res.Status,
-			"courier_id", res.CourierId,
+	res, err := h.uc.Process(ctx, &event)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownOrderStatus) {
+			return OutcomeUnknownStatus
+		}
+		if errors.Is(err, service.ErrDeliveryExists) {
+			h.l.InfoContext(ctx, "order.changed handler: delivery already exists", "order_id", event.OrderID)
+			return OutcomeDuplicate
+		}
+		h.l.ErrorContext(ctx, op+"failed process order",
+			"error", err.Error(),
 		)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return OutcomeFailed
+	}
 
-		sess.MarkMessage(dtoMsg, "")
+	h.l.InfoContext(ctx, op+" message processed",
+		"order_id", res.OrderId,
+		"status", res.Status,
+		"courier_id", res.CourierId,
+	)
 
-	}
-	return nil
+	h.markMessage(sess, dtoMsg)
+	return OutcomeProcessed
 }
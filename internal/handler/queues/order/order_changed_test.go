@@ -0,0 +1,188 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto/kafka/order"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/observability/requestid"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type fakeGateway map[string]string
+
+func (g fakeGateway) GetOrderStatusById(_ context.Context, id string) (string, error) {
+	return g[id], nil
+}
+
+type fakeUsecase map[string]error
+
+func (u fakeUsecase) Process(_ context.Context, event *order.Event) (*order.ProcessedEvent, error) {
+	if err := u[event.OrderID]; err != nil {
+		return nil, err
+	}
+	return &order.ProcessedEvent{OrderId: event.OrderID, Status: event.Status, CourierId: 1}, nil
+}
+
+type nopRetries struct{}
+
+func (nopRetries) IncTotalGatewayRetries() {}
+
+type fakeMetrics struct {
+	outcomes   []string
+	lag        map[int32]int64
+	lagHistory []int64
+	rebalances int
+	sessions   int
+}
+
+func (m *fakeMetrics) ObserveMessage(_ string, _ int32, outcome string, _ time.Duration) {
+	m.outcomes = append(m.outcomes, outcome)
+}
+func (m *fakeMetrics) SetLag(_ string, partition int32, lag int64) {
+	m.lag[partition] = lag
+	m.lagHistory = append(m.lagHistory, lag)
+}
+func (m *fakeMetrics) DeleteLag(_ string, partition int32) { delete(m.lag, partition) }
+func (m *fakeMetrics) IncRebalances()                      { m.rebalances++ }
+func (m *fakeMetrics) ObserveSession(time.Duration)        { m.sessions++ }
+
+type fakeState struct {
+	active   bool
+	claimed  bool
+	consumed []int64
+	marked   []int64
+}
+
+func (s *fakeState) SessionStarted(string, int32)             { s.active = true }
+func (s *fakeState) SessionEnded()                            { s.active = false }
+func (s *fakeState) ClaimStarted(string, int32, int64, int64) { s.claimed = true }
+func (s *fakeState) ClaimEnded(string, int32)                 { s.claimed = false }
+func (s *fakeState) MessageConsumed(_ string, _ int32, o, _ int64) {
+	s.consumed = append(s.consumed, o)
+}
+func (s *fakeState) MessageMarked(_ string, _ int32, offset int64) {
+	s.marked = append(s.marked, offset)
+}
+
+type fakeSession struct {
+	marked []int64
+}
+
+func (s *fakeSession) Claims() map[string][]int32               { return map[string][]int32{"orders": {0}} }
+func (s *fakeSession) MemberID() string                         { return "member-1" }
+func (s *fakeSession) GenerationID() int32                      { return 1 }
+func (s *fakeSession) MarkOffset(string, int32, int64, string)  {}
+func (s *fakeSession) Commit()                                  {}
+func (s *fakeSession) ResetOffset(string, int32, int64, string) {}
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+func (s *fakeSession) Context() context.Context { return context.Background() }
+
+type fakeClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return "orders" }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return 10 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func TestHandler_ConsumeClaim(t *testing.T) {
+	gateway := fakeGateway{
+		"1": order.StatusCreated,
+		"2": order.StatusCancelled, // в сообщении created
+		"3": "returned",
+		"4": order.StatusCreated,
+		"5": order.StatusCreated,
+	}
+	usecase := fakeUsecase{
+		"3": service.ErrUnknownOrderStatus,
+		"4": service.ErrDeliveryExists,
+		"5": errors.New("db is down"),
+	}
+	metrics := &fakeMetrics{lag: map[int32]int64{}}
+	state := &fakeState{}
+	h := NewOrderChangedHandler(nopLogger{}, gateway, usecase, nopRetries{}, metrics, state)
+
+	values := []string{
+		`{"order_id":"1","status":"created"}`,
+		`{"order_id":"2","status":"created"}`,
+		`{"order_id":"3","status":"returned"}`,
+		`{"order_id":"4","status":"created"}`,
+		`{"order_id":"5","status":"created"}`,
+		`not json`,
+	}
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(values))}
+	for i, v := range values {
+		claim.messages <- &sarama.ConsumerMessage{Topic: "orders", Offset: int64(i), Value: []byte(v)}
+	}
+	close(claim.messages)
+
+	sess := &fakeSession{}
+	require.NoError(t, h.Setup(sess))
+	require.True(t, state.active)
+
+	require.NoError(t, h.ConsumeClaim(sess, claim))
+	require.NoError(t, h.Cleanup(sess))
+
+	require.Equal(t, []string{
+		OutcomeProcessed, OutcomeStaleStatus, OutcomeUnknownStatus, OutcomeDuplicate, OutcomeFailed, OutcomeBadJSON,
+	}, metrics.outcomes)
+	require.Equal(t, []int64{0, 5}, sess.marked, "only processed and bad messages are marked")
+	require.Equal(t, sess.marked, state.marked)
+	require.Equal(t, []int64{0, 1, 2, 3, 4, 5}, state.consumed)
+	require.Equal(t, []int64{9, 8, 7, 6, 5, 4}, metrics.lagHistory, "high water mark is 10")
+	require.Empty(t, metrics.lag, "lag of a released partition is removed")
+	require.False(t, state.claimed)
+	require.False(t, state.active)
+	require.Equal(t, 1, metrics.rebalances)
+	require.Equal(t, 1, metrics.sessions)
+}
+
+type requestIdUsecase struct {
+	ids []string
+}
+
+func (u *requestIdUsecase) Process(ctx context.Context, event *order.Event) (*order.ProcessedEvent, error) {
+	u.ids = append(u.ids, requestid.FromContext(ctx))
+	return &order.ProcessedEvent{OrderId: event.OrderID, Status: event.Status}, nil
+}
+
+func TestHandler_RequestId(t *testing.T) {
+	usecase := &requestIdUsecase{}
+	h := NewOrderChangedHandler(nopLogger{}, fakeGateway{"1": order.StatusCreated}, usecase, nopRetries{}, &fakeMetrics{lag: map[int32]int64{}}, &fakeState{})
+
+	value := []byte(`{"order_id":"1","status":"created"}`)
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "orders", Value: value, Headers: []*sarama.RecordHeader{
+		{Key: []byte(requestid.MetadataKey), Value: []byte("req-1")},
+	}}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "orders", Offset: 1, Value: value}
+	close(claim.messages)
+
+	require.NoError(t, h.ConsumeClaim(&fakeSession{}, claim))
+	require.Len(t, usecase.ids, 2)
+	require.Equal(t, "req-1", usecase.ids[0], "producer's request id is kept")
+	require.Len(t, usecase.ids[1], 32, "message without request id gets a new one")
+}
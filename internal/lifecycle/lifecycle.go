@@ -0,0 +1,235 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"service-order-avito/internal/adapters/logger"
+	"sync"
+	"time"
+)
+
+// Phase этап остановки. Компоненты останавливаются по этапам по возрастанию, внутри этапа параллельно,
+// запускаются в обратном порядке: сначала соединения, последними - то, что принимает работу извне
+type Phase int
+
+const (
+	// PhaseReadiness переводит readiness в fail и дает балансировщику время убрать под, сервер еще работает
+	PhaseReadiness Phase = iota
+	// PhaseIntake HTTP серверы и консьюмер Kafka: перестают принимать новую работу и дожидаются текущей
+	PhaseIntake
+	// PhaseWorkers фоновые воркеры, которые пишут в базу
+	PhaseWorkers
+	// PhaseFlush дописывает буферы: события в Kafka, спаны
+	PhaseFlush
+	// PhaseConnections закрывает соединения с order-service и базой, ими пользуются все предыдущие этапы
+	PhaseConnections
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseReadiness:
+		return "readiness"
+	case PhaseIntake:
+		return "intake"
+	case PhaseWorkers:
+		return "workers"
+	case PhaseFlush:
+		return "flush"
+	case PhaseConnections:
+		return "connections"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// Hook компонент приложения. Start и Stop необязательны. Start не должен блокироваться,
+// долгую работу он запускает в горутине (см. Worker). Stop получает контекст с Timeout
+// и должен вернуться, когда компонент остановлен полностью
+type Hook struct {
+	Name    string
+	Phase   Phase
+	Timeout time.Duration // 0 - таймаут менеджера по умолчанию
+	Start   func(ctx context.Context) error
+	Stop    func(ctx context.Context) error
+}
+
+// Manager запускает и останавливает компоненты в порядке этапов
+type Manager struct {
+	log            logger.LoggerAdapter
+	defaultTimeout time.Duration
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started []Hook
+}
+
+func NewManager(log logger.LoggerAdapter, defaultTimeout time.Duration) *Manager {
+	return &Manager{log: log.With("component", "lifecycle"), defaultTimeout: defaultTimeout}
+}
+
+func (m *Manager) Add(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, h)
+}
+
+// Start запускает компоненты начиная с последнего этапа, внутри этапа в порядке добавления.
+// Если компонент не запустился, уже запущенные останавливаются
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]Hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	for phase := PhaseConnections; phase >= PhaseReadiness; phase-- {
+		for _, h := range hooks {
+			if h.Phase != phase {
+				continue
+			}
+			if h.Start != nil {
+				if err := h.Start(ctx); err != nil {
+					err = fmt.Errorf("start %s: %w", h.Name, err)
+					return errors.Join(err, m.Stop(context.WithoutCancel(ctx)))
+				}
+				m.log.Info("component started", "name", h.Name, "phase", h.Phase.String())
+			}
+			m.mu.Lock()
+			m.started = append(m.started, h)
+			m.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Stop останавливает запущенные компоненты по этапам. Следующий этап начинается, когда закончились
+// все остановки текущего или истекли их таймауты: зависший компонент не должен держать остальные
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for phase := PhaseReadiness; phase <= PhaseConnections; phase++ {
+		var (
+			wg sync.WaitGroup
+			mu sync.Mutex
+		)
+		for _, h := range started {
+			if h.Phase != phase || h.Stop == nil {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := m.stop(ctx, h); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stop(ctx context.Context, h Hook) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = m.defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Stop(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// Stop не уважает контекст, дальше его не ждем
+		err = fmt.Errorf("timeout after %s", timeout)
+	}
+	if err != nil {
+		m.log.Error("component stop failed", "name", h.Name, "phase", h.Phase.String(), "error", err.Error())
+		return fmt.Errorf("stop %s: %w", h.Name, err)
+	}
+	m.log.Info("component stopped", "name", h.Name, "phase", h.Phase.String(), "duration", time.Since(start).String())
+	return nil
+}
+
+// Worker хук для воркера вида Start(ctx), который работает до отмены контекста. Воркер получает
+// собственный контекст, поэтому сигнал остановки приложения его не прерывает, пока не дошла очередь его этапа
+func Worker(name string, phase Phase, run func(ctx context.Context)) Hook {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+	return Hook{
+		Name:  name,
+		Phase: phase,
+		Start: func(ctx context.Context) error {
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				run(runCtx)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Server хук для http.Server на этапе приема запросов. Порт занимается в Start, чтобы ошибка bind
+// остановила запуск. Stop дожидается текущих запросов, а если не дождался, отменяет их контекст
+func Server(name string, srv *http.Server, log logger.LoggerAdapter) Hook {
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	srv.BaseContext = func(net.Listener) context.Context { return baseCtx }
+	done := make(chan struct{})
+
+	return Hook{
+		Name:  name,
+		Phase: PhaseIntake,
+		Start: func(context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			// при порте 0 в Addr попадает выбранный порт
+			srv.Addr = ln.Addr().String()
+			go func() {
+				defer close(done)
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Error("http server error", "name", name, "error", err.Error())
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			cancelBase()
+			if err != nil {
+				_ = srv.Close()
+			}
+			<-done
+			return err
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"service-order-avito/internal/adapters/logger"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type journal struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (j *journal) add(event string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.events = append(j.events, event)
+}
+
+func (j *journal) hook(name string, phase Phase) Hook {
+	return Hook{
+		Name:  name,
+		Phase: phase,
+		Start: func(context.Context) error { j.add("start " + name); return nil },
+		Stop:  func(context.Context) error { j.add("stop " + name); return nil },
+	}
+}
+
+func TestManager_Order(t *testing.T) {
+	j := &journal{}
+	m := NewManager(nopLogger{}, time.Second)
+	m.Add(j.hook("http", PhaseIntake))
+	m.Add(j.hook("postgres", PhaseConnections))
+	m.Add(j.hook("worker", PhaseWorkers))
+	m.Add(j.hook("producer", PhaseFlush))
+	m.Add(j.hook("readiness", PhaseReadiness))
+
+	require.NoError(t, m.Start(context.Background()))
+	require.NoError(t, m.Stop(context.Background()))
+	require.Equal(t, []string{
+		"start postgres", "start producer", "start worker", "start http", "start readiness",
+		"stop readiness", "stop http", "stop worker", "stop producer", "stop postgres",
+	}, j.events)
+
+	require.NoError(t, m.Stop(context.Background()), "second stop is a no-op")
+	require.Len(t, j.events, 10)
+}
+
+func TestManager_StartFailureStopsStarted(t *testing.T) {
+	j := &journal{}
+	m := NewManager(nopLogger{}, time.Second)
+	m.Add(j.hook("postgres", PhaseConnections))
+	m.Add(Hook{Name: "http", Phase: PhaseIntake, Start: func(context.Context) error { return errors.New("address already in use") }})
+
+	err := m.Start(context.Background())
+	require.ErrorContains(t, err, "start http: address already in use")
+	require.Equal(t, []string{"start postgres", "stop postgres"}, j.events)
+}
+
+func TestManager_StopTimeout(t *testing.T) {
+	j := &journal{}
+	release := make(chan struct{})
+	defer close(release)
+
+	m := NewManager(nopLogger{}, time.Second)
+	m.Add(Hook{Name: "stuck", Phase: PhaseWorkers, Timeout: 20 * time.Millisecond, Stop: func(context.Context) error {
+		<-release // остановка не смотрит на контекст
+		return nil
+	}})
+	m.Add(j.hook("postgres", PhaseConnections))
+	require.NoError(t, m.Start(context.Background()))
+
+	err := m.Stop(context.Background())
+	require.ErrorContains(t, err, "stop stuck: timeout after 20ms")
+	require.Contains(t, j.events, "stop postgres", "stuck component doesn't block next phases")
+}
+
+// TestManager_NoGoroutineLeaks поднимает HTTP сервер и воркер, держит запрос в обработке во время остановки
+// и проверяет, что запрос дообработан, а горутин после Stop не осталось
+func TestManager_NoGoroutineLeaks(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	inHandler := make(chan struct{})
+	srv := &http.Server{Addr: "127.0.0.1:0", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(inHandler)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})}
+	ticked := make(chan struct{})
+	var workerStopped atomic.Bool
+
+	m := NewManager(nopLogger{}, time.Second)
+	m.Add(Server("http", srv, nopLogger{}))
+	m.Add(Worker("worker", PhaseWorkers, func(ctx context.Context) {
+		close(ticked)
+		<-ctx.Done()
+		workerStopped.Store(true)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, m.Start(ctx))
+	cancel() // отмена контекста старта не останавливает компоненты
+	<-ticked
+	require.False(t, workerStopped.Load())
+
+	client := &http.Client{Transport: &http.Transport{}}
+	defer client.CloseIdleConnections()
+	type result struct {
+		body string
+		err  error
+	}
+	res := make(chan result, 1)
+	go func() {
+		resp, err := client.Get("http://" + srv.Addr)
+		if err != nil {
+			res <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		res <- result{body: string(body), err: err}
+	}()
+	<-inHandler
+
+	require.NoError(t, m.Stop(context.Background()))
+	require.True(t, workerStopped.Load())
+
+	r := <-res
+	require.NoError(t, r.err)
+	require.Equal(t, "done", r.body, "in-flight request is drained")
+}
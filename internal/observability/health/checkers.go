@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Postgres пингует базу через пул и отдает его статистику. Если свободных соединений нет,
+// пинг ждет освобождения, поэтому перегруженный пул тоже провалит проверку по таймауту
+func Postgres(pool *pgxpool.Pool) Checker {
+	return CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		stat := pool.Stat()
+		details := map[string]any{
+			"total_conns":    stat.TotalConns(),
+			"idle_conns":     stat.IdleConns(),
+			"acquired_conns": stat.AcquiredConns(),
+			"max_conns":      stat.MaxConns(),
+		}
+		if err := pool.Ping(ctx); err != nil {
+			return details, err
+		}
+		return details, nil
+	})
+}
+
+type grpcConn interface {
+	GetState() connectivity.State
+	Connect()
+}
+
+// GRPC проверяет состояние соединения с order-service. Idle - нормальное состояние ленивого клиента,
+// в нем соединение только будится, недоступен сервис лишь в TRANSIENT_FAILURE и SHUTDOWN
+func GRPC(conn grpcConn) Checker {
+	return CheckerFunc(func(context.Context) (map[string]any, error) {
+		state := conn.GetState()
+		details := map[string]any{"state": state.String()}
+		switch state {
+		case connectivity.Idle:
+			conn.Connect()
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return details, fmt.Errorf("connection is %s", state)
+		}
+		return details, nil
+	})
+}
+
+type consumerSession interface {
+	Session() (active bool, since time.Time)
+}
+
+// KafkaConsumer проверяет, что консьюмер состоит в группе. Между сессиями (ребалансировка)
+// консьюмер неактивен какое-то время, поэтому проверка падает только после grace
+func KafkaConsumer(session consumerSession, grace time.Duration) Checker {
+	return CheckerFunc(func(context.Context) (map[string]any, error) {
+		active, since := session.Session()
+		details := map[string]any{"active": active, "since": since}
+		if !active && time.Since(since) > grace {
+			return details, fmt.Errorf("no consumer group session for %s", time.Since(since).Round(time.Second))
+		}
+		return details, nil
+	})
+}
@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/domain/dto"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// Checker проверка одной зависимости. details попадают в ответ как есть, например статистика пула
+type Checker interface {
+	Check(ctx context.Context) (details map[string]any, err error)
+}
+
+type CheckerFunc func(ctx context.Context) (map[string]any, error)
+
+func (f CheckerFunc) Check(ctx context.Context) (map[string]any, error) {
+	return f(ctx)
+}
+
+type check struct {
+	name    string
+	checker Checker
+}
+
+// probe набор проверок одной ручки и последний результат. Kubernetes и балансировщик дергают пробы
+// часто, поэтому результат кешируется на ttl, а в базу уходит не больше одного пинга за это время
+type probe struct {
+	checks []check
+
+	mu        sync.Mutex
+	report    dto.HealthResponse
+	checkedAt time.Time
+}
+
+// Health liveness и readiness пробы. Liveness отвечает, жив ли процесс (например, не зависли ли воркеры),
+// readiness - готов ли он принимать трафик: доступны ли база, Kafka и order-service
+type Health struct {
+	timeout      time.Duration
+	ttl          time.Duration
+	now          func() time.Time
+	live         probe
+	ready        probe
+	shuttingDown atomic.Bool
+}
+
+// NewHealth timeout ограничивает каждую проверку, ttl - время жизни закешированного результата
+func NewHealth(timeout, ttl time.Duration) *Health {
+	return &Health{timeout: timeout, ttl: ttl, now: time.Now}
+}
+
+// AddLiveness добавляет проверку в liveness. Провал liveness приводит к перезапуску, поэтому
+// внешние зависимости сюда добавлять нельзя: падение базы не лечится рестартом
+func (h *Health) AddLiveness(name string, c Checker) {
+	h.live.checks = append(h.live.checks, check{name: name, checker: c})
+}
+
+func (h *Health) AddReadiness(name string, c Checker) {
+	h.ready.checks = append(h.ready.checks, check{name: name, checker: c})
+}
+
+// Shutdown переводит readiness в fail, чтобы балансировщик перестал слать запросы до остановки сервера
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *Health) Live(ctx context.Context) dto.HealthResponse {
+	return h.run(ctx, &h.live)
+}
+
+func (h *Health) Ready(ctx context.Context) dto.HealthResponse {
+	if h.shuttingDown.Load() {
+		return dto.HealthResponse{
+			Status: dto.HealthStatusFail,
+			Checks: []dto.HealthCheckResponse{{Name: "shutdown", Status: dto.HealthStatusFail, Error: ErrShuttingDown.Error()}},
+		}
+	}
+	return h.run(ctx, &h.ready)
+}
+
+func (h *Health) run(ctx context.Context, p *probe) dto.HealthResponse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := h.now()
+	if !p.checkedAt.IsZero() && now.Sub(p.checkedAt) < h.ttl {
+		return p.report
+	}
+
+	report := dto.HealthResponse{Status: dto.HealthStatusOK, Checks: make([]dto.HealthCheckResponse, len(p.checks))}
+	var wg sync.WaitGroup
+	for i, c := range p.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = h.runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range report.Checks {
+		if c.Status != dto.HealthStatusOK {
+			report.Status = dto.HealthStatusFail
+		}
+	}
+	p.report, p.checkedAt = report, now
+	return report
+}
+
+func (h *Health) runCheck(ctx context.Context, c check) dto.HealthCheckResponse {
+	// результат кешируется для всех, поэтому отмена запроса, который запустил проверку, ее не прерывает
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	res := dto.HealthCheckResponse{Name: c.name, Status: dto.HealthStatusOK}
+
+	// проверка может не уважать контекст, ждем ее не дольше таймаута
+	type result struct {
+		details map[string]any
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		details, err := c.checker.Check(ctx)
+		done <- result{details, err}
+	}()
+
+	select {
+	case r := <-done:
+		res.Details = r.details
+		if r.err != nil {
+			res.Status, res.Error = dto.HealthStatusFail, r.err.Error()
+		}
+	case <-ctx.Done():
+		res.Status, res.Error = dto.HealthStatusFail, "timeout after "+h.timeout.String()
+	}
+	res.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	return res
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/domain/dto"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestHealth_Ready(t *testing.T) {
+	var dbCalls atomic.Int32
+	dbErr := error(nil)
+	h := NewHealth(50*time.Millisecond, time.Second)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	h.AddReadiness("postgres", CheckerFunc(func(context.Context) (map[string]any, error) {
+		dbCalls.Add(1)
+		return map[string]any{"total_conns": 1}, dbErr
+	}))
+	h.AddReadiness("kafka_consumer", CheckerFunc(func(context.Context) (map[string]any, error) {
+		return nil, nil
+	}))
+
+	report := h.Ready(context.Background())
+	require.Equal(t, dto.HealthStatusOK, report.Status)
+	require.Len(t, report.Checks, 2)
+	require.Equal(t, "postgres", report.Checks[0].Name, "checks keep registration order")
+	require.Equal(t, map[string]any{"total_conns": 1}, report.Checks[0].Details)
+
+	t.Run("result is cached", func(t *testing.T) {
+		dbErr = errors.New("connection refused")
+		now = now.Add(500 * time.Millisecond)
+		require.Equal(t, dto.HealthStatusOK, h.Ready(context.Background()).Status)
+		require.Equal(t, int32(1), dbCalls.Load())
+	})
+
+	t.Run("failed check fails the probe", func(t *testing.T) {
+		now = now.Add(time.Second)
+		report := h.Ready(context.Background())
+		require.Equal(t, dto.HealthStatusFail, report.Status)
+		require.Equal(t, dto.HealthStatusFail, report.Checks[0].Status)
+		require.Equal(t, "connection refused", report.Checks[0].Error)
+		require.Equal(t, dto.HealthStatusOK, report.Checks[1].Status)
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		dbErr = nil
+		now = now.Add(time.Minute)
+		require.Equal(t, dto.HealthStatusOK, h.Ready(context.Background()).Status)
+
+		h.Shutdown()
+		report := h.Ready(context.Background())
+		require.Equal(t, dto.HealthStatusFail, report.Status)
+		require.Equal(t, ErrShuttingDown.Error(), report.Checks[0].Error)
+	})
+}
+
+func TestHealth_CheckTimeout(t *testing.T) {
+	h := NewHealth(20*time.Millisecond, 0)
+	release := make(chan struct{})
+	defer close(release)
+	h.AddLiveness("stuck", CheckerFunc(func(context.Context) (map[string]any, error) {
+		<-release // проверка не смотрит на контекст
+		return nil, nil
+	}))
+
+	report := h.Live(context.Background())
+	require.Equal(t, dto.HealthStatusFail, report.Status)
+	require.Contains(t, report.Checks[0].Error, "timeout")
+}
+
+func TestHealth_CanceledRequestDoesNotFailCheck(t *testing.T) {
+	h := NewHealth(time.Second, time.Minute)
+	h.AddReadiness("postgres", CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		return nil, ctx.Err()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, dto.HealthStatusOK, h.Ready(ctx).Status)
+}
+
+type fakeConn struct {
+	state     connectivity.State
+	connected bool
+}
+
+func (c *fakeConn) GetState() connectivity.State { return c.state }
+func (c *fakeConn) Connect()                     { c.connected = true }
+
+func TestGRPC(t *testing.T) {
+	conn := &fakeConn{state: connectivity.Idle}
+	details, err := GRPC(conn).Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "IDLE", details["state"])
+	require.True(t, conn.connected, "idle connection is woken up")
+
+	conn.state = connectivity.TransientFailure
+	_, err = GRPC(conn).Check(context.Background())
+	require.EqualError(t, err, "connection is TRANSIENT_FAILURE")
+}
+
+type fakeSession struct {
+	active bool
+	since  time.Time
+}
+
+func (s fakeSession) Session() (bool, time.Time) { return s.active, s.since }
+
+func TestKafkaConsumer(t *testing.T) {
+	_, err := KafkaConsumer(fakeSession{active: true, since: time.Now().Add(-time.Hour)}, time.Minute).Check(context.Background())
+	require.NoError(t, err)
+
+	_, err = KafkaConsumer(fakeSession{since: time.Now().Add(-10 * time.Second)}, time.Minute).Check(context.Background())
+	require.NoError(t, err, "rebalance within grace")
+
+	_, err = KafkaConsumer(fakeSession{since: time.Now().Add(-2 * time.Minute)}, time.Minute).Check(context.Background())
+	require.ErrorContains(t, err, "no consumer group session")
+}
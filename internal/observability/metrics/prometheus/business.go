@@ -0,0 +1,82 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type prometheusDeliveryObserver struct {
+	createdTotal             prometheus.Counter
+	finishedTotal            *prometheus.CounterVec
+	assignmentDuration       prometheus.Histogram
+	timeToAssign             prometheus.Histogram
+	noAvailableCouriersTotal prometheus.Counter
+}
+
+func NewPrometheusDeliveryObserver(reg prometheus.Registerer) *prometheusDeliveryObserver {
+	factory := promauto.With(reg)
+
+	createdTotal := factory.NewCounter(prometheus.CounterOpts{
+		Name: "service_courier_deliveries_created_total",
+		Help: "total deliveries assigned to couriers",
+	})
+
+	finishedTotal := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_deliveries_finished_total",
+			Help: "total deliveries moved to history by final status (completed, cancelled, expired)",
+		},
+		[]string{"status"},
+	)
+
+	assignmentDuration := factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "service_courier_delivery_assignment_duration_seconds",
+		Help:    "time spent on choosing a courier and saving the delivery",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	})
+
+	timeToAssign := factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "service_courier_delivery_time_to_assign_seconds",
+		Help:    "time from order creation in order-service to courier assignment",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+	})
+
+	noAvailableCouriersTotal := factory.NewCounter(prometheus.CounterOpts{
+		Name: "service_courier_no_available_couriers_total",
+		Help: "total assignment attempts that found no available courier",
+	})
+
+	return &prometheusDeliveryObserver{
+		createdTotal:             createdTotal,
+		finishedTotal:            finishedTotal,
+		assignmentDuration:       assignmentDuration,
+		timeToAssign:             timeToAssign,
+		noAvailableCouriersTotal: noAvailableCouriersTotal,
+	}
+}
+
+func (p *prometheusDeliveryObserver) IncDeliveriesCreated() {
+	p.createdTotal.Inc()
+}
+
+func (p *prometheusDeliveryObserver) AddDeliveriesFinished(status string, n int) {
+	p.finishedTotal.WithLabelValues(status).Add(float64(n))
+}
+
+func (p *prometheusDeliveryObserver) ObserveAssignment(latency time.Duration) {
+	p.assignmentDuration.Observe(latency.Seconds())
+}
+
+func (p *prometheusDeliveryObserver) ObserveTimeToAssign(d time.Duration) {
+	// часы order-service и наши могут расходиться, отрицательное время в гистограмму не пишем
+	if d < 0 {
+		d = 0
+	}
+	p.timeToAssign.Observe(d.Seconds())
+}
+
+func (p *prometheusDeliveryObserver) IncNoAvailableCouriers() {
+	p.noAvailableCouriersTotal.Inc()
+}
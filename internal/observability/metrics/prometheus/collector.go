@@ -0,0 +1,127 @@
+package prometheus
+
+import (
+	"context"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/model"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type businessStatsSource interface {
+	GetCourierCounts(context.Context) ([]model.CourierGroupCount, error)
+	CountActiveDeliveries(context.Context) (int, error)
+}
+
+var (
+	couriersDesc = prometheus.NewDesc(
+		"service_courier_couriers",
+		"current amount of couriers by status and transport type",
+		[]string{"status", "transport_type"}, nil,
+	)
+	deliveriesAssignedDesc = prometheus.NewDesc(
+		"service_courier_deliveries_assigned",
+		"current amount of deliveries assigned to couriers and not finished yet",
+		nil, nil,
+	)
+)
+
+// известные значения меток, для них отдается 0, чтобы ряд не пропадал, когда курьеров в группе не осталось
+var (
+	courierStatuses = []string{model.StatusAvailable, model.StatusBusy, model.StatusPaused}
+	transportTypes  = []string{model.TransportTypeFoot, model.TransportTypeScooter, model.TransportTypeCar}
+)
+
+type businessSnapshot struct {
+	couriers   map[[2]string]int
+	deliveries int
+}
+
+// businessCollector считает gauge по базе во время scrape. Результат кешируется на ttl, чтобы несколько
+// Prometheus (или частый scrape) не нагружали базу. Если запрос упал, отдаются последние полученные значения
+type businessCollector struct {
+	source  businessStatsSource
+	ttl     time.Duration
+	timeout time.Duration
+	l       logger.LoggerAdapter
+	now     func() time.Time
+
+	mu        sync.Mutex
+	snapshot  *businessSnapshot
+	fetchedAt time.Time
+}
+
+func NewBusinessCollector(reg prometheus.Registerer, source businessStatsSource, ttl, timeout time.Duration, l logger.LoggerAdapter) *businessCollector {
+	c := &businessCollector{
+		source:  source,
+		ttl:     ttl,
+		timeout: timeout,
+		l:       l,
+		now:     time.Now,
+	}
+	reg.MustRegister(c)
+	return c
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- couriersDesc
+	ch <- deliveriesAssignedDesc
+}
+
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := c.get()
+	if snapshot == nil {
+		return
+	}
+
+	for key, n := range snapshot.couriers {
+		ch <- prometheus.MustNewConstMetric(couriersDesc, prometheus.GaugeValue, float64(n), key[0], key[1])
+	}
+	ch <- prometheus.MustNewConstMetric(deliveriesAssignedDesc, prometheus.GaugeValue, float64(snapshot.deliveries))
+}
+
+// get блокирует параллельные scrape на время запроса, поэтому в базу уходит один запрос, а не по одному на каждый
+func (c *businessCollector) get() *businessSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.snapshot != nil && now.Sub(c.fetchedAt) < c.ttl {
+		return c.snapshot
+	}
+
+	snapshot, err := c.fetch()
+	if err != nil {
+		c.l.Warn("business metrics: query stats", "error", err.Error())
+		return c.snapshot
+	}
+	c.snapshot, c.fetchedAt = snapshot, now
+	return snapshot
+}
+
+func (c *businessCollector) fetch() (*businessSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.source.GetCourierCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := c.source.CountActiveDeliveries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &businessSnapshot{couriers: make(map[[2]string]int), deliveries: deliveries}
+	for _, status := range courierStatuses {
+		for _, transport := range transportTypes {
+			snapshot.couriers[[2]string{status, transport}] = 0
+		}
+	}
+	for _, cnt := range counts {
+		snapshot.couriers[[2]string{cnt.Status, cnt.TransportType}] = cnt.Count
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,117 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/model"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type fakeStatsSource struct {
+	calls      int
+	counts     []model.CourierGroupCount
+	deliveries int
+	err        error
+}
+
+func (s *fakeStatsSource) GetCourierCounts(context.Context) ([]model.CourierGroupCount, error) {
+	s.calls++
+	return s.counts, s.err
+}
+
+func (s *fakeStatsSource) CountActiveDeliveries(context.Context) (int, error) {
+	return s.deliveries, s.err
+}
+
+func gather(t *testing.T, reg *prometheus.Registry) map[string]*dto.MetricFamily {
+	t.Helper()
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	for _, f := range families {
+		byName[f.GetName()] = f
+	}
+	return byName
+}
+
+func courierGauge(f *dto.MetricFamily, status, transport string) float64 {
+	for _, m := range f.GetMetric() {
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["status"] == status && labels["transport_type"] == transport {
+			return m.GetGauge().GetValue()
+		}
+	}
+	return -1
+}
+
+func TestBusinessCollector(t *testing.T) {
+	source := &fakeStatsSource{
+		counts: []model.CourierGroupCount{
+			{Status: model.StatusAvailable, TransportType: model.TransportTypeCar, Count: 3},
+		},
+		deliveries: 2,
+	}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := &businessCollector{
+		source:  source,
+		ttl:     15 * time.Second,
+		timeout: time.Second,
+		l:       nopLogger{},
+		now:     func() time.Time { return now },
+	}
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+
+	families := gather(t, reg)
+	couriers := families["service_courier_couriers"]
+	require.Len(t, couriers.GetMetric(), len(courierStatuses)*len(transportTypes), "all known groups are reported")
+	require.Equal(t, 3.0, courierGauge(couriers, model.StatusAvailable, model.TransportTypeCar))
+	require.Equal(t, 0.0, courierGauge(couriers, model.StatusBusy, model.TransportTypeFoot))
+	require.Equal(t, 2.0, families["service_courier_deliveries_assigned"].GetMetric()[0].GetGauge().GetValue())
+
+	t.Run("cached within ttl", func(t *testing.T) {
+		source.deliveries = 5
+		now = now.Add(10 * time.Second)
+		families = gather(t, reg)
+		require.Equal(t, 1, source.calls)
+		require.Equal(t, 2.0, families["service_courier_deliveries_assigned"].GetMetric()[0].GetGauge().GetValue())
+	})
+
+	t.Run("refreshed after ttl", func(t *testing.T) {
+		now = now.Add(10 * time.Second)
+		families = gather(t, reg)
+		require.Equal(t, 2, source.calls)
+		require.Equal(t, 5.0, families["service_courier_deliveries_assigned"].GetMetric()[0].GetGauge().GetValue())
+	})
+
+	t.Run("stale values on error", func(t *testing.T) {
+		source.err = errors.New("db is down")
+		source.deliveries = 7
+		now = now.Add(time.Minute)
+		families = gather(t, reg)
+		require.Equal(t, 3, source.calls)
+		require.Equal(t, 5.0, families["service_courier_deliveries_assigned"].GetMetric()[0].GetGauge().GetValue())
+	})
+}
@@ -0,0 +1,92 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type prometheusJobsObserver struct {
+	runs        *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	running     *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+	skipped     *prometheus.CounterVec
+}
+
+// NewPrometheusJobsObserver метрики планировщика фоновых задач. Для алерта на задачу, которая давно
+// не отрабатывала, удобнее time() - service_courier_job_last_success_timestamp_seconds
+func NewPrometheusJobsObserver(reg prometheus.Registerer) *prometheusJobsObserver {
+	factory := promauto.With(reg)
+
+	runs := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_job_runs_total",
+			Help: "total background job runs by outcome: success, failed, timeout, canceled",
+		},
+		[]string{"job", "outcome"},
+	)
+
+	duration := factory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "service_courier_job_duration_seconds",
+			Help:    "background job run duration including retries",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 15, 30, 60, 300},
+		},
+		[]string{"job"},
+	)
+
+	running := factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "service_courier_job_running",
+			Help: "1 while the background job is running",
+		},
+		[]string{"job"},
+	)
+
+	lastSuccess := factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "service_courier_job_last_success_timestamp_seconds",
+			Help: "unix time of the last successful background job run",
+		},
+		[]string{"job"},
+	)
+
+	skipped := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_job_skipped_total",
+			Help: "total background job runs skipped because the previous run was still in progress",
+		},
+		[]string{"job"},
+	)
+
+	return &prometheusJobsObserver{
+		runs:        runs,
+		duration:    duration,
+		running:     running,
+		lastSuccess: lastSuccess,
+		skipped:     skipped,
+	}
+}
+
+func (p *prometheusJobsObserver) ObserveRun(job, outcome string, duration time.Duration) {
+	p.runs.WithLabelValues(job, outcome).Inc()
+	p.duration.WithLabelValues(job).Observe(duration.Seconds())
+}
+
+func (p *prometheusJobsObserver) SetRunning(job string, running bool) {
+	value := 0.0
+	if running {
+		value = 1
+	}
+	p.running.WithLabelValues(job).Set(value)
+}
+
+func (p *prometheusJobsObserver) SetLastSuccess(job string, at time.Time) {
+	p.lastSuccess.WithLabelValues(job).Set(float64(at.Unix()))
+}
+
+func (p *prometheusJobsObserver) IncSkipped(job string) {
+	p.skipped.WithLabelValues(job).Inc()
+}
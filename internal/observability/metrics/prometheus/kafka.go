@@ -0,0 +1,109 @@
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type prometheusKafkaObserver struct {
+	consumedTotal      *prometheus.CounterVec
+	processedTotal     *prometheus.CounterVec
+	processingDuration *prometheus.HistogramVec
+	lag                *prometheus.GaugeVec
+	rebalancesTotal    prometheus.Counter
+	sessionDuration    prometheus.Histogram
+	consumeErrorsTotal prometheus.Counter
+}
+
+func NewPrometheusKafkaObserver(reg prometheus.Registerer) *prometheusKafkaObserver {
+	factory := promauto.With(reg)
+
+	consumedTotal := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_kafka_messages_consumed_total",
+			Help: "total messages received from kafka by topic and partition",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	processedTotal := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_kafka_messages_processed_total",
+			Help: "total kafka messages by processing outcome (processed, duplicate, stale_status, unknown_status, failed, bad_json)",
+		},
+		[]string{"topic", "outcome"},
+	)
+
+	processingDuration := factory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "service_courier_kafka_message_processing_duration_seconds",
+			Help:    "time spent on processing one kafka message, including order-service requests and retries",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		},
+		[]string{"topic", "outcome"},
+	)
+
+	lag := factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "service_courier_kafka_consumer_lag",
+			Help: "messages in the partition not read yet, by high water mark",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	rebalancesTotal := factory.NewCounter(prometheus.CounterOpts{
+		Name: "service_courier_kafka_rebalances_total",
+		Help: "total consumer group sessions started, every rebalance starts a new one",
+	})
+
+	sessionDuration := factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "service_courier_kafka_session_duration_seconds",
+		Help:    "lifetime of consumer group sessions between rebalances",
+		Buckets: []float64{1, 10, 60, 300, 900, 3600, 4 * 3600, 24 * 3600},
+	})
+
+	consumeErrorsTotal := factory.NewCounter(prometheus.CounterOpts{
+		Name: "service_courier_kafka_consume_errors_total",
+		Help: "total errors returned by consumer group Consume",
+	})
+
+	return &prometheusKafkaObserver{
+		consumedTotal:      consumedTotal,
+		processedTotal:     processedTotal,
+		processingDuration: processingDuration,
+		lag:                lag,
+		rebalancesTotal:    rebalancesTotal,
+		sessionDuration:    sessionDuration,
+		consumeErrorsTotal: consumeErrorsTotal,
+	}
+}
+
+func (p *prometheusKafkaObserver) ObserveMessage(topic string, partition int32, outcome string, duration time.Duration) {
+	p.consumedTotal.WithLabelValues(topic, strconv.Itoa(int(partition))).Inc()
+	p.processedTotal.WithLabelValues(topic, outcome).Inc()
+	p.processingDuration.WithLabelValues(topic, outcome).Observe(duration.Seconds())
+}
+
+func (p *prometheusKafkaObserver) SetLag(topic string, partition int32, lag int64) {
+	p.lag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+// DeleteLag партиция ушла другому консьюмеру, ее лаг теперь отдает он
+func (p *prometheusKafkaObserver) DeleteLag(topic string, partition int32) {
+	p.lag.DeleteLabelValues(topic, strconv.Itoa(int(partition)))
+}
+
+func (p *prometheusKafkaObserver) IncRebalances() {
+	p.rebalancesTotal.Inc()
+}
+
+func (p *prometheusKafkaObserver) ObserveSession(d time.Duration) {
+	p.sessionDuration.Observe(d.Seconds())
+}
+
+func (p *prometheusKafkaObserver) IncConsumeErrors() {
+	p.consumeErrorsTotal.Inc()
+}
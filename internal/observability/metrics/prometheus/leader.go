@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type prometheusLeaderObserver struct {
+	leader      *prometheus.GaugeVec
+	transitions *prometheus.CounterVec
+}
+
+// NewPrometheusLeaderObserver service_courier_leader равен 1 только на реплике-лидере,
+// поэтому sum by (lease) больше 1 означает двух лидеров одновременно
+func NewPrometheusLeaderObserver(reg prometheus.Registerer) *prometheusLeaderObserver {
+	factory := promauto.With(reg)
+
+	leader := factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "service_courier_leader",
+			Help: "1 if this instance holds the leader lease, 0 otherwise",
+		},
+		[]string{"lease"},
+	)
+
+	transitions := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_leader_transitions_total",
+			Help: "total times this instance became or stopped being the leader",
+		},
+		[]string{"lease"},
+	)
+
+	return &prometheusLeaderObserver{
+		leader:      leader,
+		transitions: transitions,
+	}
+}
+
+func (p *prometheusLeaderObserver) SetLeader(lease string, leader bool) {
+	value := 0.0
+	if leader {
+		value = 1
+	}
+	p.leader.WithLabelValues(lease).Set(value)
+}
+
+func (p *prometheusLeaderObserver) IncTransitions(lease string) {
+	p.transitions.WithLabelValues(lease).Inc()
+}
@@ -1,58 +1,196 @@
 package prometheus
 
 import (
+	"sort"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// route, для которого chi не нашел обработчик. Сам путь в метку не пишем, иначе сканер создаст тысячи рядов
+const unmatchedRoute = "unmatched"
+
+// размеры тел: от 64 байт до 1 MiB
+var sizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+
+// RouteConfig бакеты гистограммы длительности и порог SLO. Заданный порог добавляется в бакеты,
+// чтобы долю запросов в SLO можно было посчитать и по гистограмме
+type RouteConfig struct {
+	Buckets []float64
+	SLO     time.Duration
+}
+
+func (c RouteConfig) buckets() []float64 {
+	buckets := append([]float64(nil), c.Buckets...)
+	if c.SLO > 0 {
+		buckets = append(buckets, c.SLO.Seconds())
+	}
+	sort.Float64s(buckets)
+	unique := buckets[:0]
+	for i, b := range buckets {
+		if i == 0 || b != buckets[i-1] {
+			unique = append(unique, b)
+		}
+	}
+	return unique
+}
+
 type prometheusHTTPObserver struct {
 	totalRequest              prometheus.Counter
-	reqDuration               *prometheus.HistogramVec
+	inFlight                  prometheus.Gauge
+	reqDuration               *routeDurations
+	slo                       RouteConfig
+	routeSLO                  map[string]time.Duration
+	sloExceeded               *prometheus.CounterVec
+	reqSize                   *prometheus.HistogramVec
+	respSize                  *prometheus.HistogramVec
 	totalRateLimitExceedances prometheus.Counter
 	totalGatewayRetries       prometheus.Counter
 }
 
-func NewPrometheusHTTPObserver() *prometheusHTTPObserver {
+// NewPrometheusHTTPObserver метрики HTTP запросов. defaults применяются ко всем роутам, кроме перечисленных в routes
+func NewPrometheusHTTPObserver(reg prometheus.Registerer, defaults RouteConfig, routes map[string]RouteConfig) *prometheusHTTPObserver {
+	factory := promauto.With(reg)
 
-	totalRequest := promauto.NewCounter(prometheus.CounterOpts{
+	totalRequest := factory.NewCounter(prometheus.CounterOpts{
 		Name: "service_courier_requests_total",
 		Help: "total amount of requests",
 	})
 
-	reqDuration := promauto.NewHistogramVec(
+	inFlight := factory.NewGauge(prometheus.GaugeOpts{
+		Name: "service_courier_requests_in_flight",
+		Help: "current amount of HTTP-requests being served",
+	})
+
+	durationOpts := prometheus.HistogramOpts{
+		Name:    "service_courier_request_duration",
+		Help:    "duration of HTTP-request in sec",
+		Buckets: defaults.buckets(),
+	}
+	durationLabels := []string{"method", "route", "status"}
+	reqDuration := &routeDurations{
+		defaults: prometheus.NewHistogramVec(durationOpts, durationLabels),
+		routes:   make(map[string]*prometheus.HistogramVec, len(routes)),
+	}
+	routeSLO := make(map[string]time.Duration, len(routes))
+	for key, cfg := range routes {
+		if len(cfg.Buckets) == 0 {
+			cfg.Buckets = defaults.Buckets
+		}
+		opts := durationOpts
+		opts.Buckets = cfg.buckets()
+		reqDuration.routes[key] = prometheus.NewHistogramVec(opts, durationLabels)
+		routeSLO[key] = cfg.SLO
+	}
+	reg.MustRegister(reqDuration)
+
+	sloExceeded := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_request_slo_exceeded_total",
+			Help: "total HTTP-requests slower than the latency SLO of their route",
+		},
+		[]string{"method", "route"},
+	)
+
+	reqSize := factory.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "service_courier_request_duration",
-			Help:    "duration of HTTP-request in sec",
-			Buckets: []float64{0.1, 0.3, 0.5, 1, 2},
+			Name:    "service_courier_request_size_bytes",
+			Help:    "size of HTTP-request body",
+			Buckets: sizeBuckets,
 		},
-		[]string{"method", "path", "status"},
+		[]string{"method", "route"},
 	)
 
-	rateLimitExceededTotal := promauto.NewCounter(prometheus.CounterOpts{
+	respSize := factory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "service_courier_response_size_bytes",
+			Help:    "size of HTTP-response body",
+			Buckets: sizeBuckets,
+		},
+		[]string{"method", "route"},
+	)
+
+	rateLimitExceededTotal := factory.NewCounter(prometheus.CounterOpts{
 		Name: "rate_limit_exceeded_total",
 		Help: "total limit exceedances",
 	})
 
-	gatewayRetriesTotal := promauto.NewCounter(prometheus.CounterOpts{
+	gatewayRetriesTotal := factory.NewCounter(prometheus.CounterOpts{
 		Name: "gateway_retries_total",
 		Help: "total retries to gateway",
 	})
 
 	return &prometheusHTTPObserver{
 		totalRequest:              totalRequest,
+		inFlight:                  inFlight,
 		reqDuration:               reqDuration,
+		slo:                       defaults,
+		routeSLO:                  routeSLO,
+		sloExceeded:               sloExceeded,
+		reqSize:                   reqSize,
+		respSize:                  respSize,
 		totalRateLimitExceedances: rateLimitExceededTotal,
 		totalGatewayRetries:       gatewayRetriesTotal,
 	}
 
 }
 
+// routeDurations гистограмма длительности запросов. У роутов со своими бакетами отдельные HistogramVec,
+// а в реестре это одна метрика: два HistogramVec с одним именем и одинаковыми метками реестр не примет.
+// Ряды не пересекаются, запросы роута из конфига пишутся только в его HistogramVec
+type routeDurations struct {
+	defaults *prometheus.HistogramVec
+	routes   map[string]*prometheus.HistogramVec // ключ "METHOD /pattern"
+}
+
+func (d *routeDurations) vec(key string) *prometheus.HistogramVec {
+	if h, ok := d.routes[key]; ok {
+		return h
+	}
+	return d.defaults
+}
+
+func (d *routeDurations) Describe(ch chan<- *prometheus.Desc) {
+	d.defaults.Describe(ch)
+}
+
+func (d *routeDurations) Collect(ch chan<- prometheus.Metric) {
+	d.defaults.Collect(ch)
+	for _, h := range d.routes {
+		h.Collect(ch)
+	}
+}
+
 func (p *prometheusHTTPObserver) IncTotalRequests() {
 	p.totalRequest.Inc()
 }
 
-func (p *prometheusHTTPObserver) NewRequest(method, path, status string, durationSec float64) {
-	p.reqDuration.WithLabelValues(method, path, status).Observe(durationSec)
+func (p *prometheusHTTPObserver) IncInFlight() {
+	p.inFlight.Inc()
+}
+
+func (p *prometheusHTTPObserver) DecInFlight() {
+	p.inFlight.Dec()
+}
+
+func (p *prometheusHTTPObserver) NewRequest(method, route, status string, durationSec float64, requestSize, responseSize int64) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+
+	key := routeKey(method, route)
+	slo := p.slo.SLO
+	if routeSLO, ok := p.routeSLO[key]; ok {
+		slo = routeSLO
+	}
+	p.reqDuration.vec(key).WithLabelValues(method, route, status).Observe(durationSec)
+	if slo > 0 && durationSec > slo.Seconds() {
+		p.sloExceeded.WithLabelValues(method, route).Inc()
+	}
+
+	p.reqSize.WithLabelValues(method, route).Observe(float64(requestSize))
+	p.respSize.WithLabelValues(method, route).Observe(float64(responseSize))
 }
 
 func (p *prometheusHTTPObserver) IncTotalRateLimitExceedances() {
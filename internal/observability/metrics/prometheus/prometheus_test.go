@@ -0,0 +1,112 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func findMetric(f *dto.MetricFamily, labels map[string]string) *dto.Metric {
+	for _, m := range f.GetMetric() {
+		got := map[string]string{}
+		for _, l := range m.GetLabel() {
+			got[l.GetName()] = l.GetValue()
+		}
+		match := true
+		for k, v := range labels {
+			if got[k] != v {
+				match = false
+			}
+		}
+		if match {
+			return m
+		}
+	}
+	return nil
+}
+
+func upperBounds(h *dto.Histogram) []float64 {
+	var bounds []float64
+	for _, b := range h.GetBucket() {
+		bounds = append(bounds, b.GetUpperBound())
+	}
+	return bounds
+}
+
+func TestParseRouteConfigs(t *testing.T) {
+	configs, err := ParseRouteConfigs([]string{
+		"post /couriers/import=5s:1,2.5,10",
+		" GET /couriers/export=2s ",
+		"",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]RouteConfig{
+		"POST /couriers/import": {SLO: 5 * time.Second, Buckets: []float64{1, 2.5, 10}},
+		"GET /couriers/export":  {SLO: 2 * time.Second},
+	}, configs)
+
+	for _, item := range []string{
+		"GET /ping",
+		"/ping=1s",
+		"GET /ping=fast",
+		"GET /ping=0s",
+		"GET /ping=1s:0.1,abc",
+		"GET /ping=1s:-1",
+	} {
+		_, err = ParseRouteConfigs([]string{item})
+		require.Error(t, err, item)
+	}
+}
+
+func TestPrometheusHTTPObserver(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	obs := NewPrometheusHTTPObserver(reg,
+		RouteConfig{Buckets: []float64{0.1, 1}, SLO: 300 * time.Millisecond},
+		map[string]RouteConfig{
+			"POST /couriers/import": {Buckets: []float64{1, 10, 30}, SLO: 5 * time.Second},
+		},
+	)
+
+	obs.IncInFlight()
+	obs.NewRequest("GET", "/courier/{id}", "200", 0.05, 0, 120)
+	obs.NewRequest("GET", "/courier/{id}", "200", 0.5, 0, 120)
+	obs.NewRequest("POST", "/couriers/import", "200", 3, 4096, 64)
+	obs.NewRequest("GET", "", "404", 0.01, 0, 80)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	byName := map[string]*dto.MetricFamily{}
+	for _, f := range families {
+		byName[f.GetName()] = f
+	}
+
+	require.Equal(t, 1.0, byName["service_courier_requests_in_flight"].GetMetric()[0].GetGauge().GetValue())
+
+	duration := byName["service_courier_request_duration"]
+	courier := findMetric(duration, map[string]string{"method": "GET", "route": "/courier/{id}", "status": "200"})
+	require.NotNil(t, courier)
+	require.Equal(t, uint64(2), courier.GetHistogram().GetSampleCount())
+	require.Equal(t, []float64{0.1, 0.3, 1}, upperBounds(courier.GetHistogram()), "slo is added to default buckets")
+
+	imp := findMetric(duration, map[string]string{"method": "POST", "route": "/couriers/import", "status": "200"})
+	require.NotNil(t, imp)
+	require.Equal(t, []float64{1, 5, 10, 30}, upperBounds(imp.GetHistogram()), "route has its own buckets")
+
+	require.NotNil(t, findMetric(duration, map[string]string{"route": "unmatched", "status": "404"}))
+
+	slo := byName["service_courier_request_slo_exceeded_total"]
+	require.Len(t, slo.GetMetric(), 1, "only the slow courier request exceeds its slo")
+	require.Equal(t, 1.0, findMetric(slo, map[string]string{"route": "/courier/{id}"}).GetCounter().GetValue())
+
+	size := findMetric(byName["service_courier_request_size_bytes"], map[string]string{"route": "/couriers/import"})
+	require.Equal(t, 4096.0, size.GetHistogram().GetSampleSum())
+
+	t.Run("observer can be built more than once", func(t *testing.T) {
+		require.NotPanics(t, func() {
+			NewPrometheusHTTPObserver(prometheus.NewRegistry(), RouteConfig{Buckets: []float64{1}}, nil)
+		})
+	})
+}
@@ -0,0 +1,59 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type prometheusReconciliationObserver struct {
+	drift  *prometheus.GaugeVec
+	fixes  *prometheus.CounterVec
+	cursor prometheus.Gauge
+}
+
+// NewPrometheusReconciliationObserver drift - расхождения, найденные последним проходом сверки.
+// Если он не падает до нуля, исправления не проходят или включен report-only режим
+func NewPrometheusReconciliationObserver(reg prometheus.Registerer) *prometheusReconciliationObserver {
+	factory := promauto.With(reg)
+
+	drift := factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "service_courier_reconciliation_drift",
+			Help: "orders out of sync with order-service found by the last reconciliation run, by kind",
+		},
+		[]string{"kind"},
+	)
+
+	fixes := factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_courier_reconciliation_fixes_total",
+			Help: "total order drift fixes by kind and result: fixed, failed, skipped after repeated failures",
+		},
+		[]string{"kind", "result"},
+	)
+
+	cursor := factory.NewGauge(prometheus.GaugeOpts{
+		Name: "service_courier_reconciliation_cursor_timestamp_seconds",
+		Help: "creation time of the last order checked by reconciliation",
+	})
+
+	return &prometheusReconciliationObserver{
+		drift:  drift,
+		fixes:  fixes,
+		cursor: cursor,
+	}
+}
+
+func (p *prometheusReconciliationObserver) SetDrift(kind string, n int) {
+	p.drift.WithLabelValues(kind).Set(float64(n))
+}
+
+func (p *prometheusReconciliationObserver) IncFixes(kind, result string) {
+	p.fixes.WithLabelValues(kind, result).Inc()
+}
+
+func (p *prometheusReconciliationObserver) SetCursor(at time.Time) {
+	p.cursor.Set(float64(at.Unix()))
+}
@@ -0,0 +1,70 @@
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseBuckets разбирает границы бакетов в секундах, они должны быть положительными
+func parseBuckets(items []string) ([]float64, error) {
+	buckets := make([]float64, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		b, err := strconv.ParseFloat(item, 64)
+		if err != nil || b <= 0 {
+			return nil, fmt.Errorf("invalid bucket %q", item)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+// ParseRouteConfigs разбирает SLO и бакеты отдельных роутов из конфига.
+// Формат элемента: "METHOD /pattern=SLO" или "METHOD /pattern=SLO:BUCKET,BUCKET", например
+// "POST /couriers/import=5s:0.5,1,2.5,5,10,30". Без бакетов берутся бакеты по умолчанию.
+// Pattern - шаблон роута chi, как он объявлен в роутере
+func ParseRouteConfigs(items []string) (map[string]RouteConfig, error) {
+	configs := make(map[string]RouteConfig, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		route, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("route metrics %q: expected METHOD /pattern=SLO[:BUCKETS]", item)
+		}
+
+		method, pattern, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("route metrics %q: expected METHOD /pattern", item)
+		}
+
+		slo, buckets, _ := strings.Cut(value, ":")
+		d, err := time.ParseDuration(strings.TrimSpace(slo))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("route metrics %q: invalid slo", item)
+		}
+
+		var cfg RouteConfig
+		cfg.SLO = d
+		if buckets != "" {
+			if cfg.Buckets, err = parseBuckets(strings.Split(buckets, ",")); err != nil {
+				return nil, fmt.Errorf("route metrics %q: %w", item, err)
+			}
+		}
+
+		configs[routeKey(method, strings.TrimSpace(pattern))] = cfg
+	}
+	return configs, nil
+}
+
+func routeKey(method, route string) string {
+	return strings.ToUpper(method) + " " + route
+}
@@ -0,0 +1,52 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type prometheusSLAObserver struct {
+	atRiskTotal   prometheus.Counter
+	breachedTotal prometheus.Counter
+	active        *prometheus.GaugeVec
+}
+
+func NewPrometheusSLAObserver(reg prometheus.Registerer) *prometheusSLAObserver {
+	factory := promauto.With(reg)
+
+	atRiskTotal := factory.NewCounter(prometheus.CounterOpts{
+		Name: "service_courier_delivery_sla_at_risk_total",
+		Help: "total deliveries that reached the at-risk fraction of their deadline",
+	})
+
+	breachedTotal := factory.NewCounter(prometheus.CounterOpts{
+		Name: "service_courier_delivery_sla_breached_total",
+		Help: "total deliveries that passed their deadline without completion",
+	})
+
+	active := factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "service_courier_deliveries_active",
+			Help: "current amount of active deliveries by SLA state",
+		},
+		[]string{"sla_state"},
+	)
+
+	return &prometheusSLAObserver{
+		atRiskTotal:   atRiskTotal,
+		breachedTotal: breachedTotal,
+		active:        active,
+	}
+}
+
+func (p *prometheusSLAObserver) AddAtRisk(n int) {
+	p.atRiskTotal.Add(float64(n))
+}
+
+func (p *prometheusSLAObserver) AddBreached(n int) {
+	p.breachedTotal.Add(float64(n))
+}
+
+func (p *prometheusSLAObserver) SetActive(slaState string, n int) {
+	p.active.WithLabelValues(slaState).Set(float64(n))
+}
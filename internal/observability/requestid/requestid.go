@@ -0,0 +1,61 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header заголовок HTTP запроса и Kafka сообщения с request id. В gRPC метаданных ключи в нижнем регистре
+const (
+	Header       = "X-Request-Id"
+	MetadataKey  = "x-request-id"
+	maxLength    = 128
+	randomLength = 16
+)
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext возвращает пустую строку, если request id в контексте нет
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// New случайный id из 32 hex символов
+func New() string {
+	b := make([]byte, randomLength)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid id от клиента попадает в логи и заголовки ответа, поэтому принимаем только
+// печатные ASCII символы без пробелов и разумной длины
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// UnaryClientInterceptor передает request id из контекста в метаданные исходящих gRPC вызовов
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
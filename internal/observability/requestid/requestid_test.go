@@ -0,0 +1,47 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestContext(t *testing.T) {
+	require.Empty(t, FromContext(context.Background()))
+	require.Equal(t, "abc", FromContext(NewContext(context.Background(), "abc")))
+}
+
+func TestNew(t *testing.T) {
+	id := New()
+	require.Len(t, id, 32)
+	require.True(t, Valid(id))
+	require.NotEqual(t, id, New())
+}
+
+func TestValid(t *testing.T) {
+	require.True(t, Valid("req-1"))
+	require.False(t, Valid(""))
+	require.False(t, Valid("with space"))
+	require.False(t, Valid("line\nbreak"))
+	require.False(t, Valid(strings.Repeat("a", 129)))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	var got []string
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		got = md.Get(MetadataKey)
+		return nil
+	}
+
+	interceptor := UnaryClientInterceptor()
+	require.NoError(t, interceptor(NewContext(context.Background(), "req-1"), "/m", nil, nil, nil, invoker))
+	require.Equal(t, []string{"req-1"}, got)
+
+	require.NoError(t, interceptor(context.Background(), "/m", nil, nil, nil, invoker))
+	require.Empty(t, got)
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+)
+
+type apiKeyRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIKeyRepositoryPostgres(pool *pgxpool.Pool) *apiKeyRepositoryPostgres {
+	return &apiKeyRepositoryPostgres{pool: pool}
+}
+
+// GetByHash активный (не отозванный) ключ по sha256 в hex
+func (a *apiKeyRepositoryPostgres) GetByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	sql := `
+        SELECT key_hash, name, role, COALESCE(courier_id, 0)
+        FROM api_keys
+        WHERE key_hash=$1 AND revoked_at IS NULL
+    `
+
+	var key model.APIKey
+	err := a.pool.QueryRow(ctx, sql, hash).Scan(&key.KeyHash, &key.Name, &key.Role, &key.CourierId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.APIKey{}, repository.ErrAPIKeyNotFound
+		}
+		return model.APIKey{}, repository.ErrInternalError
+	}
+	return key, nil
+}
@@ -67,6 +67,7 @@ func parseConfig(cfg config.PostgresStorage, env string) (*pgxpool.Config, error
 	pgxCfg.MaxConns = cfg.MaxConns
 	pgxCfg.MinConns = cfg.MinConns
 	pgxCfg.MaxConnLifetime = cfg.MaxConnLifeTime
+	pgxCfg.ConnConfig.Tracer = queryTracer{}
 
 	return pgxCfg, nil
 }
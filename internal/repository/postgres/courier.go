@@ -52,12 +52,13 @@ func (c *courierRepositoryPostgres) Create(ctx context.Context, courier model.Co
 
 func (c *courierRepositoryPostgres) GetById(ctx context.Context, id int) (model.Courier, error) {
 	sql := `
-        SELECT name, phone, status, transport_type, created_at, updated_at
+        SELECT name, phone, status, transport_type, rating_sum, rating_count, created_at, updated_at
         FROM couriers
         WHERE id=$1
     `
 
 	var courier model.Courier
+	var ratingSum int
 	var err error
 
 	if tx := GetTx(ctx); tx != nil { // с транзакцией
@@ -66,6 +67,8 @@ func (c *courierRepositoryPostgres) GetById(ctx context.Context, id int) (model.
 			&courier.Phone,
 			&courier.Status,
 			&courier.TransportType,
+			&ratingSum,
+			&courier.RatingCount,
 			&courier.CreatedAt,
 			&courier.UpdatedAt,
 		)
@@ -75,6 +78,8 @@ func (c *courierRepositoryPostgres) GetById(ctx context.Context, id int) (model.
 			&courier.Phone,
 			&courier.Status,
 			&courier.TransportType,
+			&ratingSum,
+			&courier.RatingCount,
 			&courier.CreatedAt,
 			&courier.UpdatedAt,
 		)
@@ -87,6 +92,7 @@ func (c *courierRepositoryPostgres) GetById(ctx context.Context, id int) (model.
 		}
 		return model.Courier{}, repository.ErrInternalError
 	}
+	courier.RatingAvg = ratingAvg(ratingSum, courier.RatingCount)
 
 	return courier, err
 }
@@ -140,13 +146,14 @@ func (c *courierRepositoryPostgres) GetAll(ctx context.Context) ([]model.Courier
 }
 
 // GetAvailable решил возвращать полный объект domain.Courier, чтобы сохранить логику геттеров.
-// Мне кажется, не очень понятно было бы возвращать только id и transport_type, тем более структура достаточно легкая
-func (c *courierRepositoryPostgres) GetAvailable(ctx context.Context) (model.Courier, error) {
+// Мне кажется, не очень понятно было бы возвращать только id и transport_type, тем более структура достаточно легкая.
+// Курьеры с низким рейтингом (по rating) получают заказ, только если свободных курьеров без низкого рейтинга нет
+func (c *courierRepositoryPostgres) GetAvailable(ctx context.Context, rating model.RatingPolicy) (model.Courier, error) {
 	sql := `
 		SELECT id, name, phone, status, transport_type, total_deliveries, created_at, updated_at
 		FROM couriers
 		WHERE status = 'available'
-		ORDER BY total_deliveries
+		ORDER BY (rating_count > 0 AND rating_count >= $1 AND rating_sum < $2::float8 * rating_count), total_deliveries
 		LIMIT 1;
     `
 
@@ -154,7 +161,7 @@ func (c *courierRepositoryPostgres) GetAvailable(ctx context.Context) (model.Cou
 	var err error
 
 	if tx := GetTx(ctx); tx != nil { // с транзакцией
-		err = tx.QueryRow(ctx, sql).Scan(
+		err = tx.QueryRow(ctx, sql, rating.MinCount, rating.LowThreshold).Scan(
 			&courier.Id,
 			&courier.Name,
 			&courier.Phone,
@@ -164,7 +171,7 @@ func (c *courierRepositoryPostgres) GetAvailable(ctx context.Context) (model.Cou
 			&courier.CreatedAt,
 			&courier.UpdatedAt)
 	} else { // без транзакции
-		err = c.pool.QueryRow(ctx, sql).Scan(
+		err = c.pool.QueryRow(ctx, sql, rating.MinCount, rating.LowThreshold).Scan(
 			&courier.Id,
 			&courier.Name,
 			&courier.Phone,
@@ -288,6 +295,44 @@ func (c *courierRepositoryPostgres) UpdateStatusManyById(ctx context.Context, id
 	return nil
 }
 
+// UpdateStatusUnlessBusy меняет статус курьера, только если он не занят доставкой: busy снимает
+// завершение или снятие доставки. Вернет ErrCourierBusy для занятого курьера и ErrCourierNotFound, если его нет
+func (c *courierRepositoryPostgres) UpdateStatusUnlessBusy(ctx context.Context, id int, status string) error {
+	sql := `
+        UPDATE couriers
+        SET status = $2, updated_at = $3
+        WHERE id = $1 AND status <> 'busy'
+    `
+	existsSQL := `SELECT EXISTS(SELECT 1 FROM couriers WHERE id = $1)`
+
+	var cmdTag pgconn.CommandTag
+	var exists bool
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		cmdTag, err = tx.Exec(ctx, sql, id, status, time.Now())
+		if err == nil && cmdTag.RowsAffected() == 0 {
+			err = tx.QueryRow(ctx, existsSQL, id).Scan(&exists)
+		}
+	} else { // без транзакции
+		cmdTag, err = c.pool.Exec(ctx, sql, id, status, time.Now())
+		if err == nil && cmdTag.RowsAffected() == 0 {
+			err = c.pool.QueryRow(ctx, existsSQL, id).Scan(&exists)
+		}
+	}
+
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	if cmdTag.RowsAffected() == 0 {
+		if exists {
+			return repository.ErrCourierBusy
+		}
+		return repository.ErrCourierNotFound
+	}
+	return nil
+}
+
 func (c *courierRepositoryPostgres) DeleteById(ctx context.Context, id int) error {
 	sql := `
         DELETE FROM couriers
@@ -312,3 +357,157 @@ func (c *courierRepositoryPostgres) DeleteById(ctx context.Context, id int) erro
 
 	return err
 }
+
+// CreateMany вставляет пачку курьеров через COPY. Возвращает количество вставленных строк.
+// COPY не умеет RETURNING, поэтому id созданных курьеров не возвращаются
+func (c *courierRepositoryPostgres) CreateMany(ctx context.Context, couriers []model.Courier) (int, error) {
+	columns := []string{"name", "phone", "status", "transport_type"}
+	rows := make([][]any, len(couriers))
+	for i, courier := range couriers {
+		rows[i] = []any{courier.Name, courier.Phone, courier.Status, courier.TransportType}
+	}
+
+	var copied int64
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		copied, err = tx.CopyFrom(ctx, pgx.Identifier{"couriers"}, columns, pgx.CopyFromRows(rows))
+	} else { // без транзакции
+		copied, err = c.pool.CopyFrom(ctx, pgx.Identifier{"couriers"}, columns, pgx.CopyFromRows(rows))
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, repository.ErrCourierExists
+		}
+		return 0, repository.ErrInternalError
+	}
+
+	return int(copied), nil
+}
+
+// GetExistingPhones возвращает те телефоны из переданных, которые уже заняты другими курьерами
+func (c *courierRepositoryPostgres) GetExistingPhones(ctx context.Context, phones []string) ([]string, error) {
+	sql := `
+        SELECT phone
+        FROM couriers
+        WHERE phone=ANY($1)
+    `
+
+	var rows pgx.Rows
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		rows, err = tx.Query(ctx, sql, phones)
+	} else { // без транзакции
+		rows, err = c.pool.Query(ctx, sql, phones)
+	}
+
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	existing := make([]string, 0)
+	for rows.Next() {
+		var phone string
+		if err = rows.Scan(&phone); err != nil {
+			return nil, repository.ErrInternalError
+		}
+		existing = append(existing, phone)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return existing, nil
+}
+
+// StreamAll построчно передает всех курьеров в fn, не загружая всю таблицу в память.
+// Ошибка, которую вернул fn, прерывает чтение и возвращается как есть
+func (c *courierRepositoryPostgres) StreamAll(ctx context.Context, fn func(model.Courier) error) error {
+	sql := `
+        SELECT id, name, phone, status, transport_type, total_deliveries, created_at, updated_at
+        FROM couriers
+        ORDER BY id
+    `
+
+	var rows pgx.Rows
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		rows, err = tx.Query(ctx, sql)
+	} else { // без транзакции
+		rows, err = c.pool.Query(ctx, sql)
+	}
+
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var courier model.Courier
+		err = rows.Scan(
+			&courier.Id,
+			&courier.Name,
+			&courier.Phone,
+			&courier.Status,
+			&courier.TransportType,
+			&courier.TotalDeliveries,
+			&courier.CreatedAt,
+			&courier.UpdatedAt,
+		)
+		if err != nil {
+			return repository.ErrInternalError
+		}
+
+		if err = fn(courier); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return repository.ErrInternalError
+	}
+
+	return nil
+}
+
+// AddRating учитывает новую оценку в рейтинге курьера и возвращает обновленные среднюю оценку и количество оценок.
+// Пересчет идет одним UPDATE, поэтому параллельные отзывы не теряются
+func (c *courierRepositoryPostgres) AddRating(ctx context.Context, id int, rating int) (float64, int, error) {
+	sql := `
+        UPDATE couriers
+        SET rating_sum = rating_sum + $1, rating_count = rating_count + 1, updated_at = $2
+        WHERE id=$3
+        RETURNING rating_sum, rating_count
+    `
+
+	var sum, count int
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		err = tx.QueryRow(ctx, sql, rating, time.Now(), id).Scan(&sum, &count)
+	} else { // без транзакции
+		err = c.pool.QueryRow(ctx, sql, rating, time.Now(), id).Scan(&sum, &count)
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, repository.ErrCourierNotFound
+		}
+		return 0, 0, repository.ErrInternalError
+	}
+
+	return ratingAvg(sum, count), count, nil
+}
+
+func ratingAvg(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
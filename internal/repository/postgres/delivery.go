@@ -22,8 +22,8 @@ func NewDeliveryRepositoryPostgres(pool *pgxpool.Pool) *deliveryRepositoryPostgr
 
 func (d *deliveryRepositoryPostgres) Create(ctx context.Context, delivery model.Delivery) (int, error) {
 	sql := `
-        INSERT INTO delivery (courier_id, order_id, assigned_at, deadline)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO delivery (courier_id, order_id, assigned_at, deadline, proof_required, handoff_code_hash)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
         RETURNING id
     `
 
@@ -31,9 +31,9 @@ func (d *deliveryRepositoryPostgres) Create(ctx context.Context, delivery model.
 	var err error
 
 	if tx := GetTx(ctx); tx != nil { // с транзакцией
-		err = tx.QueryRow(ctx, sql, delivery.CourierId, delivery.OrderId, delivery.AssignedAt, delivery.Deadline).Scan(&id)
+		err = tx.QueryRow(ctx, sql, delivery.CourierId, delivery.OrderId, delivery.AssignedAt, delivery.Deadline, delivery.ProofRequired, delivery.HandoffCodeHash).Scan(&id)
 	} else { // без транзакции
-		err = d.pool.QueryRow(ctx, sql, delivery.CourierId, delivery.OrderId, delivery.AssignedAt, delivery.Deadline).Scan(&id)
+		err = d.pool.QueryRow(ctx, sql, delivery.CourierId, delivery.OrderId, delivery.AssignedAt, delivery.Deadline, delivery.ProofRequired, delivery.HandoffCodeHash).Scan(&id)
 	}
 
 	if err != nil {
@@ -51,9 +51,11 @@ func (d *deliveryRepositoryPostgres) Create(ctx context.Context, delivery model.
 
 func (c *deliveryRepositoryPostgres) GetByOrderId(ctx context.Context, orderId string) (model.Delivery, error) {
 	sql := `
-        SELECT id, courier_id, order_id, assigned_at, deadline
-        FROM delivery
-        WHERE order_id=$1
+        SELECT d.id, d.courier_id, d.order_id, d.assigned_at, d.deadline, d.status, d.sla_state, d.proof_required,
+               EXISTS(SELECT 1 FROM delivery_proofs p WHERE p.delivery_id = d.id),
+               COALESCE(d.handoff_code_hash, '')
+        FROM delivery d
+        WHERE d.order_id=$1
     `
 
 	var delivery model.Delivery
@@ -66,6 +68,11 @@ func (c *deliveryRepositoryPostgres) GetByOrderId(ctx context.Context, orderId s
 			&delivery.OrderId,
 			&delivery.AssignedAt,
 			&delivery.Deadline,
+			&delivery.Status,
+			&delivery.SLAState,
+			&delivery.ProofRequired,
+			&delivery.ProofAttached,
+			&delivery.HandoffCodeHash,
 		)
 	} else { // без транзакции
 		err = c.pool.QueryRow(ctx, sql, orderId).Scan(
@@ -74,6 +81,11 @@ func (c *deliveryRepositoryPostgres) GetByOrderId(ctx context.Context, orderId s
 			&delivery.OrderId,
 			&delivery.AssignedAt,
 			&delivery.Deadline,
+			&delivery.Status,
+			&delivery.SLAState,
+			&delivery.ProofRequired,
+			&delivery.ProofAttached,
+			&delivery.HandoffCodeHash,
 		)
 	}
 
@@ -88,7 +100,8 @@ func (c *deliveryRepositoryPostgres) GetByOrderId(ctx context.Context, orderId s
 	return delivery, err
 }
 
-func (c *deliveryRepositoryPostgres) GetAllCompleted(ctx context.Context) ([]model.Delivery, error) {
+// GetAllCompleted возвращает доставки, дедлайн которых прошел раньше before
+func (c *deliveryRepositoryPostgres) GetAllCompleted(ctx context.Context, before time.Time) ([]model.Delivery, error) {
 	sql := `
         SELECT id, courier_id, order_id, assigned_at, deadline
         FROM delivery
@@ -99,9 +112,9 @@ func (c *deliveryRepositoryPostgres) GetAllCompleted(ctx context.Context) ([]mod
 	var err error
 
 	if tx := GetTx(ctx); tx != nil { // с транзакцией
-		rows, err = tx.Query(ctx, sql, time.Now())
+		rows, err = tx.Query(ctx, sql, before)
 	} else { // без транзакции
-		rows, err = c.pool.Query(ctx, sql, time.Now())
+		rows, err = c.pool.Query(ctx, sql, before)
 	}
 
 	if err != nil {
@@ -186,3 +199,425 @@ func (d *deliveryRepositoryPostgres) DeleteManyById(ctx context.Context, ids ...
 
 	return err
 }
+
+// ArchiveManyById переносит копии доставок в delivery_history с переданным статусом.
+// Сами доставки не удаляются - это делают DeleteByOrderId / DeleteManyById в той же транзакции.
+// Тип транспорта берется у курьера на момент завершения доставки.
+// Если доставка завершилась после дедлайна, в истории она считается breached, даже если монитор не успел ее пометить
+func (d *deliveryRepositoryPostgres) ArchiveManyById(ctx context.Context, status string, ids ...int) error {
+	sql := `
+        INSERT INTO delivery_history (courier_id, order_id, transport_type, status, assigned_at, deadline, finished_at, sla_state)
+        SELECT d.courier_id, d.order_id, c.transport_type, $1, d.assigned_at, d.deadline, $2,
+               CASE WHEN $2 > d.deadline THEN 'breached' ELSE d.sla_state END
+        FROM delivery d
+        JOIN couriers c ON c.id = d.courier_id
+        WHERE d.id=ANY($3)
+    `
+
+	var cmdTag pgconn.CommandTag
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		cmdTag, err = tx.Exec(ctx, sql, status, time.Now(), ids)
+	} else { // без транзакции
+		cmdTag, err = d.pool.Exec(ctx, sql, status, time.Now(), ids)
+	}
+
+	if err != nil {
+		return repository.ErrInternalError
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return repository.ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// GetLastFinishedByOrderId возвращает последнюю завершенную доставку заказа.
+// Заказ мог несколько раз сниматься с курьера, поэтому в истории по нему может быть несколько записей
+func (d *deliveryRepositoryPostgres) GetLastFinishedByOrderId(ctx context.Context, orderId string) (model.FinishedDelivery, error) {
+	sql := `
+        SELECT id, courier_id, order_id, transport_type, status, sla_state, assigned_at, deadline, finished_at
+        FROM delivery_history
+        WHERE order_id=$1
+        ORDER BY finished_at DESC, id DESC
+        LIMIT 1
+    `
+
+	var delivery model.FinishedDelivery
+	var row pgx.Row
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		row = tx.QueryRow(ctx, sql, orderId)
+	} else { // без транзакции
+		row = d.pool.QueryRow(ctx, sql, orderId)
+	}
+
+	err := row.Scan(
+		&delivery.Id,
+		&delivery.CourierId,
+		&delivery.OrderId,
+		&delivery.TransportType,
+		&delivery.Status,
+		&delivery.SLAState,
+		&delivery.AssignedAt,
+		&delivery.Deadline,
+		&delivery.FinishedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.FinishedDelivery{}, repository.ErrDeliveryNotFound
+		}
+		return model.FinishedDelivery{}, repository.ErrInternalError
+	}
+
+	return delivery, nil
+}
+
+// GetStatesByOrderIds состояние доставки каждого заказа: active, если доставка в delivery, иначе статус
+// последней записи delivery_history. Заказов без доставки в результате нет
+func (d *deliveryRepositoryPostgres) GetStatesByOrderIds(ctx context.Context, orderIds []string) (map[string]string, error) {
+	sql := `
+        SELECT o.order_id,
+               CASE WHEN EXISTS(SELECT 1 FROM delivery d WHERE d.order_id = o.order_id) THEN $2::text
+                    ELSE (SELECT h.status FROM delivery_history h WHERE h.order_id = o.order_id
+                          ORDER BY h.finished_at DESC, h.id DESC LIMIT 1)
+               END
+        FROM unnest($1::text[]) AS o(order_id)
+    `
+
+	rows, err := d.pool.Query(ctx, sql, orderIds, model.DeliveryActive)
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	states := make(map[string]string, len(orderIds))
+	for rows.Next() {
+		var orderId string
+		var state *string
+		if err = rows.Scan(&orderId, &state); err != nil {
+			return nil, repository.ErrInternalError
+		}
+		if state != nil {
+			states[orderId] = *state
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return states, nil
+}
+
+// GetActiveOrderIds до limit заказов доставок в работе с order_id больше after, по возрастанию order_id
+func (d *deliveryRepositoryPostgres) GetActiveOrderIds(ctx context.Context, after string, limit int) ([]string, error) {
+	rows, err := d.pool.Query(ctx, `SELECT order_id FROM delivery WHERE order_id > $1 ORDER BY order_id LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	var orderIds []string
+	for rows.Next() {
+		var orderId string
+		if err = rows.Scan(&orderId); err != nil {
+			return nil, repository.ErrInternalError
+		}
+		orderIds = append(orderIds, orderId)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return orderIds, nil
+}
+
+// MarkAtRisk переводит в at_risk доставки, у которых прошла доля fraction времени от назначения до дедлайна.
+// Возвращает только доставки, состояние которых изменилось, поэтому каждая доставка попадает сюда один раз
+func (d *deliveryRepositoryPostgres) MarkAtRisk(ctx context.Context, fraction float64, now time.Time) ([]model.Delivery, error) {
+	sql := `
+        UPDATE delivery
+        SET sla_state = 'at_risk', at_risk_at = $2
+        WHERE sla_state = 'on_time'
+          AND deadline > $2
+          AND assigned_at + (deadline - assigned_at) * $1::float8 <= $2
+        RETURNING id, courier_id, order_id, assigned_at, deadline, sla_state
+    `
+
+	var rows pgx.Rows
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		rows, err = tx.Query(ctx, sql, fraction, now)
+	} else { // без транзакции
+		rows, err = d.pool.Query(ctx, sql, fraction, now)
+	}
+
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	return collectSLADeliveries(rows)
+}
+
+// MarkBreached переводит в breached доставки, дедлайн которых прошел. Как и MarkAtRisk, возвращает только изменившиеся
+func (d *deliveryRepositoryPostgres) MarkBreached(ctx context.Context, now time.Time) ([]model.Delivery, error) {
+	sql := `
+        UPDATE delivery
+        SET sla_state = 'breached', breached_at = $1
+        WHERE sla_state <> 'breached' AND deadline <= $1
+        RETURNING id, courier_id, order_id, assigned_at, deadline, sla_state
+    `
+
+	var rows pgx.Rows
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		rows, err = tx.Query(ctx, sql, now)
+	} else { // без транзакции
+		rows, err = d.pool.Query(ctx, sql, now)
+	}
+
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	return collectSLADeliveries(rows)
+}
+
+// CountBySLAState количество текущих доставок в каждом состоянии SLA
+func (d *deliveryRepositoryPostgres) CountBySLAState(ctx context.Context) (map[string]int, error) {
+	sql := `
+        SELECT sla_state, COUNT(*)
+        FROM delivery
+        GROUP BY sla_state
+    `
+
+	var rows pgx.Rows
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		rows, err = tx.Query(ctx, sql)
+	} else { // без транзакции
+		rows, err = d.pool.Query(ctx, sql)
+	}
+
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	counts := map[string]int{
+		model.SLAStateOnTime:   0,
+		model.SLAStateAtRisk:   0,
+		model.SLAStateBreached: 0,
+	}
+	for rows.Next() {
+		var state string
+		var count int
+		if err = rows.Scan(&state, &count); err != nil {
+			return nil, repository.ErrInternalError
+		}
+		counts[state] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return counts, nil
+}
+
+func collectSLADeliveries(rows pgx.Rows) ([]model.Delivery, error) {
+	defer rows.Close()
+
+	var deliveries []model.Delivery
+	for rows.Next() {
+		var delivery model.Delivery
+		err := rows.Scan(
+			&delivery.Id,
+			&delivery.CourierId,
+			&delivery.OrderId,
+			&delivery.AssignedAt,
+			&delivery.Deadline,
+			&delivery.SLAState,
+		)
+		if err != nil {
+			return nil, repository.ErrInternalError
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return deliveries, nil
+}
+
+// UpdateStatus переводит доставку на следующий этап. Условие на текущий этап защищает от гонки двух событий:
+// если этап уже успел смениться, вернется ErrDeliveryStatusConflict
+func (d *deliveryRepositoryPostgres) UpdateStatus(ctx context.Context, id int, from, to string) error {
+	sql := `
+        UPDATE delivery
+        SET status=$3
+        WHERE id=$1 AND status=$2
+    `
+
+	var cmdTag pgconn.CommandTag
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		cmdTag, err = tx.Exec(ctx, sql, id, from, to)
+	} else { // без транзакции
+		cmdTag, err = d.pool.Exec(ctx, sql, id, from, to)
+	}
+
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return repository.ErrDeliveryStatusConflict
+	}
+
+	return nil
+}
+
+// AddEventManyById пишет в таймлайн событие status для каждой из доставок. Как и ArchiveManyById,
+// должен вызываться до удаления доставок
+func (d *deliveryRepositoryPostgres) AddEventManyById(ctx context.Context, status string, at time.Time, ids ...int) error {
+	sql := `
+        INSERT INTO delivery_events (order_id, courier_id, status, occurred_at, actor)
+        SELECT order_id, courier_id, $1, $2, $4
+        FROM delivery
+        WHERE id=ANY($3)
+    `
+
+	// автор события берется из контекста запроса, у внутренних событий его нет
+	var actor *string
+	if p, ok := model.PrincipalFromContext(ctx); ok {
+		s := p.String()
+		actor = &s
+	}
+
+	var cmdTag pgconn.CommandTag
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		cmdTag, err = tx.Exec(ctx, sql, status, at, ids, actor)
+	} else { // без транзакции
+		cmdTag, err = d.pool.Exec(ctx, sql, status, at, ids, actor)
+	}
+
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return repository.ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// GetEventsByOrderId таймлайн заказа по порядку. Если заказ переназначали, в нем будут события нескольких курьеров
+func (d *deliveryRepositoryPostgres) GetEventsByOrderId(ctx context.Context, orderId string) ([]model.DeliveryEvent, error) {
+	sql := `
+        SELECT id, order_id, courier_id, status, COALESCE(actor, ''), occurred_at
+        FROM delivery_events
+        WHERE order_id=$1
+        ORDER BY occurred_at, id
+    `
+
+	var rows pgx.Rows
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		rows, err = tx.Query(ctx, sql, orderId)
+	} else { // без транзакции
+		rows, err = d.pool.Query(ctx, sql, orderId)
+	}
+
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	var events []model.DeliveryEvent
+	for rows.Next() {
+		var event model.DeliveryEvent
+		err = rows.Scan(
+			&event.Id,
+			&event.OrderId,
+			&event.CourierId,
+			&event.Status,
+			&event.Actor,
+			&event.OccurredAt,
+		)
+		if err != nil {
+			return nil, repository.ErrInternalError
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return events, nil
+}
+
+// ReserveHandoffAttempt атомарно учитывает попытку ввода кода передачи до его проверки, иначе параллельные запросы обходят лимит.
+// Попытка с номером maxAttempts тем же запросом блокирует ввод до lockUntil и сбрасывает счетчик:
+// счетчик не может остаться на лимите без блокировки, даже если следующий запрос к базе не пройдет.
+// Если ввод заблокирован (или доставки уже нет), вернется ErrHandoffLocked
+func (d *deliveryRepositoryPostgres) ReserveHandoffAttempt(ctx context.Context, id int, now time.Time, maxAttempts int, lockUntil time.Time) error {
+	sql := `
+        UPDATE delivery
+        SET handoff_failed_attempts = CASE WHEN handoff_failed_attempts + 1 >= $3 THEN 0 ELSE handoff_failed_attempts + 1 END,
+            handoff_locked_until    = CASE WHEN handoff_failed_attempts + 1 >= $3 THEN $4 END
+        WHERE id=$1 AND (handoff_locked_until IS NULL OR handoff_locked_until<=$2)
+    `
+
+	var cmdTag pgconn.CommandTag
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		cmdTag, err = tx.Exec(ctx, sql, id, now, maxAttempts, lockUntil)
+	} else { // без транзакции
+		cmdTag, err = d.pool.Exec(ctx, sql, id, now, maxAttempts, lockUntil)
+	}
+
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return repository.ErrHandoffLocked
+	}
+	return nil
+}
+
+// ReleaseHandoffAttempt возвращает попытку, занятую ReserveHandoffAttempt, если код оказался верным.
+// Так в лимит попадают только неверные коды, а курьер, которому отказали уже после кода (например, нет подтверждения), не блокируется.
+// Если эта попытка была последней и поставила блокировку lockUntil, блокировка снимается, а счетчик возвращается к maxAttempts-1.
+// Блокировку, которую поставил параллельный неверный код, release не трогает
+func (d *deliveryRepositoryPostgres) ReleaseHandoffAttempt(ctx context.Context, id int, maxAttempts int, lockUntil time.Time) error {
+	sql := `
+        UPDATE delivery
+        SET handoff_failed_attempts = CASE WHEN handoff_locked_until = $3 THEN $2::int - 1 ELSE GREATEST(handoff_failed_attempts - 1, 0) END,
+            handoff_locked_until    = CASE WHEN handoff_locked_until = $3 THEN NULL ELSE handoff_locked_until END
+        WHERE id=$1
+    `
+
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		_, err = tx.Exec(ctx, sql, id, maxAttempts, lockUntil)
+	} else { // без транзакции
+		_, err = d.pool.Exec(ctx, sql, id, maxAttempts, lockUntil)
+	}
+
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+)
+
+type feedbackRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewFeedbackRepositoryPostgres(pool *pgxpool.Pool) *feedbackRepositoryPostgres {
+	return &feedbackRepositoryPostgres{pool: pool}
+}
+
+// Create сохраняет отзыв. На order_id стоит UNIQUE, поэтому второй отзыв на тот же заказ вернет ErrFeedbackExists
+func (f *feedbackRepositoryPostgres) Create(ctx context.Context, feedback model.Feedback) (int, error) {
+	sql := `
+        INSERT INTO delivery_feedback (order_id, courier_id, rating, tags, comment)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+
+	tags := feedback.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var id int
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		err = tx.QueryRow(ctx, sql, feedback.OrderId, feedback.CourierId, feedback.Rating, tags, feedback.Comment).Scan(&id)
+	} else { // без транзакции
+		err = f.pool.QueryRow(ctx, sql, feedback.OrderId, feedback.CourierId, feedback.Rating, tags, feedback.Comment).Scan(&id)
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return -1, repository.ErrFeedbackExists
+		}
+		return -1, repository.ErrInternalError
+	}
+	return id, nil
+}
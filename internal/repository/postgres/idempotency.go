@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+	"time"
+)
+
+type idempotencyRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepositoryPostgres(pool *pgxpool.Pool) *idempotencyRepositoryPostgres {
+	return &idempotencyRepositoryPostgres{pool: pool}
+}
+
+// Acquire занимает ключ под новый запрос. Занятый ключ можно перехватить, только если его запись истекла
+// или запрос с тем же телом бросил блокировку (например, реплика упала). Если ключ занять не удалось,
+// возвращается существующая запись и false
+func (i *idempotencyRepositoryPostgres) Acquire(ctx context.Context, record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error) {
+	sql := `
+        INSERT INTO idempotency_keys (scope, key, fingerprint, lock_token, locked_until, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (scope, key) DO UPDATE
+            SET fingerprint  = EXCLUDED.fingerprint,
+                lock_token   = EXCLUDED.lock_token,
+                status       = NULL,
+                content_type = NULL,
+                body         = NULL,
+                locked_until = EXCLUDED.locked_until,
+                expires_at   = EXCLUDED.expires_at,
+                created_at   = now()
+            WHERE idempotency_keys.expires_at < $7
+               OR (idempotency_keys.status IS NULL
+                   AND idempotency_keys.locked_until < $7
+                   AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+        RETURNING scope
+    `
+
+	var scope string
+	err := i.pool.QueryRow(ctx, sql,
+		record.Scope,
+		record.Key,
+		record.Fingerprint,
+		record.LockToken,
+		record.LockedUntil.UTC(),
+		record.ExpiresAt.UTC(),
+		now.UTC(),
+	).Scan(&scope)
+	if err == nil {
+		return record, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return model.IdempotencyRecord{}, false, repository.ErrInternalError
+	}
+
+	existing, err := i.get(ctx, record.Scope, record.Key)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+	return existing, false, nil
+}
+
+func (i *idempotencyRepositoryPostgres) get(ctx context.Context, scope, key string) (model.IdempotencyRecord, error) {
+	sql := `
+        SELECT scope, key, fingerprint, lock_token, COALESCE(status, 0), COALESCE(content_type, ''), body, locked_until, expires_at
+        FROM idempotency_keys
+        WHERE scope=$1 AND key=$2
+    `
+
+	var r model.IdempotencyRecord
+	err := i.pool.QueryRow(ctx, sql, scope, key).Scan(
+		&r.Scope,
+		&r.Key,
+		&r.Fingerprint,
+		&r.LockToken,
+		&r.Status,
+		&r.ContentType,
+		&r.Body,
+		&r.LockedUntil,
+		&r.ExpiresAt,
+	)
+	if err != nil {
+		// запись могли удалить между вставкой и чтением, клиенту стоит просто повторить запрос
+		return model.IdempotencyRecord{}, repository.ErrInternalError
+	}
+	return r, nil
+}
+
+// Complete сохраняет ответ. Если блокировку уже перехватил другой запрос, ничего не меняется
+func (i *idempotencyRepositoryPostgres) Complete(ctx context.Context, record model.IdempotencyRecord) error {
+	sql := `
+        UPDATE idempotency_keys
+        SET status=$4, content_type=$5, body=$6
+        WHERE scope=$1 AND key=$2 AND lock_token=$3 AND status IS NULL
+    `
+
+	_, err := i.pool.Exec(ctx, sql, record.Scope, record.Key, record.LockToken, record.Status, record.ContentType, record.Body)
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	return nil
+}
+
+// Release освобождает ключ без ответа, чтобы запрос можно было повторить
+func (i *idempotencyRepositoryPostgres) Release(ctx context.Context, record model.IdempotencyRecord) error {
+	sql := `
+        DELETE FROM idempotency_keys
+        WHERE scope=$1 AND key=$2 AND lock_token=$3 AND status IS NULL
+    `
+
+	_, err := i.pool.Exec(ctx, sql, record.Scope, record.Key, record.LockToken)
+	if err != nil {
+		return repository.ErrInternalError
+	}
+	return nil
+}
+
+// DeleteExpired удаляет записи, истекшие до before
+func (i *idempotencyRepositoryPostgres) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	tag, err := i.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, before.UTC())
+	if err != nil {
+		return 0, repository.ErrInternalError
+	}
+	return int(tag.RowsAffected()), nil
+}
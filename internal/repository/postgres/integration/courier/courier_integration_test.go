@@ -18,8 +18,13 @@ type CourierRepository interface {
 	GetAll(context.Context) ([]model.Courier, error)
 	Update(context.Context, model.Courier) error
 	UpdateStatusManyById(context.Context, ...int) error
+	UpdateStatusUnlessBusy(ctx context.Context, id int, status string) error
 	DeleteById(context.Context, int) error
-	GetAvailable(context.Context) (model.Courier, error)
+	GetAvailable(context.Context, model.RatingPolicy) (model.Courier, error)
+	CreateMany(context.Context, []model.Courier) (int, error)
+	GetExistingPhones(context.Context, []string) ([]string, error)
+	StreamAll(context.Context, func(model.Courier) error) error
+	AddRating(ctx context.Context, id int, rating int) (float64, int, error)
 }
 
 type CourierRepositoryTestSuite struct {
@@ -209,7 +214,7 @@ func (s *CourierRepositoryTestSuite) TestGetAvailable_Success() {
 	_, err = s.pool.Exec(s.ctx, `UPDATE couriers SET total_deliveries=1 WHERE id=$1`, id3)
 	s.Require().NoError(err)
 
-	got, err := s.repo.GetAvailable(s.ctx)
+	got, err := s.repo.GetAvailable(s.ctx, model.RatingPolicy{})
 	s.Require().NoError(err)
 
 	s.Require().Equal("Available2", got.Name)
@@ -232,11 +237,64 @@ func (s *CourierRepositoryTestSuite) TestGetAvailable_NotFound() {
 	_, err = s.repo.Create(s.ctx, c)
 	s.Require().NoError(err)
 
-	_, err = s.repo.GetAvailable(s.ctx)
+	_, err = s.repo.GetAvailable(s.ctx, model.RatingPolicy{})
 	s.Require().Error(err)
 	s.Require().Equal(repository.ErrNoAvailableCouriers, err)
 }
 
+func (s *CourierRepositoryTestSuite) TestGetAvailable_LowRatingLast() {
+	_, err := s.pool.Exec(s.ctx, "DELETE FROM couriers")
+	s.Require().NoError(err)
+
+	lowId, err := s.repo.Create(s.ctx, model.Courier{Name: "LowRated", Phone: "+79990000011", Status: "available", TransportType: "car"})
+	s.Require().NoError(err)
+	_, err = s.repo.Create(s.ctx, model.Courier{Name: "Busier", Phone: "+79990000012", Status: "available", TransportType: "car"})
+	s.Require().NoError(err)
+
+	// у курьера с низким рейтингом меньше доставок, но заказ все равно должен уйти другому
+	_, err = s.pool.Exec(s.ctx, `UPDATE couriers SET total_deliveries = 10 WHERE phone = '+79990000012'`)
+	s.Require().NoError(err)
+	for _, r := range []int{1, 2, 3} {
+		_, _, err = s.repo.AddRating(s.ctx, lowId, r)
+		s.Require().NoError(err)
+	}
+
+	policy := model.RatingPolicy{LowThreshold: 3.5, MinCount: 3}
+
+	got, err := s.repo.GetAvailable(s.ctx, policy)
+	s.Require().NoError(err)
+	s.Require().Equal("Busier", got.Name)
+
+	// пока оценок меньше MinCount, рейтинг не учитывается
+	policy.MinCount = 4
+	got, err = s.repo.GetAvailable(s.ctx, policy)
+	s.Require().NoError(err)
+	s.Require().Equal("LowRated", got.Name)
+}
+
+func (s *CourierRepositoryTestSuite) TestAddRating() {
+	_, err := s.pool.Exec(s.ctx, "DELETE FROM couriers")
+	s.Require().NoError(err)
+
+	id, err := s.repo.Create(s.ctx, model.Courier{Name: "Rated", Phone: "+79990000013", Status: "available", TransportType: "car"})
+	s.Require().NoError(err)
+
+	_, _, err = s.repo.AddRating(s.ctx, id, 5)
+	s.Require().NoError(err)
+	avg, count, err := s.repo.AddRating(s.ctx, id, 2)
+	s.Require().NoError(err)
+	s.Require().Equal(3.5, avg)
+	s.Require().Equal(2, count)
+
+	got, err := s.repo.GetById(s.ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal(3.5, got.RatingAvg)
+	s.Require().Equal(2, got.RatingCount)
+
+	_, _, err = s.repo.AddRating(s.ctx, id+1000, 5)
+	s.Require().Equal(repository.ErrCourierNotFound, err)
+}
+
 func (s *CourierRepositoryTestSuite) TestUpdate_Success() {
 	_, err := s.pool.Exec(s.ctx, "DELETE FROM couriers")
 	s.Require().NoError(err)
@@ -357,6 +415,26 @@ func (s *CourierRepositoryTestSuite) TestUpdateStatusManyById_NoRowsAffected() {
 	s.Require().Equal(repository.ErrInternalError, err)
 }
 
+func (s *CourierRepositoryTestSuite) TestUpdateStatusUnlessBusy() {
+	free, err := s.repo.Create(s.ctx, model.Courier{Name: "X1", Phone: "+70000000010", Status: "available", TransportType: "car"})
+	s.Require().NoError(err)
+	busy, err := s.repo.Create(s.ctx, model.Courier{Name: "X2", Phone: "+70000000011", Status: "busy", TransportType: "car"})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.UpdateStatusUnlessBusy(s.ctx, free, "paused"))
+	courier, err := s.repo.GetById(s.ctx, free)
+	s.Require().NoError(err)
+	s.Equal("paused", courier.Status)
+
+	// занятый курьер сам себя не освобождает
+	s.ErrorIs(s.repo.UpdateStatusUnlessBusy(s.ctx, busy, "available"), repository.ErrCourierBusy)
+	courier, err = s.repo.GetById(s.ctx, busy)
+	s.Require().NoError(err)
+	s.Equal("busy", courier.Status)
+
+	s.ErrorIs(s.repo.UpdateStatusUnlessBusy(s.ctx, 999999, "available"), repository.ErrCourierNotFound)
+}
+
 func (s *CourierRepositoryTestSuite) TestDeleteById_Success() {
 	_, err := s.pool.Exec(s.ctx, "DELETE FROM couriers")
 	s.Require().NoError(err)
@@ -387,3 +465,43 @@ func (s *CourierRepositoryTestSuite) TestDeleteById_NotFound() {
 	s.Require().Error(err)
 	s.Require().Equal(repository.ErrCourierNotFound, err)
 }
+
+func (s *CourierRepositoryTestSuite) TestCreateMany_Success() {
+	couriers := []model.Courier{
+		{Name: "Иван", Phone: "+79990000001", Status: "available", TransportType: "car"},
+		{Name: "Петр", Phone: "+79990000002", Status: "paused", TransportType: "on_foot"},
+	}
+
+	n, err := s.repo.CreateMany(s.ctx, couriers)
+	s.Require().NoError(err)
+	s.Require().Equal(2, n)
+
+	var streamed []model.Courier
+	err = s.repo.StreamAll(s.ctx, func(c model.Courier) error {
+		streamed = append(streamed, c)
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Require().Len(streamed, 2)
+	s.Equal("+79990000001", streamed[0].Phone)
+	s.Equal("+79990000002", streamed[1].Phone)
+}
+
+func (s *CourierRepositoryTestSuite) TestCreateMany_DuplicatePhone() {
+	_, err := s.repo.Create(s.ctx, model.Courier{Name: "Иван", Phone: "+79990000001", Status: "available", TransportType: "car"})
+	s.Require().NoError(err)
+
+	_, err = s.repo.CreateMany(s.ctx, []model.Courier{
+		{Name: "Петр", Phone: "+79990000001", Status: "available", TransportType: "car"},
+	})
+	s.Require().ErrorIs(err, repository.ErrCourierExists)
+}
+
+func (s *CourierRepositoryTestSuite) TestGetExistingPhones() {
+	_, err := s.repo.Create(s.ctx, model.Courier{Name: "Иван", Phone: "+79990000001", Status: "available", TransportType: "car"})
+	s.Require().NoError(err)
+
+	existing, err := s.repo.GetExistingPhones(s.ctx, []string{"+79990000001", "+79990000002"})
+	s.Require().NoError(err)
+	s.Equal([]string{"+79990000001"}, existing)
+}
@@ -15,9 +15,20 @@ import (
 type DeliveryRepository interface {
 	Create(context.Context, model.Delivery) (int, error)
 	GetByOrderId(context.Context, string) (model.Delivery, error)
-	GetAllCompleted(context.Context) ([]model.Delivery, error)
+	GetAllCompleted(context.Context, time.Time) ([]model.Delivery, error)
 	DeleteByOrderId(context.Context, string) error
 	DeleteManyById(context.Context, ...int) error
+	ArchiveManyById(ctx context.Context, status string, ids ...int) error
+	GetStatesByOrderIds(ctx context.Context, orderIds []string) (map[string]string, error)
+	GetActiveOrderIds(ctx context.Context, after string, limit int) ([]string, error)
+	MarkAtRisk(ctx context.Context, fraction float64, now time.Time) ([]model.Delivery, error)
+	MarkBreached(ctx context.Context, now time.Time) ([]model.Delivery, error)
+	CountBySLAState(context.Context) (map[string]int, error)
+	UpdateStatus(ctx context.Context, id int, from, to string) error
+	AddEventManyById(ctx context.Context, status string, at time.Time, ids ...int) error
+	GetEventsByOrderId(context.Context, string) ([]model.DeliveryEvent, error)
+	ReserveHandoffAttempt(ctx context.Context, id int, now time.Time, maxAttempts int, lockUntil time.Time) error
+	ReleaseHandoffAttempt(ctx context.Context, id int, maxAttempts int, lockUntil time.Time) error
 }
 
 type DeliveryRepositoryTestSuite struct {
@@ -161,7 +172,7 @@ func (s *DeliveryRepositoryTestSuite) TestGetAllCompleted_ReturnsPastDeadlines()
 
 	s.insertDelivery(1, "O3", time.Now(), time.Now().Add(1*time.Hour))
 
-	list, err := s.repo.GetAllCompleted(s.ctx)
+	list, err := s.repo.GetAllCompleted(s.ctx, time.Now())
 	s.Require().NoError(err)
 
 	s.Len(list, 2)
@@ -169,6 +180,202 @@ func (s *DeliveryRepositoryTestSuite) TestGetAllCompleted_ReturnsPastDeadlines()
 		[]string{"O1", "O2"},
 		[]string{list[0].OrderId, list[1].OrderId},
 	)
+
+	// с запасом в 20 минут после дедлайна снимается только O1
+	list, err = s.repo.GetAllCompleted(s.ctx, time.Now().Add(-20*time.Minute))
+	s.Require().NoError(err)
+	s.Require().Len(list, 1)
+	s.Equal("O1", list[0].OrderId)
+}
+
+func (s *DeliveryRepositoryTestSuite) TestMarkSLA() {
+	_, err := s.pool.Exec(s.ctx, `
+        INSERT INTO couriers (id, name, phone, status, transport_type, total_deliveries, created_at)
+        VALUES (1, 'Mike', '555', 'available', 'car', 0, NOW())
+    `)
+	s.Require().NoError(err)
+
+	now := time.Now()
+	s.insertDelivery(1, "ON_TIME", now.Add(-10*time.Minute), now.Add(50*time.Minute))
+	s.insertDelivery(1, "AT_RISK", now.Add(-50*time.Minute), now.Add(10*time.Minute))
+	s.insertDelivery(1, "LATE", now.Add(-time.Hour), now.Add(-time.Minute))
+
+	atRisk, err := s.repo.MarkAtRisk(s.ctx, 0.8, now)
+	s.Require().NoError(err)
+	s.Require().Len(atRisk, 1)
+	s.Equal("AT_RISK", atRisk[0].OrderId)
+	s.Equal(model.SLAStateAtRisk, atRisk[0].SLAState)
+
+	breached, err := s.repo.MarkBreached(s.ctx, now)
+	s.Require().NoError(err)
+	s.Require().Len(breached, 1)
+	s.Equal("LATE", breached[0].OrderId)
+
+	// повторный проход ничего не меняет
+	atRisk, err = s.repo.MarkAtRisk(s.ctx, 0.8, now)
+	s.Require().NoError(err)
+	s.Empty(atRisk)
+	breached, err = s.repo.MarkBreached(s.ctx, now)
+	s.Require().NoError(err)
+	s.Empty(breached)
+
+	counts, err := s.repo.CountBySLAState(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(map[string]int{
+		model.SLAStateOnTime:   1,
+		model.SLAStateAtRisk:   1,
+		model.SLAStateBreached: 1,
+	}, counts)
+}
+
+func (s *DeliveryRepositoryTestSuite) TestStatusAndEvents() {
+	_, err := s.pool.Exec(s.ctx, `
+        INSERT INTO couriers (id, name, phone, status, transport_type, total_deliveries, created_at)
+        VALUES (1, 'Mike', '555', 'busy', 'car', 1, NOW())
+    `)
+	s.Require().NoError(err)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	id := s.insertDelivery(1, "EV-1", now, now.Add(time.Hour))
+
+	s.Require().NoError(s.repo.AddEventManyById(s.ctx, model.StatusAssigned, now, id))
+
+	s.Require().NoError(s.repo.UpdateStatus(s.ctx, id, model.StatusAssigned, model.StatusAccepted))
+	courierCtx := model.WithPrincipal(s.ctx, model.Principal{Subject: "app", Role: model.RoleCourier, CourierId: 1})
+	s.Require().NoError(s.repo.AddEventManyById(courierCtx, model.StatusAccepted, now.Add(time.Minute), id))
+
+	// второй запрос с тем же переходом уже не проходит
+	err = s.repo.UpdateStatus(s.ctx, id, model.StatusAssigned, model.StatusAccepted)
+	s.ErrorIs(err, repository.ErrDeliveryStatusConflict)
+
+	delivery, err := s.repo.GetByOrderId(s.ctx, "EV-1")
+	s.Require().NoError(err)
+	s.Equal(model.StatusAccepted, delivery.Status)
+
+	events, err := s.repo.GetEventsByOrderId(s.ctx, "EV-1")
+	s.Require().NoError(err)
+	s.Require().Len(events, 2)
+	s.Equal(model.StatusAssigned, events[0].Status)
+	s.Equal(model.StatusAccepted, events[1].Status)
+	s.Equal(1, events[1].CourierId)
+	// автор события есть только у запросов с principal
+	s.Empty(events[0].Actor)
+	s.Equal("courier:1", events[1].Actor)
+	s.True(now.Add(time.Minute).Equal(events[1].OccurredAt))
+
+	// таймлайн остается после удаления доставки
+	s.Require().NoError(s.repo.DeleteByOrderId(s.ctx, "EV-1"))
+	events, err = s.repo.GetEventsByOrderId(s.ctx, "EV-1")
+	s.Require().NoError(err)
+	s.Len(events, 2)
+
+	err = s.repo.AddEventManyById(s.ctx, model.StatusAccepted, now, id)
+	s.ErrorIs(err, repository.ErrDeliveryNotFound)
+}
+
+func (s *DeliveryRepositoryTestSuite) TestProof() {
+	_, err := s.pool.Exec(s.ctx, `
+        INSERT INTO couriers (id, name, phone, status, transport_type, total_deliveries, created_at)
+        VALUES (1, 'Mike', '555', 'busy', 'car', 1, NOW())
+    `)
+	s.Require().NoError(err)
+
+	id, err := s.repo.Create(s.ctx, model.Delivery{
+		CourierId:     1,
+		OrderId:       "POD-1",
+		AssignedAt:    time.Now(),
+		Deadline:      time.Now().Add(time.Hour),
+		ProofRequired: true,
+	})
+	s.Require().NoError(err)
+
+	delivery, err := s.repo.GetByOrderId(s.ctx, "POD-1")
+	s.Require().NoError(err)
+	s.True(delivery.ProofRequired)
+	s.False(delivery.ProofAttached)
+
+	proofRepo := postgres.NewProofRepositoryPostgres(s.pool)
+	proof := model.DeliveryProof{
+		DeliveryId: id,
+		OrderId:    "POD-1",
+		CourierId:  1,
+		Photo:      model.Blob{Key: "proofs/POD-1/photo.jpg", ContentType: "image/jpeg", Size: 100},
+		PINHash:    "hash",
+		Latitude:   55.75,
+		Longitude:  37.61,
+	}
+	_, err = proofRepo.Create(s.ctx, proof)
+	s.Require().NoError(err)
+
+	delivery, err = s.repo.GetByOrderId(s.ctx, "POD-1")
+	s.Require().NoError(err)
+	s.True(delivery.ProofAttached)
+
+	_, err = proofRepo.Create(s.ctx, proof)
+	s.ErrorIs(err, repository.ErrProofExists)
+
+	// после переназначения заказа подтверждение прежней доставки не засчитывается, новое загружается заново
+	s.Require().NoError(s.repo.DeleteByOrderId(s.ctx, "POD-1"))
+	newId, err := s.repo.Create(s.ctx, model.Delivery{
+		CourierId:     1,
+		OrderId:       "POD-1",
+		AssignedAt:    time.Now(),
+		Deadline:      time.Now().Add(time.Hour),
+		ProofRequired: true,
+	})
+	s.Require().NoError(err)
+
+	delivery, err = s.repo.GetByOrderId(s.ctx, "POD-1")
+	s.Require().NoError(err)
+	s.False(delivery.ProofAttached)
+
+	proof.DeliveryId = newId
+	_, err = proofRepo.Create(s.ctx, proof)
+	s.Require().NoError(err)
+}
+
+func (s *DeliveryRepositoryTestSuite) TestHandoff() {
+	_, err := s.pool.Exec(s.ctx, `
+        INSERT INTO couriers (id, name, phone, status, transport_type, total_deliveries, created_at)
+        VALUES (1, 'Mike', '555', 'busy', 'car', 1, NOW())
+    `)
+	s.Require().NoError(err)
+
+	id, err := s.repo.Create(s.ctx, model.Delivery{
+		CourierId:       1,
+		OrderId:         "PIN-1",
+		AssignedAt:      time.Now(),
+		Deadline:        time.Now().Add(time.Hour),
+		HandoffCodeHash: "hash",
+	})
+	s.Require().NoError(err)
+
+	delivery, err := s.repo.GetByOrderId(s.ctx, "PIN-1")
+	s.Require().NoError(err)
+	s.Equal("hash", delivery.HandoffCodeHash)
+
+	now := time.Now().UTC()
+	lockUntil := now.Add(time.Minute)
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, now, 3, lockUntil))
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, now, 3, lockUntil))
+
+	// третья попытка блокирует ввод тем же запросом, верный код снимает свою блокировку
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, now, 3, lockUntil))
+	s.ErrorIs(s.repo.ReserveHandoffAttempt(s.ctx, id, now, 3, lockUntil), repository.ErrHandoffLocked)
+	s.Require().NoError(s.repo.ReleaseHandoffAttempt(s.ctx, id, 3, lockUntil))
+
+	// счетчик вернулся к двум попыткам: следующая снова блокирует
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, now, 3, lockUntil))
+	s.ErrorIs(s.repo.ReserveHandoffAttempt(s.ctx, id, now, 3, lockUntil), repository.ErrHandoffLocked)
+
+	// после блокировки счетчик начинается заново, верный код возвращает обычную попытку
+	later := now.Add(2 * time.Minute)
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, later, 3, later.Add(time.Minute)))
+	s.Require().NoError(s.repo.ReleaseHandoffAttempt(s.ctx, id, 3, later.Add(time.Minute)))
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, later, 3, later.Add(time.Minute)))
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, later, 3, later.Add(time.Minute)))
+	s.Require().NoError(s.repo.ReserveHandoffAttempt(s.ctx, id, later, 3, later.Add(time.Minute)))
+	s.ErrorIs(s.repo.ReserveHandoffAttempt(s.ctx, id, later, 3, later.Add(time.Minute)), repository.ErrHandoffLocked)
 }
 
 func (s *DeliveryRepositoryTestSuite) TestDeleteByOrderId_Success() {
@@ -228,6 +435,36 @@ func (s *DeliveryRepositoryTestSuite) TestDeleteManyById_NotFound() {
 	s.ErrorIs(err, repository.ErrDeliveryNotFound)
 }
 
+func (s *DeliveryRepositoryTestSuite) TestGetStatesByOrderIds() {
+	_, err := s.pool.Exec(s.ctx, "TRUNCATE TABLE delivery_history RESTART IDENTITY")
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(s.ctx, `
+        INSERT INTO couriers (id, name, phone, status, transport_type, total_deliveries, created_at)
+        VALUES (1, 'Mike', '555', 'available', 'car', 0, NOW())
+    `)
+	s.Require().NoError(err)
+
+	s.insertDelivery(1, "ACTIVE", time.Now(), time.Now().Add(time.Hour))
+	cancelledId := s.insertDelivery(1, "CANCELLED", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	s.Require().NoError(s.repo.ArchiveManyById(s.ctx, model.StatusCancelled, cancelledId))
+	s.Require().NoError(s.repo.DeleteManyById(s.ctx, cancelledId))
+
+	states, err := s.repo.GetStatesByOrderIds(s.ctx, []string{"ACTIVE", "CANCELLED", "UNKNOWN"})
+	s.Require().NoError(err)
+	s.Equal(map[string]string{"ACTIVE": model.DeliveryActive, "CANCELLED": model.StatusCancelled}, states)
+
+	s.insertDelivery(1, "ACTIVE-2", time.Now(), time.Now().Add(time.Hour))
+	s.insertDelivery(1, "ACTIVE-3", time.Now(), time.Now().Add(time.Hour))
+
+	orderIds, err := s.repo.GetActiveOrderIds(s.ctx, "", 2)
+	s.Require().NoError(err)
+	s.Equal([]string{"ACTIVE", "ACTIVE-2"}, orderIds)
+
+	orderIds, err = s.repo.GetActiveOrderIds(s.ctx, "ACTIVE-2", 2)
+	s.Require().NoError(err)
+	s.Equal([]string{"ACTIVE-3"}, orderIds)
+}
+
 func (s *DeliveryRepositoryTestSuite) insertDelivery(courierId int, orderId string, assigned, deadline time.Time) int {
 	var id int
 	err := s.pool.QueryRow(s.ctx,
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type leaderLeaseRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewLeaderLeaseRepositoryPostgres(pool *pgxpool.Pool) *leaderLeaseRepositoryPostgres {
+	return &leaderLeaseRepositoryPostgres{pool: pool}
+}
+
+// TryAcquire берет аренду name, если она свободна или истекла, и продлевает на ttl, если она уже у holder.
+// Возвращает текущую аренду и признак того, что она принадлежит holder
+func (r *leaderLeaseRepositoryPostgres) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (model.LeaderLease, bool, error) {
+	sql := `
+        INSERT INTO leader_leases (name, holder, acquired_at, renewed_at, expires_at)
+        VALUES ($1, $2, now(), now(), now() + make_interval(secs => $3::float8))
+        ON CONFLICT (name) DO UPDATE
+            SET holder      = EXCLUDED.holder,
+                acquired_at = CASE WHEN leader_leases.holder = EXCLUDED.holder
+                                   THEN leader_leases.acquired_at ELSE EXCLUDED.acquired_at END,
+                renewed_at  = EXCLUDED.renewed_at,
+                expires_at  = EXCLUDED.expires_at
+            WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < now()
+        RETURNING name, holder, acquired_at, renewed_at, expires_at
+    `
+
+	var lease model.LeaderLease
+	err := r.pool.QueryRow(ctx, sql, name, holder, ttl.Seconds()).
+		Scan(&lease.Name, &lease.Holder, &lease.AcquiredAt, &lease.RenewedAt, &lease.ExpiresAt)
+	if err == nil {
+		return lease, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return model.LeaderLease{}, false, repository.ErrInternalError
+	}
+
+	// аренда у другой реплики и еще не истекла
+	err = r.pool.QueryRow(ctx, `
+        SELECT name, holder, acquired_at, renewed_at, expires_at FROM leader_leases WHERE name = $1
+    `, name).Scan(&lease.Name, &lease.Holder, &lease.AcquiredAt, &lease.RenewedAt, &lease.ExpiresAt)
+	if err != nil {
+		return model.LeaderLease{}, false, repository.ErrInternalError
+	}
+	return lease, false, nil
+}
+
+// Release отдает аренду при остановке, чтобы другая реплика стала лидером сразу, а не после ttl
+func (r *leaderLeaseRepositoryPostgres) Release(ctx context.Context, name, holder string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM leader_leases WHERE name = $1 AND holder = $2`, name, holder); err != nil {
+		return repository.ErrInternalError
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+)
+
+type proofRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewProofRepositoryPostgres(pool *pgxpool.Pool) *proofRepositoryPostgres {
+	return &proofRepositoryPostgres{pool: pool}
+}
+
+// Create сохраняет метаданные подтверждения. На delivery_id стоит UNIQUE, повторная загрузка вернет ErrProofExists
+func (p *proofRepositoryPostgres) Create(ctx context.Context, proof model.DeliveryProof) (int, error) {
+	sql := `
+        INSERT INTO delivery_proofs (
+            delivery_id, order_id, courier_id,
+            photo_key, photo_content_type, photo_size,
+            signature_key, signature_content_type, signature_size,
+            pin_hash, latitude, longitude
+        )
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        RETURNING id
+    `
+
+	var sigKey, sigContentType *string
+	var sigSize *int64
+	if proof.Signature != nil {
+		sigKey, sigContentType, sigSize = &proof.Signature.Key, &proof.Signature.ContentType, &proof.Signature.Size
+	}
+
+	args := []any{
+		proof.DeliveryId, proof.OrderId, proof.CourierId,
+		proof.Photo.Key, proof.Photo.ContentType, proof.Photo.Size,
+		sigKey, sigContentType, sigSize,
+		proof.PINHash, proof.Latitude, proof.Longitude,
+	}
+
+	var id int
+	var err error
+
+	if tx := GetTx(ctx); tx != nil { // с транзакцией
+		err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	} else { // без транзакции
+		err = p.pool.QueryRow(ctx, sql, args...).Scan(&id)
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return -1, repository.ErrProofExists
+		}
+		return -1, repository.ErrInternalError
+	}
+	return id, nil
+}
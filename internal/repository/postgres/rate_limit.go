@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type rateLimitRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewRateLimitRepositoryPostgres(pool *pgxpool.Pool) *rateLimitRepositoryPostgres {
+	return &rateLimitRepositoryPostgres{pool: pool}
+}
+
+// AddMany атомарно прибавляет Count к счетчикам (key, window_start) и возвращает их значения
+// после прибавления, то есть с учетом запросов всех реплик
+func (r *rateLimitRepositoryPostgres) AddMany(ctx context.Context, counters []model.RateLimitCounter) ([]model.RateLimitCounter, error) {
+	if len(counters) == 0 {
+		return nil, nil
+	}
+
+	// реплики обновляют строки в одном порядке, иначе параллельные пачки могут взять блокировки крест-накрест
+	sorted := append([]model.RateLimitCounter(nil), counters...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Key != sorted[j].Key {
+			return sorted[i].Key < sorted[j].Key
+		}
+		return sorted[i].WindowStart.Before(sorted[j].WindowStart)
+	})
+
+	keys := make([]string, len(sorted))
+	starts := make([]time.Time, len(sorted))
+	expires := make([]time.Time, len(sorted))
+	counts := make([]int32, len(sorted))
+	for i, c := range sorted {
+		keys[i], starts[i], expires[i], counts[i] = c.Key, c.WindowStart.UTC(), c.ExpiresAt.UTC(), int32(c.Count)
+	}
+
+	sql := `
+        INSERT INTO rate_limit_counters (key, window_start, expires_at, count)
+        SELECT * FROM unnest($1::text[], $2::timestamp[], $3::timestamp[], $4::int[])
+        ON CONFLICT (key, window_start) DO UPDATE
+            SET count = rate_limit_counters.count + EXCLUDED.count
+        RETURNING key, window_start, expires_at, count
+    `
+
+	rows, err := r.pool.Query(ctx, sql, keys, starts, expires, counts)
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	totals := make([]model.RateLimitCounter, 0, len(sorted))
+	for rows.Next() {
+		var c model.RateLimitCounter
+		if err = rows.Scan(&c.Key, &c.WindowStart, &c.ExpiresAt, &c.Count); err != nil {
+			return nil, repository.ErrInternalError
+		}
+		totals = append(totals, c)
+	}
+	if rows.Err() != nil {
+		return nil, repository.ErrInternalError
+	}
+	return totals, nil
+}
+
+// DeleteExpired удаляет окна, которые закончились до before
+func (r *rateLimitRepositoryPostgres) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM rate_limit_counters WHERE expires_at < $1`, before.UTC())
+	if err != nil {
+		return 0, repository.ErrInternalError
+	}
+	return int(tag.RowsAffected()), nil
+}
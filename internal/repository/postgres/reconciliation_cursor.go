@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reconciliationCursorRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewReconciliationCursorRepositoryPostgres(pool *pgxpool.Pool) *reconciliationCursorRepositoryPostgres {
+	return &reconciliationCursorRepositoryPostgres{pool: pool}
+}
+
+// Get возвращает курсор name, если сверка еще не запускалась - пустой курсор без ошибки
+func (r *reconciliationCursorRepositoryPostgres) Get(ctx context.Context, name string) (model.ReconciliationCursor, error) {
+	cursor := model.ReconciliationCursor{Name: name}
+	err := r.pool.QueryRow(ctx, `
+        SELECT created_at, order_id FROM reconciliation_cursors WHERE name = $1
+    `, name).Scan(&cursor.CreatedAt, &cursor.OrderId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return model.ReconciliationCursor{}, repository.ErrInternalError
+	}
+	return cursor, nil
+}
+
+func (r *reconciliationCursorRepositoryPostgres) Save(ctx context.Context, cursor model.ReconciliationCursor) error {
+	sql := `
+        INSERT INTO reconciliation_cursors (name, created_at, order_id, updated_at)
+        VALUES ($1, $2, $3, now())
+        ON CONFLICT (name) DO UPDATE
+            SET created_at = EXCLUDED.created_at,
+                order_id   = EXCLUDED.order_id,
+                updated_at = EXCLUDED.updated_at
+    `
+	if _, err := r.pool.Exec(ctx, sql, cursor.Name, cursor.CreatedAt, cursor.OrderId); err != nil {
+		return repository.ErrInternalError
+	}
+	return nil
+}
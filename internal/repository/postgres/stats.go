@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/model"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// statsRepositoryPostgres только читает, поэтому транзакции из контекста здесь не поддерживаются
+type statsRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatsRepositoryPostgres(pool *pgxpool.Pool) *statsRepositoryPostgres {
+	return &statsRepositoryPostgres{pool: pool}
+}
+
+// GetDeliveryStats агрегирует доставки, завершенные в [from, to), по типу транспорта.
+// courierId == 0 - по всем курьерам
+func (s *statsRepositoryPostgres) GetDeliveryStats(ctx context.Context, courierId int, from, to time.Time) ([]model.DeliveryStats, error) {
+	sql := `
+        SELECT transport_type,
+               COUNT(*) FILTER (WHERE status = 'completed'),
+               COUNT(*) FILTER (WHERE status = 'cancelled'),
+               COUNT(*) FILTER (WHERE status = 'expired'),
+               COUNT(*) FILTER (WHERE sla_state = 'breached'),
+               COUNT(*) FILTER (WHERE status = 'completed' AND finished_at <= deadline),
+               COALESCE(AVG(EXTRACT(EPOCH FROM finished_at - assigned_at)) FILTER (WHERE status = 'completed'), 0)::float8
+        FROM delivery_history
+        WHERE ($1 = 0 OR courier_id = $1)
+          AND finished_at >= $2 AND finished_at < $3
+        GROUP BY transport_type
+        ORDER BY transport_type
+    `
+
+	rows, err := s.pool.Query(ctx, sql, courierId, from, to)
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+	defer rows.Close()
+
+	stats := make([]model.DeliveryStats, 0)
+	for rows.Next() {
+		var st model.DeliveryStats
+		err = rows.Scan(
+			&st.TransportType,
+			&st.Completed,
+			&st.Cancelled,
+			&st.Expired,
+			&st.Breached,
+			&st.CompletedOnTime,
+			&st.AvgDurationSec,
+		)
+		if err != nil {
+			return nil, repository.ErrInternalError
+		}
+		stats = append(stats, st)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return stats, nil
+}
+
+// GetActiveSeconds считает, сколько секунд из окна [from, to) курьер был занят доставками.
+// Учитываются и завершенные доставки, и текущие (для них концом считается now)
+func (s *statsRepositoryPostgres) GetActiveSeconds(ctx context.Context, courierId int, from, to, now time.Time) (float64, error) {
+	sql := `
+        SELECT COALESCE(SUM(GREATEST(EXTRACT(EPOCH FROM LEAST(finished, $3) - GREATEST(assigned_at, $2)), 0)), 0)::float8
+        FROM (
+            SELECT assigned_at, finished_at AS finished
+            FROM delivery_history
+            WHERE courier_id = $1 AND assigned_at < $3 AND finished_at > $2
+            UNION ALL
+            SELECT assigned_at, $4::timestamp AS finished
+            FROM delivery
+            WHERE courier_id = $1 AND assigned_at < $3
+        ) AS busy
+    `
+
+	var seconds float64
+	err := s.pool.QueryRow(ctx, sql, courierId, from, to, now).Scan(&seconds)
+	if err != nil {
+		return 0, repository.ErrInternalError
+	}
+
+	return seconds, nil
+}
+
+// GetCourierCounts количество курьеров в разрезе статуса и типа транспорта (текущее состояние)
+func (s *statsRepositoryPostgres) GetCourierCounts(ctx context.Context) ([]model.CourierGroupCount, error) {
+	sql := `
+        SELECT status, transport_type, COUNT(*)
+        FROM couriers
+        GROUP BY status, transport_type
+        ORDER BY status, transport_type
+    `
+
+	rows, err := s.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	defer rows.Close()
+
+	counts := make([]model.CourierGroupCount, 0)
+	for rows.Next() {
+		var c model.CourierGroupCount
+		if err = rows.Scan(&c.Status, &c.TransportType, &c.Count); err != nil {
+			return nil, repository.ErrInternalError
+		}
+		counts = append(counts, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, repository.ErrInternalError
+	}
+
+	return counts, nil
+}
+
+// CountActiveDeliveries количество назначенных и еще не завершенных доставок
+func (s *statsRepositoryPostgres) CountActiveDeliveries(ctx context.Context) (int, error) {
+	sql := `SELECT COUNT(*) FROM delivery`
+
+	var count int
+	if err := s.pool.QueryRow(ctx, sql).Scan(&count); err != nil {
+		return 0, repository.ErrInternalError
+	}
+
+	return count, nil
+}
@@ -12,21 +12,27 @@ import (
 type courierService struct {
 	tm         dep.TransactionManager
 	repository dep.CourierRepository
+	phones     dep.PhoneNormalizer
 }
 
-func NewCourierService(tm dep.TransactionManager, repository dep.CourierRepository) *courierService {
-	return &courierService{tm: tm, repository: repository}
+func NewCourierService(tm dep.TransactionManager, repository dep.CourierRepository, phones dep.PhoneNormalizer) *courierService {
+	return &courierService{tm: tm, repository: repository, phones: phones}
 }
 
 func (cs *courierService) CreateCourier(ctx context.Context, req *dto.CreateCourierRequest) (*dto.CreateCourierResponse, error) {
+	var verr service.ValidationError
 	if !IsValidName(req.Name) {
-		return nil, service.ErrInvalidName
+		verr.Add("name", service.ErrInvalidName)
 	}
-	if !IsValidPhone(req.Phone) {
-		return nil, service.ErrInvalidPhone
+	phone, err := cs.normalizePhone(req.Phone)
+	if err != nil {
+		verr.Add("phone", err)
 	}
 	if !IsValidStatus(req.Status) {
-		return nil, service.ErrInvalidStatus
+		verr.Add("status", service.ErrInvalidStatus)
+	}
+	if err = verr.Err(); err != nil {
+		return nil, err
 	}
 	// выбрал вариант не возвращать ошибку, так как все равно есть дефолтное значение "on_foot"
 	// (хотя по такой логике, надо было и с полем status так же сделать)
@@ -36,7 +42,7 @@ func (cs *courierService) CreateCourier(ctx context.Context, req *dto.CreateCour
 
 	courierDB := model.Courier{
 		Name:          req.Name,
-		Phone:         req.Phone,
+		Phone:         phone,
 		Status:        req.Status,
 		TransportType: req.TransportType,
 	}
@@ -50,6 +56,10 @@ func (cs *courierService) CreateCourier(ctx context.Context, req *dto.CreateCour
 }
 
 func (cs *courierService) GetCourier(ctx context.Context, req *dto.GetCourierRequest) (*dto.GetCourierResponse, error) {
+	if !model.CanAccessCourier(ctx, req.Id) {
+		return nil, service.ErrForbidden
+	}
+
 	courierDb, err := cs.repository.GetById(ctx, req.Id)
 	if err != nil {
 		return nil, adapters.ErrUnwrapRepoToService(err)
@@ -61,6 +71,8 @@ func (cs *courierService) GetCourier(ctx context.Context, req *dto.GetCourierReq
 		Phone:         courierDb.Phone,
 		Status:        courierDb.Status,
 		TransportType: courierDb.TransportType,
+		Rating:        courierDb.RatingAvg,
+		RatingCount:   courierDb.RatingCount,
 	}
 	return &courier, nil
 }
@@ -88,17 +100,31 @@ func (cs *courierService) GetAllCouriers(ctx context.Context) ([]dto.GetCourierR
 }
 
 func (cs *courierService) UpdateCourier(ctx context.Context, req *dto.UpdateCourierRequest) error {
+	if !model.CanAccessCourier(ctx, req.Id) {
+		return service.ErrForbidden
+	}
+	if model.IsCourier(ctx) {
+		return cs.updateOwnStatus(ctx, req)
+	}
+	var verr service.ValidationError
 	if req.Name != "" && !IsValidName(req.Name) {
-		return service.ErrInvalidName
+		verr.Add("name", service.ErrInvalidName)
 	}
-	if req.Phone != "" && !IsValidPhone(req.Phone) {
-		return service.ErrInvalidPhone
+	if req.Phone != "" {
+		phone, err := cs.normalizePhone(req.Phone)
+		if err != nil {
+			verr.Add("phone", err)
+		}
+		req.Phone = phone
 	}
 	if req.Status != "" && !IsValidStatus(req.Status) {
-		return service.ErrInvalidStatus
+		verr.Add("status", service.ErrInvalidStatus)
 	}
 	if req.TransportType != "" && !IsValidTransportType(req.TransportType) {
-		return service.ErrInvalidTransportType
+		verr.Add("transport_type", service.ErrInvalidTransportType)
+	}
+	if err := verr.Err(); err != nil {
+		return err
 	}
 
 	courierDb := model.Courier{
@@ -115,6 +141,24 @@ func (cs *courierService) UpdateCourier(ctx context.Context, req *dto.UpdateCour
 	return nil
 }
 
+// updateOwnStatus курьер сам только выходит на линию и уходит на паузу. Имя, телефон и транспорт меняет диспетчер,
+// а busy ставит и снимает только доставка: иначе занятый курьер освободил бы себя и получил второй заказ
+func (cs *courierService) updateOwnStatus(ctx context.Context, req *dto.UpdateCourierRequest) error {
+	if req.Name != "" || req.Phone != "" || req.TransportType != "" || req.Status == model.StatusBusy {
+		return service.ErrForbidden
+	}
+	if req.Status != model.StatusAvailable && req.Status != model.StatusPaused {
+		var verr service.ValidationError
+		verr.Add("status", service.ErrInvalidStatus)
+		return verr.Err()
+	}
+
+	if err := cs.repository.UpdateStatusUnlessBusy(ctx, req.Id, req.Status); err != nil {
+		return adapters.ErrUnwrapRepoToService(err)
+	}
+	return nil
+}
+
 func (cs *courierService) DeleteCourier(ctx context.Context, req *dto.DeleteCourierRequest) error {
 	err := cs.repository.DeleteById(ctx, req.Id)
 	if err != nil {
@@ -9,9 +9,13 @@ import (
 	"service-order-avito/internal/domain/errors/service"
 	"service-order-avito/internal/domain/model"
 	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"service-order-avito/pkg/phone"
 	"testing"
 )
 
+// testPhones парсер телефонов с настройками по умолчанию (все поддерживаемые страны, Россия для номеров без кода)
+var testPhones, _ = phone.NewParser("RU")
+
 // TODO: можно объединить тесты с ошибками репозитория в 1 табличный
 func TestCourierService_CreateCourier_Success(t *testing.T) {
 	t.Parallel()
@@ -22,11 +26,11 @@ func TestCourierService_CreateCourier_Success(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.CreateCourierRequest{
 		Name:          "John",
-		Phone:         "+12345678901",
+		Phone:         "+79991234567",
 		Status:        model.StatusAvailable,
 		TransportType: "car",
 	}
@@ -58,11 +62,11 @@ func TestCourierService_CreateCourier_SuccessUnknownTransportType(t *testing.T)
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.CreateCourierRequest{
 		Name:          "John",
-		Phone:         "+12345678901",
+		Phone:         "+79991234567",
 		Status:        model.StatusAvailable,
 		TransportType: "balloon",
 	}
@@ -95,7 +99,7 @@ func TestCourierService_CreateCourier_ValidationErrors(t *testing.T) {
 			name: "invalid name",
 			req: dto.CreateCourierRequest{
 				Name:          "",
-				Phone:         "+12345678901",
+				Phone:         "+79991234567",
 				Status:        model.StatusAvailable,
 				TransportType: "car",
 			},
@@ -115,7 +119,7 @@ func TestCourierService_CreateCourier_ValidationErrors(t *testing.T) {
 			name: "invalid status",
 			req: dto.CreateCourierRequest{
 				Name:          "John",
-				Phone:         "+12345678901",
+				Phone:         "+79991234567",
 				Status:        "UNKNOWN",
 				TransportType: "car",
 			},
@@ -134,7 +138,7 @@ func TestCourierService_CreateCourier_ValidationErrors(t *testing.T) {
 			mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 			mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-			cs := NewCourierService(mockTM, mockRepo)
+			cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 			resp, err := cs.CreateCourier(context.Background(), &tt.req)
 
@@ -144,6 +148,87 @@ func TestCourierService_CreateCourier_ValidationErrors(t *testing.T) {
 	}
 }
 
+func TestCourierService_CreateCourier_AllFieldErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cs := NewCourierService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), testPhones)
+
+	_, err := cs.CreateCourier(context.Background(), &dto.CreateCourierRequest{
+		Name:   "",
+		Phone:  "bad phone",
+		Status: "UNKNOWN",
+	})
+
+	verr, ok := service.AsValidationError(err)
+	require.True(t, ok)
+	require.Equal(t, []service.FieldError{
+		{Field: "name", Err: service.ErrInvalidName},
+		{Field: "phone", Err: service.ErrInvalidPhone},
+		{Field: "status", Err: service.ErrInvalidStatus},
+	}, verr.Fields)
+	require.EqualError(t, err, "invalid name; invalid phone; invalid status")
+}
+
+func TestCourierService_CreateCourier_NormalizesPhone(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+	req := &dto.CreateCourierRequest{
+		Name:          "John",
+		Phone:         "8 (999) 123-45-67",
+		Status:        model.StatusAvailable,
+		TransportType: "car",
+	}
+
+	mockRepo.
+		EXPECT().
+		Create(gomock.Any(), model.Courier{
+			Name:          req.Name,
+			Phone:         "+79991234567",
+			Status:        req.Status,
+			TransportType: req.TransportType,
+		}).
+		Return(10, nil)
+
+	resp, err := cs.CreateCourier(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, 10, resp.Id)
+}
+
+func TestCourierService_CreateCourier_PhoneCountryNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	onlyRussia, err := phone.NewParser("RU", "RU")
+	require.NoError(t, err)
+
+	cs := NewCourierService(mockTM, mockRepo, onlyRussia)
+
+	req := &dto.CreateCourierRequest{
+		Name:          "John",
+		Phone:         "+995 555 12 34 56",
+		Status:        model.StatusAvailable,
+		TransportType: "car",
+	}
+
+	resp, err := cs.CreateCourier(context.Background(), req)
+	require.Nil(t, resp)
+	require.ErrorIs(t, err, service.ErrPhoneCountry)
+}
+
 func TestCourierService_CreateCourier_CourierExistsError(t *testing.T) {
 	t.Parallel()
 
@@ -153,11 +238,11 @@ func TestCourierService_CreateCourier_CourierExistsError(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.CreateCourierRequest{
 		Name:          "John",
-		Phone:         "+12345678901",
+		Phone:         "+79991234567",
 		Status:        model.StatusAvailable,
 		TransportType: "car",
 	}
@@ -180,11 +265,11 @@ func TestCourierService_CreateCourier_InternalError(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.CreateCourierRequest{
 		Name:          "John",
-		Phone:         "+12345678901",
+		Phone:         "+79991234567",
 		Status:        model.StatusAvailable,
 		TransportType: "car",
 	}
@@ -207,7 +292,7 @@ func TestCourierService_GetCourier_Success(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.GetCourierRequest{
 		Id: 1,
@@ -216,9 +301,11 @@ func TestCourierService_GetCourier_Success(t *testing.T) {
 	expectedResponse := &dto.GetCourierResponse{
 		Id:            1,
 		Name:          "John",
-		Phone:         "+12345678901",
+		Phone:         "+79991234567",
 		Status:        model.StatusAvailable,
 		TransportType: "car",
+		Rating:        4.25,
+		RatingCount:   8,
 	}
 
 	mockRepo.
@@ -230,6 +317,8 @@ func TestCourierService_GetCourier_Success(t *testing.T) {
 			Phone:         expectedResponse.Phone,
 			Status:        expectedResponse.Status,
 			TransportType: expectedResponse.TransportType,
+			RatingAvg:     expectedResponse.Rating,
+			RatingCount:   expectedResponse.RatingCount,
 		}, nil)
 
 	resp, err := cs.GetCourier(context.Background(), req)
@@ -240,6 +329,78 @@ func TestCourierService_GetCourier_Success(t *testing.T) {
 	require.Equal(t, expectedResponse.Phone, resp.Phone)
 	require.Equal(t, expectedResponse.Status, resp.Status)
 	require.Equal(t, expectedResponse.TransportType, resp.TransportType)
+	require.Equal(t, expectedResponse.Rating, resp.Rating)
+	require.Equal(t, expectedResponse.RatingCount, resp.RatingCount)
+}
+
+func TestCourierService_CourierAccess(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	cs := NewCourierService(mock_dep.NewMockTransactionManager(ctrl), mockRepo, testPhones)
+
+	ctx := model.WithPrincipal(context.Background(), model.Principal{Subject: "app", Role: model.RoleCourier, CourierId: 1})
+
+	// свой профиль курьер читает и меняет себе статус
+	mockRepo.EXPECT().GetById(gomock.Any(), 1).Return(model.Courier{Id: 1, Name: "John"}, nil)
+	_, err := cs.GetCourier(ctx, &dto.GetCourierRequest{Id: 1})
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().UpdateStatusUnlessBusy(gomock.Any(), 1, model.StatusPaused).Return(nil)
+	require.NoError(t, cs.UpdateCourier(ctx, &dto.UpdateCourierRequest{Id: 1, Status: model.StatusPaused}))
+
+	// чужой нет, до репозитория запрос не доходит
+	_, err = cs.GetCourier(ctx, &dto.GetCourierRequest{Id: 2})
+	require.ErrorIs(t, err, service.ErrForbidden)
+	require.ErrorIs(t, cs.UpdateCourier(ctx, &dto.UpdateCourierRequest{Id: 2, Status: model.StatusPaused}), service.ErrForbidden)
+}
+
+func TestCourierService_CourierUpdatesOwnStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := model.WithPrincipal(context.Background(), model.Principal{Subject: "app", Role: model.RoleCourier, CourierId: 1})
+
+	tests := []struct {
+		name    string
+		req     dto.UpdateCourierRequest
+		repoErr error
+		callsDB bool
+		wantErr error
+	}{
+		{name: "available", req: dto.UpdateCourierRequest{Id: 1, Status: model.StatusAvailable}, callsDB: true},
+		{name: "busy courier can't free himself", req: dto.UpdateCourierRequest{Id: 1, Status: model.StatusAvailable}, repoErr: repository.ErrCourierBusy, callsDB: true, wantErr: service.ErrCourierBusy},
+		{name: "busy", req: dto.UpdateCourierRequest{Id: 1, Status: model.StatusBusy}, wantErr: service.ErrForbidden},
+		{name: "name", req: dto.UpdateCourierRequest{Id: 1, Name: "Johnny"}, wantErr: service.ErrForbidden},
+		{name: "phone with status", req: dto.UpdateCourierRequest{Id: 1, Phone: "+79991234567", Status: model.StatusPaused}, wantErr: service.ErrForbidden},
+		{name: "transport", req: dto.UpdateCourierRequest{Id: 1, TransportType: "car"}, wantErr: service.ErrForbidden},
+		{name: "empty status", req: dto.UpdateCourierRequest{Id: 1}, wantErr: service.ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+			cs := NewCourierService(mock_dep.NewMockTransactionManager(ctrl), mockRepo, testPhones)
+
+			if tt.callsDB {
+				mockRepo.EXPECT().UpdateStatusUnlessBusy(gomock.Any(), 1, tt.req.Status).Return(tt.repoErr)
+			}
+
+			err := cs.UpdateCourier(ctx, &tt.req)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
 }
 
 func TestCourierService_GetCourier_CourierNotFoundError(t *testing.T) {
@@ -251,7 +412,7 @@ func TestCourierService_GetCourier_CourierNotFoundError(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.GetCourierRequest{
 		Id: 1,
@@ -276,7 +437,7 @@ func TestCourierService_GetCourier_InternalError(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.GetCourierRequest{
 		Id: 1,
@@ -301,13 +462,13 @@ func TestCourierService_GetAllCouriers_Success(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	expectedResponse := []dto.GetCourierResponse{
 		{
 			Id:            1,
 			Name:          "John",
-			Phone:         "+12345678901",
+			Phone:         "+79991234567",
 			Status:        model.StatusAvailable,
 			TransportType: "car",
 		},
@@ -324,7 +485,7 @@ func TestCourierService_GetAllCouriers_Success(t *testing.T) {
 		{
 			Id:            1,
 			Name:          "John",
-			Phone:         "+12345678901",
+			Phone:         "+79991234567",
 			Status:        model.StatusAvailable,
 			TransportType: "car",
 		},
@@ -363,7 +524,7 @@ func TestCourierService_GetAllCouriers_InternalError(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	mockRepo.
 		EXPECT().
@@ -384,12 +545,12 @@ func TestCourierService_UpdateCourier_Success(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.UpdateCourierRequest{
 		Id:            1,
 		Name:          "John",
-		Phone:         "+12345678901",
+		Phone:         "+79991234567",
 		Status:        model.StatusAvailable,
 		TransportType: "car",
 	}
@@ -421,7 +582,7 @@ func TestCourierService_UpdateCourier_ValidationErrors(t *testing.T) {
 			name: "invalid name",
 			req: dto.UpdateCourierRequest{
 				Name:          "s1mple",
-				Phone:         "+12345678901",
+				Phone:         "+79991234567",
 				Status:        model.StatusAvailable,
 				TransportType: "car",
 			},
@@ -441,7 +602,7 @@ func TestCourierService_UpdateCourier_ValidationErrors(t *testing.T) {
 			name: "invalid status",
 			req: dto.UpdateCourierRequest{
 				Name:          "John",
-				Phone:         "+12345678901",
+				Phone:         "+79991234567",
 				Status:        "UNKNOWN",
 				TransportType: "car",
 			},
@@ -451,7 +612,7 @@ func TestCourierService_UpdateCourier_ValidationErrors(t *testing.T) {
 			name: "invalid transport type",
 			req: dto.UpdateCourierRequest{
 				Name:          "John",
-				Phone:         "+12345678901",
+				Phone:         "+79991234567",
 				Status:        model.StatusAvailable,
 				TransportType: "batmobile",
 			},
@@ -470,7 +631,7 @@ func TestCourierService_UpdateCourier_ValidationErrors(t *testing.T) {
 			mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 			mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-			cs := NewCourierService(mockTM, mockRepo)
+			cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 			err := cs.UpdateCourier(context.Background(), &tt.req)
 
@@ -491,7 +652,7 @@ func TestCourierService_UpdateCourier_RepositoryErrors(t *testing.T) {
 			req: &dto.UpdateCourierRequest{
 				Id:            1,
 				Name:          "John",
-				Phone:         "+12345678901",
+				Phone:         "+79991234567",
 				Status:        model.StatusAvailable,
 				TransportType: "car",
 			},
@@ -503,7 +664,7 @@ func TestCourierService_UpdateCourier_RepositoryErrors(t *testing.T) {
 			req: &dto.UpdateCourierRequest{
 				Id:            1,
 				Name:          "John",
-				Phone:         "+12345678901",
+				Phone:         "+79991234567",
 				Status:        model.StatusAvailable,
 				TransportType: "car",
 			},
@@ -520,7 +681,7 @@ func TestCourierService_UpdateCourier_RepositoryErrors(t *testing.T) {
 			mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 			mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-			cs := NewCourierService(mockTM, mockRepo)
+			cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 			expectedCourier := model.Courier{
 				Id:            tt.req.Id,
@@ -551,7 +712,7 @@ func TestCourierService_DeleteCourier_Success(t *testing.T) {
 	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-	cs := NewCourierService(mockTM, mockRepo)
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 	req := &dto.DeleteCourierRequest{
 		Id: 1,
@@ -599,7 +760,7 @@ func TestCourierService_DeleteCourier_RepositoryErrors(t *testing.T) {
 			mockRepo := mock_dep.NewMockCourierRepository(ctrl)
 			mockTM := mock_dep.NewMockTransactionManager(ctrl)
 
-			cs := NewCourierService(mockTM, mockRepo)
+			cs := NewCourierService(mockTM, mockRepo, testPhones)
 
 			mockRepo.
 				EXPECT().
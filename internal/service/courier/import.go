@@ -0,0 +1,159 @@
+package courier
+
+import (
+	"context"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+)
+
+// importBatchSize количество строк в одном COPY. Большие файлы режутся на пачки,
+// чтобы не держать в памяти драйвера один огромный буфер
+const importBatchSize = 500
+
+// ImportCouriers проверяет каждую строку импорта и вставляет валидные пачками в одной транзакции.
+// Невалидные строки не прерывают импорт, а попадают в отчет с описанием ошибки.
+// В режиме dry-run выполняются все проверки (в том числе на занятые телефоны), но ничего не вставляется
+func (cs *courierService) ImportCouriers(ctx context.Context, req *dto.ImportCouriersRequest) (*dto.ImportCouriersResponse, error) {
+	res := &dto.ImportCouriersResponse{
+		Total:  len(req.Couriers),
+		DryRun: req.DryRun,
+		Rows:   make([]dto.ImportCourierRowResult, len(req.Couriers)),
+	}
+
+	// rowByPhone индекс строки отчета по телефону. Заодно ловит дубли внутри самого файла
+	rowByPhone := make(map[string]int, len(req.Couriers))
+	for i, row := range req.Couriers {
+		res.Rows[i] = dto.ImportCourierRowResult{Row: i + 1, Phone: row.Phone}
+
+		// телефон нормализуется, поэтому дубли ловятся даже при разной записи одного номера
+		err := cs.validateImportRow(&row)
+		if err == nil {
+			res.Rows[i].Phone = row.Phone
+			if _, dup := rowByPhone[row.Phone]; dup {
+				err = service.ErrCourierExists
+			}
+		}
+		if err != nil {
+			res.Rows[i].Status = dto.ImportRowStatusError
+			res.Rows[i].Error = err.Error()
+			continue
+		}
+
+		req.Couriers[i] = row
+		rowByPhone[row.Phone] = i
+	}
+
+	if len(rowByPhone) > 0 {
+		phones := make([]string, 0, len(rowByPhone))
+		for phone := range rowByPhone {
+			phones = append(phones, phone)
+		}
+
+		existing, err := cs.repository.GetExistingPhones(ctx, phones)
+		if err != nil {
+			return nil, adapters.ErrUnwrapRepoToService(err)
+		}
+		for _, phone := range existing {
+			i := rowByPhone[phone]
+			res.Rows[i].Status = dto.ImportRowStatusError
+			res.Rows[i].Error = service.ErrCourierExists.Error()
+			delete(rowByPhone, phone)
+		}
+	}
+
+	// сохраняем порядок строк файла, map для этого не подходит
+	couriers := make([]model.Courier, 0, len(rowByPhone))
+	for i, row := range req.Couriers {
+		if res.Rows[i].Status == dto.ImportRowStatusError {
+			continue
+		}
+		couriers = append(couriers, model.Courier{
+			Name:          row.Name,
+			Phone:         row.Phone,
+			Status:        row.Status,
+			TransportType: row.TransportType,
+		})
+	}
+
+	if !req.DryRun && len(couriers) > 0 {
+		err := cs.tm.Begin(ctx, func(ctx context.Context) error {
+			for start := 0; start < len(couriers); start += importBatchSize {
+				end := min(start+importBatchSize, len(couriers))
+				if _, err := cs.repository.CreateMany(ctx, couriers[start:end]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, adapters.ErrUnwrapRepoToService(err)
+		}
+	}
+
+	okStatus := dto.ImportRowStatusCreated
+	if req.DryRun {
+		okStatus = dto.ImportRowStatusValid
+	}
+	for i := range res.Rows {
+		if res.Rows[i].Status == dto.ImportRowStatusError {
+			res.Failed++
+			continue
+		}
+		res.Rows[i].Status = okStatus
+		if !req.DryRun {
+			res.Created++
+		}
+	}
+
+	return res, nil
+}
+
+// validateImportRow проверяет строку импорта и проставляет значения по умолчанию.
+// В отличие от CreateCourier, неизвестный тип транспорта здесь считается ошибкой:
+// при массовой загрузке молча подменять данные хуже, чем показать ошибку в отчете
+func (cs *courierService) validateImportRow(row *dto.CreateCourierRequest) error {
+	if row.Status == "" {
+		row.Status = model.StatusAvailable
+	}
+	if row.TransportType == "" {
+		row.TransportType = model.TransportTypeFoot
+	}
+
+	var verr service.ValidationError
+	if !IsValidName(row.Name) {
+		verr.Add("name", service.ErrInvalidName)
+	}
+	phone, err := cs.normalizePhone(row.Phone)
+	if err != nil {
+		verr.Add("phone", err)
+	}
+	row.Phone = phone
+	if !IsValidStatus(row.Status) {
+		verr.Add("status", service.ErrInvalidStatus)
+	}
+	if !IsValidTransportType(row.TransportType) {
+		verr.Add("transport_type", service.ErrInvalidTransportType)
+	}
+	return verr.Err()
+}
+
+// ExportCouriers построчно передает всех курьеров в fn
+func (cs *courierService) ExportCouriers(ctx context.Context, fn func(dto.ExportCourier) error) error {
+	err := cs.repository.StreamAll(ctx, func(courier model.Courier) error {
+		return fn(dto.ExportCourier{
+			Id:              courier.Id,
+			Name:            courier.Name,
+			Phone:           courier.Phone,
+			Status:          courier.Status,
+			TransportType:   courier.TransportType,
+			TotalDeliveries: courier.TotalDeliveries,
+			CreatedAt:       courier.CreatedAt,
+		})
+	})
+	if err != nil {
+		return adapters.ErrUnwrapRepoToService(err)
+	}
+	return nil
+}
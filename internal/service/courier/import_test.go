@@ -0,0 +1,263 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"testing"
+	"time"
+)
+
+func TestCourierService_ImportCouriers_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+	req := &dto.ImportCouriersRequest{
+		Couriers: []dto.CreateCourierRequest{
+			{Name: "John", Phone: "+79990000001", Status: model.StatusAvailable, TransportType: model.TransportTypeCar},
+			{Name: "Ivan", Phone: "+79990000002"},
+		},
+	}
+
+	mockRepo.EXPECT().GetExistingPhones(gomock.Any(), gomock.Any()).Return([]string{}, nil)
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
+	)
+	mockRepo.EXPECT().CreateMany(gomock.Any(), []model.Courier{
+		{Name: "John", Phone: "+79990000001", Status: model.StatusAvailable, TransportType: model.TransportTypeCar},
+		{Name: "Ivan", Phone: "+79990000002", Status: model.StatusAvailable, TransportType: model.TransportTypeFoot},
+	}).Return(2, nil)
+
+	res, err := cs.ImportCouriers(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, res.Total)
+	require.Equal(t, 2, res.Created)
+	require.Equal(t, 0, res.Failed)
+	for _, row := range res.Rows {
+		require.Equal(t, dto.ImportRowStatusCreated, row.Status)
+	}
+}
+
+func TestCourierService_ImportCouriers_RowErrors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+	req := &dto.ImportCouriersRequest{
+		Couriers: []dto.CreateCourierRequest{
+			{Name: "John", Phone: "+79990000001"},
+			{Name: "s1mple", Phone: "+79990000002"},
+			{Name: "Ivan", Phone: "bad phone"},
+			{Name: "Petr", Phone: "+79990000003", TransportType: "batmobile"},
+			{Name: "Johnny", Phone: "8 (999) 000-00-01"}, // дубль внутри файла в другой записи
+			{Name: "Oleg", Phone: "+79990000004"},        // уже есть в базе
+		},
+	}
+
+	mockRepo.EXPECT().GetExistingPhones(gomock.Any(), gomock.Any()).Return([]string{"+79990000004"}, nil)
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
+	)
+	mockRepo.EXPECT().CreateMany(gomock.Any(), []model.Courier{
+		{Name: "John", Phone: "+79990000001", Status: model.StatusAvailable, TransportType: model.TransportTypeFoot},
+	}).Return(1, nil)
+
+	res, err := cs.ImportCouriers(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Equal(t, 6, res.Total)
+	require.Equal(t, 1, res.Created)
+	require.Equal(t, 5, res.Failed)
+
+	require.Equal(t, dto.ImportRowStatusCreated, res.Rows[0].Status)
+	require.Equal(t, service.ErrInvalidName.Error(), res.Rows[1].Error)
+	require.Equal(t, service.ErrInvalidPhone.Error(), res.Rows[2].Error)
+	require.Equal(t, service.ErrInvalidTransportType.Error(), res.Rows[3].Error)
+	require.Equal(t, service.ErrCourierExists.Error(), res.Rows[4].Error)
+	require.Equal(t, service.ErrCourierExists.Error(), res.Rows[5].Error)
+	require.Equal(t, 6, res.Rows[5].Row)
+}
+
+func TestCourierService_ImportCouriers_DryRun(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+	req := &dto.ImportCouriersRequest{
+		Couriers: []dto.CreateCourierRequest{
+			{Name: "John", Phone: "+79990000001"},
+			{Name: "", Phone: "+79990000002"},
+		},
+		DryRun: true,
+	}
+
+	// ни транзакции, ни вставки быть не должно
+	mockRepo.EXPECT().GetExistingPhones(gomock.Any(), []string{"+79990000001"}).Return(nil, nil)
+
+	res, err := cs.ImportCouriers(context.Background(), req)
+	require.NoError(t, err)
+
+	require.True(t, res.DryRun)
+	require.Equal(t, 0, res.Created)
+	require.Equal(t, 1, res.Failed)
+	require.Equal(t, dto.ImportRowStatusValid, res.Rows[0].Status)
+	require.Equal(t, dto.ImportRowStatusError, res.Rows[1].Status)
+}
+
+func TestCourierService_ImportCouriers_RepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		existingErr error
+		createErr   error
+		expectedErr error
+	}{
+		{
+			name:        "get existing phones fails",
+			existingErr: repository.ErrInternalError,
+			expectedErr: service.ErrInternalError,
+		},
+		{
+			name:        "copy conflicts with concurrent insert",
+			createErr:   repository.ErrCourierExists,
+			expectedErr: service.ErrCourierExists,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+			mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+			cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+			req := &dto.ImportCouriersRequest{
+				Couriers: []dto.CreateCourierRequest{{Name: "John", Phone: "+79990000001"}},
+			}
+
+			mockRepo.EXPECT().GetExistingPhones(gomock.Any(), gomock.Any()).Return(nil, tt.existingErr)
+			if tt.existingErr == nil {
+				mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
+				)
+				mockRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Return(0, tt.createErr)
+			}
+
+			res, err := cs.ImportCouriers(context.Background(), req)
+			require.Nil(t, res)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestCourierService_ImportCouriers_Batches(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+	total := importBatchSize*2 + 1
+	req := &dto.ImportCouriersRequest{Couriers: make([]dto.CreateCourierRequest, total)}
+	for i := range req.Couriers {
+		req.Couriers[i] = dto.CreateCourierRequest{Name: "John", Phone: fmt.Sprintf("+7999%07d", i)}
+	}
+
+	mockRepo.EXPECT().GetExistingPhones(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
+	)
+	var batches []int
+	mockRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Times(3).DoAndReturn(
+		func(ctx context.Context, couriers []model.Courier) (int, error) {
+			batches = append(batches, len(couriers))
+			return len(couriers), nil
+		},
+	)
+
+	res, err := cs.ImportCouriers(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, total, res.Created)
+	require.Equal(t, []int{importBatchSize, importBatchSize, 1}, batches)
+}
+
+func TestCourierService_ExportCouriers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+	createdAt := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.EXPECT().StreamAll(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(model.Courier) error) error {
+			return fn(model.Courier{Id: 1, Name: "John", Phone: "+79990000001", Status: model.StatusBusy,
+				TransportType: model.TransportTypeCar, TotalDeliveries: 3, CreatedAt: createdAt})
+		},
+	)
+
+	var exported []dto.ExportCourier
+	err := cs.ExportCouriers(context.Background(), func(c dto.ExportCourier) error {
+		exported = append(exported, c)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []dto.ExportCourier{{Id: 1, Name: "John", Phone: "+79990000001", Status: model.StatusBusy,
+		TransportType: model.TransportTypeCar, TotalDeliveries: 3, CreatedAt: createdAt}}, exported)
+}
+
+func TestCourierService_ExportCouriers_InternalError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+
+	cs := NewCourierService(mockTM, mockRepo, testPhones)
+
+	mockRepo.EXPECT().StreamAll(gomock.Any(), gomock.Any()).Return(repository.ErrInternalError)
+
+	err := cs.ExportCouriers(context.Background(), func(dto.ExportCourier) error { return nil })
+	require.ErrorIs(t, err, service.ErrInternalError)
+}
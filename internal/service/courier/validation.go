@@ -1,9 +1,11 @@
 package courier
 
 import (
+	"errors"
 	"regexp"
+	"service-order-avito/internal/domain/errors/service"
 	"service-order-avito/internal/domain/model"
-	"unicode"
+	"service-order-avito/pkg/phone"
 )
 
 var validName = regexp.MustCompile(`^[A-Za-zА-Яа-яЁё]+$`)
@@ -12,20 +14,17 @@ func IsValidName(name string) bool {
 	return validName.MatchString(name)
 }
 
-func IsValidPhone(phone string) bool {
-	const correctPhoneNumberLen = 12
-	if len(phone) != correctPhoneNumberLen {
-		return false // длина должна быть ровно 12 символов
-	}
-	if phone[0] != '+' {
-		return false // первый символ должен быть '+'
-	}
-	for _, r := range phone[1:] {
-		if !unicode.IsDigit(r) {
-			return false
+// normalizePhone приводит телефон к E.164. Храним в базе только нормализованный номер,
+// поэтому уникальность couriers.phone работает для любых вариантов записи одного и того же номера
+func (cs *courierService) normalizePhone(raw string) (string, error) {
+	normalized, err := cs.phones.Normalize(raw)
+	if err != nil {
+		if errors.Is(err, phone.ErrCountryNotAllowed) {
+			return "", service.ErrPhoneCountry
 		}
+		return "", service.ErrInvalidPhone
 	}
-	return true
+	return normalized, nil
 }
 
 func IsValidStatus(status string) bool {
@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"context"
+	"errors"
 	"service-order-avito/internal/adapters"
 	"service-order-avito/internal/domain/dto"
 	"service-order-avito/internal/domain/errors/service"
@@ -15,16 +16,44 @@ type deliveryService struct {
 	delRepo     dep.DeliveryRepository
 	courRepo    dep.CourierRepository
 	delTimeCalc dep.DeliveryTimeCalculator
+	events      dep.EventPublisher
+	metrics     dep.DeliveryMetrics
+	rating      model.RatingPolicy // по нему курьеры с низким рейтингом получают заказы в последнюю очередь
+	sla         model.SLAPolicy
+	proof       model.ProofPolicy
+	handoff     model.HandoffPolicy
 }
 
-func NewDeliveryService(tm dep.TransactionManager, courRepo dep.CourierRepository, delRepo dep.DeliveryRepository) *deliveryService {
-	return &deliveryService{tm: tm, delRepo: delRepo, courRepo: courRepo, delTimeCalc: NewDeliveryTimeFactory()}
+func NewDeliveryService(tm dep.TransactionManager,
+	courRepo dep.CourierRepository,
+	delRepo dep.DeliveryRepository,
+	events dep.EventPublisher,
+	metrics dep.DeliveryMetrics,
+	rating model.RatingPolicy,
+	sla model.SLAPolicy,
+	proof model.ProofPolicy,
+	handoff model.HandoffPolicy,
+) *deliveryService {
+	return &deliveryService{
+		tm:          tm,
+		delRepo:     delRepo,
+		courRepo:    courRepo,
+		delTimeCalc: NewDeliveryTimeFactory(),
+		events:      events,
+		metrics:     metrics,
+		rating:      rating,
+		sla:         sla,
+		proof:       proof,
+		handoff:     handoff,
+	}
 }
 
 func (ds *deliveryService) Assign(ctx context.Context, req *dto.AssignDeliveryRequest) (*dto.AssignDeliveryResponse, error) {
+	start := time.Now()
 	var res *dto.AssignDeliveryResponse
+	var assignedAt time.Time
 	err := ds.tm.Begin(ctx, func(ctx context.Context) error {
-		courier, err := ds.courRepo.GetAvailable(ctx)
+		courier, err := ds.courRepo.GetAvailable(ctx, ds.rating)
 		if err != nil {
 			return err
 		}
@@ -33,9 +62,25 @@ func (ds *deliveryService) Assign(ctx context.Context, req *dto.AssignDeliveryRe
 			OrderId:    req.OrderId,
 			AssignedAt: time.Now(),
 			Deadline:   ds.delTimeCalc.Calculate(courier.TransportType),
+			// политика фиксируется при назначении, смена настройки не влияет на доставки в работе
+			ProofRequired: req.ProofRequired || ds.proof.RequiredForAll,
+		}
+
+		var code string
+		if ds.handoff.Required(req.TotalPrice) {
+			code, err = newHandoffCode()
+			if err != nil {
+				return service.ErrInternalError
+			}
+			delivery.HandoffCodeHash = hashHandoffCode(ds.handoff.Secret, req.OrderId, code)
 		}
 
-		_, err = ds.delRepo.Create(ctx, delivery)
+		deliveryId, err := ds.delRepo.Create(ctx, delivery)
+		if err != nil {
+			return err
+		}
+
+		err = ds.delRepo.AddEventManyById(ctx, model.StatusAssigned, delivery.AssignedAt, deliveryId)
 		if err != nil {
 			return err
 		}
@@ -51,16 +96,32 @@ func (ds *deliveryService) Assign(ctx context.Context, req *dto.AssignDeliveryRe
 			return service.ErrInternalError
 		}
 
+		assignedAt = delivery.AssignedAt
 		res = &dto.AssignDeliveryResponse{
 			CourierId:        courier.Id,
 			OrderId:          req.OrderId,
 			TransportType:    courier.TransportType,
 			DeliveryDeadline: delivery.Deadline,
+			HandoffCode:      code,
 		}
 		return nil
 	})
 	if err != nil {
-		return nil, adapters.ErrUnwrapRepoToService(err)
+		err = adapters.ErrUnwrapRepoToService(err)
+		if errors.Is(err, service.ErrNoAvailableCouriers) {
+			ds.metrics.IncNoAvailableCouriers()
+		}
+		return nil, err
+	}
+
+	ds.metrics.IncDeliveriesCreated()
+	ds.metrics.ObserveAssignment(time.Since(start))
+	if !req.OrderCreatedAt.IsZero() {
+		ds.metrics.ObserveTimeToAssign(assignedAt.Sub(req.OrderCreatedAt))
+	}
+
+	if res.HandoffCode != "" {
+		ds.publishHandoffCode(ctx, res)
 	}
 	return res, nil
 }
@@ -73,6 +134,16 @@ func (ds *deliveryService) Unassign(ctx context.Context, req *dto.UnassignDelive
 			return err
 		}
 
+		err = ds.delRepo.AddEventManyById(ctx, model.StatusUnassigned, time.Now(), delivery.Id)
+		if err != nil {
+			return err
+		}
+
+		err = ds.delRepo.ArchiveManyById(ctx, model.StatusCancelled, delivery.Id)
+		if err != nil {
+			return err
+		}
+
 		err = ds.delRepo.DeleteByOrderId(ctx, req.OrderId)
 		if err != nil {
 			return err
@@ -98,15 +169,16 @@ func (ds *deliveryService) Unassign(ctx context.Context, req *dto.UnassignDelive
 	if err != nil {
 		return nil, adapters.ErrUnwrapRepoToService(err)
 	}
+	ds.metrics.AddDeliveriesFinished(model.StatusCancelled, 1)
 	return res, nil
 }
 
-// UnassignAllCompleted завершает все заказы, дедлайн которых прошел, меняет статус ответственных курьеров на 'available'
-// возвращает количество завершенных заказов.
+// UnassignAllCompleted завершает все заказы, дедлайн которых прошел больше чем UnassignGrace назад,
+// меняет статус ответственных курьеров на 'available'. Возвращает количество завершенных заказов.
 func (ds *deliveryService) UnassignAllCompleted(ctx context.Context) (int, error) {
 	var totalUnassigned int
 	err := ds.tm.Begin(ctx, func(ctx context.Context) error {
-		completedDeliveries, err := ds.delRepo.GetAllCompleted(ctx)
+		completedDeliveries, err := ds.delRepo.GetAllCompleted(ctx, time.Now().Add(-ds.sla.UnassignGrace))
 		if err != nil {
 			return err
 		}
@@ -123,6 +195,16 @@ func (ds *deliveryService) UnassignAllCompleted(ctx context.Context) (int, error
 			deliveriesIds[i] = d.Id
 		}
 
+		err = ds.delRepo.AddEventManyById(ctx, model.StatusExpired, time.Now(), deliveriesIds...)
+		if err != nil {
+			return err
+		}
+
+		err = ds.delRepo.ArchiveManyById(ctx, model.StatusExpired, deliveriesIds...)
+		if err != nil {
+			return err
+		}
+
 		err = ds.delRepo.DeleteManyById(ctx, deliveriesIds...)
 		if err != nil {
 			return err
@@ -137,10 +219,17 @@ func (ds *deliveryService) UnassignAllCompleted(ctx context.Context) (int, error
 	if err != nil {
 		return 0, adapters.ErrUnwrapRepoToService(err)
 	}
+	if totalUnassigned > 0 {
+		ds.metrics.AddDeliveriesFinished(model.StatusExpired, totalUnassigned)
+	}
 	return totalUnassigned, nil
 }
 
-// Complete завершает заказ. Освобождает курьера, но не удаляет заказ из таблицы delivery
+// Complete завершает заказ. Освобождает курьера и переносит доставку в delivery_history.
+// Вызывается только по order.changed от order-service: выполнение заказа он уже подтвердил, а кода передачи
+// у него нет, поэтому код здесь не проверяется. Курьер завершает доставку событием delivered, там код нужен.
+// Раньше завершенная доставка оставалась в delivery до дедлайна, из-за чего монитор потом считал ее просроченной
+// и еще раз освобождал курьера, который к тому моменту мог уже везти другой заказ
 func (ds *deliveryService) Complete(ctx context.Context, req *dto.CompleteDeliveryRequest) (*dto.CompleteDeliveryResponse, error) {
 	var res *dto.CompleteDeliveryResponse
 	err := ds.tm.Begin(ctx, func(ctx context.Context) error {
@@ -149,12 +238,7 @@ func (ds *deliveryService) Complete(ctx context.Context, req *dto.CompleteDelive
 			return err
 		}
 
-		courier := model.Courier{
-			Id:     delivery.CourierId,
-			Status: model.StatusAvailable,
-		}
-
-		err = ds.courRepo.Update(ctx, courier)
+		err = ds.complete(ctx, delivery, time.Now())
 		if err != nil {
 			return err
 		}
@@ -162,12 +246,111 @@ func (ds *deliveryService) Complete(ctx context.Context, req *dto.CompleteDelive
 		res = &dto.CompleteDeliveryResponse{
 			OrderId:   req.OrderId,
 			Status:    model.StatusCompleted,
-			CourierId: courier.Id,
+			CourierId: delivery.CourierId,
 		}
 		return nil
 	})
+	if errors.Is(err, service.ErrProofRequired) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, adapters.ErrUnwrapRepoToService(err)
 	}
+	ds.metrics.AddDeliveriesFinished(model.StatusCompleted, 1)
 	return res, nil
 }
+
+// complete общая часть завершения доставки для Complete и события delivered. Вызывается внутри транзакции.
+// Доставку с proof_required без загруженного подтверждения завершить нельзя
+func (ds *deliveryService) complete(ctx context.Context, delivery model.Delivery, at time.Time) error {
+	if delivery.ProofRequired && !delivery.ProofAttached {
+		return service.ErrProofRequired
+	}
+
+	err := ds.delRepo.AddEventManyById(ctx, model.StatusDelivered, at, delivery.Id)
+	if err != nil {
+		return err
+	}
+
+	err = ds.delRepo.ArchiveManyById(ctx, model.StatusCompleted, delivery.Id)
+	if err != nil {
+		return err
+	}
+
+	err = ds.delRepo.DeleteByOrderId(ctx, delivery.OrderId)
+	if err != nil {
+		return err
+	}
+
+	return ds.courRepo.Update(ctx, model.Courier{
+		Id:     delivery.CourierId,
+		Status: model.StatusAvailable,
+	})
+}
+
+// GetOrderStates состояние доставки заказов для сверки с order-service: model.DeliveryActive для доставки в работе
+// или статус из истории. Заказов, которые никогда не назначались, в результате нет
+func (ds *deliveryService) GetOrderStates(ctx context.Context, orderIds []string) (map[string]string, error) {
+	if len(orderIds) == 0 {
+		return map[string]string{}, nil
+	}
+	states, err := ds.delRepo.GetStatesByOrderIds(ctx, orderIds)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+	return states, nil
+}
+
+// GetActiveOrderIds страница заказов доставок в работе после заказа after для сверки с order-service
+func (ds *deliveryService) GetActiveOrderIds(ctx context.Context, after string, limit int) ([]string, error) {
+	orderIds, err := ds.delRepo.GetActiveOrderIds(ctx, after, limit)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+	return orderIds, nil
+}
+
+// TrackSLA помечает доставки, которые скоро не уложатся в срок (at_risk) или уже не уложились (breached),
+// и публикует по событию на каждый переход. Каждое UPDATE атомарно, поэтому переход попадает в отчет один раз,
+// даже если монитор запущен в нескольких экземплярах сервиса
+func (ds *deliveryService) TrackSLA(ctx context.Context) (*model.SLAReport, error) {
+	now := time.Now()
+
+	atRisk, err := ds.delRepo.MarkAtRisk(ctx, ds.sla.AtRiskFraction, now)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	breached, err := ds.delRepo.MarkBreached(ctx, now)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	active, err := ds.delRepo.CountBySLAState(ctx)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	ds.publishSLA(ctx, model.EventDeliverySLAAtRisk, atRisk, now)
+	ds.publishSLA(ctx, model.EventDeliverySLABreached, breached, now)
+
+	return &model.SLAReport{AtRisk: atRisk, Breached: breached, Active: active}, nil
+}
+
+// publishSLA ошибки публикации логирует сам publisher, монитор из-за них не останавливается
+func (ds *deliveryService) publishSLA(ctx context.Context, eventType string, deliveries []model.Delivery, now time.Time) {
+	for _, d := range deliveries {
+		_ = ds.events.Publish(ctx, model.Event{
+			Type: eventType,
+			Key:  d.OrderId,
+			Payload: dto.DeliverySLAEvent{
+				OrderId:    d.OrderId,
+				CourierId:  d.CourierId,
+				SLAState:   d.SLAState,
+				AssignedAt: d.AssignedAt,
+				Deadline:   d.Deadline,
+			},
+			OccurredAt: now,
+		})
+	}
+}
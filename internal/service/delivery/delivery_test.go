@@ -13,6 +13,34 @@ import (
 	"time"
 )
 
+var (
+	testRating  = model.RatingPolicy{LowThreshold: 3.5, MinCount: 5, AlertRating: 2}
+	testSLA     = model.SLAPolicy{AtRiskFraction: 0.8, UnassignGrace: time.Minute}
+	testProof   = model.ProofPolicy{MaxPhotoSize: 1 << 20, MaxSignatureSize: 1 << 10}
+	testHandoff = model.HandoffPolicy{PriceThreshold: 10000, MaxAttempts: 3, Lockout: 15 * time.Minute, Secret: []byte("secret")}
+)
+
+type stubMetrics struct {
+	created             int
+	finished            map[string]int
+	assignments         int
+	timeToAssign        []time.Duration
+	noAvailableCouriers int
+}
+
+func (m *stubMetrics) IncDeliveriesCreated() { m.created++ }
+func (m *stubMetrics) AddDeliveriesFinished(status string, n int) {
+	if m.finished == nil {
+		m.finished = map[string]int{}
+	}
+	m.finished[status] += n
+}
+func (m *stubMetrics) ObserveAssignment(time.Duration) { m.assignments++ }
+func (m *stubMetrics) ObserveTimeToAssign(d time.Duration) {
+	m.timeToAssign = append(m.timeToAssign, d)
+}
+func (m *stubMetrics) IncNoAvailableCouriers() { m.noAvailableCouriers++ }
+
 func TestDeliveryService_AssignDelivery_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -21,11 +49,13 @@ func TestDeliveryService_AssignDelivery_Success(t *testing.T) {
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	metrics := &stubMetrics{}
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), metrics, testRating, testSLA, testProof, testHandoff)
 
 	ctx := context.Background()
 	req := &dto.AssignDeliveryRequest{
-		OrderId: "ORDER-123",
+		OrderId:        "ORDER-123",
+		OrderCreatedAt: time.Now().Add(-30 * time.Second),
 	}
 
 	courier := model.Courier{
@@ -43,7 +73,7 @@ func TestDeliveryService_AssignDelivery_Success(t *testing.T) {
 		},
 	)
 
-	mockCourierRepo.EXPECT().GetAvailable(gomock.Any()).Return(courier, nil)
+	mockCourierRepo.EXPECT().GetAvailable(gomock.Any(), testRating).Return(courier, nil)
 
 	mockDeliveryRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
 		func(ctx context.Context, delivery model.Delivery) (int, error) {
@@ -54,6 +84,7 @@ func TestDeliveryService_AssignDelivery_Success(t *testing.T) {
 			return 1, nil
 		},
 	)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusAssigned, gomock.Any(), 1).Return(nil)
 
 	mockCourierRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
 		func(ctx context.Context, updated model.Courier) error {
@@ -71,6 +102,11 @@ func TestDeliveryService_AssignDelivery_Success(t *testing.T) {
 	require.Equal(t, req.OrderId, resp.OrderId)
 	require.Equal(t, courier.TransportType, resp.TransportType)
 	require.WithinDuration(t, time.Now(), resp.DeliveryDeadline, 2*time.Hour) // допустимо ±2 часа
+
+	require.Equal(t, 1, metrics.created)
+	require.Equal(t, 1, metrics.assignments)
+	require.Len(t, metrics.timeToAssign, 1)
+	require.InDelta(t, 30*time.Second, metrics.timeToAssign[0], float64(time.Second))
 }
 func TestDeliveryService_AssignDelivery_Errors(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -80,7 +116,8 @@ func TestDeliveryService_AssignDelivery_Errors(t *testing.T) {
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	metrics := &stubMetrics{}
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), metrics, testRating, testSLA, testProof, testHandoff)
 
 	ctx := context.Background()
 	req := &dto.AssignDeliveryRequest{OrderId: "ORDER-123"}
@@ -91,11 +128,13 @@ func TestDeliveryService_AssignDelivery_Errors(t *testing.T) {
 				return fn(ctx)
 			},
 		)
-		mockCourierRepo.EXPECT().GetAvailable(gomock.Any()).Return(model.Courier{}, repository.ErrNoAvailableCouriers)
+		mockCourierRepo.EXPECT().GetAvailable(gomock.Any(), testRating).Return(model.Courier{}, repository.ErrNoAvailableCouriers)
 
 		resp, err := ds.Assign(ctx, req)
 		require.Nil(t, resp)
 		require.ErrorIs(t, err, service.ErrNoAvailableCouriers)
+		require.Equal(t, 1, metrics.noAvailableCouriers)
+		require.Zero(t, metrics.created)
 	})
 
 	t.Run("delivery repo Create returns ErrDeliveryExists", func(t *testing.T) {
@@ -105,7 +144,7 @@ func TestDeliveryService_AssignDelivery_Errors(t *testing.T) {
 				return fn(ctx)
 			},
 		)
-		mockCourierRepo.EXPECT().GetAvailable(gomock.Any()).Return(courier, nil)
+		mockCourierRepo.EXPECT().GetAvailable(gomock.Any(), testRating).Return(courier, nil)
 		mockDeliveryRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(0, repository.ErrDeliveryExists)
 
 		resp, err := ds.Assign(ctx, req)
@@ -120,8 +159,9 @@ func TestDeliveryService_AssignDelivery_Errors(t *testing.T) {
 				return fn(ctx)
 			},
 		)
-		mockCourierRepo.EXPECT().GetAvailable(gomock.Any()).Return(courier, nil)
+		mockCourierRepo.EXPECT().GetAvailable(gomock.Any(), testRating).Return(courier, nil)
 		mockDeliveryRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
+		mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusAssigned, gomock.Any(), 1).Return(nil)
 		mockCourierRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(service.ErrInternalError)
 
 		resp, err := ds.Assign(ctx, req)
@@ -138,7 +178,7 @@ func TestDeliveryService_UnassignDelivery_Success(t *testing.T) {
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 
 	ctx := context.Background()
 	req := &dto.UnassignDeliveryRequest{
@@ -158,6 +198,8 @@ func TestDeliveryService_UnassignDelivery_Success(t *testing.T) {
 	)
 
 	mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), req.OrderId).Return(delivery, nil)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusUnassigned, gomock.Any(), delivery.Id).Return(nil)
+	mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCancelled, delivery.Id).Return(nil)
 	mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), req.OrderId).Return(nil)
 	mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{
 		Id:     delivery.CourierId,
@@ -181,7 +223,7 @@ func TestDeliveryService_UnassignDelivery_DeliveryNotFound(t *testing.T) {
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 	req := &dto.UnassignDeliveryRequest{OrderId: "ORDER-123"}
 
@@ -204,7 +246,7 @@ func TestDeliveryService_UnassignDelivery_DeleteFails(t *testing.T) {
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 	req := &dto.UnassignDeliveryRequest{OrderId: "ORDER-123"}
 
@@ -214,6 +256,8 @@ func TestDeliveryService_UnassignDelivery_DeleteFails(t *testing.T) {
 		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
 	)
 	mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), req.OrderId).Return(delivery, nil)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusUnassigned, gomock.Any(), delivery.Id).Return(nil)
+	mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCancelled, delivery.Id).Return(nil)
 	mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), req.OrderId).Return(repository.ErrInternalError)
 
 	resp, err := ds.Unassign(ctx, req)
@@ -230,7 +274,7 @@ func TestDeliveryService_UnassignDelivery_CourierUpdateFails(t *testing.T) {
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 	req := &dto.UnassignDeliveryRequest{OrderId: "ORDER-123"}
 
@@ -240,6 +284,8 @@ func TestDeliveryService_UnassignDelivery_CourierUpdateFails(t *testing.T) {
 		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
 	)
 	mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), req.OrderId).Return(delivery, nil)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusUnassigned, gomock.Any(), delivery.Id).Return(nil)
+	mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCancelled, delivery.Id).Return(nil)
 	mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), req.OrderId).Return(nil)
 	mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{
 		Id:     delivery.CourierId,
@@ -260,7 +306,7 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_Success(t *testing.T) {
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 
 	completedDeliveries := []model.Delivery{
@@ -272,7 +318,9 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_Success(t *testing.T) {
 		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
 	)
 
-	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any()).Return(completedDeliveries, nil)
+	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any(), gomock.Any()).Return(completedDeliveries, nil)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusExpired, gomock.Any(), 1, 2).Return(nil)
+	mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusExpired, 1, 2).Return(nil)
 	mockDeliveryRepo.EXPECT().DeleteManyById(gomock.Any(), 1, 2).Return(nil)
 	mockCourierRepo.EXPECT().UpdateStatusManyById(gomock.Any(), 101, 102).Return(nil)
 
@@ -290,7 +338,7 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_NoCompletedDeliveries(t
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 
 	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
@@ -298,7 +346,7 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_NoCompletedDeliveries(t
 	)
 
 	// Возвращаем пустой слайс completedDeliveries
-	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any()).Return([]model.Delivery{}, nil)
+	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any(), gomock.Any()).Return([]model.Delivery{}, nil)
 
 	total, err := ds.UnassignAllCompleted(ctx)
 
@@ -315,14 +363,14 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_GetAllCompletedError(t *
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 
 	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
 		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
 	)
 
-	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any()).Return(nil, repository.ErrInternalError)
+	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any(), gomock.Any()).Return(nil, repository.ErrInternalError)
 
 	total, err := ds.UnassignAllCompleted(ctx)
 
@@ -339,7 +387,7 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_DeleteManyByIdError(t *t
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 
 	completedDeliveries := []model.Delivery{
@@ -351,7 +399,9 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_DeleteManyByIdError(t *t
 		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
 	)
 
-	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any()).Return(completedDeliveries, nil)
+	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any(), gomock.Any()).Return(completedDeliveries, nil)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusExpired, gomock.Any(), 1, 2).Return(nil)
+	mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusExpired, 1, 2).Return(nil)
 	mockDeliveryRepo.EXPECT().DeleteManyById(gomock.Any(), 1, 2).Return(repository.ErrDeliveryNotFound)
 
 	total, err := ds.UnassignAllCompleted(ctx)
@@ -369,7 +419,7 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_UpdateStatusManyError(t
 	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
 	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
 
-	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
 	ctx := context.Background()
 
 	completedDeliveries := []model.Delivery{
@@ -381,7 +431,9 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_UpdateStatusManyError(t
 		func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
 	)
 
-	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any()).Return(completedDeliveries, nil)
+	mockDeliveryRepo.EXPECT().GetAllCompleted(gomock.Any(), gomock.Any()).Return(completedDeliveries, nil)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusExpired, gomock.Any(), 1, 2).Return(nil)
+	mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusExpired, 1, 2).Return(nil)
 	mockDeliveryRepo.EXPECT().DeleteManyById(gomock.Any(), 1, 2).Return(nil)
 	mockCourierRepo.EXPECT().UpdateStatusManyById(gomock.Any(), 101, 102).Return(repository.ErrInternalError)
 
@@ -390,3 +442,201 @@ func TestDeliveryService_UnassignAllCompletedDeliveries_UpdateStatusManyError(t
 	require.Equal(t, 0, total)
 	require.ErrorIs(t, err, service.ErrInternalError)
 }
+
+func TestDeliveryService_CompleteDelivery_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	req := &dto.CompleteDeliveryRequest{
+		OrderId: "ORDER-123",
+	}
+
+	delivery := model.Delivery{
+		Id:        7,
+		CourierId: 1,
+		OrderId:   req.OrderId,
+		Deadline:  time.Now().Add(2 * time.Hour),
+	}
+
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+
+	// доставка уходит в историю и удаляется, чтобы монитор не освободил курьера второй раз
+	gomock.InOrder(
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), req.OrderId).Return(delivery, nil),
+		mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusDelivered, gomock.Any(), delivery.Id).Return(nil),
+		mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCompleted, delivery.Id).Return(nil),
+		mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), req.OrderId).Return(nil),
+		mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{
+			Id:     delivery.CourierId,
+			Status: model.StatusAvailable,
+		}).Return(nil),
+	)
+
+	resp, err := ds.Complete(context.Background(), req)
+
+	require.NoError(t, err)
+	require.Equal(t, delivery.CourierId, resp.CourierId)
+	require.Equal(t, model.StatusCompleted, resp.Status)
+}
+
+func TestDeliveryService_CompleteDelivery_ProofRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery model.Delivery
+		wantErr  error
+	}{
+		{name: "proof missing", delivery: model.Delivery{Id: 7, CourierId: 1, OrderId: "ORDER-123", ProofRequired: true}, wantErr: service.ErrProofRequired},
+		{name: "proof attached", delivery: model.Delivery{Id: 7, CourierId: 1, OrderId: "ORDER-123", ProofRequired: true, ProofAttached: true}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTM := mock_dep.NewMockTransactionManager(ctrl)
+			mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+			mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+			ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+			mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				},
+			)
+			mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-123").Return(tt.delivery, nil)
+			if tt.wantErr == nil {
+				mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusDelivered, gomock.Any(), 7).Return(nil)
+				mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCompleted, 7).Return(nil)
+				mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), "ORDER-123").Return(nil)
+				mockCourierRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			_, err := ds.Complete(context.Background(), &dto.CompleteDeliveryRequest{OrderId: "ORDER-123"})
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDeliveryService_AssignDelivery_ProofRequiredForAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA,
+		model.ProofPolicy{RequiredForAll: true}, testHandoff)
+
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+	mockCourierRepo.EXPECT().GetAvailable(gomock.Any(), testRating).Return(model.Courier{Id: 1, TransportType: "car"}, nil)
+	mockDeliveryRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, delivery model.Delivery) (int, error) {
+			require.True(t, delivery.ProofRequired)
+			return 1, nil
+		},
+	)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusAssigned, gomock.Any(), 1).Return(nil)
+	mockCourierRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	_, err := ds.Assign(context.Background(), &dto.AssignDeliveryRequest{OrderId: "ORDER-123"})
+	require.NoError(t, err)
+}
+
+func TestDeliveryService_TrackSLA_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	mockEvents := mock_dep.NewMockEventPublisher(ctrl)
+
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mockEvents, &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	atRisk := []model.Delivery{{Id: 1, CourierId: 1, OrderId: "ORDER-1", SLAState: model.SLAStateAtRisk}}
+	breached := []model.Delivery{
+		{Id: 2, CourierId: 2, OrderId: "ORDER-2", SLAState: model.SLAStateBreached},
+		{Id: 3, CourierId: 3, OrderId: "ORDER-3", SLAState: model.SLAStateBreached},
+	}
+	active := map[string]int{model.SLAStateOnTime: 4, model.SLAStateAtRisk: 1, model.SLAStateBreached: 2}
+
+	mockDeliveryRepo.EXPECT().MarkAtRisk(gomock.Any(), testSLA.AtRiskFraction, gomock.Any()).Return(atRisk, nil)
+	mockDeliveryRepo.EXPECT().MarkBreached(gomock.Any(), gomock.Any()).Return(breached, nil)
+	mockDeliveryRepo.EXPECT().CountBySLAState(gomock.Any()).Return(active, nil)
+
+	var published []string
+	mockEvents.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event model.Event) error {
+			published = append(published, event.Type+":"+event.Key)
+			return nil
+		},
+	).Times(3)
+
+	report, err := ds.TrackSLA(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, atRisk, report.AtRisk)
+	require.Equal(t, breached, report.Breached)
+	require.Equal(t, active, report.Active)
+	require.Equal(t, []string{
+		model.EventDeliverySLAAtRisk + ":ORDER-1",
+		model.EventDeliverySLABreached + ":ORDER-2",
+		model.EventDeliverySLABreached + ":ORDER-3",
+	}, published)
+}
+
+func TestDeliveryService_TrackSLA_RepoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	mockDeliveryRepo.EXPECT().MarkAtRisk(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockDeliveryRepo.EXPECT().MarkBreached(gomock.Any(), gomock.Any()).Return(nil, repository.ErrInternalError)
+
+	_, err := ds.TrackSLA(context.Background())
+	require.ErrorIs(t, err, service.ErrInternalError)
+}
+
+func TestDeliveryService_GetOrderStates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := NewDeliveryService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	// пустая страница не ходит в базу
+	states, err := ds.GetOrderStates(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, states)
+
+	mockDeliveryRepo.EXPECT().GetStatesByOrderIds(gomock.Any(), []string{"ORDER-1", "ORDER-2"}).
+		Return(map[string]string{"ORDER-1": model.DeliveryActive}, nil)
+	states, err = ds.GetOrderStates(context.Background(), []string{"ORDER-1", "ORDER-2"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"ORDER-1": model.DeliveryActive}, states)
+
+	mockDeliveryRepo.EXPECT().GetStatesByOrderIds(gomock.Any(), gomock.Any()).Return(nil, repository.ErrInternalError)
+	_, err = ds.GetOrderStates(context.Background(), []string{"ORDER-1"})
+	require.ErrorIs(t, err, service.ErrInternalError)
+}
@@ -0,0 +1,117 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"time"
+)
+
+// AddEvent переводит доставку на следующий этап по событию из приложения курьера.
+// Этапы проверяются по model.CanTransition, событие delivered завершает доставку так же, как Complete,
+// в том числе с проверкой кода передачи
+func (ds *deliveryService) AddEvent(ctx context.Context, req *dto.AddDeliveryEventRequest) (*dto.AddDeliveryEventResponse, error) {
+	if !model.IsDeliveryEventStatus(req.Status) {
+		var verr service.ValidationError
+		verr.Add("status", service.ErrInvalidDeliveryStatus)
+		return nil, verr.Err()
+	}
+	if req.Status == model.StatusDelivered {
+		if err := ds.checkHandoff(ctx, req.OrderId, req.HandoffCode); err != nil {
+			return nil, err
+		}
+	}
+
+	var res *dto.AddDeliveryEventResponse
+	err := ds.tm.Begin(ctx, func(ctx context.Context) error {
+		delivery, err := ds.delRepo.GetByOrderId(ctx, req.OrderId)
+		if err != nil {
+			return err
+		}
+
+		if !model.CanAccessCourier(ctx, delivery.CourierId) {
+			return service.ErrForbidden
+		}
+		if !model.CanTransition(delivery.Status, req.Status) {
+			return service.ErrInvalidStatusTransition
+		}
+
+		now := time.Now()
+		if req.Status == model.StatusDelivered {
+			err = ds.complete(ctx, delivery, now)
+		} else {
+			err = ds.advance(ctx, delivery, req.Status, now)
+		}
+		if err != nil {
+			return err
+		}
+
+		res = &dto.AddDeliveryEventResponse{
+			OrderId:    req.OrderId,
+			CourierId:  delivery.CourierId,
+			Status:     req.Status,
+			OccurredAt: now,
+		}
+		return nil
+	})
+	if errors.Is(err, service.ErrInvalidStatusTransition) || errors.Is(err, service.ErrProofRequired) || errors.Is(err, service.ErrForbidden) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+	if req.Status == model.StatusDelivered {
+		ds.metrics.AddDeliveriesFinished(model.StatusCompleted, 1)
+	}
+	return res, nil
+}
+
+func (ds *deliveryService) advance(ctx context.Context, delivery model.Delivery, status string, at time.Time) error {
+	err := ds.delRepo.UpdateStatus(ctx, delivery.Id, delivery.Status, status)
+	if err != nil {
+		return err
+	}
+	return ds.delRepo.AddEventManyById(ctx, status, at, delivery.Id)
+}
+
+// GetTimeline все события заказа по порядку, включая назначение и завершение
+func (ds *deliveryService) GetTimeline(ctx context.Context, req *dto.GetDeliveryTimelineRequest) (*dto.DeliveryTimelineResponse, error) {
+	events, err := ds.delRepo.GetEventsByOrderId(ctx, req.OrderId)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+	if len(events) == 0 {
+		return nil, service.ErrDeliveryNotFound
+	}
+	if !canAccessTimeline(ctx, events) {
+		return nil, service.ErrForbidden
+	}
+
+	res := &dto.DeliveryTimelineResponse{
+		OrderId: req.OrderId,
+		Status:  events[len(events)-1].Status,
+		Events:  make([]dto.DeliveryTimelineEvent, len(events)),
+	}
+	for i, e := range events {
+		res.Events[i] = dto.DeliveryTimelineEvent{
+			Status:     e.Status,
+			CourierId:  e.CourierId,
+			Actor:      e.Actor,
+			OccurredAt: e.OccurredAt,
+		}
+	}
+	return res, nil
+}
+
+// canAccessTimeline курьер видит таймлайн заказа, если хотя бы часть доставки была на нем
+func canAccessTimeline(ctx context.Context, events []model.DeliveryEvent) bool {
+	for _, e := range events {
+		if model.CanAccessCourier(ctx, e.CourierId) {
+			return true
+		}
+	}
+	return false
+}
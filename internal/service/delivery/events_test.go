@@ -0,0 +1,230 @@
+package delivery
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"testing"
+	"time"
+)
+
+func expectTx(tm *mock_dep.MockTransactionManager) {
+	tm.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+}
+
+func TestDeliveryService_AddEvent_Advance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := NewDeliveryService(mockTM, mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	delivery := model.Delivery{Id: 3, CourierId: 7, OrderId: "ORDER-1", Status: model.StatusAccepted}
+
+	expectTx(mockTM)
+	gomock.InOrder(
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(delivery, nil),
+		mockDeliveryRepo.EXPECT().UpdateStatus(gomock.Any(), 3, model.StatusAccepted, model.StatusArrivedAtRestaurant).Return(nil),
+		mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusArrivedAtRestaurant, gomock.Any(), 3).Return(nil),
+	)
+
+	resp, err := ds.AddEvent(context.Background(), &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: model.StatusArrivedAtRestaurant})
+	require.NoError(t, err)
+	require.Equal(t, 7, resp.CourierId)
+	require.Equal(t, model.StatusArrivedAtRestaurant, resp.Status)
+	require.WithinDuration(t, time.Now(), resp.OccurredAt, time.Second)
+}
+
+func TestDeliveryService_AddEvent_DeliveredCompletes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	delivery := model.Delivery{Id: 3, CourierId: 7, OrderId: "ORDER-1", Status: model.StatusArrived}
+
+	expectTx(mockTM)
+	gomock.InOrder(
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(delivery, nil).Times(2),
+		mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusDelivered, gomock.Any(), 3).Return(nil),
+		mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCompleted, 3).Return(nil),
+		mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), "ORDER-1").Return(nil),
+		mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{Id: 7, Status: model.StatusAvailable}).Return(nil),
+	)
+
+	resp, err := ds.AddEvent(context.Background(), &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: model.StatusDelivered})
+	require.NoError(t, err)
+	require.Equal(t, model.StatusDelivered, resp.Status)
+}
+
+func TestDeliveryService_AddEvent_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		delivery  model.Delivery
+		getErr    error
+		updateErr error
+		wantErr   error
+	}{
+		{name: "unknown status", status: "teleported", wantErr: service.ErrInvalidDeliveryStatus},
+		{name: "assigned is not an event", status: model.StatusAssigned, wantErr: service.ErrInvalidDeliveryStatus},
+		{name: "delivery not found", status: model.StatusAccepted, getErr: repository.ErrDeliveryNotFound, wantErr: service.ErrDeliveryNotFound},
+		{
+			name:     "skipped stage",
+			status:   model.StatusPickedUp,
+			delivery: model.Delivery{Id: 3, Status: model.StatusAssigned},
+			wantErr:  service.ErrInvalidStatusTransition,
+		},
+		{
+			name:     "repeated stage",
+			status:   model.StatusAccepted,
+			delivery: model.Delivery{Id: 3, Status: model.StatusAccepted},
+			wantErr:  service.ErrInvalidStatusTransition,
+		},
+		{
+			name:      "concurrent update",
+			status:    model.StatusAccepted,
+			delivery:  model.Delivery{Id: 3, Status: model.StatusAssigned},
+			updateErr: repository.ErrDeliveryStatusConflict,
+			wantErr:   service.ErrInvalidStatusTransition,
+		},
+		{
+			name:     "delivered without required proof",
+			status:   model.StatusDelivered,
+			delivery: model.Delivery{Id: 3, Status: model.StatusArrived, ProofRequired: true},
+			wantErr:  service.ErrProofRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTM := mock_dep.NewMockTransactionManager(ctrl)
+			mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+			ds := NewDeliveryService(mockTM, mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+			if model.IsDeliveryEventStatus(tt.status) {
+				expectTx(mockTM)
+				get := mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(tt.delivery, tt.getErr)
+				if tt.status == model.StatusDelivered {
+					get.Times(2) // еще раз при проверке кода передачи
+				}
+			}
+			if tt.updateErr != nil {
+				mockDeliveryRepo.EXPECT().UpdateStatus(gomock.Any(), tt.delivery.Id, tt.delivery.Status, tt.status).Return(tt.updateErr)
+			}
+
+			resp, err := ds.AddEvent(context.Background(), &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: tt.status})
+			require.Nil(t, resp)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDeliveryService_GetTimeline(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		ds := NewDeliveryService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+		at := time.Date(2025, time.December, 8, 12, 0, 0, 0, time.UTC)
+		mockDeliveryRepo.EXPECT().GetEventsByOrderId(gomock.Any(), "ORDER-1").Return([]model.DeliveryEvent{
+			{OrderId: "ORDER-1", CourierId: 7, Status: model.StatusAssigned, OccurredAt: at},
+			{OrderId: "ORDER-1", CourierId: 7, Status: model.StatusAccepted, OccurredAt: at.Add(time.Minute)},
+		}, nil)
+
+		resp, err := ds.GetTimeline(context.Background(), &dto.GetDeliveryTimelineRequest{OrderId: "ORDER-1"})
+		require.NoError(t, err)
+		require.Equal(t, model.StatusAccepted, resp.Status)
+		require.Len(t, resp.Events, 2)
+		require.Equal(t, at, resp.Events[0].OccurredAt)
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		ds := NewDeliveryService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+		mockDeliveryRepo.EXPECT().GetEventsByOrderId(gomock.Any(), "ORDER-1").Return(nil, nil)
+
+		_, err := ds.GetTimeline(context.Background(), &dto.GetDeliveryTimelineRequest{OrderId: "ORDER-1"})
+		require.ErrorIs(t, err, service.ErrDeliveryNotFound)
+	})
+}
+
+func TestDeliveryService_CourierAccess(t *testing.T) {
+	other := model.WithPrincipal(context.Background(), model.Principal{Subject: "app", Role: model.RoleCourier, CourierId: 8})
+	own := model.WithPrincipal(context.Background(), model.Principal{Subject: "app", Role: model.RoleCourier, CourierId: 7})
+
+	t.Run("event for another courier's delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTM := mock_dep.NewMockTransactionManager(ctrl)
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		ds := NewDeliveryService(mockTM, mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+		expectTx(mockTM)
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{Id: 3, CourierId: 7, Status: model.StatusAccepted}, nil)
+
+		_, err := ds.AddEvent(other, &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: model.StatusArrivedAtRestaurant})
+		require.ErrorIs(t, err, service.ErrForbidden)
+	})
+
+	t.Run("another courier can't spend handoff attempts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		ds := NewDeliveryService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{Id: 3, CourierId: 7, Status: model.StatusArrived, HandoffCodeHash: "hash"}, nil)
+
+		_, err := ds.AddEvent(other, &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: model.StatusDelivered, HandoffCode: "0000"})
+		require.ErrorIs(t, err, service.ErrForbidden)
+	})
+
+	t.Run("timeline", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		ds := NewDeliveryService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+		// заказ переназначили с 7 на 9, курьер 7 все еще видит таймлайн
+		events := []model.DeliveryEvent{
+			{CourierId: 7, Status: model.StatusAssigned},
+			{CourierId: 9, Status: model.StatusAssigned, Actor: "dispatcher:ops"},
+		}
+		mockDeliveryRepo.EXPECT().GetEventsByOrderId(gomock.Any(), "ORDER-1").Return(events, nil).Times(2)
+
+		resp, err := ds.GetTimeline(own, &dto.GetDeliveryTimelineRequest{OrderId: "ORDER-1"})
+		require.NoError(t, err)
+		require.Equal(t, "dispatcher:ops", resp.Events[1].Actor)
+
+		_, err = ds.GetTimeline(other, &dto.GetDeliveryTimelineRequest{OrderId: "ORDER-1"})
+		require.ErrorIs(t, err, service.ErrForbidden)
+	})
+}
@@ -0,0 +1,81 @@
+package delivery
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"time"
+)
+
+const handoffCodeSpace = 10000 // 4 цифры
+
+func newHandoffCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(handoffCodeSpace))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%04d", n.Int64()), nil
+}
+
+// hashHandoffCode HMAC от кода вместе с заказом: одинаковые коды разных заказов дают разные хеши
+func hashHandoffCode(secret []byte, orderId, code string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(orderId + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkHandoff проверяет код передачи заказа, если он был выдан при назначении.
+// Каждая попытка учитывается в базе отдельно от завершения доставки, поэтому неверный код
+// не откатывается вместе с транзакцией. Последняя из MaxAttempts попыток в том же запросе блокирует ввод на Lockout.
+// Верный код свою попытку (и поставленную ей блокировку) возвращает: завершение может сорваться позже
+// (нет подтверждения доставки), и повтор с тем же кодом не должен приближать блокировку
+func (ds *deliveryService) checkHandoff(ctx context.Context, orderId, code string) error {
+	delivery, err := ds.delRepo.GetByOrderId(ctx, orderId)
+	if err != nil {
+		return adapters.ErrUnwrapRepoToService(err)
+	}
+	// чужой курьер не должен тратить попытки ввода кода
+	if !model.CanAccessCourier(ctx, delivery.CourierId) {
+		return service.ErrForbidden
+	}
+	if delivery.HandoffCodeHash == "" {
+		return nil
+	}
+	if code == "" {
+		return service.ErrHandoffCodeRequired
+	}
+
+	now := time.Now()
+	lockUntil := now.Add(ds.handoff.Lockout)
+	if err = ds.delRepo.ReserveHandoffAttempt(ctx, delivery.Id, now, ds.handoff.MaxAttempts, lockUntil); err != nil {
+		return adapters.ErrUnwrapRepoToService(err)
+	}
+
+	expected := hashHandoffCode(ds.handoff.Secret, orderId, code)
+	if hmac.Equal([]byte(expected), []byte(delivery.HandoffCodeHash)) {
+		return adapters.ErrUnwrapRepoToService(ds.delRepo.ReleaseHandoffAttempt(ctx, delivery.Id, ds.handoff.MaxAttempts, lockUntil))
+	}
+	return service.ErrInvalidHandoffCode
+}
+
+// publishHandoffCode ошибку публикации логирует сам publisher. Код при этом остается в ответе Assign
+func (ds *deliveryService) publishHandoffCode(ctx context.Context, res *dto.AssignDeliveryResponse) {
+	_ = ds.events.Publish(ctx, model.Event{
+		Type: model.EventDeliveryHandoffCode,
+		Key:  res.OrderId,
+		Payload: dto.DeliveryHandoffCodeEvent{
+			OrderId:     res.OrderId,
+			CourierId:   res.CourierId,
+			HandoffCode: res.HandoffCode,
+		},
+		OccurredAt: time.Now(),
+	})
+}
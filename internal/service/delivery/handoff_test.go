@@ -0,0 +1,279 @@
+package delivery
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"testing"
+	"time"
+)
+
+func TestDeliveryService_Assign_HandoffCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalPrice int64
+		wantCode   bool
+	}{
+		{name: "cheap order", totalPrice: testHandoff.PriceThreshold},
+		{name: "expensive order", totalPrice: testHandoff.PriceThreshold + 1, wantCode: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTM := mock_dep.NewMockTransactionManager(ctrl)
+			mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+			mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+			mockEvents := mock_dep.NewMockEventPublisher(ctrl)
+			ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mockEvents, &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+			var storedHash string
+			expectTx(mockTM)
+			mockCourierRepo.EXPECT().GetAvailable(gomock.Any(), testRating).Return(model.Courier{Id: 1, TransportType: "car"}, nil)
+			mockDeliveryRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, delivery model.Delivery) (int, error) {
+					storedHash = delivery.HandoffCodeHash
+					return 1, nil
+				},
+			)
+			mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusAssigned, gomock.Any(), 1).Return(nil)
+			mockCourierRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+			var published model.Event
+			if tt.wantCode {
+				mockEvents.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event model.Event) error {
+						published = event
+						return nil
+					},
+				)
+			}
+
+			resp, err := ds.Assign(context.Background(), &dto.AssignDeliveryRequest{OrderId: "ORDER-1", TotalPrice: tt.totalPrice})
+			require.NoError(t, err)
+
+			if !tt.wantCode {
+				require.Empty(t, resp.HandoffCode)
+				require.Empty(t, storedHash)
+				return
+			}
+
+			require.Regexp(t, regexp.MustCompile(`^\d{4}$`), resp.HandoffCode)
+			// в базу уходит только HMAC кода
+			require.Equal(t, hashHandoffCode(testHandoff.Secret, "ORDER-1", resp.HandoffCode), storedHash)
+			require.Equal(t, model.EventDeliveryHandoffCode, published.Type)
+			require.Equal(t, resp.HandoffCode, published.Payload.(dto.DeliveryHandoffCodeEvent).HandoffCode)
+		})
+	}
+}
+
+// handoffCounter попытки ввода кода и блокировка так, как их меняют ReserveHandoffAttempt и ReleaseHandoffAttempt в базе
+type handoffCounter struct {
+	attempts    int
+	lockedUntil time.Time
+}
+
+func (c *handoffCounter) reserve(_ context.Context, _ int, now time.Time, maxAttempts int, lockUntil time.Time) error {
+	if now.Before(c.lockedUntil) {
+		return repository.ErrHandoffLocked
+	}
+	c.attempts++
+	c.lockedUntil = time.Time{}
+	if c.attempts >= maxAttempts {
+		c.attempts, c.lockedUntil = 0, lockUntil
+	}
+	return nil
+}
+
+func (c *handoffCounter) release(_ context.Context, _ int, maxAttempts int, lockUntil time.Time) error {
+	if c.lockedUntil.Equal(lockUntil) {
+		c.attempts, c.lockedUntil = maxAttempts-1, time.Time{}
+		return nil
+	}
+	if c.attempts > 0 {
+		c.attempts--
+	}
+	return nil
+}
+
+func TestDeliveryService_Delivered_HandoffCode(t *testing.T) {
+	hash := hashHandoffCode(testHandoff.Secret, "ORDER-1", "0421")
+
+	tests := []struct {
+		name       string
+		code       string
+		reserveErr error
+		wantErr    error
+	}{
+		{name: "code missing", wantErr: service.ErrHandoffCodeRequired},
+		{name: "wrong code", code: "1111", wantErr: service.ErrInvalidHandoffCode},
+		{name: "locked", code: "0421", reserveErr: repository.ErrHandoffLocked, wantErr: service.ErrHandoffLocked},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+			ds := NewDeliveryService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+			mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{Id: 3, CourierId: 7, OrderId: "ORDER-1", HandoffCodeHash: hash}, nil)
+			if tt.code != "" {
+				mockDeliveryRepo.EXPECT().ReserveHandoffAttempt(gomock.Any(), 3, gomock.Any(), testHandoff.MaxAttempts, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int, now time.Time, _ int, lockUntil time.Time) error {
+						require.Equal(t, now.Add(testHandoff.Lockout), lockUntil)
+						return tt.reserveErr
+					},
+				)
+			}
+
+			resp, err := ds.AddEvent(context.Background(), &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: model.StatusDelivered, HandoffCode: tt.code})
+			require.Nil(t, resp)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// верный код на последней попытке и параллельный неверный: счетчик не должен остаться на лимите без блокировки,
+// иначе курьер уже никогда не введет код
+func TestDeliveryService_HandoffLastAttemptRace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := NewDeliveryService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	counter := &handoffCounter{attempts: testHandoff.MaxAttempts - 1}
+	raced := false
+	mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{
+		Id:              3,
+		CourierId:       7,
+		OrderId:         "ORDER-1",
+		HandoffCodeHash: hashHandoffCode(testHandoff.Secret, "ORDER-1", "0421"),
+	}, nil).AnyTimes()
+	mockDeliveryRepo.EXPECT().ReserveHandoffAttempt(gomock.Any(), 3, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(counter.reserve).AnyTimes()
+	mockDeliveryRepo.EXPECT().ReleaseHandoffAttempt(gomock.Any(), 3, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, id int, maxAttempts int, lockUntil time.Time) error {
+			// неверный код приходит между проверкой верного и возвратом его попытки
+			if !raced {
+				raced = true
+				require.ErrorIs(t, ds.checkHandoff(context.Background(), "ORDER-1", "1111"), service.ErrHandoffLocked)
+			}
+			return counter.release(ctx, id, maxAttempts, lockUntil)
+		},
+	).AnyTimes()
+
+	require.NoError(t, ds.checkHandoff(context.Background(), "ORDER-1", "0421"))
+	require.True(t, raced)
+	require.Equal(t, testHandoff.MaxAttempts-1, counter.attempts)
+	require.True(t, counter.lockedUntil.IsZero())
+
+	// верный код по-прежнему принимается, а следующий неверный блокирует ввод
+	require.NoError(t, ds.checkHandoff(context.Background(), "ORDER-1", "0421"))
+	require.ErrorIs(t, ds.checkHandoff(context.Background(), "ORDER-1", "1111"), service.ErrInvalidHandoffCode)
+	require.ErrorIs(t, ds.checkHandoff(context.Background(), "ORDER-1", "0421"), service.ErrHandoffLocked)
+}
+
+func TestDeliveryService_Delivered_HandoffCodeAccepted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	delivery := model.Delivery{Id: 3, CourierId: 7, OrderId: "ORDER-1", Status: model.StatusArrived, HandoffCodeHash: hashHandoffCode(testHandoff.Secret, "ORDER-1", "0421")}
+
+	expectTx(mockTM)
+	gomock.InOrder(
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(delivery, nil),
+		mockDeliveryRepo.EXPECT().ReserveHandoffAttempt(gomock.Any(), 3, gomock.Any(), testHandoff.MaxAttempts, gomock.Any()).Return(nil),
+		mockDeliveryRepo.EXPECT().ReleaseHandoffAttempt(gomock.Any(), 3, testHandoff.MaxAttempts, gomock.Any()).Return(nil),
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(delivery, nil),
+		mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusDelivered, gomock.Any(), 3).Return(nil),
+		mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCompleted, 3).Return(nil),
+		mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), "ORDER-1").Return(nil),
+		mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{Id: 7, Status: model.StatusAvailable}).Return(nil),
+	)
+
+	resp, err := ds.AddEvent(context.Background(), &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: model.StatusDelivered, HandoffCode: "0421"})
+	require.NoError(t, err)
+	require.Equal(t, 7, resp.CourierId)
+}
+
+// верный код, но нет подтверждения доставки: повторы с тем же кодом не должны заблокировать курьера
+func TestDeliveryService_HandoffCodeRetryAfterProofRequired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := NewDeliveryService(mockTM, mock_dep.NewMockCourierRepository(ctrl), mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	delivery := model.Delivery{
+		Id:              3,
+		CourierId:       7,
+		OrderId:         "ORDER-1",
+		Status:          model.StatusArrived,
+		ProofRequired:   true,
+		HandoffCodeHash: hashHandoffCode(testHandoff.Secret, "ORDER-1", "0421"),
+	}
+
+	counter := &handoffCounter{}
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	).AnyTimes()
+	mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(delivery, nil).AnyTimes()
+	mockDeliveryRepo.EXPECT().ReserveHandoffAttempt(gomock.Any(), 3, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(counter.reserve).AnyTimes()
+	mockDeliveryRepo.EXPECT().ReleaseHandoffAttempt(gomock.Any(), 3, gomock.Any(), gomock.Any()).DoAndReturn(counter.release).AnyTimes()
+
+	for i := 0; i <= testHandoff.MaxAttempts; i++ {
+		_, err := ds.AddEvent(context.Background(), &dto.AddDeliveryEventRequest{OrderId: "ORDER-1", Status: model.StatusDelivered, HandoffCode: "0421"})
+		require.ErrorIs(t, err, service.ErrProofRequired)
+	}
+	require.Zero(t, counter.attempts)
+	require.True(t, counter.lockedUntil.IsZero())
+}
+
+// order-service подтверждает выполнение без кода, у него кода нет
+func TestDeliveryService_Complete_SkipsHandoffCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), &stubMetrics{}, testRating, testSLA, testProof, testHandoff)
+
+	delivery := model.Delivery{Id: 3, CourierId: 7, OrderId: "ORDER-1", HandoffCodeHash: hashHandoffCode(testHandoff.Secret, "ORDER-1", "0421")}
+
+	// попытки ввода кода не тратятся
+	expectTx(mockTM)
+	mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(delivery, nil)
+	mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusDelivered, gomock.Any(), 3).Return(nil)
+	mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCompleted, 3).Return(nil)
+	mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), "ORDER-1").Return(nil)
+	mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{Id: 7, Status: model.StatusAvailable}).Return(nil)
+
+	resp, err := ds.Complete(context.Background(), &dto.CompleteDeliveryRequest{OrderId: "ORDER-1"})
+	require.NoError(t, err)
+	require.Equal(t, 7, resp.CourierId)
+}
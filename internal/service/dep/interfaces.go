@@ -2,6 +2,7 @@ package dep
 
 import (
 	"context"
+	"io"
 	"service-order-avito/internal/domain/model"
 	"time"
 )
@@ -17,16 +18,74 @@ type CourierRepository interface {
 	GetAll(context.Context) ([]model.Courier, error)
 	Update(context.Context, model.Courier) error
 	UpdateStatusManyById(context.Context, ...int) error
+	UpdateStatusUnlessBusy(ctx context.Context, id int, status string) error
 	DeleteById(context.Context, int) error
-	GetAvailable(context.Context) (model.Courier, error)
+	GetAvailable(context.Context, model.RatingPolicy) (model.Courier, error)
+	CreateMany(context.Context, []model.Courier) (int, error)
+	GetExistingPhones(context.Context, []string) ([]string, error)
+	StreamAll(context.Context, func(model.Courier) error) error
+	AddRating(ctx context.Context, id int, rating int) (avg float64, count int, err error)
 }
 
 type DeliveryRepository interface {
 	Create(context.Context, model.Delivery) (int, error)
 	GetByOrderId(context.Context, string) (model.Delivery, error)
-	GetAllCompleted(context.Context) ([]model.Delivery, error)
+	GetAllCompleted(ctx context.Context, before time.Time) ([]model.Delivery, error)
 	DeleteByOrderId(context.Context, string) error
 	DeleteManyById(context.Context, ...int) error
+	ArchiveManyById(ctx context.Context, status string, ids ...int) error
+	GetLastFinishedByOrderId(context.Context, string) (model.FinishedDelivery, error)
+	GetStatesByOrderIds(ctx context.Context, orderIds []string) (map[string]string, error)
+	GetActiveOrderIds(ctx context.Context, after string, limit int) ([]string, error)
+	MarkAtRisk(ctx context.Context, fraction float64, now time.Time) ([]model.Delivery, error)
+	MarkBreached(ctx context.Context, now time.Time) ([]model.Delivery, error)
+	CountBySLAState(context.Context) (map[string]int, error)
+	UpdateStatus(ctx context.Context, id int, from, to string) error
+	AddEventManyById(ctx context.Context, status string, at time.Time, ids ...int) error
+	GetEventsByOrderId(context.Context, string) ([]model.DeliveryEvent, error)
+	ReserveHandoffAttempt(ctx context.Context, id int, now time.Time, maxAttempts int, lockUntil time.Time) error
+	ReleaseHandoffAttempt(ctx context.Context, id int, maxAttempts int, lockUntil time.Time) error
+}
+
+type FeedbackRepository interface {
+	Create(context.Context, model.Feedback) (int, error)
+}
+
+type ProofRepository interface {
+	Create(context.Context, model.DeliveryProof) (int, error)
+}
+
+type StatsRepository interface {
+	GetDeliveryStats(ctx context.Context, courierId int, from, to time.Time) ([]model.DeliveryStats, error)
+	GetActiveSeconds(ctx context.Context, courierId int, from, to, now time.Time) (float64, error)
+	GetCourierCounts(context.Context) ([]model.CourierGroupCount, error)
+	CountActiveDeliveries(context.Context) (int, error)
+}
+
+// EventPublisher публикует события сервиса в брокер сообщений
+type EventPublisher interface {
+	Publish(context.Context, model.Event) error
+}
+
+// BlobStorage хранилище файлов. Ключ выбирает сервис, Put возвращает количество записанных байт
+type BlobStorage interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// PhoneNormalizer приводит телефон к формату E.164 и проверяет, что страна номера разрешена
+type PhoneNormalizer interface {
+	Normalize(string) (string, error)
+}
+
+// DeliveryMetrics бизнес-метрики доставок. Считаются в сервисе, а не в хендлерах: назначение приходит и из HTTP, и из Kafka.
+// status в AddDeliveriesFinished - статус доставки в delivery_history (completed, cancelled, expired)
+type DeliveryMetrics interface {
+	IncDeliveriesCreated()
+	AddDeliveriesFinished(status string, n int)
+	ObserveAssignment(latency time.Duration)
+	ObserveTimeToAssign(time.Duration)
+	IncNoAvailableCouriers()
 }
 
 type DeliveryTimeCalculator interface {
@@ -6,8 +6,9 @@ package mock_dep
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
-	"service-order-avito/internal/domain/model"
+	model "service-order-avito/internal/domain/model"
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
@@ -73,6 +74,22 @@ func (m *MockCourierRepository) EXPECT() *MockCourierRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddRating mocks base method.
+func (m *MockCourierRepository) AddRating(ctx context.Context, id, rating int) (float64, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRating", ctx, id, rating)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddRating indicates an expected call of AddRating.
+func (mr *MockCourierRepositoryMockRecorder) AddRating(ctx, id, rating interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRating", reflect.TypeOf((*MockCourierRepository)(nil).AddRating), ctx, id, rating)
+}
+
 // Create mocks base method.
 func (m *MockCourierRepository) Create(arg0 context.Context, arg1 model.Courier) (int, error) {
 	m.ctrl.T.Helper()
@@ -88,6 +105,21 @@ func (mr *MockCourierRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCourierRepository)(nil).Create), arg0, arg1)
 }
 
+// CreateMany mocks base method.
+func (m *MockCourierRepository) CreateMany(arg0 context.Context, arg1 []model.Courier) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockCourierRepositoryMockRecorder) CreateMany(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockCourierRepository)(nil).CreateMany), arg0, arg1)
+}
+
 // DeleteById mocks base method.
 func (m *MockCourierRepository) DeleteById(arg0 context.Context, arg1 int) error {
 	m.ctrl.T.Helper()
@@ -118,18 +150,18 @@ func (mr *MockCourierRepositoryMockRecorder) GetAll(arg0 interface{}) *gomock.Ca
 }
 
 // GetAvailable mocks base method.
-func (m *MockCourierRepository) GetAvailable(arg0 context.Context) (model.Courier, error) {
+func (m *MockCourierRepository) GetAvailable(arg0 context.Context, arg1 model.RatingPolicy) (model.Courier, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAvailable", arg0)
+	ret := m.ctrl.Call(m, "GetAvailable", arg0, arg1)
 	ret0, _ := ret[0].(model.Courier)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAvailable indicates an expected call of GetAvailable.
-func (mr *MockCourierRepositoryMockRecorder) GetAvailable(arg0 interface{}) *gomock.Call {
+func (mr *MockCourierRepositoryMockRecorder) GetAvailable(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailable", reflect.TypeOf((*MockCourierRepository)(nil).GetAvailable), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailable", reflect.TypeOf((*MockCourierRepository)(nil).GetAvailable), arg0, arg1)
 }
 
 // GetById mocks base method.
@@ -147,6 +179,35 @@ func (mr *MockCourierRepositoryMockRecorder) GetById(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockCourierRepository)(nil).GetById), arg0, arg1)
 }
 
+// GetExistingPhones mocks base method.
+func (m *MockCourierRepository) GetExistingPhones(arg0 context.Context, arg1 []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExistingPhones", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExistingPhones indicates an expected call of GetExistingPhones.
+func (mr *MockCourierRepositoryMockRecorder) GetExistingPhones(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExistingPhones", reflect.TypeOf((*MockCourierRepository)(nil).GetExistingPhones), arg0, arg1)
+}
+
+// StreamAll mocks base method.
+func (m *MockCourierRepository) StreamAll(arg0 context.Context, arg1 func(model.Courier) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAll", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAll indicates an expected call of StreamAll.
+func (mr *MockCourierRepositoryMockRecorder) StreamAll(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAll", reflect.TypeOf((*MockCourierRepository)(nil).StreamAll), arg0, arg1)
+}
+
 // Update mocks base method.
 func (m *MockCourierRepository) Update(arg0 context.Context, arg1 model.Courier) error {
 	m.ctrl.T.Helper()
@@ -180,6 +241,20 @@ func (mr *MockCourierRepositoryMockRecorder) UpdateStatusManyById(arg0 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusManyById", reflect.TypeOf((*MockCourierRepository)(nil).UpdateStatusManyById), varargs...)
 }
 
+// UpdateStatusUnlessBusy mocks base method.
+func (m *MockCourierRepository) UpdateStatusUnlessBusy(ctx context.Context, id int, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusUnlessBusy", ctx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatusUnlessBusy indicates an expected call of UpdateStatusUnlessBusy.
+func (mr *MockCourierRepositoryMockRecorder) UpdateStatusUnlessBusy(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusUnlessBusy", reflect.TypeOf((*MockCourierRepository)(nil).UpdateStatusUnlessBusy), ctx, id, status)
+}
+
 // MockDeliveryRepository is a mock of DeliveryRepository interface.
 type MockDeliveryRepository struct {
 	ctrl     *gomock.Controller
@@ -203,6 +278,59 @@ func (m *MockDeliveryRepository) EXPECT() *MockDeliveryRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddEventManyById mocks base method.
+func (m *MockDeliveryRepository) AddEventManyById(ctx context.Context, status string, at time.Time, ids ...int) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, status, at}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddEventManyById", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEventManyById indicates an expected call of AddEventManyById.
+func (mr *MockDeliveryRepositoryMockRecorder) AddEventManyById(ctx, status, at interface{}, ids ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, status, at}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEventManyById", reflect.TypeOf((*MockDeliveryRepository)(nil).AddEventManyById), varargs...)
+}
+
+// ArchiveManyById mocks base method.
+func (m *MockDeliveryRepository) ArchiveManyById(ctx context.Context, status string, ids ...int) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, status}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ArchiveManyById", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveManyById indicates an expected call of ArchiveManyById.
+func (mr *MockDeliveryRepositoryMockRecorder) ArchiveManyById(ctx, status interface{}, ids ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, status}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveManyById", reflect.TypeOf((*MockDeliveryRepository)(nil).ArchiveManyById), varargs...)
+}
+
+// CountBySLAState mocks base method.
+func (m *MockDeliveryRepository) CountBySLAState(arg0 context.Context) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountBySLAState", arg0)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountBySLAState indicates an expected call of CountBySLAState.
+func (mr *MockDeliveryRepositoryMockRecorder) CountBySLAState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountBySLAState", reflect.TypeOf((*MockDeliveryRepository)(nil).CountBySLAState), arg0)
+}
+
 // Create mocks base method.
 func (m *MockDeliveryRepository) Create(arg0 context.Context, arg1 model.Delivery) (int, error) {
 	m.ctrl.T.Helper()
@@ -251,19 +379,34 @@ func (mr *MockDeliveryRepositoryMockRecorder) DeleteManyById(arg0 interface{}, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteManyById", reflect.TypeOf((*MockDeliveryRepository)(nil).DeleteManyById), varargs...)
 }
 
+// GetActiveOrderIds mocks base method.
+func (m *MockDeliveryRepository) GetActiveOrderIds(ctx context.Context, after string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveOrderIds", ctx, after, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveOrderIds indicates an expected call of GetActiveOrderIds.
+func (mr *MockDeliveryRepositoryMockRecorder) GetActiveOrderIds(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveOrderIds", reflect.TypeOf((*MockDeliveryRepository)(nil).GetActiveOrderIds), ctx, after, limit)
+}
+
 // GetAllCompleted mocks base method.
-func (m *MockDeliveryRepository) GetAllCompleted(arg0 context.Context) ([]model.Delivery, error) {
+func (m *MockDeliveryRepository) GetAllCompleted(ctx context.Context, before time.Time) ([]model.Delivery, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllCompleted", arg0)
+	ret := m.ctrl.Call(m, "GetAllCompleted", ctx, before)
 	ret0, _ := ret[0].([]model.Delivery)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllCompleted indicates an expected call of GetAllCompleted.
-func (mr *MockDeliveryRepositoryMockRecorder) GetAllCompleted(arg0 interface{}) *gomock.Call {
+func (mr *MockDeliveryRepositoryMockRecorder) GetAllCompleted(ctx, before interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCompleted", reflect.TypeOf((*MockDeliveryRepository)(nil).GetAllCompleted), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCompleted", reflect.TypeOf((*MockDeliveryRepository)(nil).GetAllCompleted), ctx, before)
 }
 
 // GetByOrderId mocks base method.
@@ -281,6 +424,492 @@ func (mr *MockDeliveryRepositoryMockRecorder) GetByOrderId(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderId", reflect.TypeOf((*MockDeliveryRepository)(nil).GetByOrderId), arg0, arg1)
 }
 
+// GetEventsByOrderId mocks base method.
+func (m *MockDeliveryRepository) GetEventsByOrderId(arg0 context.Context, arg1 string) ([]model.DeliveryEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventsByOrderId", arg0, arg1)
+	ret0, _ := ret[0].([]model.DeliveryEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventsByOrderId indicates an expected call of GetEventsByOrderId.
+func (mr *MockDeliveryRepositoryMockRecorder) GetEventsByOrderId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventsByOrderId", reflect.TypeOf((*MockDeliveryRepository)(nil).GetEventsByOrderId), arg0, arg1)
+}
+
+// GetLastFinishedByOrderId mocks base method.
+func (m *MockDeliveryRepository) GetLastFinishedByOrderId(arg0 context.Context, arg1 string) (model.FinishedDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastFinishedByOrderId", arg0, arg1)
+	ret0, _ := ret[0].(model.FinishedDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastFinishedByOrderId indicates an expected call of GetLastFinishedByOrderId.
+func (mr *MockDeliveryRepositoryMockRecorder) GetLastFinishedByOrderId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastFinishedByOrderId", reflect.TypeOf((*MockDeliveryRepository)(nil).GetLastFinishedByOrderId), arg0, arg1)
+}
+
+// GetStatesByOrderIds mocks base method.
+func (m *MockDeliveryRepository) GetStatesByOrderIds(ctx context.Context, orderIds []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatesByOrderIds", ctx, orderIds)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatesByOrderIds indicates an expected call of GetStatesByOrderIds.
+func (mr *MockDeliveryRepositoryMockRecorder) GetStatesByOrderIds(ctx, orderIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatesByOrderIds", reflect.TypeOf((*MockDeliveryRepository)(nil).GetStatesByOrderIds), ctx, orderIds)
+}
+
+// MarkAtRisk mocks base method.
+func (m *MockDeliveryRepository) MarkAtRisk(ctx context.Context, fraction float64, now time.Time) ([]model.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAtRisk", ctx, fraction, now)
+	ret0, _ := ret[0].([]model.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAtRisk indicates an expected call of MarkAtRisk.
+func (mr *MockDeliveryRepositoryMockRecorder) MarkAtRisk(ctx, fraction, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAtRisk", reflect.TypeOf((*MockDeliveryRepository)(nil).MarkAtRisk), ctx, fraction, now)
+}
+
+// MarkBreached mocks base method.
+func (m *MockDeliveryRepository) MarkBreached(ctx context.Context, now time.Time) ([]model.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkBreached", ctx, now)
+	ret0, _ := ret[0].([]model.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkBreached indicates an expected call of MarkBreached.
+func (mr *MockDeliveryRepositoryMockRecorder) MarkBreached(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkBreached", reflect.TypeOf((*MockDeliveryRepository)(nil).MarkBreached), ctx, now)
+}
+
+// ReleaseHandoffAttempt mocks base method.
+func (m *MockDeliveryRepository) ReleaseHandoffAttempt(ctx context.Context, id, maxAttempts int, lockUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHandoffAttempt", ctx, id, maxAttempts, lockUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseHandoffAttempt indicates an expected call of ReleaseHandoffAttempt.
+func (mr *MockDeliveryRepositoryMockRecorder) ReleaseHandoffAttempt(ctx, id, maxAttempts, lockUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHandoffAttempt", reflect.TypeOf((*MockDeliveryRepository)(nil).ReleaseHandoffAttempt), ctx, id, maxAttempts, lockUntil)
+}
+
+// ReserveHandoffAttempt mocks base method.
+func (m *MockDeliveryRepository) ReserveHandoffAttempt(ctx context.Context, id int, now time.Time, maxAttempts int, lockUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveHandoffAttempt", ctx, id, now, maxAttempts, lockUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveHandoffAttempt indicates an expected call of ReserveHandoffAttempt.
+func (mr *MockDeliveryRepositoryMockRecorder) ReserveHandoffAttempt(ctx, id, now, maxAttempts, lockUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveHandoffAttempt", reflect.TypeOf((*MockDeliveryRepository)(nil).ReserveHandoffAttempt), ctx, id, now, maxAttempts, lockUntil)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockDeliveryRepository) UpdateStatus(ctx context.Context, id int, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockDeliveryRepositoryMockRecorder) UpdateStatus(ctx, id, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockDeliveryRepository)(nil).UpdateStatus), ctx, id, from, to)
+}
+
+// MockFeedbackRepository is a mock of FeedbackRepository interface.
+type MockFeedbackRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedbackRepositoryMockRecorder
+}
+
+// MockFeedbackRepositoryMockRecorder is the mock recorder for MockFeedbackRepository.
+type MockFeedbackRepositoryMockRecorder struct {
+	mock *MockFeedbackRepository
+}
+
+// NewMockFeedbackRepository creates a new mock instance.
+func NewMockFeedbackRepository(ctrl *gomock.Controller) *MockFeedbackRepository {
+	mock := &MockFeedbackRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedbackRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedbackRepository) EXPECT() *MockFeedbackRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFeedbackRepository) Create(arg0 context.Context, arg1 model.Feedback) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFeedbackRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFeedbackRepository)(nil).Create), arg0, arg1)
+}
+
+// MockProofRepository is a mock of ProofRepository interface.
+type MockProofRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProofRepositoryMockRecorder
+}
+
+// MockProofRepositoryMockRecorder is the mock recorder for MockProofRepository.
+type MockProofRepositoryMockRecorder struct {
+	mock *MockProofRepository
+}
+
+// NewMockProofRepository creates a new mock instance.
+func NewMockProofRepository(ctrl *gomock.Controller) *MockProofRepository {
+	mock := &MockProofRepository{ctrl: ctrl}
+	mock.recorder = &MockProofRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProofRepository) EXPECT() *MockProofRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockProofRepository) Create(arg0 context.Context, arg1 model.DeliveryProof) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProofRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProofRepository)(nil).Create), arg0, arg1)
+}
+
+// MockStatsRepository is a mock of StatsRepository interface.
+type MockStatsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsRepositoryMockRecorder
+}
+
+// MockStatsRepositoryMockRecorder is the mock recorder for MockStatsRepository.
+type MockStatsRepositoryMockRecorder struct {
+	mock *MockStatsRepository
+}
+
+// NewMockStatsRepository creates a new mock instance.
+func NewMockStatsRepository(ctrl *gomock.Controller) *MockStatsRepository {
+	mock := &MockStatsRepository{ctrl: ctrl}
+	mock.recorder = &MockStatsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsRepository) EXPECT() *MockStatsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountActiveDeliveries mocks base method.
+func (m *MockStatsRepository) CountActiveDeliveries(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountActiveDeliveries", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountActiveDeliveries indicates an expected call of CountActiveDeliveries.
+func (mr *MockStatsRepositoryMockRecorder) CountActiveDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountActiveDeliveries", reflect.TypeOf((*MockStatsRepository)(nil).CountActiveDeliveries), arg0)
+}
+
+// GetActiveSeconds mocks base method.
+func (m *MockStatsRepository) GetActiveSeconds(ctx context.Context, courierId int, from, to, now time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveSeconds", ctx, courierId, from, to, now)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveSeconds indicates an expected call of GetActiveSeconds.
+func (mr *MockStatsRepositoryMockRecorder) GetActiveSeconds(ctx, courierId, from, to, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveSeconds", reflect.TypeOf((*MockStatsRepository)(nil).GetActiveSeconds), ctx, courierId, from, to, now)
+}
+
+// GetCourierCounts mocks base method.
+func (m *MockStatsRepository) GetCourierCounts(arg0 context.Context) ([]model.CourierGroupCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCourierCounts", arg0)
+	ret0, _ := ret[0].([]model.CourierGroupCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCourierCounts indicates an expected call of GetCourierCounts.
+func (mr *MockStatsRepositoryMockRecorder) GetCourierCounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCourierCounts", reflect.TypeOf((*MockStatsRepository)(nil).GetCourierCounts), arg0)
+}
+
+// GetDeliveryStats mocks base method.
+func (m *MockStatsRepository) GetDeliveryStats(ctx context.Context, courierId int, from, to time.Time) ([]model.DeliveryStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryStats", ctx, courierId, from, to)
+	ret0, _ := ret[0].([]model.DeliveryStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryStats indicates an expected call of GetDeliveryStats.
+func (mr *MockStatsRepositoryMockRecorder) GetDeliveryStats(ctx, courierId, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryStats", reflect.TypeOf((*MockStatsRepository)(nil).GetDeliveryStats), ctx, courierId, from, to)
+}
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(arg0 context.Context, arg1 model.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), arg0, arg1)
+}
+
+// MockBlobStorage is a mock of BlobStorage interface.
+type MockBlobStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobStorageMockRecorder
+}
+
+// MockBlobStorageMockRecorder is the mock recorder for MockBlobStorage.
+type MockBlobStorageMockRecorder struct {
+	mock *MockBlobStorage
+}
+
+// NewMockBlobStorage creates a new mock instance.
+func NewMockBlobStorage(ctrl *gomock.Controller) *MockBlobStorage {
+	mock := &MockBlobStorage{ctrl: ctrl}
+	mock.recorder = &MockBlobStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobStorage) EXPECT() *MockBlobStorageMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockBlobStorage) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBlobStorageMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBlobStorage)(nil).Delete), ctx, key)
+}
+
+// Put mocks base method.
+func (m *MockBlobStorage) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, key, r)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockBlobStorageMockRecorder) Put(ctx, key, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobStorage)(nil).Put), ctx, key, r)
+}
+
+// MockPhoneNormalizer is a mock of PhoneNormalizer interface.
+type MockPhoneNormalizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockPhoneNormalizerMockRecorder
+}
+
+// MockPhoneNormalizerMockRecorder is the mock recorder for MockPhoneNormalizer.
+type MockPhoneNormalizerMockRecorder struct {
+	mock *MockPhoneNormalizer
+}
+
+// NewMockPhoneNormalizer creates a new mock instance.
+func NewMockPhoneNormalizer(ctrl *gomock.Controller) *MockPhoneNormalizer {
+	mock := &MockPhoneNormalizer{ctrl: ctrl}
+	mock.recorder = &MockPhoneNormalizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPhoneNormalizer) EXPECT() *MockPhoneNormalizerMockRecorder {
+	return m.recorder
+}
+
+// Normalize mocks base method.
+func (m *MockPhoneNormalizer) Normalize(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Normalize", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Normalize indicates an expected call of Normalize.
+func (mr *MockPhoneNormalizerMockRecorder) Normalize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Normalize", reflect.TypeOf((*MockPhoneNormalizer)(nil).Normalize), arg0)
+}
+
+// MockDeliveryMetrics is a mock of DeliveryMetrics interface.
+type MockDeliveryMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryMetricsMockRecorder
+}
+
+// MockDeliveryMetricsMockRecorder is the mock recorder for MockDeliveryMetrics.
+type MockDeliveryMetricsMockRecorder struct {
+	mock *MockDeliveryMetrics
+}
+
+// NewMockDeliveryMetrics creates a new mock instance.
+func NewMockDeliveryMetrics(ctrl *gomock.Controller) *MockDeliveryMetrics {
+	mock := &MockDeliveryMetrics{ctrl: ctrl}
+	mock.recorder = &MockDeliveryMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryMetrics) EXPECT() *MockDeliveryMetricsMockRecorder {
+	return m.recorder
+}
+
+// AddDeliveriesFinished mocks base method.
+func (m *MockDeliveryMetrics) AddDeliveriesFinished(status string, n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddDeliveriesFinished", status, n)
+}
+
+// AddDeliveriesFinished indicates an expected call of AddDeliveriesFinished.
+func (mr *MockDeliveryMetricsMockRecorder) AddDeliveriesFinished(status, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDeliveriesFinished", reflect.TypeOf((*MockDeliveryMetrics)(nil).AddDeliveriesFinished), status, n)
+}
+
+// IncDeliveriesCreated mocks base method.
+func (m *MockDeliveryMetrics) IncDeliveriesCreated() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncDeliveriesCreated")
+}
+
+// IncDeliveriesCreated indicates an expected call of IncDeliveriesCreated.
+func (mr *MockDeliveryMetricsMockRecorder) IncDeliveriesCreated() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncDeliveriesCreated", reflect.TypeOf((*MockDeliveryMetrics)(nil).IncDeliveriesCreated))
+}
+
+// IncNoAvailableCouriers mocks base method.
+func (m *MockDeliveryMetrics) IncNoAvailableCouriers() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncNoAvailableCouriers")
+}
+
+// IncNoAvailableCouriers indicates an expected call of IncNoAvailableCouriers.
+func (mr *MockDeliveryMetricsMockRecorder) IncNoAvailableCouriers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncNoAvailableCouriers", reflect.TypeOf((*MockDeliveryMetrics)(nil).IncNoAvailableCouriers))
+}
+
+// ObserveAssignment mocks base method.
+func (m *MockDeliveryMetrics) ObserveAssignment(latency time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveAssignment", latency)
+}
+
+// ObserveAssignment indicates an expected call of ObserveAssignment.
+func (mr *MockDeliveryMetricsMockRecorder) ObserveAssignment(latency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveAssignment", reflect.TypeOf((*MockDeliveryMetrics)(nil).ObserveAssignment), latency)
+}
+
+// ObserveTimeToAssign mocks base method.
+func (m *MockDeliveryMetrics) ObserveTimeToAssign(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveTimeToAssign", arg0)
+}
+
+// ObserveTimeToAssign indicates an expected call of ObserveTimeToAssign.
+func (mr *MockDeliveryMetricsMockRecorder) ObserveTimeToAssign(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveTimeToAssign", reflect.TypeOf((*MockDeliveryMetrics)(nil).ObserveTimeToAssign), arg0)
+}
+
 // MockDeliveryTimeCalculator is a mock of DeliveryTimeCalculator interface.
 type MockDeliveryTimeCalculator struct {
 	ctrl     *gomock.Controller
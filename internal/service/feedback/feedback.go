@@ -0,0 +1,157 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/service/dep"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxCommentLen = 1000
+
+var allowedTags = map[string]bool{
+	model.FeedbackTagLate:    true,
+	model.FeedbackTagRude:    true,
+	model.FeedbackTagDamaged: true,
+}
+
+type feedbackService struct {
+	tm       dep.TransactionManager
+	delRepo  dep.DeliveryRepository
+	courRepo dep.CourierRepository
+	repo     dep.FeedbackRepository
+	events   dep.EventPublisher
+	rating   model.RatingPolicy
+}
+
+func NewFeedbackService(tm dep.TransactionManager,
+	delRepo dep.DeliveryRepository,
+	courRepo dep.CourierRepository,
+	repo dep.FeedbackRepository,
+	events dep.EventPublisher,
+	rating model.RatingPolicy,
+) *feedbackService {
+	return &feedbackService{tm: tm, delRepo: delRepo, courRepo: courRepo, repo: repo, events: events, rating: rating}
+}
+
+// LeaveFeedback сохраняет отзыв о завершенной доставке и пересчитывает рейтинг курьера.
+// Отзыв можно оставить только один раз и только на доставку, которая завершилась успешно.
+// Если оценка низкая или средняя оценка курьера упала ниже порога, в поток событий уходит алерт
+func (fs *feedbackService) LeaveFeedback(ctx context.Context, req *dto.LeaveFeedbackRequest) (*dto.LeaveFeedbackResponse, error) {
+	tags, comment, err := validateFeedback(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// история доставок не меняется, поэтому проверять ее можно вне транзакции.
+	// повторный отзыв все равно не пройдет из-за UNIQUE на order_id
+	delivery, err := fs.delRepo.GetLastFinishedByOrderId(ctx, req.OrderId)
+	if err != nil {
+		if !errors.Is(err, repository.ErrDeliveryNotFound) {
+			return nil, adapters.ErrUnwrapRepoToService(err)
+		}
+		// в истории заказа нет, но он может быть еще в работе
+		if _, activeErr := fs.delRepo.GetByOrderId(ctx, req.OrderId); activeErr == nil {
+			return nil, service.ErrDeliveryNotCompleted
+		}
+		return nil, service.ErrDeliveryNotFound
+	}
+	if delivery.Status != model.StatusCompleted {
+		return nil, service.ErrDeliveryNotCompleted
+	}
+
+	feedback := model.Feedback{
+		OrderId:   req.OrderId,
+		CourierId: delivery.CourierId,
+		Rating:    req.Rating,
+		Tags:      tags,
+		Comment:   comment,
+	}
+
+	var avg float64
+	var count int
+	err = fs.tm.Begin(ctx, func(ctx context.Context) error {
+		if _, err := fs.repo.Create(ctx, feedback); err != nil {
+			return err
+		}
+
+		var err error
+		avg, count, err = fs.courRepo.AddRating(ctx, delivery.CourierId, req.Rating)
+		return err
+	})
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	lowAverage := fs.rating.IsLow(avg, count)
+	if req.Rating <= fs.rating.AlertRating || lowAverage {
+		// алерт не должен ломать сохранение отзыва: ошибку публикации логирует сам publisher
+		_ = fs.events.Publish(ctx, model.Event{
+			Type: model.EventCourierLowRating,
+			Key:  strconv.Itoa(delivery.CourierId),
+			Payload: dto.CourierLowRatingEvent{
+				CourierId:     delivery.CourierId,
+				OrderId:       req.OrderId,
+				Rating:        req.Rating,
+				Tags:          tags,
+				Comment:       comment,
+				CourierRating: avg,
+				RatingCount:   count,
+				LowAverage:    lowAverage,
+			},
+			OccurredAt: time.Now(),
+		})
+	}
+
+	return &dto.LeaveFeedbackResponse{
+		OrderId:            req.OrderId,
+		CourierId:          delivery.CourierId,
+		Rating:             req.Rating,
+		Tags:               tags,
+		Comment:            comment,
+		CourierRating:      avg,
+		CourierRatingCount: count,
+	}, nil
+}
+
+// validateFeedback проверяет оценку, теги и комментарий. Теги приводятся к нижнему регистру, дубли убираются
+func validateFeedback(req *dto.LeaveFeedbackRequest) ([]string, string, error) {
+	var verr service.ValidationError
+	if req.Rating < 1 || req.Rating > 5 {
+		verr.Add("rating", service.ErrInvalidRating)
+	}
+
+	tags := make([]string, 0, len(req.Tags))
+	seen := make(map[string]bool, len(req.Tags))
+	for i, tag := range req.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !allowedTags[tag] {
+			verr.Add(fmt.Sprintf("tags[%d]", i), service.ErrInvalidFeedbackTag)
+			continue
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(comment) > maxCommentLen {
+		verr.Add("comment", service.ErrFeedbackCommentTooLong)
+	}
+
+	if err := verr.Err(); err != nil {
+		return nil, "", err
+	}
+	return tags, comment, nil
+}
@@ -0,0 +1,244 @@
+package feedback
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"strings"
+	"testing"
+)
+
+var testRating = model.RatingPolicy{LowThreshold: 3.5, MinCount: 3, AlertRating: 2}
+
+func expectTx(tm *mock_dep.MockTransactionManager) {
+	tm.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+}
+
+func TestFeedbackService_LeaveFeedback_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockFeedbackRepo := mock_dep.NewMockFeedbackRepository(ctrl)
+
+	fs := NewFeedbackService(mockTM, mockDeliveryRepo, mockCourierRepo, mockFeedbackRepo, mock_dep.NewMockEventPublisher(ctrl), testRating)
+	expectTx(mockTM)
+
+	req := &dto.LeaveFeedbackRequest{
+		OrderId: "ORDER-1",
+		Rating:  5,
+		Tags:    []string{" Late ", "late"},
+		Comment: "  fast anyway ",
+	}
+
+	mockDeliveryRepo.EXPECT().GetLastFinishedByOrderId(gomock.Any(), "ORDER-1").
+		Return(model.FinishedDelivery{CourierId: 7, OrderId: "ORDER-1", Status: model.StatusCompleted}, nil)
+	mockFeedbackRepo.EXPECT().Create(gomock.Any(), model.Feedback{
+		OrderId:   "ORDER-1",
+		CourierId: 7,
+		Rating:    5,
+		Tags:      []string{"late"},
+		Comment:   "fast anyway",
+	}).Return(1, nil)
+	mockCourierRepo.EXPECT().AddRating(gomock.Any(), 7, 5).Return(4.5, 10, nil)
+
+	resp, err := fs.LeaveFeedback(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Equal(t, 7, resp.CourierId)
+	require.Equal(t, []string{"late"}, resp.Tags)
+	require.Equal(t, 4.5, resp.CourierRating)
+	require.Equal(t, 10, resp.CourierRatingCount)
+}
+
+func TestFeedbackService_LeaveFeedback_LowRatingAlert(t *testing.T) {
+	tests := []struct {
+		name           string
+		rating         int
+		avg            float64
+		count          int
+		wantLowAverage bool
+	}{
+		{name: "low rating, good average", rating: 1, avg: 4.6, count: 20},
+		{name: "low average", rating: 3, avg: 3.2, count: 5, wantLowAverage: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTM := mock_dep.NewMockTransactionManager(ctrl)
+			mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+			mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+			mockFeedbackRepo := mock_dep.NewMockFeedbackRepository(ctrl)
+			mockEvents := mock_dep.NewMockEventPublisher(ctrl)
+
+			fs := NewFeedbackService(mockTM, mockDeliveryRepo, mockCourierRepo, mockFeedbackRepo, mockEvents, testRating)
+			expectTx(mockTM)
+
+			mockDeliveryRepo.EXPECT().GetLastFinishedByOrderId(gomock.Any(), "ORDER-1").
+				Return(model.FinishedDelivery{CourierId: 7, Status: model.StatusCompleted}, nil)
+			mockFeedbackRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
+			mockCourierRepo.EXPECT().AddRating(gomock.Any(), 7, tt.rating).Return(tt.avg, tt.count, nil)
+
+			mockEvents.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, event model.Event) error {
+					require.Equal(t, model.EventCourierLowRating, event.Type)
+					require.Equal(t, "7", event.Key)
+
+					payload, ok := event.Payload.(dto.CourierLowRatingEvent)
+					require.True(t, ok)
+					require.Equal(t, tt.rating, payload.Rating)
+					require.Equal(t, tt.wantLowAverage, payload.LowAverage)
+					return nil
+				},
+			)
+
+			_, err := fs.LeaveFeedback(context.Background(), &dto.LeaveFeedbackRequest{OrderId: "ORDER-1", Rating: tt.rating})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFeedbackService_LeaveFeedback_PublishErrorIgnored(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockFeedbackRepo := mock_dep.NewMockFeedbackRepository(ctrl)
+	mockEvents := mock_dep.NewMockEventPublisher(ctrl)
+
+	fs := NewFeedbackService(mockTM, mockDeliveryRepo, mockCourierRepo, mockFeedbackRepo, mockEvents, testRating)
+	expectTx(mockTM)
+
+	mockDeliveryRepo.EXPECT().GetLastFinishedByOrderId(gomock.Any(), "ORDER-1").
+		Return(model.FinishedDelivery{CourierId: 7, Status: model.StatusCompleted}, nil)
+	mockFeedbackRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
+	mockCourierRepo.EXPECT().AddRating(gomock.Any(), 7, 1).Return(1.0, 1, nil)
+	mockEvents.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(repository.ErrInternalError)
+
+	resp, err := fs.LeaveFeedback(context.Background(), &dto.LeaveFeedbackRequest{OrderId: "ORDER-1", Rating: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, resp.Rating)
+}
+
+func TestFeedbackService_LeaveFeedback_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     dto.LeaveFeedbackRequest
+		wantErr error
+	}{
+		{name: "rating too low", req: dto.LeaveFeedbackRequest{Rating: 0}, wantErr: service.ErrInvalidRating},
+		{name: "rating too high", req: dto.LeaveFeedbackRequest{Rating: 6}, wantErr: service.ErrInvalidRating},
+		{name: "unknown tag", req: dto.LeaveFeedbackRequest{Rating: 3, Tags: []string{"slow"}}, wantErr: service.ErrInvalidFeedbackTag},
+		{
+			name:    "comment too long",
+			req:     dto.LeaveFeedbackRequest{Rating: 3, Comment: strings.Repeat("я", maxCommentLen+1)},
+			wantErr: service.ErrFeedbackCommentTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fs := NewFeedbackService(mock_dep.NewMockTransactionManager(ctrl), mock_dep.NewMockDeliveryRepository(ctrl), mock_dep.NewMockCourierRepository(ctrl), mock_dep.NewMockFeedbackRepository(ctrl), mock_dep.NewMockEventPublisher(ctrl), testRating)
+
+			_, err := fs.LeaveFeedback(context.Background(), &tt.req)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFeedbackService_LeaveFeedback_DeliveryErrors(t *testing.T) {
+	t.Run("delivery still active", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		fs := NewFeedbackService(mock_dep.NewMockTransactionManager(ctrl), mockDeliveryRepo, mock_dep.NewMockCourierRepository(ctrl), mock_dep.NewMockFeedbackRepository(ctrl), mock_dep.NewMockEventPublisher(ctrl), testRating)
+
+		mockDeliveryRepo.EXPECT().GetLastFinishedByOrderId(gomock.Any(), "ORDER-1").
+			Return(model.FinishedDelivery{}, repository.ErrDeliveryNotFound)
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{Id: 1}, nil)
+
+		_, err := fs.LeaveFeedback(context.Background(), &dto.LeaveFeedbackRequest{OrderId: "ORDER-1", Rating: 4})
+		require.ErrorIs(t, err, service.ErrDeliveryNotCompleted)
+	})
+
+	t.Run("delivery not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		fs := NewFeedbackService(mock_dep.NewMockTransactionManager(ctrl), mockDeliveryRepo, mock_dep.NewMockCourierRepository(ctrl), mock_dep.NewMockFeedbackRepository(ctrl), mock_dep.NewMockEventPublisher(ctrl), testRating)
+
+		mockDeliveryRepo.EXPECT().GetLastFinishedByOrderId(gomock.Any(), "ORDER-1").
+			Return(model.FinishedDelivery{}, repository.ErrDeliveryNotFound)
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{}, repository.ErrDeliveryNotFound)
+
+		_, err := fs.LeaveFeedback(context.Background(), &dto.LeaveFeedbackRequest{OrderId: "ORDER-1", Rating: 4})
+		require.ErrorIs(t, err, service.ErrDeliveryNotFound)
+	})
+
+	t.Run("delivery cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		fs := NewFeedbackService(mock_dep.NewMockTransactionManager(ctrl), mockDeliveryRepo, mock_dep.NewMockCourierRepository(ctrl), mock_dep.NewMockFeedbackRepository(ctrl), mock_dep.NewMockEventPublisher(ctrl), testRating)
+
+		mockDeliveryRepo.EXPECT().GetLastFinishedByOrderId(gomock.Any(), "ORDER-1").
+			Return(model.FinishedDelivery{Status: model.StatusCancelled}, nil)
+
+		_, err := fs.LeaveFeedback(context.Background(), &dto.LeaveFeedbackRequest{OrderId: "ORDER-1", Rating: 4})
+		require.ErrorIs(t, err, service.ErrDeliveryNotCompleted)
+	})
+
+	t.Run("feedback already exists", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTM := mock_dep.NewMockTransactionManager(ctrl)
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		mockFeedbackRepo := mock_dep.NewMockFeedbackRepository(ctrl)
+
+		fs := NewFeedbackService(mockTM, mockDeliveryRepo, mock_dep.NewMockCourierRepository(ctrl), mockFeedbackRepo, mock_dep.NewMockEventPublisher(ctrl), testRating)
+		expectTx(mockTM)
+
+		mockDeliveryRepo.EXPECT().GetLastFinishedByOrderId(gomock.Any(), "ORDER-1").
+			Return(model.FinishedDelivery{CourierId: 7, Status: model.StatusCompleted}, nil)
+		mockFeedbackRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(-1, repository.ErrFeedbackExists)
+
+		_, err := fs.LeaveFeedback(context.Background(), &dto.LeaveFeedbackRequest{OrderId: "ORDER-1", Rating: 4})
+		require.ErrorIs(t, err, service.ErrFeedbackExists)
+	})
+}
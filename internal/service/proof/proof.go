@@ -0,0 +1,194 @@
+package proof
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/service/dep"
+	"time"
+)
+
+// sniffLen столько байт нужно http.DetectContentType, чтобы определить тип файла
+const sniffLen = 512
+
+// по типу файла выбирается расширение ключа в хранилище
+var allowedContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type proofService struct {
+	delRepo dep.DeliveryRepository
+	repo    dep.ProofRepository
+	storage dep.BlobStorage
+	policy  model.ProofPolicy
+	now     func() time.Time
+}
+
+func NewProofService(delRepo dep.DeliveryRepository,
+	repo dep.ProofRepository,
+	storage dep.BlobStorage,
+	policy model.ProofPolicy,
+) *proofService {
+	return &proofService{delRepo: delRepo, repo: repo, storage: storage, policy: policy, now: time.Now}
+}
+
+// Upload сохраняет фото (и подпись, если есть) в blob-хранилище, а метаданные в delivery_proofs.
+// Подтверждение можно загрузить один раз и только для доставки, которая еще в работе.
+// Тип файла определяется по содержимому, Content-Type из формы не учитывается
+func (ps *proofService) Upload(ctx context.Context, req *dto.UploadProofRequest) (*dto.UploadProofResponse, error) {
+	if err := validateProof(req); err != nil {
+		return nil, err
+	}
+
+	delivery, err := ps.delRepo.GetByOrderId(ctx, req.OrderId)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+	if !model.CanAccessCourier(ctx, delivery.CourierId) {
+		return nil, service.ErrForbidden
+	}
+	if delivery.ProofAttached {
+		return nil, service.ErrProofExists
+	}
+
+	now := ps.now()
+	prefix := fmt.Sprintf("proofs/%s/%d", url.PathEscape(req.OrderId), now.UnixNano())
+
+	photo, err := ps.put(ctx, prefix+"-photo", req.Photo, ps.policy.MaxPhotoSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var signature *model.Blob
+	if req.Signature != nil {
+		signature, err = ps.put(ctx, prefix+"-signature", *req.Signature, ps.policy.MaxSignatureSize)
+		if err != nil {
+			ps.cleanup(ctx, photo)
+			return nil, err
+		}
+	}
+
+	proof := model.DeliveryProof{
+		DeliveryId: delivery.Id,
+		OrderId:    req.OrderId,
+		CourierId:  delivery.CourierId,
+		Photo:      *photo,
+		Signature:  signature,
+		PINHash:    hashPIN(ps.policy.PINSecret, delivery.Id, req.PIN),
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+	}
+	if _, err = ps.repo.Create(ctx, proof); err != nil {
+		ps.cleanup(ctx, photo, signature)
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	res := &dto.UploadProofResponse{
+		OrderId:   req.OrderId,
+		CourierId: delivery.CourierId,
+		Photo:     blobInfo(*photo),
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		CreatedAt: now,
+	}
+	if signature != nil {
+		info := blobInfo(*signature)
+		res.Signature = &info
+	}
+	return res, nil
+}
+
+// put проверяет размер и тип файла и кладет его в хранилище под ключом key + расширение
+func (ps *proofService) put(ctx context.Context, key string, file dto.ProofFile, maxSize int64) (*model.Blob, error) {
+	if file.Size > maxSize {
+		return nil, service.ErrProofFileTooLarge
+	}
+
+	br := bufio.NewReaderSize(file.Content, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, service.ErrInternalError
+	}
+
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedContentTypes[contentType]
+	if !ok {
+		return nil, service.ErrProofContentType
+	}
+
+	blob := &model.Blob{Key: key + ext, ContentType: contentType}
+
+	// размер из формы мог и соврать, поэтому читаем на байт больше лимита
+	blob.Size, err = ps.storage.Put(ctx, blob.Key, io.LimitReader(br, maxSize+1))
+	if err != nil {
+		return nil, service.ErrInternalError
+	}
+	if blob.Size > maxSize {
+		ps.cleanup(ctx, blob)
+		return nil, service.ErrProofFileTooLarge
+	}
+	return blob, nil
+}
+
+// cleanup удаляет уже загруженные файлы, если подтверждение не сохранилось. Ошибки удаления не важны:
+// файл без записи в delivery_proofs никому не виден
+func (ps *proofService) cleanup(ctx context.Context, blobs ...*model.Blob) {
+	for _, b := range blobs {
+		if b != nil {
+			_ = ps.storage.Delete(ctx, b.Key)
+		}
+	}
+}
+
+func validateProof(req *dto.UploadProofRequest) error {
+	var verr service.ValidationError
+	if !isValidPIN(req.PIN) {
+		verr.Add("pin", service.ErrInvalidProofPIN)
+	}
+
+	// NaN не попадает ни под одно сравнение, поэтому проверяем его отдельно
+	if math.IsNaN(req.Latitude) || req.Latitude < -90 || req.Latitude > 90 {
+		verr.Add("lat", service.ErrInvalidCoordinates)
+	}
+	if math.IsNaN(req.Longitude) || req.Longitude < -180 || req.Longitude > 180 {
+		verr.Add("lon", service.ErrInvalidCoordinates)
+	}
+	return verr.Err()
+}
+
+func isValidPIN(pin string) bool {
+	if len(pin) < 4 || len(pin) > 8 {
+		return false
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// hashPIN HMAC от PIN вместе с доставкой, как и код передачи: 4-8 цифр без ключа подбираются перебором
+func hashPIN(secret []byte, deliveryId int, pin string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(fmt.Sprintf("%d:%s", deliveryId, pin)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func blobInfo(b model.Blob) dto.ProofFileInfo {
+	return dto.ProofFileInfo{Key: b.Key, ContentType: b.ContentType, Size: b.Size}
+}
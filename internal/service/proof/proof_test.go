@@ -0,0 +1,245 @@
+package proof
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testPolicy = model.ProofPolicy{MaxPhotoSize: 1024, MaxSignatureSize: 64, PINSecret: []byte("secret")}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func png(size int) dto.ProofFile {
+	content := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, size-len(pngHeader))...)
+	return dto.ProofFile{Content: bytes.NewReader(content), Size: int64(size)}
+}
+
+// readAll эмулирует хранилище: вычитывает файл и возвращает его размер
+func readAll(_ context.Context, _ string, r io.Reader) (int64, error) {
+	return io.Copy(io.Discard, r)
+}
+
+func TestProofService_Upload_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	mockProofRepo := mock_dep.NewMockProofRepository(ctrl)
+	mockStorage := mock_dep.NewMockBlobStorage(ctrl)
+
+	ps := NewProofService(mockDeliveryRepo, mockProofRepo, mockStorage, testPolicy)
+	ps.now = func() time.Time { return time.Unix(0, 42) }
+
+	mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER/1").Return(model.Delivery{Id: 3, CourierId: 7, ProofRequired: true}, nil)
+	mockStorage.EXPECT().Put(gomock.Any(), "proofs/ORDER%2F1/42-photo.png", gomock.Any()).DoAndReturn(readAll)
+	mockStorage.EXPECT().Put(gomock.Any(), "proofs/ORDER%2F1/42-signature.png", gomock.Any()).DoAndReturn(readAll)
+	// PIN в базу попадает только как HMAC
+	mockProofRepo.EXPECT().Create(gomock.Any(), model.DeliveryProof{
+		DeliveryId: 3,
+		OrderId:    "ORDER/1",
+		CourierId:  7,
+		Photo:      model.Blob{Key: "proofs/ORDER%2F1/42-photo.png", ContentType: "image/png", Size: 600},
+		Signature:  &model.Blob{Key: "proofs/ORDER%2F1/42-signature.png", ContentType: "image/png", Size: 32},
+		PINHash:    hashPIN(testPolicy.PINSecret, 3, "0421"),
+		Latitude:   55.75,
+		Longitude:  37.61,
+	}).Return(1, nil)
+
+	signature := png(32)
+	resp, err := ps.Upload(context.Background(), &dto.UploadProofRequest{
+		OrderId:   "ORDER/1",
+		Photo:     png(600),
+		Signature: &signature,
+		PIN:       "0421",
+		Latitude:  55.75,
+		Longitude: 37.61,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 7, resp.CourierId)
+	require.Equal(t, int64(600), resp.Photo.Size)
+	require.NotNil(t, resp.Signature)
+	require.Equal(t, "image/png", resp.Signature.ContentType)
+}
+
+func TestHashPIN(t *testing.T) {
+	hash := hashPIN(testPolicy.PINSecret, 3, "0421")
+	require.NotContains(t, hash, "0421")
+	require.NotEqual(t, hash, hashPIN(testPolicy.PINSecret, 4, "0421"))
+	require.NotEqual(t, hash, hashPIN([]byte("other"), 3, "0421"))
+}
+
+func TestProofService_Upload_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     dto.UploadProofRequest
+		wantErr error
+	}{
+		{name: "short pin", req: dto.UploadProofRequest{PIN: "123"}, wantErr: service.ErrInvalidProofPIN},
+		{name: "pin with letters", req: dto.UploadProofRequest{PIN: "12a4"}, wantErr: service.ErrInvalidProofPIN},
+		{name: "latitude out of range", req: dto.UploadProofRequest{PIN: "1234", Latitude: 91}, wantErr: service.ErrInvalidCoordinates},
+		{name: "longitude out of range", req: dto.UploadProofRequest{PIN: "1234", Longitude: -181}, wantErr: service.ErrInvalidCoordinates},
+		{name: "nan", req: dto.UploadProofRequest{PIN: "1234", Latitude: math.NaN()}, wantErr: service.ErrInvalidCoordinates},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ps := NewProofService(mock_dep.NewMockDeliveryRepository(ctrl), mock_dep.NewMockProofRepository(ctrl), mock_dep.NewMockBlobStorage(ctrl), testPolicy)
+			ps.now = func() time.Time { return time.Unix(0, 42) }
+
+			_, err := ps.Upload(context.Background(), &tt.req)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestProofService_Upload_FileErrors(t *testing.T) {
+	t.Run("declared size too large", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mock_dep.NewMockProofRepository(ctrl), mock_dep.NewMockBlobStorage(ctrl), testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{CourierId: 7}, nil)
+
+		_, err := ps.Upload(context.Background(), &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: png(2048)})
+		require.ErrorIs(t, err, service.ErrProofFileTooLarge)
+	})
+
+	t.Run("actual size too large", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		mockStorage := mock_dep.NewMockBlobStorage(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mock_dep.NewMockProofRepository(ctrl), mockStorage, testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{CourierId: 7}, nil)
+		mockStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(readAll)
+		mockStorage.EXPECT().Delete(gomock.Any(), "proofs/ORDER-1/42-photo.png").Return(nil)
+
+		photo := png(2048)
+		photo.Size = 100
+		_, err := ps.Upload(context.Background(), &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: photo})
+		require.ErrorIs(t, err, service.ErrProofFileTooLarge)
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mock_dep.NewMockProofRepository(ctrl), mock_dep.NewMockBlobStorage(ctrl), testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{CourierId: 7}, nil)
+
+		photo := dto.ProofFile{Content: strings.NewReader("<html>definitely a photo</html>"), Size: 31}
+		_, err := ps.Upload(context.Background(), &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: photo})
+		require.ErrorIs(t, err, service.ErrProofContentType)
+	})
+
+	t.Run("bad signature removes photo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		mockStorage := mock_dep.NewMockBlobStorage(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mock_dep.NewMockProofRepository(ctrl), mockStorage, testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{CourierId: 7}, nil)
+		mockStorage.EXPECT().Put(gomock.Any(), "proofs/ORDER-1/42-photo.png", gomock.Any()).DoAndReturn(readAll)
+		mockStorage.EXPECT().Delete(gomock.Any(), "proofs/ORDER-1/42-photo.png").Return(nil)
+
+		signature := png(128)
+		_, err := ps.Upload(context.Background(), &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: png(100), Signature: &signature})
+		require.ErrorIs(t, err, service.ErrProofFileTooLarge)
+	})
+}
+
+func TestProofService_Upload_DeliveryErrors(t *testing.T) {
+	t.Run("delivery not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mock_dep.NewMockProofRepository(ctrl), mock_dep.NewMockBlobStorage(ctrl), testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{}, repository.ErrDeliveryNotFound)
+
+		_, err := ps.Upload(context.Background(), &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: png(100)})
+		require.ErrorIs(t, err, service.ErrDeliveryNotFound)
+	})
+
+	t.Run("another courier's delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mock_dep.NewMockProofRepository(ctrl), mock_dep.NewMockBlobStorage(ctrl), testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{CourierId: 7}, nil)
+
+		ctx := model.WithPrincipal(context.Background(), model.Principal{Subject: "app", Role: model.RoleCourier, CourierId: 8})
+		_, err := ps.Upload(ctx, &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: png(100)})
+		require.ErrorIs(t, err, service.ErrForbidden)
+	})
+
+	t.Run("proof already attached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mock_dep.NewMockProofRepository(ctrl), mock_dep.NewMockBlobStorage(ctrl), testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{CourierId: 7, ProofAttached: true}, nil)
+
+		_, err := ps.Upload(context.Background(), &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: png(100)})
+		require.ErrorIs(t, err, service.ErrProofExists)
+	})
+
+	t.Run("concurrent upload removes files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+		mockProofRepo := mock_dep.NewMockProofRepository(ctrl)
+		mockStorage := mock_dep.NewMockBlobStorage(ctrl)
+
+		ps := NewProofService(mockDeliveryRepo, mockProofRepo, mockStorage, testPolicy)
+		ps.now = func() time.Time { return time.Unix(0, 42) }
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{CourierId: 7}, nil)
+		mockStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(readAll)
+		mockProofRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(-1, repository.ErrProofExists)
+		mockStorage.EXPECT().Delete(gomock.Any(), "proofs/ORDER-1/42-photo.png").Return(nil)
+
+		_, err := ps.Upload(context.Background(), &dto.UploadProofRequest{OrderId: "ORDER-1", PIN: "1234", Photo: png(100)})
+		require.ErrorIs(t, err, service.ErrProofExists)
+	})
+}
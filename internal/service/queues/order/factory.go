@@ -4,6 +4,7 @@ import (
 	"context"
 	"service-order-avito/internal/domain/dto"
 	"service-order-avito/internal/domain/dto/kafka/order"
+	"service-order-avito/internal/domain/model"
 	"service-order-avito/internal/service/queues/order/strategies"
 )
 
@@ -14,22 +15,28 @@ type delService interface {
 }
 
 type orderChangedStrategyFabric interface {
-	Process(context.Context, string) (*order.ProcessedEvent, error)
+	Process(context.Context, *order.Event) (*order.ProcessedEvent, error)
+}
+
+type orderGateway interface {
+	GetOrderTotalPriceById(ctx context.Context, id string) (int64, error)
 }
 
 type orderStrategyFactory struct {
 	service delService
+	og      orderGateway
+	handoff model.HandoffPolicy
 }
 
-func NewOrderStrategyFactory(service delService) *orderStrategyFactory {
-	return &orderStrategyFactory{service: service}
+func NewOrderStrategyFactory(service delService, og orderGateway, handoff model.HandoffPolicy) *orderStrategyFactory {
+	return &orderStrategyFactory{service: service, og: og, handoff: handoff}
 }
 
 func (of *orderStrategyFactory) SelectStrategy(status string) orderChangedStrategyFabric {
 
 	switch status {
 	case order.StatusCreated:
-		return strategies.NewCreateStrategy(of.service)
+		return strategies.NewCreateStrategy(of.service, of.og, of.handoff)
 	case order.StatusCancelled:
 		return strategies.NewCancelStrategy(of.service)
 	case order.StatusCompleted:
@@ -4,6 +4,7 @@ import (
 	"context"
 	"service-order-avito/internal/domain/dto/kafka/order"
 	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
 )
 
 type orderChangedService struct {
@@ -11,8 +12,8 @@ type orderChangedService struct {
 	fab  *orderStrategyFactory
 }
 
-func NewOrderChangedService(serv delService) *orderChangedService {
-	return &orderChangedService{serv: serv, fab: NewOrderStrategyFactory(serv)}
+func NewOrderChangedService(serv delService, og orderGateway, handoff model.HandoffPolicy) *orderChangedService {
+	return &orderChangedService{serv: serv, fab: NewOrderStrategyFactory(serv, og, handoff)}
 }
 
 func (os *orderChangedService) Process(ctx context.Context, event *order.Event) (*order.ProcessedEvent, error) {
@@ -21,5 +22,5 @@ func (os *orderChangedService) Process(ctx context.Context, event *order.Event)
 		return nil, service.ErrUnknownOrderStatus
 	}
 
-	return strategy.Process(ctx, event.OrderID)
+	return strategy.Process(ctx, event)
 }
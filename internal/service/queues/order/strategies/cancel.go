@@ -19,8 +19,8 @@ func NewCancelStrategy(service cancelService) *cancelStrategy {
 	return &cancelStrategy{service: service}
 }
 
-func (cs *cancelStrategy) Process(ctx context.Context, orderId string) (*order.ProcessedEvent, error) {
-	req := &dto.UnassignDeliveryRequest{OrderId: orderId}
+func (cs *cancelStrategy) Process(ctx context.Context, event *order.Event) (*order.ProcessedEvent, error) {
+	req := &dto.UnassignDeliveryRequest{OrderId: event.OrderID}
 
 	res, err := cs.service.Unassign(ctx, req)
 	if err != nil {
@@ -19,8 +19,8 @@ func NewCompleteStrategy(service completeService) *completeStrategy {
 	return &completeStrategy{service: service}
 }
 
-func (cs *completeStrategy) Process(ctx context.Context, orderId string) (*order.ProcessedEvent, error) {
-	req := &dto.CompleteDeliveryRequest{OrderId: orderId}
+func (cs *completeStrategy) Process(ctx context.Context, event *order.Event) (*order.ProcessedEvent, error) {
+	req := &dto.CompleteDeliveryRequest{OrderId: event.OrderID}
 
 	res, err := cs.service.Complete(ctx, req)
 	if err != nil {
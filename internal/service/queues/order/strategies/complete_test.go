@@ -0,0 +1,55 @@
+package strategies
+
+import (
+	"context"
+	"service-order-avito/internal/domain/dto/kafka/order"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/service/delivery"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type nopDeliveryMetrics struct{}
+
+func (nopDeliveryMetrics) IncDeliveriesCreated()             {}
+func (nopDeliveryMetrics) AddDeliveriesFinished(string, int) {}
+func (nopDeliveryMetrics) ObserveAssignment(time.Duration)   {}
+func (nopDeliveryMetrics) ObserveTimeToAssign(time.Duration) {}
+func (nopDeliveryMetrics) IncNoAvailableCouriers()           {}
+
+// у дорогого заказа есть код передачи, но в order.changed его нет: завершение от order-service проходит без кода
+func TestCompleteStrategy_HandoffCodeOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := delivery.NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), nopDeliveryMetrics{},
+		model.RatingPolicy{},
+		model.SLAPolicy{},
+		model.ProofPolicy{},
+		model.HandoffPolicy{PriceThreshold: 10000, MaxAttempts: 3, Lockout: time.Minute, Secret: []byte("secret")},
+	)
+
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+	gomock.InOrder(
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "ORDER-1").Return(model.Delivery{Id: 3, CourierId: 7, OrderId: "ORDER-1", HandoffCodeHash: "hash"}, nil),
+		mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusDelivered, gomock.Any(), 3).Return(nil),
+		mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCompleted, 3).Return(nil),
+		mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), "ORDER-1").Return(nil),
+		mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{Id: 7, Status: model.StatusAvailable}).Return(nil),
+	)
+
+	res, err := NewCompleteStrategy(ds).Process(context.Background(), &order.Event{OrderID: "ORDER-1", Status: order.StatusCompleted})
+	require.NoError(t, err)
+	require.Equal(t, &order.ProcessedEvent{OrderId: "ORDER-1", Status: model.StatusCompleted, CourierId: 7}, res)
+}
@@ -5,22 +5,42 @@ import (
 	"service-order-avito/internal/domain/dto"
 	"service-order-avito/internal/domain/dto/kafka/order"
 	"service-order-avito/internal/domain/model"
+	"time"
 )
 
 type createService interface {
 	Assign(context.Context, *dto.AssignDeliveryRequest) (*dto.AssignDeliveryResponse, error)
 }
 
+type orderPriceGateway interface {
+	GetOrderTotalPriceById(ctx context.Context, id string) (int64, error)
+}
+
 type createStrategy struct {
 	service createService
+	og      orderPriceGateway
+	handoff model.HandoffPolicy
 }
 
-func NewCreateStrategy(service createService) *createStrategy {
-	return &createStrategy{service: service}
+func NewCreateStrategy(service createService, og orderPriceGateway, handoff model.HandoffPolicy) *createStrategy {
+	return &createStrategy{service: service, og: og, handoff: handoff}
 }
 
-func (cs *createStrategy) Process(ctx context.Context, orderId string) (*order.ProcessedEvent, error) {
-	req := &dto.AssignDeliveryRequest{OrderId: orderId}
+func (cs *createStrategy) Process(ctx context.Context, event *order.Event) (*order.ProcessedEvent, error) {
+	req := &dto.AssignDeliveryRequest{OrderId: event.OrderID}
+	// стоимость нужна только для решения о коде передачи. Без нее заказ не назначаем: дорогой заказ уехал бы без кода.
+	// Сообщение останется неподтвержденным, а заказ без доставки назначит сверка с order-service
+	if cs.handoff.Enabled() {
+		totalPrice, err := cs.og.GetOrderTotalPriceById(ctx, event.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		req.TotalPrice = totalPrice
+	}
+	// время создания нужно только для метрики, битое значение не мешает назначению
+	if createdAt, err := time.Parse(time.RFC3339Nano, event.CreatedAt); err == nil {
+		req.OrderCreatedAt = createdAt
+	}
 
 	res, err := cs.service.Assign(ctx, req)
 	if err != nil {
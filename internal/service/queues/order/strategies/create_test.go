@@ -0,0 +1,70 @@
+package strategies
+
+import (
+	"context"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/dto/kafka/order"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubCreateService struct {
+	req *dto.AssignDeliveryRequest
+}
+
+func (s *stubCreateService) Assign(_ context.Context, req *dto.AssignDeliveryRequest) (*dto.AssignDeliveryResponse, error) {
+	s.req = req
+	return &dto.AssignDeliveryResponse{OrderId: req.OrderId, CourierId: 7}, nil
+}
+
+type stubPriceGateway struct {
+	price int64
+	err   error
+	calls int
+}
+
+func (g *stubPriceGateway) GetOrderTotalPriceById(context.Context, string) (int64, error) {
+	g.calls++
+	return g.price, g.err
+}
+
+func TestCreateStrategy_TotalPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int64
+		gateway   *stubPriceGateway
+		wantCalls int
+		wantPrice int64
+		wantErr   error
+	}{
+		{name: "handoff codes disabled", gateway: &stubPriceGateway{err: service.ErrInternalError}},
+		{name: "price found", threshold: 10000, gateway: &stubPriceGateway{price: 20000}, wantCalls: 1, wantPrice: 20000},
+		// order-service недоступен: без стоимости дорогой заказ уехал бы без кода, поэтому не назначаем
+		{name: "price lookup failed", threshold: 10000, gateway: &stubPriceGateway{err: service.ErrInternalError}, wantCalls: 1, wantErr: service.ErrInternalError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assign := &stubCreateService{}
+			strategy := NewCreateStrategy(assign, tt.gateway, model.HandoffPolicy{PriceThreshold: tt.threshold})
+
+			res, err := strategy.Process(context.Background(), &order.Event{OrderID: "ORDER-1", Status: order.StatusCreated})
+			require.Equal(t, tt.wantCalls, tt.gateway.calls)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, res)
+				require.Nil(t, assign.req)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &order.ProcessedEvent{OrderId: "ORDER-1", Status: model.StatusAssigned, CourierId: 7}, res)
+			require.Equal(t, tt.wantPrice, assign.req.TotalPrice)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package stats
+
+import (
+	"context"
+	"service-order-avito/internal/adapters"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/service/dep"
+	"time"
+)
+
+const (
+	// defaultPeriod период по умолчанию, если from/to не переданы
+	defaultPeriod = 7 * 24 * time.Hour
+	// maxPeriod ограничение на длину периода, чтобы один запрос не сканировал всю историю
+	maxPeriod = 366 * 24 * time.Hour
+)
+
+type statsService struct {
+	courRepo  dep.CourierRepository
+	statsRepo dep.StatsRepository
+	now       func() time.Time
+}
+
+func NewStatsService(courRepo dep.CourierRepository, statsRepo dep.StatsRepository) *statsService {
+	return &statsService{courRepo: courRepo, statsRepo: statsRepo, now: time.Now}
+}
+
+// GetCourierStats статистика курьера за период: количество завершенных/отмененных/просроченных доставок,
+// доля доставок в срок, среднее время доставки по типу транспорта и загрузка курьера
+func (ss *statsService) GetCourierStats(ctx context.Context, req *dto.GetCourierStatsRequest) (*dto.CourierStatsResponse, error) {
+	if !model.CanAccessCourier(ctx, req.CourierId) {
+		return nil, service.ErrForbidden
+	}
+
+	from, to, err := ss.period(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = ss.courRepo.GetById(ctx, req.CourierId); err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	deliveryStats, err := ss.statsRepo.GetDeliveryStats(ctx, req.CourierId, from, to)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	activeSec, err := ss.statsRepo.GetActiveSeconds(ctx, req.CourierId, from, to, ss.now())
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	res := &dto.CourierStatsResponse{
+		CourierId:              req.CourierId,
+		From:                   from,
+		To:                     to,
+		AvgDeliveryDurationSec: make(map[string]float64, len(deliveryStats)),
+		ActiveHours:            activeSec / time.Hour.Seconds(),
+		Utilisation:            activeSec / to.Sub(from).Seconds(),
+	}
+
+	onTime := 0
+	for _, st := range deliveryStats {
+		res.Completed += st.Completed
+		res.Cancelled += st.Cancelled
+		res.Expired += st.Expired
+		res.Breached += st.Breached
+		onTime += st.CompletedOnTime
+		if st.Completed > 0 {
+			res.AvgDeliveryDurationSec[st.TransportType] = st.AvgDurationSec
+		}
+	}
+	res.OnTimeRate = rate(onTime, res.Completed)
+
+	return res, nil
+}
+
+// GetFleetStats агрегаты по всем курьерам: текущее распределение курьеров по статусу и транспорту
+// и доставки, завершенные за период, по типу транспорта
+func (ss *statsService) GetFleetStats(ctx context.Context, req *dto.GetFleetStatsRequest) (*dto.FleetStatsResponse, error) {
+	from, to, err := ss.period(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := ss.statsRepo.GetCourierCounts(ctx)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	deliveryStats, err := ss.statsRepo.GetDeliveryStats(ctx, 0, from, to)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	active, err := ss.statsRepo.CountActiveDeliveries(ctx)
+	if err != nil {
+		return nil, adapters.ErrUnwrapRepoToService(err)
+	}
+
+	res := &dto.FleetStatsResponse{
+		From:             from,
+		To:               to,
+		Couriers:         make([]dto.FleetCourierGroup, len(counts)),
+		Deliveries:       make([]dto.FleetDeliveryGroup, len(deliveryStats)),
+		ActiveDeliveries: active,
+	}
+	for i, c := range counts {
+		res.Couriers[i] = dto.FleetCourierGroup{Status: c.Status, TransportType: c.TransportType, Count: c.Count}
+	}
+	for i, st := range deliveryStats {
+		res.Deliveries[i] = toFleetDeliveryGroup(st)
+	}
+
+	return res, nil
+}
+
+// period подставляет значения по умолчанию и проверяет границы периода
+func (ss *statsService) period(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = ss.now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultPeriod)
+	}
+	if !from.Before(to) || to.Sub(from) > maxPeriod {
+		return time.Time{}, time.Time{}, service.ErrInvalidStatsPeriod
+	}
+	return from, to, nil
+}
+
+func toFleetDeliveryGroup(st model.DeliveryStats) dto.FleetDeliveryGroup {
+	return dto.FleetDeliveryGroup{
+		TransportType:          st.TransportType,
+		Completed:              st.Completed,
+		Cancelled:              st.Cancelled,
+		Expired:                st.Expired,
+		Breached:               st.Breached,
+		OnTimeRate:             rate(st.CompletedOnTime, st.Completed),
+		AvgDeliveryDurationSec: st.AvgDurationSec,
+	}
+}
+
+func rate(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
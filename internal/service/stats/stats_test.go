@@ -0,0 +1,183 @@
+package stats
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/repository"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, time.December, 8, 0, 0, 0, 0, time.UTC)
+
+func TestStatsService_GetCourierStats_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCourRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockStatsRepo := mock_dep.NewMockStatsRepository(ctrl)
+
+	ss := NewStatsService(mockCourRepo, mockStatsRepo)
+	ss.now = func() time.Time { return testNow }
+
+	// период по умолчанию - последние 7 дней
+	from := testNow.Add(-7 * 24 * time.Hour)
+
+	mockCourRepo.
+		EXPECT().
+		GetById(gomock.Any(), 1).
+		Return(model.Courier{Id: 1}, nil)
+
+	mockStatsRepo.
+		EXPECT().
+		GetDeliveryStats(gomock.Any(), 1, from, testNow).
+		Return([]model.DeliveryStats{
+			{TransportType: "car", Completed: 3, Cancelled: 1, Breached: 1, CompletedOnTime: 2, AvgDurationSec: 600},
+			{TransportType: "on_foot", Completed: 1, Expired: 2, Breached: 2, CompletedOnTime: 1, AvgDurationSec: 1800},
+			{TransportType: "scooter", Cancelled: 1},
+		}, nil)
+
+	// 42 часа из 168 часов периода
+	mockStatsRepo.
+		EXPECT().
+		GetActiveSeconds(gomock.Any(), 1, from, testNow, testNow).
+		Return(float64(42*3600), nil)
+
+	resp, err := ss.GetCourierStats(context.Background(), &dto.GetCourierStatsRequest{CourierId: 1})
+	require.NoError(t, err)
+
+	require.Equal(t, from, resp.From)
+	require.Equal(t, testNow, resp.To)
+	require.Equal(t, 4, resp.Completed)
+	require.Equal(t, 2, resp.Cancelled)
+	require.Equal(t, 2, resp.Expired)
+	require.Equal(t, 3, resp.Breached)
+	require.InDelta(t, 0.75, resp.OnTimeRate, 1e-9)
+	require.Equal(t, map[string]float64{"car": 600, "on_foot": 1800}, resp.AvgDeliveryDurationSec)
+	require.InDelta(t, 42, resp.ActiveHours, 1e-9)
+	require.InDelta(t, 0.25, resp.Utilisation, 1e-9)
+}
+
+func TestStatsService_GetCourierStats_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCourRepo := mock_dep.NewMockCourierRepository(ctrl)
+
+	ss := NewStatsService(mockCourRepo, mock_dep.NewMockStatsRepository(ctrl))
+	ss.now = func() time.Time { return testNow }
+
+	mockCourRepo.
+		EXPECT().
+		GetById(gomock.Any(), 1).
+		Return(model.Courier{}, repository.ErrCourierNotFound)
+
+	_, err := ss.GetCourierStats(context.Background(), &dto.GetCourierStatsRequest{CourierId: 1})
+	require.ErrorIs(t, err, service.ErrCourierNotFound)
+}
+
+func TestStatsService_InvalidPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{name: "from after to", from: testNow, to: testNow.Add(-time.Hour)},
+		{name: "empty period", from: testNow, to: testNow},
+		{name: "too long", from: testNow.Add(-400 * 24 * time.Hour), to: testNow},
+		{name: "from in the future", from: testNow.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ss := NewStatsService(mock_dep.NewMockCourierRepository(ctrl), mock_dep.NewMockStatsRepository(ctrl))
+			ss.now = func() time.Time { return testNow }
+
+			_, err := ss.GetCourierStats(context.Background(), &dto.GetCourierStatsRequest{CourierId: 1, From: tt.from, To: tt.to})
+			require.ErrorIs(t, err, service.ErrInvalidStatsPeriod)
+
+			_, err = ss.GetFleetStats(context.Background(), &dto.GetFleetStatsRequest{From: tt.from, To: tt.to})
+			require.ErrorIs(t, err, service.ErrInvalidStatsPeriod)
+		})
+	}
+}
+
+func TestStatsService_GetFleetStats_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStatsRepo := mock_dep.NewMockStatsRepository(ctrl)
+
+	ss := NewStatsService(mock_dep.NewMockCourierRepository(ctrl), mockStatsRepo)
+	ss.now = func() time.Time { return testNow }
+
+	from := testNow.Add(-24 * time.Hour)
+
+	mockStatsRepo.
+		EXPECT().
+		GetCourierCounts(gomock.Any()).
+		Return([]model.CourierGroupCount{
+			{Status: "available", TransportType: "car", Count: 5},
+			{Status: "busy", TransportType: "on_foot", Count: 2},
+		}, nil)
+
+	mockStatsRepo.
+		EXPECT().
+		GetDeliveryStats(gomock.Any(), 0, from, testNow).
+		Return([]model.DeliveryStats{
+			{TransportType: "car", Completed: 4, Cancelled: 1, Breached: 1, CompletedOnTime: 3, AvgDurationSec: 900},
+		}, nil)
+
+	mockStatsRepo.
+		EXPECT().
+		CountActiveDeliveries(gomock.Any()).
+		Return(2, nil)
+
+	resp, err := ss.GetFleetStats(context.Background(), &dto.GetFleetStatsRequest{From: from, To: testNow})
+	require.NoError(t, err)
+
+	require.Equal(t, []dto.FleetCourierGroup{
+		{Status: "available", TransportType: "car", Count: 5},
+		{Status: "busy", TransportType: "on_foot", Count: 2},
+	}, resp.Couriers)
+	require.Equal(t, []dto.FleetDeliveryGroup{
+		{TransportType: "car", Completed: 4, Cancelled: 1, Breached: 1, OnTimeRate: 0.75, AvgDeliveryDurationSec: 900},
+	}, resp.Deliveries)
+	require.Equal(t, 2, resp.ActiveDeliveries)
+}
+
+func TestStatsService_GetFleetStats_RepoError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStatsRepo := mock_dep.NewMockStatsRepository(ctrl)
+
+	ss := NewStatsService(mock_dep.NewMockCourierRepository(ctrl), mockStatsRepo)
+	ss.now = func() time.Time { return testNow }
+
+	mockStatsRepo.
+		EXPECT().
+		GetCourierCounts(gomock.Any()).
+		Return(nil, repository.ErrInternalError)
+
+	_, err := ss.GetFleetStats(context.Background(), &dto.GetFleetStatsRequest{})
+	require.ErrorIs(t, err, service.ErrInternalError)
+}
@@ -2,44 +2,83 @@ package delivery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"service-order-avito/internal/adapters/logger"
-	"time"
+	"service-order-avito/internal/domain/model"
 )
 
 type deliveryService interface {
 	UnassignAllCompleted(context.Context) (int, error)
+	TrackSLA(context.Context) (*model.SLAReport, error)
+}
+
+type MetricsObserverSLA interface {
+	AddAtRisk(n int)
+	AddBreached(n int)
+	SetActive(slaState string, n int)
 }
 
 type deliveryMonitorWorker struct {
-	interval   time.Duration
-	log        logger.LoggerAdapter
-	delService deliveryService
+	log          logger.LoggerAdapter
+	delService   deliveryService
+	metrics      MetricsObserverSLA
+	autoUnassign bool
 }
 
-func NewDeliveryMonitorWorker(interval time.Duration, log logger.LoggerAdapter, delService deliveryService) *deliveryMonitorWorker {
-	return &deliveryMonitorWorker{interval: interval, log: log, delService: delService}
+// NewDeliveryMonitorWorker autoUnassign включает снятие доставок с курьеров после дедлайна.
+// Если выключено, просроченные доставки только помечаются как breached и ждут complete/unassign
+func NewDeliveryMonitorWorker(
+	log logger.LoggerAdapter,
+	delService deliveryService,
+	metrics MetricsObserverSLA,
+	autoUnassign bool,
+) *deliveryMonitorWorker {
+	return &deliveryMonitorWorker{
+		log:          log,
+		delService:   delService,
+		metrics:      metrics,
+		autoUnassign: autoUnassign,
+	}
 }
 
-func (w *deliveryMonitorWorker) Start(ctx context.Context) {
-	ticker := time.NewTicker(w.interval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			w.log.Info("delivery monitor worker gracefully stopped")
-			return
-		case <-ticker.C:
-			totalUnassigned, err := w.delService.UnassignAllCompleted(ctx)
-			if err != nil {
-				w.log.Error(err.Error())
-				continue
-			}
-
-			if totalUnassigned > 0 {
-				w.log.Info(fmt.Sprintf("unassigned %d deliveries", totalUnassigned))
-			}
-		}
+// Run одна итерация, расписание задает планировщик
+func (w *deliveryMonitorWorker) Run(ctx context.Context) error {
+	// SLA отслеживаем до снятия доставок, иначе просроченная доставка успеет уйти в историю не помеченной
+	report, err := w.delService.TrackSLA(ctx)
+	if err != nil {
+		err = fmt.Errorf("track sla: %w", err)
+	} else {
+		w.observe(report)
+	}
+
+	if !w.autoUnassign {
+		return err
+	}
+
+	totalUnassigned, unassignErr := w.delService.UnassignAllCompleted(ctx)
+	if unassignErr != nil {
+		return errors.Join(err, fmt.Errorf("unassign completed: %w", unassignErr))
+	}
+
+	if totalUnassigned > 0 {
+		w.log.InfoContext(ctx, fmt.Sprintf("unassigned %d deliveries", totalUnassigned))
+	}
+	return err
+}
+
+func (w *deliveryMonitorWorker) observe(report *model.SLAReport) {
+	w.metrics.AddAtRisk(len(report.AtRisk))
+	w.metrics.AddBreached(len(report.Breached))
+	for state, n := range report.Active {
+		w.metrics.SetActive(state, n)
+	}
 
+	for _, d := range report.Breached {
+		w.log.Warn("delivery SLA breached",
+			"order_id", d.OrderId,
+			"courier_id", d.CourierId,
+			"deadline", d.Deadline,
+		)
 	}
 }
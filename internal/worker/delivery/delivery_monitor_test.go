@@ -0,0 +1,74 @@
+package delivery
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/model"
+	"testing"
+)
+
+type stubDeliveryService struct {
+	report         *model.SLAReport
+	unassignCalled int
+}
+
+func (s *stubDeliveryService) UnassignAllCompleted(context.Context) (int, error) {
+	s.unassignCalled++
+	return 0, nil
+}
+
+func (s *stubDeliveryService) TrackSLA(context.Context) (*model.SLAReport, error) {
+	return s.report, nil
+}
+
+type stubSLAObserver struct {
+	atRisk, breached int
+	active           map[string]int
+}
+
+func (s *stubSLAObserver) AddAtRisk(n int)   { s.atRisk += n }
+func (s *stubSLAObserver) AddBreached(n int) { s.breached += n }
+func (s *stubSLAObserver) SetActive(state string, n int) {
+	s.active[state] = n
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+func TestDeliveryMonitorWorker_Run(t *testing.T) {
+	report := &model.SLAReport{
+		AtRisk:   []model.Delivery{{OrderId: "ORDER-1"}},
+		Breached: []model.Delivery{{OrderId: "ORDER-2"}, {OrderId: "ORDER-3"}},
+		Active:   map[string]int{model.SLAStateAtRisk: 1, model.SLAStateBreached: 2},
+	}
+
+	for _, autoUnassign := range []bool{true, false} {
+		svc := &stubDeliveryService{report: report}
+		metrics := &stubSLAObserver{active: map[string]int{}}
+
+		w := NewDeliveryMonitorWorker(nopLogger{}, svc, metrics, autoUnassign)
+		require.NoError(t, w.Run(context.Background()))
+
+		require.Equal(t, 1, metrics.atRisk)
+		require.Equal(t, 2, metrics.breached)
+		require.Equal(t, report.Active, metrics.active)
+
+		// без autoUnassign просроченные доставки только помечаются
+		if autoUnassign {
+			require.Equal(t, 1, svc.unassignCalled)
+		} else {
+			require.Zero(t, svc.unassignCalled)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package leader
+
+import (
+	"context"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/model"
+	"sync"
+	"time"
+)
+
+type leaseStore interface {
+	TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (model.LeaderLease, bool, error)
+	Release(ctx context.Context, name, holder string) error
+}
+
+type MetricsObserverLeader interface {
+	SetLeader(lease string, leader bool)
+	IncTransitions(lease string)
+}
+
+type job struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// Elector выбирает лидера среди реплик через аренду в базе и запускает синглтон воркеры только на лидере.
+// Лидер продлевает аренду каждые renewInterval, остальные реплики с тем же интервалом пробуют ее забрать.
+// Если лидер упал, аренда истекает через ttl и ее забирает другая реплика. Если лидер не может продлить
+// аренду (например, недоступна база), он останавливает воркеры раньше, чем аренда истечет у остальных
+type Elector struct {
+	store         leaseStore
+	lease         string
+	instanceId    string
+	ttl           time.Duration
+	renewInterval time.Duration
+	log           logger.LoggerAdapter
+	metrics       MetricsObserverLeader
+	jobs          []job
+
+	mu        sync.RWMutex
+	leading   bool
+	current   model.LeaderLease
+	renewedAt time.Time
+	lastErr   error
+
+	// только из горутины Start
+	cancelJobs context.CancelFunc
+	jobsWg     sync.WaitGroup
+}
+
+// NewElector lease - имя аренды, instanceId - уникальный id реплики, например имя пода.
+// renewInterval должен быть заметно меньше ttl, иначе лидер не успеет продлить аренду
+func NewElector(store leaseStore, lease, instanceId string, ttl, renewInterval time.Duration, log logger.LoggerAdapter, metrics MetricsObserverLeader) *Elector {
+	return &Elector{
+		store:         store,
+		lease:         lease,
+		instanceId:    instanceId,
+		ttl:           ttl,
+		renewInterval: renewInterval,
+		log:           log.With("component", "worker/leader", "lease", lease, "instance_id", instanceId),
+		metrics:       metrics,
+	}
+}
+
+// Run добавляет синглтон воркер вида Start(ctx). Воркер запускается, когда реплика становится лидером,
+// и получает отмену контекста, когда она перестает им быть. Вызывается до Start
+func (e *Elector) Run(name string, run func(ctx context.Context)) {
+	e.jobs = append(e.jobs, job{name: name, run: run})
+}
+
+func (e *Elector) Start(ctx context.Context) {
+	e.metrics.SetLeader(e.lease, false)
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	e.tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			e.stop(ctx)
+			e.log.Info("leader elector gracefully stopped")
+			return
+		case <-ticker.C:
+			e.tick(ctx)
+		}
+	}
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leading
+}
+
+func (e *Elector) Status() dto.LeaderResponse {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	res := dto.LeaderResponse{
+		Lease:      e.lease,
+		InstanceId: e.instanceId,
+		Leader:     e.leading,
+		Holder:     e.current.Holder,
+		Jobs:       make([]string, 0, len(e.jobs)),
+	}
+	if !e.current.AcquiredAt.IsZero() {
+		acquiredAt, expiresAt := e.current.AcquiredAt, e.current.ExpiresAt
+		res.AcquiredAt, res.ExpiresAt = &acquiredAt, &expiresAt
+	}
+	for _, j := range e.jobs {
+		res.Jobs = append(res.Jobs, j.name)
+	}
+	if e.lastErr != nil {
+		res.LastError = e.lastErr.Error()
+	}
+	return res
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	attemptAt := time.Now()
+	reqCtx, cancel := context.WithTimeout(ctx, e.renewInterval)
+	lease, acquired, err := e.store.TryAcquire(reqCtx, e.lease, e.instanceId, e.ttl)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		e.mu.Lock()
+		e.lastErr = err
+		// аренда в базе считается от момента последнего продления, к ее концу воркеры должны быть остановлены
+		expired := e.leading && time.Since(e.renewedAt) >= e.ttl-e.renewInterval
+		e.mu.Unlock()
+		e.log.Warn("leader lease is not renewed", "error", err.Error())
+		if expired {
+			e.stepDown("lease is not renewed in time")
+		}
+		return
+	}
+
+	e.mu.Lock()
+	e.current, e.lastErr = lease, nil
+	wasLeading := e.leading
+	if acquired {
+		e.renewedAt = attemptAt
+	}
+	e.mu.Unlock()
+
+	switch {
+	case acquired && !wasLeading:
+		e.becomeLeader(ctx)
+	case !acquired && wasLeading:
+		e.stepDown("lease is taken by " + lease.Holder)
+	}
+}
+
+func (e *Elector) becomeLeader(ctx context.Context) {
+	jobsCtx, cancel := context.WithCancel(ctx)
+	e.cancelJobs = cancel
+	for _, j := range e.jobs {
+		e.jobsWg.Add(1)
+		go func() {
+			defer e.jobsWg.Done()
+			j.run(jobsCtx)
+		}()
+	}
+
+	e.mu.Lock()
+	e.leading = true
+	e.mu.Unlock()
+	e.metrics.SetLeader(e.lease, true)
+	e.metrics.IncTransitions(e.lease)
+	e.log.Info("became leader, singleton workers are started", "jobs", len(e.jobs))
+}
+
+// stepDown останавливает воркеры и дожидается их, только после этого реплика перестает считаться лидером
+func (e *Elector) stepDown(reason string) {
+	if e.cancelJobs != nil {
+		e.cancelJobs()
+		e.jobsWg.Wait()
+		e.cancelJobs = nil
+	}
+
+	e.mu.Lock()
+	e.leading = false
+	e.mu.Unlock()
+	e.metrics.SetLeader(e.lease, false)
+	e.metrics.IncTransitions(e.lease)
+	e.log.Warn("lost leadership, singleton workers are stopped", "reason", reason)
+}
+
+func (e *Elector) stop(ctx context.Context) {
+	if !e.IsLeader() {
+		return
+	}
+	e.stepDown("shutdown")
+
+	// контекст уже отменен, аренда отдается со своим таймаутом
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.renewInterval)
+	defer cancel()
+	if err := e.store.Release(releaseCtx, e.lease, e.instanceId); err != nil {
+		e.log.Error("release leader lease", "error", err.Error())
+	}
+}
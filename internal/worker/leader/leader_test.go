@@ -0,0 +1,138 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/model"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type nopMetrics struct{}
+
+func (nopMetrics) SetLeader(string, bool) {}
+func (nopMetrics) IncTransitions(string)  {}
+
+// memoryStore аренда в памяти с той же семантикой, что и leader_leases
+type memoryStore struct {
+	mu    sync.Mutex
+	lease model.LeaderLease
+	down  atomic.Bool
+}
+
+func (s *memoryStore) TryAcquire(_ context.Context, name, holder string, ttl time.Duration) (model.LeaderLease, bool, error) {
+	if s.down.Load() {
+		return model.LeaderLease{}, false, errors.New("connection refused")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.lease.Holder != "" && s.lease.Holder != holder && now.Before(s.lease.ExpiresAt) {
+		return s.lease, false, nil
+	}
+	if s.lease.Holder != holder {
+		s.lease = model.LeaderLease{Name: name, Holder: holder, AcquiredAt: now}
+	}
+	s.lease.RenewedAt, s.lease.ExpiresAt = now, now.Add(ttl)
+	return s.lease, true, nil
+}
+
+func (s *memoryStore) Release(_ context.Context, _, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lease.Holder == holder {
+		s.lease = model.LeaderLease{}
+	}
+	return nil
+}
+
+// replica реплика с одним синглтон воркером, который считает свои запуски
+type replica struct {
+	elector *Elector
+	running atomic.Int32
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func startReplica(store leaseStore, id string) *replica {
+	r := &replica{done: make(chan struct{})}
+	r.elector = NewElector(store, "singleton_workers", id, 100*time.Millisecond, 10*time.Millisecond, nopLogger{}, nopMetrics{})
+	r.elector.Run("delivery_monitor_worker", func(ctx context.Context) {
+		r.running.Add(1)
+		defer r.running.Add(-1)
+		<-ctx.Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go func() {
+		defer close(r.done)
+		r.elector.Start(ctx)
+	}()
+	return r
+}
+
+func (r *replica) stop() {
+	r.cancel()
+	<-r.done
+}
+
+func TestElector_SingleLeaderAndFailover(t *testing.T) {
+	store := &memoryStore{}
+	a := startReplica(store, "a")
+	require.Eventually(t, a.elector.IsLeader, time.Second, time.Millisecond)
+	b := startReplica(store, "b")
+	defer b.stop()
+
+	// b видит лидера, но воркеры крутятся только на a
+	require.Eventually(t, func() bool { return b.elector.Status().Holder == "a" }, time.Second, time.Millisecond)
+	require.False(t, b.elector.IsLeader())
+	require.Equal(t, int32(1), a.running.Load())
+	require.Equal(t, int32(0), b.running.Load())
+
+	status := a.elector.Status()
+	require.True(t, status.Leader)
+	require.Equal(t, []string{"delivery_monitor_worker"}, status.Jobs)
+	require.NotNil(t, status.ExpiresAt)
+
+	// при остановке a отдает аренду, b становится лидером, не дожидаясь ttl
+	a.stop()
+	require.Equal(t, int32(0), a.running.Load(), "workers are stopped before shutdown completes")
+	require.Eventually(t, b.elector.IsLeader, 50*time.Millisecond, time.Millisecond)
+	require.Eventually(t, func() bool { return b.running.Load() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestElector_StepsDownWhenLeaseIsNotRenewed(t *testing.T) {
+	store := &memoryStore{}
+	r := startReplica(store, "a")
+	defer r.stop()
+	require.Eventually(t, r.elector.IsLeader, time.Second, time.Millisecond)
+
+	store.down.Store(true)
+	// шаг вниз до истечения аренды (100ms), чтобы другая реплика не запустила воркеры параллельно
+	require.Eventually(t, func() bool { return !r.elector.IsLeader() }, 100*time.Millisecond, time.Millisecond)
+	require.Equal(t, int32(0), r.running.Load())
+	require.Equal(t, "connection refused", r.elector.Status().LastError)
+
+	store.down.Store(false)
+	require.Eventually(t, r.elector.IsLeader, time.Second, time.Millisecond)
+	require.Empty(t, r.elector.Status().LastError)
+}
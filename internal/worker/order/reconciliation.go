@@ -0,0 +1,280 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/dto/kafka/order"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"time"
+)
+
+const (
+	cursorName       = "order_service"
+	activeCursorName = "active_deliveries"
+
+	FixResultFixed   = "fixed"
+	FixResultFailed  = "failed"
+	FixResultSkipped = "skipped"
+)
+
+var driftKinds = []string{model.DriftMissingDelivery, model.DriftCancelledActive, model.DriftCompletedActive}
+
+type deliveryService interface {
+	Assign(context.Context, *dto.AssignDeliveryRequest) (*dto.AssignDeliveryResponse, error)
+	Unassign(context.Context, *dto.UnassignDeliveryRequest) (*dto.UnassignDeliveryResponse, error)
+	Complete(context.Context, *dto.CompleteDeliveryRequest) (*dto.CompleteDeliveryResponse, error)
+	GetOrderStates(ctx context.Context, orderIds []string) (map[string]string, error)
+	GetActiveOrderIds(ctx context.Context, after string, limit int) ([]string, error)
+}
+
+type orderGateway interface {
+	GetOrdersAfter(ctx context.Context, cursor model.ReconciliationCursor, limit int) ([]model.Order, error)
+	GetOrderStatusById(ctx context.Context, id string) (string, error)
+}
+
+type cursorStore interface {
+	Get(ctx context.Context, name string) (model.ReconciliationCursor, error)
+	Save(ctx context.Context, cursor model.ReconciliationCursor) error
+}
+
+type MetricsObserverReconciliation interface {
+	SetDrift(kind string, n int)
+	IncFixes(kind, result string)
+	SetCursor(at time.Time)
+}
+
+type reconciliationWorker struct {
+	log        logger.LoggerAdapter
+	delService deliveryService
+	orders     orderGateway
+	cursors    cursorStore
+	metrics    MetricsObserverReconciliation
+	policy     model.ReconciliationPolicy
+	now        func() time.Time
+	// attempts неудачные исправления заказа, на котором стоит курсор новых заказов. Живет в памяти:
+	// после рестарта или смены лидера попытки считаются заново. Run не вызывается параллельно, планировщик пропускает перекрытия
+	attempts map[string]int
+}
+
+// NewReconciliationWorker сверка заказов order-service с доставками на случай потерянных событий order.changed.
+// Новые заказы читаются страницами от сохраненного курсора, им назначается доставка, если ее нет.
+// Доставки в работе обходятся по кругу своим курсором по ActiveBatchSize за проход:
+// отмена или выполнение заказа могут прийти когда угодно после создания
+func NewReconciliationWorker(
+	log logger.LoggerAdapter,
+	delService deliveryService,
+	orders orderGateway,
+	cursors cursorStore,
+	metrics MetricsObserverReconciliation,
+	policy model.ReconciliationPolicy,
+) *reconciliationWorker {
+	return &reconciliationWorker{
+		log:        log.With("component", "worker/reconciliation"),
+		delService: delService,
+		orders:     orders,
+		cursors:    cursors,
+		metrics:    metrics,
+		policy:     policy,
+		now:        time.Now,
+		attempts:   make(map[string]int),
+	}
+}
+
+// Run один проход сверки, расписание задает планировщик
+func (w *reconciliationWorker) Run(ctx context.Context) error {
+	drift := make(map[string]int, len(driftKinds))
+	checked := make(map[string]bool)
+
+	newErr := w.reconcileNew(ctx, drift, checked)
+	activeErr := w.reconcileActive(ctx, drift, checked)
+
+	// gauge показывает расхождения последнего прохода, нули тоже выставляются
+	for _, kind := range driftKinds {
+		w.metrics.SetDrift(kind, drift[kind])
+	}
+	if len(drift) > 0 {
+		w.log.WarnContext(ctx, "order drift found", "drift", drift, "report_only", w.policy.ReportOnly)
+	}
+	return errors.Join(newErr, activeErr)
+}
+
+// reconcileNew страница заказов после курсора. Курсор сдвигается на последний проверенный заказ,
+// но останавливается перед первым неисправленным расхождением: его исправление повторится на следующем проходе.
+// Нет свободных курьеров - временная ошибка для всех заказов, ее ждем сколько угодно. Любая другая ошибка
+// одного заказа держит курсор не дольше MaxFixAttempts проходов, потом заказ пропускается с метрикой skipped,
+// иначе один сломанный заказ навсегда останавливает сверку всех следующих
+func (w *reconciliationWorker) reconcileNew(ctx context.Context, drift map[string]int, checked map[string]bool) error {
+	cursor, err := w.cursors.Get(ctx, cursorName)
+	if err != nil {
+		return fmt.Errorf("get reconciliation cursor: %w", err)
+	}
+	now := w.now()
+	if cursor.CreatedAt.IsZero() {
+		cursor.CreatedAt = now.Add(-w.policy.Lookback)
+	}
+
+	orders, err := w.orders.GetOrdersAfter(ctx, cursor, w.policy.BatchSize)
+	if err != nil {
+		return fmt.Errorf("get orders from order-service: %w", err)
+	}
+	page := w.page(orders, now)
+	if len(page) == 0 {
+		w.metrics.SetCursor(cursor.CreatedAt)
+		return nil
+	}
+
+	orderIds := make([]string, len(page))
+	for i, o := range page {
+		orderIds[i] = o.Id
+	}
+	states, err := w.delService.GetOrderStates(ctx, orderIds)
+	if err != nil {
+		return fmt.Errorf("get delivery states: %w", err)
+	}
+
+	var fixErr error
+	for _, o := range page {
+		if kind := driftOf(o.Status, states[o.Id]); kind != "" {
+			drift[kind]++
+			if err := w.fix(ctx, kind, o); err != nil && !w.skip(ctx, kind, o, err) {
+				fixErr = err
+				break
+			}
+		}
+		delete(w.attempts, o.Id)
+		checked[o.Id] = true
+		cursor.CreatedAt, cursor.OrderId = o.CreatedAt, o.Id
+	}
+
+	if err = w.cursors.Save(ctx, cursor); err != nil {
+		return errors.Join(fixErr, fmt.Errorf("save reconciliation cursor: %w", err))
+	}
+	w.metrics.SetCursor(cursor.CreatedAt)
+	return fixErr
+}
+
+// skip считает неудачную попытку исправить заказ и решает, пропустить ли его
+func (w *reconciliationWorker) skip(ctx context.Context, kind string, o model.Order, err error) bool {
+	if errors.Is(err, service.ErrNoAvailableCouriers) {
+		return false
+	}
+	w.attempts[o.Id]++
+	if w.attempts[o.Id] < w.policy.MaxFixAttempts {
+		return false
+	}
+
+	w.metrics.IncFixes(kind, FixResultSkipped)
+	w.log.ErrorContext(ctx, "order drift is skipped after failed fixes", "order_id", o.Id, "order_status", o.Status,
+		"drift", kind, "attempts", w.attempts[o.Id], "error", err.Error())
+	return true
+}
+
+// page заказы старше Grace, orders уже идут в порядке курсора
+func (w *reconciliationWorker) page(orders []model.Order, now time.Time) []model.Order {
+	for i, o := range orders {
+		// дальше только более новые заказы
+		if o.CreatedAt.After(now.Add(-w.policy.Grace)) {
+			return orders[:i]
+		}
+	}
+	return orders
+}
+
+// reconcileActive проверяет в order-service статус заказа следующих ActiveBatchSize доставок в работе,
+// кроме уже проверенных в reconcileNew. Дойдя до конца, курсор начинает обход заново.
+// Курсор сдвигается до проверки: доставка с ошибкой проверится на следующем круге, а остальные не ждут ее
+func (w *reconciliationWorker) reconcileActive(ctx context.Context, drift map[string]int, checked map[string]bool) error {
+	cursor, err := w.cursors.Get(ctx, activeCursorName)
+	if err != nil {
+		return fmt.Errorf("get active deliveries cursor: %w", err)
+	}
+	orderIds, err := w.delService.GetActiveOrderIds(ctx, cursor.OrderId, w.policy.ActiveBatchSize)
+	if err != nil {
+		return fmt.Errorf("get active deliveries: %w", err)
+	}
+
+	cursor.CreatedAt, cursor.OrderId = w.now(), ""
+	if len(orderIds) == w.policy.ActiveBatchSize {
+		cursor.OrderId = orderIds[len(orderIds)-1]
+	}
+	if err = w.cursors.Save(ctx, cursor); err != nil {
+		return fmt.Errorf("save active deliveries cursor: %w", err)
+	}
+
+	var errs []error
+	for _, orderId := range orderIds {
+		if checked[orderId] {
+			continue
+		}
+		if ctx.Err() != nil {
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+
+		status, err := w.orders.GetOrderStatusById(ctx, orderId)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get order %s status: %w", orderId, err))
+			continue
+		}
+		if kind := driftOf(status, model.DeliveryActive); kind != "" {
+			drift[kind]++
+			// ошибку исправления fix уже залогировал, доставка проверится снова на следующем проходе
+			_ = w.fix(ctx, kind, model.Order{Id: orderId, Status: status})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fix исправляет расхождение теми же методами, что и обработчик order.changed.
+// Complete, как и для order.changed, не требует кода передачи
+func (w *reconciliationWorker) fix(ctx context.Context, kind string, o model.Order) error {
+	log := w.log.With("order_id", o.Id, "order_status", o.Status, "drift", kind)
+	if w.policy.ReportOnly {
+		log.WarnContext(ctx, "order drift is not fixed in report-only mode")
+		return nil
+	}
+
+	var err error
+	switch kind {
+	case model.DriftMissingDelivery:
+		_, err = w.delService.Assign(ctx, &dto.AssignDeliveryRequest{
+			OrderId:        o.Id,
+			TotalPrice:     o.TotalPrice,
+			OrderCreatedAt: o.CreatedAt,
+		})
+	case model.DriftCancelledActive:
+		_, err = w.delService.Unassign(ctx, &dto.UnassignDeliveryRequest{OrderId: o.Id})
+	case model.DriftCompletedActive:
+		_, err = w.delService.Complete(ctx, &dto.CompleteDeliveryRequest{OrderId: o.Id})
+	}
+
+	// событие успело обработаться между проверкой и исправлением
+	if errors.Is(err, service.ErrDeliveryExists) || errors.Is(err, service.ErrDeliveryNotFound) {
+		log.DebugContext(ctx, "order drift is already fixed")
+		return nil
+	}
+	if err != nil {
+		w.metrics.IncFixes(kind, FixResultFailed)
+		log.ErrorContext(ctx, "fix order drift", "error", err.Error())
+		return err
+	}
+	w.metrics.IncFixes(kind, FixResultFixed)
+	log.InfoContext(ctx, "order drift is fixed")
+	return nil
+}
+
+// driftOf state - состояние доставки из GetOrderStates, пустое, если доставки не было
+func driftOf(orderStatus, state string) string {
+	switch {
+	case orderStatus == order.StatusCreated && state == "":
+		return model.DriftMissingDelivery
+	case orderStatus == order.StatusCancelled && state == model.DeliveryActive:
+		return model.DriftCancelledActive
+	case orderStatus == order.StatusCompleted && state == model.DeliveryActive:
+		return model.DriftCompletedActive
+	}
+	return ""
+}
@@ -0,0 +1,400 @@
+package order
+
+import (
+	"context"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/dto/kafka/order"
+	"service-order-avito/internal/domain/errors/service"
+	"service-order-avito/internal/domain/model"
+	"service-order-avito/internal/service/delivery"
+	mock_dep "service-order-avito/internal/service/dep/mocks"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+// stubDeliveryService states - состояния доставок как в GetOrderStates, исправления меняют их
+type stubDeliveryService struct {
+	states    map[string]string
+	assignErr error
+	calls     []string
+}
+
+func (s *stubDeliveryService) Assign(_ context.Context, req *dto.AssignDeliveryRequest) (*dto.AssignDeliveryResponse, error) {
+	s.calls = append(s.calls, "assign "+req.OrderId)
+	if s.assignErr != nil {
+		return nil, s.assignErr
+	}
+	s.states[req.OrderId] = model.DeliveryActive
+	return &dto.AssignDeliveryResponse{OrderId: req.OrderId}, nil
+}
+
+func (s *stubDeliveryService) Unassign(_ context.Context, req *dto.UnassignDeliveryRequest) (*dto.UnassignDeliveryResponse, error) {
+	s.calls = append(s.calls, "unassign "+req.OrderId)
+	s.states[req.OrderId] = model.StatusCancelled
+	return &dto.UnassignDeliveryResponse{OrderId: req.OrderId}, nil
+}
+
+func (s *stubDeliveryService) Complete(_ context.Context, req *dto.CompleteDeliveryRequest) (*dto.CompleteDeliveryResponse, error) {
+	s.calls = append(s.calls, "complete "+req.OrderId)
+	s.states[req.OrderId] = model.StatusCompleted
+	return &dto.CompleteDeliveryResponse{OrderId: req.OrderId}, nil
+}
+
+func (s *stubDeliveryService) GetOrderStates(_ context.Context, orderIds []string) (map[string]string, error) {
+	states := map[string]string{}
+	for _, id := range orderIds {
+		if state, ok := s.states[id]; ok {
+			states[id] = state
+		}
+	}
+	return states, nil
+}
+
+func (s *stubDeliveryService) GetActiveOrderIds(_ context.Context, after string, limit int) ([]string, error) {
+	var orderIds []string
+	for id, state := range s.states {
+		if state == model.DeliveryActive && id > after {
+			orderIds = append(orderIds, id)
+		}
+	}
+	slices.Sort(orderIds)
+	return orderIds[:min(len(orderIds), limit)], nil
+}
+
+type stubOrderGateway struct {
+	orders      []model.Order
+	statusCalls int
+}
+
+func (g *stubOrderGateway) GetOrdersAfter(_ context.Context, cursor model.ReconciliationCursor, limit int) ([]model.Order, error) {
+	var orders []model.Order
+	for _, o := range g.orders {
+		if !cursor.Passed(o) {
+			orders = append(orders, o)
+		}
+	}
+	slices.SortFunc(orders, func(a, b model.Order) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return orders[:min(len(orders), limit)], nil
+}
+
+func (g *stubOrderGateway) GetOrderStatusById(_ context.Context, id string) (string, error) {
+	g.statusCalls++
+	for _, o := range g.orders {
+		if o.Id == id {
+			return o.Status, nil
+		}
+	}
+	return "", service.ErrInternalError
+}
+
+type memoryCursorStore struct {
+	cursors map[string]model.ReconciliationCursor
+}
+
+func (m *memoryCursorStore) Get(_ context.Context, name string) (model.ReconciliationCursor, error) {
+	if cursor, ok := m.cursors[name]; ok {
+		return cursor, nil
+	}
+	return model.ReconciliationCursor{Name: name}, nil
+}
+
+func (m *memoryCursorStore) Save(_ context.Context, cursor model.ReconciliationCursor) error {
+	m.cursors[cursor.Name] = cursor
+	return nil
+}
+
+func (m *memoryCursorStore) orders() model.ReconciliationCursor {
+	return m.cursors[cursorName]
+}
+
+type stubReconciliationObserver struct {
+	drift map[string]int
+	fixes map[string]int
+}
+
+func (o *stubReconciliationObserver) SetDrift(kind string, n int) { o.drift[kind] = n }
+func (o *stubReconciliationObserver) IncFixes(kind, result string) {
+	o.fixes[kind+"/"+result]++
+}
+func (o *stubReconciliationObserver) SetCursor(time.Time) {}
+
+type reconciliationFixture struct {
+	delivery *stubDeliveryService
+	orders   *stubOrderGateway
+	cursors  *memoryCursorStore
+	metrics  *stubReconciliationObserver
+	worker   *reconciliationWorker
+}
+
+var testNow = time.Date(2025, 12, 26, 12, 0, 0, 0, time.UTC)
+
+func newReconciliationFixture(policy model.ReconciliationPolicy, states map[string]string, orders ...model.Order) *reconciliationFixture {
+	f := &reconciliationFixture{
+		delivery: &stubDeliveryService{states: states},
+		orders:   &stubOrderGateway{orders: orders},
+		cursors:  &memoryCursorStore{cursors: map[string]model.ReconciliationCursor{}},
+		metrics:  &stubReconciliationObserver{drift: map[string]int{}, fixes: map[string]int{}},
+	}
+	f.worker = NewReconciliationWorker(nopLogger{}, f.delivery, f.orders, f.cursors, f.metrics, policy)
+	f.worker.now = func() time.Time { return testNow }
+	return f
+}
+
+func testOrder(id, status string, age time.Duration) model.Order {
+	return model.Order{Id: id, Status: status, TotalPrice: 1000, CreatedAt: testNow.Add(-age)}
+}
+
+var testPolicy = model.ReconciliationPolicy{BatchSize: 100, ActiveBatchSize: 100, Grace: time.Minute, Lookback: 24 * time.Hour, MaxFixAttempts: 3}
+
+func TestReconciliationWorker_FixesDrift(t *testing.T) {
+	f := newReconciliationFixture(testPolicy,
+		map[string]string{
+			"CANCELLED":     model.DeliveryActive,
+			"COMPLETED":     model.DeliveryActive,
+			"IN_PROGRESS":   model.DeliveryActive,
+			"EXPIRED":       model.StatusExpired,
+			"OLD_CANCELLED": model.DeliveryActive,
+		},
+		testOrder("MISSING", order.StatusCreated, time.Hour),
+		testOrder("CANCELLED", order.StatusCancelled, 50*time.Minute),
+		testOrder("COMPLETED", order.StatusCompleted, 40*time.Minute),
+		testOrder("IN_PROGRESS", order.StatusCreated, 30*time.Minute),
+		testOrder("EXPIRED", order.StatusCreated, 20*time.Minute),
+		// событие о заказе еще может прийти из Kafka
+		testOrder("FRESH", order.StatusCreated, 10*time.Second),
+		// заказ старше Lookback, его доставку проверяет только второй проход
+		testOrder("OLD_CANCELLED", order.StatusCancelled, 48*time.Hour),
+	)
+
+	require.NoError(t, f.worker.Run(context.Background()))
+
+	require.Equal(t, []string{"assign MISSING", "unassign CANCELLED", "complete COMPLETED", "unassign OLD_CANCELLED"}, f.delivery.calls)
+	require.Equal(t, map[string]int{
+		model.DriftMissingDelivery: 1,
+		model.DriftCancelledActive: 2,
+		model.DriftCompletedActive: 1,
+	}, f.metrics.drift)
+	require.Equal(t, map[string]int{
+		"missing_delivery/fixed": 1,
+		"cancelled_active/fixed": 2,
+		"completed_active/fixed": 1,
+	}, f.metrics.fixes)
+	require.Equal(t, "EXPIRED", f.cursors.orders().OrderId)
+
+	// повторный проход ничего не находит, FRESH все еще моложе Grace
+	f.delivery.calls = nil
+	require.NoError(t, f.worker.Run(context.Background()))
+	require.Empty(t, f.delivery.calls)
+	require.Equal(t, 0, f.metrics.drift[model.DriftCancelledActive])
+}
+
+func TestReconciliationWorker_ReportOnly(t *testing.T) {
+	policy := testPolicy
+	policy.ReportOnly = true
+	f := newReconciliationFixture(policy,
+		map[string]string{"CANCELLED": model.DeliveryActive},
+		testOrder("MISSING", order.StatusCreated, time.Hour),
+		testOrder("CANCELLED", order.StatusCancelled, 30*time.Minute),
+	)
+
+	require.NoError(t, f.worker.Run(context.Background()))
+
+	require.Empty(t, f.delivery.calls)
+	require.Empty(t, f.metrics.fixes)
+	require.Equal(t, 1, f.metrics.drift[model.DriftMissingDelivery])
+	require.Equal(t, 1, f.metrics.drift[model.DriftCancelledActive])
+	require.Equal(t, "CANCELLED", f.cursors.orders().OrderId)
+}
+
+func TestReconciliationWorker_FixFailed(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "no available couriers", err: service.ErrNoAvailableCouriers},
+		{name: "internal error", err: service.ErrInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newReconciliationFixture(testPolicy, map[string]string{},
+				testOrder("DONE", order.StatusCancelled, 2*time.Hour),
+				testOrder("FIRST", order.StatusCreated, time.Hour),
+				testOrder("SECOND", order.StatusCreated, 30*time.Minute),
+			)
+			f.delivery.assignErr = tt.err
+
+			require.ErrorIs(t, f.worker.Run(context.Background()), tt.err)
+			require.Equal(t, []string{"assign FIRST"}, f.delivery.calls)
+			require.Equal(t, 1, f.metrics.fixes["missing_delivery/failed"])
+			// курсор остановился перед FIRST, назначение повторится
+			require.Equal(t, "DONE", f.cursors.orders().OrderId)
+
+			f.delivery.assignErr = nil
+			require.NoError(t, f.worker.Run(context.Background()))
+			require.Equal(t, []string{"assign FIRST", "assign FIRST", "assign SECOND"}, f.delivery.calls)
+			require.Equal(t, "SECOND", f.cursors.orders().OrderId)
+		})
+	}
+}
+
+// заказ, который не удается исправить, держит курсор MaxFixAttempts проходов, нехватка курьеров - сколько угодно
+func TestReconciliationWorker_SkipsBrokenOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCalls   []string
+		wantCursor  string
+		wantSkipped int
+	}{
+		{
+			name: "internal error",
+			err:  service.ErrInternalError,
+			// на третьей попытке FIRST пропускается, и в том же проходе начинаются попытки SECOND
+			wantCalls:   []string{"assign FIRST", "assign FIRST", "assign FIRST", "assign SECOND"},
+			wantCursor:  "FIRST",
+			wantSkipped: 1,
+		},
+		{
+			name:       "no available couriers",
+			err:        service.ErrNoAvailableCouriers,
+			wantCalls:  []string{"assign FIRST", "assign FIRST", "assign FIRST"},
+			wantCursor: "DONE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newReconciliationFixture(testPolicy, map[string]string{},
+				testOrder("DONE", order.StatusCancelled, 2*time.Hour),
+				testOrder("FIRST", order.StatusCreated, time.Hour),
+				testOrder("SECOND", order.StatusCreated, 30*time.Minute),
+			)
+			f.delivery.assignErr = tt.err
+
+			for range testPolicy.MaxFixAttempts {
+				require.ErrorIs(t, f.worker.Run(context.Background()), tt.err)
+			}
+			require.Equal(t, tt.wantCalls, f.delivery.calls)
+			require.Equal(t, tt.wantCursor, f.cursors.orders().OrderId)
+			require.Equal(t, tt.wantSkipped, f.metrics.fixes["missing_delivery/skipped"])
+			require.Equal(t, len(tt.wantCalls), f.metrics.fixes["missing_delivery/failed"])
+		})
+	}
+}
+
+func TestReconciliationWorker_ActiveDeliveriesInBatches(t *testing.T) {
+	policy := testPolicy
+	policy.ActiveBatchSize = 2
+	f := newReconciliationFixture(policy,
+		map[string]string{"A": model.DeliveryActive, "B": model.DeliveryActive, "C": model.DeliveryActive},
+		testOrder("A", order.StatusCreated, 48*time.Hour),
+		testOrder("B", order.StatusCreated, 48*time.Hour),
+		testOrder("C", order.StatusCancelled, 48*time.Hour),
+	)
+
+	// за проход не больше ActiveBatchSize запросов в order-service
+	require.NoError(t, f.worker.Run(context.Background()))
+	require.Equal(t, 2, f.orders.statusCalls)
+	require.Empty(t, f.delivery.calls)
+
+	require.NoError(t, f.worker.Run(context.Background()))
+	require.Equal(t, 3, f.orders.statusCalls)
+	require.Equal(t, []string{"unassign C"}, f.delivery.calls)
+
+	// обход начинается заново
+	require.NoError(t, f.worker.Run(context.Background()))
+	require.Equal(t, 5, f.orders.statusCalls)
+	require.Equal(t, "B", f.cursors.cursors[activeCursorName].OrderId)
+}
+
+func TestReconciliationWorker_Pages(t *testing.T) {
+	policy := testPolicy
+	policy.BatchSize = 2
+	// у заказов одно время создания, порядок внутри него задает id
+	createdAt := testNow.Add(-time.Hour)
+	f := newReconciliationFixture(policy, map[string]string{},
+		model.Order{Id: "C", Status: order.StatusCreated, CreatedAt: createdAt},
+		model.Order{Id: "A", Status: order.StatusCreated, CreatedAt: createdAt},
+		model.Order{Id: "B", Status: order.StatusCreated, CreatedAt: createdAt},
+	)
+
+	require.NoError(t, f.worker.Run(context.Background()))
+	require.Equal(t, []string{"assign A", "assign B"}, f.delivery.calls)
+	require.Equal(t, model.ReconciliationCursor{Name: cursorName, CreatedAt: createdAt, OrderId: "B"}, f.cursors.orders())
+
+	require.NoError(t, f.worker.Run(context.Background()))
+	require.Equal(t, []string{"assign A", "assign B", "assign C"}, f.delivery.calls)
+}
+
+type nopDeliveryMetrics struct{}
+
+func (nopDeliveryMetrics) IncDeliveriesCreated()             {}
+func (nopDeliveryMetrics) AddDeliveriesFinished(string, int) {}
+func (nopDeliveryMetrics) ObserveAssignment(time.Duration)   {}
+func (nopDeliveryMetrics) ObserveTimeToAssign(time.Duration) {}
+func (nopDeliveryMetrics) IncNoAvailableCouriers()           {}
+
+// выполненный дорогой заказ: кода передачи у сверки нет, доставка завершается как системная
+func TestReconciliationWorker_CompletesHandoffCodeOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTM := mock_dep.NewMockTransactionManager(ctrl)
+	mockCourierRepo := mock_dep.NewMockCourierRepository(ctrl)
+	mockDeliveryRepo := mock_dep.NewMockDeliveryRepository(ctrl)
+	ds := delivery.NewDeliveryService(mockTM, mockCourierRepo, mockDeliveryRepo, mock_dep.NewMockEventPublisher(ctrl), nopDeliveryMetrics{},
+		model.RatingPolicy{},
+		model.SLAPolicy{},
+		model.ProofPolicy{},
+		model.HandoffPolicy{PriceThreshold: 10000, MaxAttempts: 3, Lockout: time.Minute, Secret: []byte("secret")},
+	)
+
+	orders := &stubOrderGateway{orders: []model.Order{
+		{Id: "RICH", Status: order.StatusCompleted, TotalPrice: 50000, CreatedAt: testNow.Add(-48 * time.Hour)},
+	}}
+	metrics := &stubReconciliationObserver{drift: map[string]int{}, fixes: map[string]int{}}
+	w := NewReconciliationWorker(nopLogger{}, ds, orders, &memoryCursorStore{cursors: map[string]model.ReconciliationCursor{}}, metrics, testPolicy)
+	w.now = func() time.Time { return testNow }
+
+	mockTM.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+	mockDeliveryRepo.EXPECT().GetActiveOrderIds(gomock.Any(), "", testPolicy.ActiveBatchSize).Return([]string{"RICH"}, nil)
+	gomock.InOrder(
+		mockDeliveryRepo.EXPECT().GetByOrderId(gomock.Any(), "RICH").Return(model.Delivery{Id: 3, CourierId: 7, OrderId: "RICH", HandoffCodeHash: "hash"}, nil),
+		mockDeliveryRepo.EXPECT().AddEventManyById(gomock.Any(), model.StatusDelivered, gomock.Any(), 3).Return(nil),
+		mockDeliveryRepo.EXPECT().ArchiveManyById(gomock.Any(), model.StatusCompleted, 3).Return(nil),
+		mockDeliveryRepo.EXPECT().DeleteByOrderId(gomock.Any(), "RICH").Return(nil),
+		mockCourierRepo.EXPECT().Update(gomock.Any(), model.Courier{Id: 7, Status: model.StatusAvailable}).Return(nil),
+	)
+
+	require.NoError(t, w.Run(context.Background()))
+	require.Equal(t, map[string]int{"completed_active/fixed": 1}, metrics.fixes)
+}
@@ -4,37 +4,66 @@ import (
 	"context"
 	"github.com/IBM/sarama"
 	"service-order-avito/internal/adapters/logger"
+	"time"
 )
 
+// пауза перед повторным входом в группу после ошибки, чтобы не крутиться в цикле, пока брокер недоступен
+const consumeRetryDelay = time.Second
+
+type consumeErrorsCounter interface {
+	IncConsumeErrors()
+}
+
 type orderConsumerWorker struct {
 	l       logger.LoggerAdapter
 	client  sarama.ConsumerGroup
 	handler sarama.ConsumerGroupHandler
 	topic   string
+	metrics consumeErrorsCounter
 }
 
-func NewOrderConsumerWorker(l logger.LoggerAdapter, client sarama.ConsumerGroup, handler sarama.ConsumerGroupHandler, topic string) *orderConsumerWorker {
+func NewOrderConsumerWorker(l logger.LoggerAdapter, client sarama.ConsumerGroup, handler sarama.ConsumerGroupHandler, topic string, metrics consumeErrorsCounter) *orderConsumerWorker {
 	return &orderConsumerWorker{
 		l:       l,
 		client:  client,
 		handler: handler,
 		topic:   topic,
+		metrics: metrics,
 	}
 }
 
+// Start держит консьюмер в группе. Consume возвращается после каждой ребалансировки,
+// поэтому пока контекст жив, в группу заходим заново. После отмены контекста Consume дожидается
+// обработки текущих сообщений, затем консьюмер выходит из группы
 func (w *orderConsumerWorker) Start(ctx context.Context) {
 	for {
 		err := w.client.Consume(ctx, []string{w.topic}, w.handler)
-		if err != nil {
-			w.l.Error("consume error",
-				"error", err.Error(),
-			)
+		if ctx.Err() != nil {
+			w.stop()
+			return
+		}
+		if err == nil {
+			continue
 		}
 
+		w.metrics.IncConsumeErrors()
+		w.l.Error("consume error",
+			"error", err.Error(),
+		)
 		select {
 		case <-ctx.Done():
-			w.l.Info("delivery monitor worker gracefully stopped")
+			w.stop()
 			return
+		case <-time.After(consumeRetryDelay):
 		}
 	}
 }
+
+// stop закрывает консьюмер группы: оффсеты коммитятся, партиции сразу переходят к другим репликам,
+// а не после session timeout
+func (w *orderConsumerWorker) stop() {
+	if err := w.client.Close(); err != nil {
+		w.l.Error("close consumer group", "error", err.Error())
+	}
+	w.l.Info("kafka order consumer worker gracefully stopped")
+}
@@ -0,0 +1,90 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/adapters/logger"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+// fakeGroup Consume ведет себя как sarama: держит сессию, пока жив контекст
+type fakeGroup struct {
+	sarama.ConsumerGroup
+	consumes atomic.Int32
+	closed   atomic.Bool
+	err      error
+}
+
+func (g *fakeGroup) Consume(ctx context.Context, _ []string, _ sarama.ConsumerGroupHandler) error {
+	g.consumes.Add(1)
+	if g.err != nil {
+		return g.err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (g *fakeGroup) Close() error {
+	g.closed.Store(true)
+	return nil
+}
+
+type errorsCounter struct {
+	n atomic.Int32
+}
+
+func (c *errorsCounter) IncConsumeErrors() { c.n.Add(1) }
+
+func TestOrderConsumerWorker_StopClosesGroup(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+	}{
+		{name: "stop during session"},
+		{name: "stop during retry delay", err: errors.New("brokers are unavailable")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &fakeGroup{err: tt.err}
+			counter := &errorsCounter{}
+			w := NewOrderConsumerWorker(nopLogger{}, group, nil, "orders", counter)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				w.Start(ctx)
+			}()
+			require.Eventually(t, func() bool { return group.consumes.Load() > 0 }, time.Second, time.Millisecond)
+			cancel()
+			<-done
+
+			require.True(t, group.closed.Load(), "consumer leaves the group on stop")
+			if tt.err != nil {
+				require.Equal(t, int32(1), counter.n.Load())
+			}
+		})
+	}
+}
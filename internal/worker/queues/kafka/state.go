@@ -0,0 +1,155 @@
+package kafka
+
+import (
+	"service-order-avito/internal/domain/dto"
+	"sort"
+	"sync"
+	"time"
+)
+
+type claimKey struct {
+	topic     string
+	partition int32
+}
+
+type claimState struct {
+	initialOffset int64
+	lastOffset    int64
+	markedOffset  int64
+	highWaterMark int64
+	messages      int64
+	claimedAt     time.Time
+	lastMessageAt time.Time
+}
+
+// ConsumerState текущая сессия consumer group и ее партиции. Обновляется хендлером из Setup, ConsumeClaim
+// и Cleanup, читается ручкой /debug/kafka. ConsumeClaim вызывается в отдельной горутине на каждую партицию
+type ConsumerState struct {
+	mu           sync.RWMutex
+	active       bool
+	memberId     string
+	generationId int32
+	startedAt    time.Time
+	changedAt    time.Time // начало или конец последней сессии
+	rebalances   int
+	claims       map[claimKey]*claimState
+	now          func() time.Time
+}
+
+func NewConsumerState() *ConsumerState {
+	return &ConsumerState{claims: make(map[claimKey]*claimState), now: time.Now, changedAt: time.Now()}
+}
+
+// Session активна ли сессия и с какого момента она в этом состоянии. До первой сессии - время создания
+func (s *ConsumerState) Session() (active bool, since time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active, s.changedAt
+}
+
+func (s *ConsumerState) SessionStarted(memberId string, generationId int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active, s.memberId, s.generationId, s.startedAt = true, memberId, generationId, s.now()
+	s.changedAt = s.startedAt
+	s.rebalances++
+	s.claims = make(map[claimKey]*claimState)
+}
+
+func (s *ConsumerState) SessionEnded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active, s.changedAt = false, s.now()
+	s.claims = make(map[claimKey]*claimState)
+}
+
+func (s *ConsumerState) ClaimStarted(topic string, partition int32, initialOffset, highWaterMark int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims[claimKey{topic, partition}] = &claimState{
+		initialOffset: initialOffset,
+		lastOffset:    -1,
+		markedOffset:  -1,
+		highWaterMark: highWaterMark,
+		claimedAt:     s.now(),
+	}
+}
+
+func (s *ConsumerState) ClaimEnded(topic string, partition int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claims, claimKey{topic, partition})
+}
+
+func (s *ConsumerState) MessageConsumed(topic string, partition int32, offset, highWaterMark int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.claims[claimKey{topic, partition}]
+	if !ok {
+		return
+	}
+	c.lastOffset, c.highWaterMark, c.lastMessageAt = offset, highWaterMark, s.now()
+	c.messages++
+}
+
+// MessageMarked сообщение обработано, следующий коммит будет с offset+1
+func (s *ConsumerState) MessageMarked(topic string, partition int32, offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.claims[claimKey{topic, partition}]; ok {
+		c.markedOffset = offset + 1
+	}
+}
+
+func (s *ConsumerState) Snapshot() dto.KafkaConsumerResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := dto.KafkaConsumerResponse{
+		Active:     s.active,
+		Rebalances: s.rebalances,
+		Claims:     make([]dto.KafkaClaimResponse, 0, len(s.claims)),
+	}
+	if s.active {
+		startedAt := s.startedAt
+		res.MemberId, res.GenerationId, res.SessionStartedAt = s.memberId, s.generationId, &startedAt
+	}
+
+	for key, c := range s.claims {
+		claim := dto.KafkaClaimResponse{
+			Topic:         key.topic,
+			Partition:     key.partition,
+			InitialOffset: c.initialOffset,
+			LastOffset:    c.lastOffset,
+			MarkedOffset:  c.markedOffset,
+			HighWaterMark: c.highWaterMark,
+			Lag:           c.lag(),
+			Messages:      c.messages,
+			ClaimedAt:     c.claimedAt,
+		}
+		if !c.lastMessageAt.IsZero() {
+			lastMessageAt := c.lastMessageAt
+			claim.LastMessageAt = &lastMessageAt
+		}
+		res.Claims = append(res.Claims, claim)
+	}
+	sort.Slice(res.Claims, func(i, j int) bool {
+		if res.Claims[i].Topic != res.Claims[j].Topic {
+			return res.Claims[i].Topic < res.Claims[j].Topic
+		}
+		return res.Claims[i].Partition < res.Claims[j].Partition
+	})
+	return res
+}
+
+// lag сколько сообщений партиции еще не прочитано. High water mark - оффсет следующего записанного сообщения
+func (c *claimState) lag() int64 {
+	next := c.initialOffset
+	if c.lastOffset >= 0 {
+		next = c.lastOffset + 1
+	}
+	if next < 0 || c.highWaterMark <= next {
+		return 0
+	}
+	return c.highWaterMark - next
+}
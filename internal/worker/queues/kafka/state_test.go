@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerState(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewConsumerState()
+	s.now = func() time.Time { return now }
+
+	require.False(t, s.Snapshot().Active)
+
+	s.SessionStarted("member-1", 4)
+	s.ClaimStarted("orders", 1, 100, 110)
+	s.ClaimStarted("orders", 0, 50, 50)
+
+	snapshot := s.Snapshot()
+	require.True(t, snapshot.Active)
+	require.Equal(t, "member-1", snapshot.MemberId)
+	require.Equal(t, 1, snapshot.Rebalances)
+	require.Len(t, snapshot.Claims, 2)
+	require.Equal(t, int32(0), snapshot.Claims[0].Partition, "claims are sorted by partition")
+	require.Equal(t, int64(0), snapshot.Claims[0].Lag)
+	require.Equal(t, int64(10), snapshot.Claims[1].Lag, "before the first message lag is counted from the initial offset")
+	require.Equal(t, int64(-1), snapshot.Claims[1].LastOffset)
+
+	s.MessageConsumed("orders", 1, 100, 112)
+	s.MessageMarked("orders", 1, 100)
+	claim := s.Snapshot().Claims[1]
+	require.Equal(t, int64(100), claim.LastOffset)
+	require.Equal(t, int64(101), claim.MarkedOffset)
+	require.Equal(t, int64(11), claim.Lag)
+	require.Equal(t, int64(1), claim.Messages)
+	require.Equal(t, now, *claim.LastMessageAt)
+
+	s.ClaimEnded("orders", 0)
+	require.Len(t, s.Snapshot().Claims, 1)
+
+	now = now.Add(time.Hour)
+	s.SessionEnded()
+	active, since := s.Session()
+	require.False(t, active)
+	require.Equal(t, now, since)
+	s.MessageConsumed("orders", 1, 101, 112) // партиция уже отозвана, сообщение игнорируется
+	snapshot = s.Snapshot()
+	require.False(t, snapshot.Active)
+	require.Empty(t, snapshot.Claims)
+	require.Nil(t, snapshot.SessionStartedAt)
+	require.Equal(t, 1, snapshot.Rebalances)
+}
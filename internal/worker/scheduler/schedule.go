@@ -0,0 +1,166 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule возвращает время следующего запуска после t
+type Schedule interface {
+	Next(t time.Time) time.Time
+	String() string
+}
+
+type every time.Duration
+
+// Every запуск через равные промежутки от предыдущего запуска
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+func (e every) Next(t time.Time) time.Time { return t.Add(time.Duration(e)) }
+func (e every) String() string             { return "@every " + time.Duration(e).String() }
+
+// ParseSchedule принимает "@every <duration>", "@hourly", "@daily", "@weekly", "@monthly"
+// или cron из пяти полей: минута, час, день месяца, месяц, день недели (0 - воскресенье).
+// В полях поддерживаются *, списки через запятую, диапазоны a-b и шаг /n
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid schedule %q: positive duration expected", spec)
+		}
+		return Every(interval), nil
+	}
+
+	expr := spec
+	switch spec {
+	case "@hourly":
+		expr = "0 * * * *"
+	case "@daily":
+		expr = "0 0 * * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: 5 cron fields expected", spec)
+	}
+	c := &cron{spec: spec}
+	var err error
+	parsers := []struct {
+		field    *uint64
+		min, max int
+		star     *bool
+	}{
+		{&c.minute, 0, 59, nil},
+		{&c.hour, 0, 23, nil},
+		{&c.dom, 1, 31, &c.domStar},
+		{&c.month, 1, 12, nil},
+		{&c.dow, 0, 7, &c.dowStar},
+	}
+	for i, p := range parsers {
+		var star bool
+		if *p.field, star, err = parseField(fields[i], p.min, p.max); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		if p.star != nil {
+			*p.star = star
+		}
+	}
+	// 7 тоже воскресенье
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// cron поля хранятся битовыми масками: бит i установлен, если значение i подходит
+type cron struct {
+	spec                     string
+	minute, hour, dom, month uint64
+	dow                      uint64
+	domStar, dowStar         bool
+}
+
+func (c *cron) String() string { return c.spec }
+
+// Next перебирает время от t вперед, перескакивая целиком неподходящие месяцы, дни и часы.
+// Расписание вроде "0 0 30 2 *" не сработает никогда, поиск ограничен пятью годами
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			// Truncate(time.Hour) округляет абсолютное время и в зонах со смещением на полчаса попадает на :30
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches как в cron: если заданы и день месяца, и день недели, подходит любой из них
+func (c *cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func parseField(field string, min, max int) (bits uint64, star bool, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, false, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+			star = star || !hasStep
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, false, fmt.Errorf("invalid value in %q", part)
+			}
+			if hi, err = strconv.Atoi(b); err != nil {
+				return 0, false, fmt.Errorf("invalid value in %q", part)
+			}
+		default:
+			if lo, err = strconv.Atoi(rangePart); err != nil {
+				return 0, false, fmt.Errorf("invalid value in %q", part)
+			}
+			hi = lo
+			if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, false, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// понедельник
+	from := time.Date(2025, 12, 22, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 1m30s", from.Add(90 * time.Second)},
+		{"* * * * *", time.Date(2025, 12, 22, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 12, 22, 10, 30, 0, 0, time.UTC)},
+		{"5,40 9-11 * * *", time.Date(2025, 12, 22, 10, 40, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 12, 22, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 12, 23, 0, 0, 0, 0, time.UTC)},
+		{"30 3 * * 0", time.Date(2025, 12, 28, 3, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// день месяца и день недели заданы оба: подходит любой
+		{"0 0 25 * 3", time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, s.Next(from))
+			require.Equal(t, tt.spec, s.String())
+		})
+	}
+}
+
+// в зоне со смещением на полчаса переход к следующему часу должен идти по местному времени
+func TestParseSchedule_NextHalfHourOffset(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*60*60+30*60)
+
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"0 3 * * *", time.Date(2025, 12, 22, 1, 10, 0, 0, kolkata), time.Date(2025, 12, 22, 3, 0, 0, 0, kolkata)},
+		{"0 3 * * *", time.Date(2025, 12, 22, 3, 10, 0, 0, kolkata), time.Date(2025, 12, 23, 3, 0, 0, 0, kolkata)},
+		{"@hourly", time.Date(2025, 12, 22, 1, 10, 0, 0, kolkata), time.Date(2025, 12, 22, 2, 0, 0, 0, kolkata)},
+		{"15 */6 * * *", time.Date(2025, 12, 22, 1, 10, 0, 0, kolkata), time.Date(2025, 12, 22, 6, 15, 0, 0, kolkata)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			require.True(t, tt.want.Equal(s.Next(tt.from)), "got %s", s.Next(tt.from))
+		})
+	}
+}
+
+func TestParseSchedule_Never(t *testing.T) {
+	s, err := ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	require.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"@every",
+		"@every -1s",
+		"@yearly",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		_, err := ParseSchedule(spec)
+		require.Error(t, err, spec)
+	}
+}
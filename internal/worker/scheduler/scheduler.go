@@ -0,0 +1,356 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/dto"
+	"service-order-avito/internal/domain/errors/service"
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailed   = "failed"
+	OutcomeTimeout  = "timeout"
+	OutcomeCanceled = "canceled"
+
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	// overdueGrace запас на срабатывание таймера и пересчет следующего запуска
+	overdueGrace = time.Minute
+)
+
+var errMaxRuntime = errors.New("max runtime exceeded")
+
+type MetricsObserverJobs interface {
+	ObserveRun(job, outcome string, duration time.Duration)
+	SetRunning(job string, running bool)
+	SetLastSuccess(job string, at time.Time)
+	IncSkipped(job string)
+}
+
+// Job фоновая задача. Run должен завершаться по отмене ctx
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Jitter случайная задержка к каждому запуску по расписанию, чтобы задачи не стартовали одновременно
+	Jitter time.Duration
+	// MaxRuntime ограничивает одну попытку, 0 - без ограничения
+	MaxRuntime time.Duration
+	// Retries сколько раз повторить упавший запуск, пауза между попытками RetryDelay и удваивается
+	Retries    int
+	RetryDelay time.Duration
+}
+
+type jobState struct {
+	Job
+	trigger chan struct{}
+
+	// под Scheduler.mu
+	running      bool
+	runningSince time.Time
+	attemptSince time.Time
+	nextRun      time.Time
+	lastRun      *dto.JobRunResponse
+	lastSuccess  time.Time
+}
+
+// Scheduler запускает задачи по расписанию, у каждой задачи своя горутина с таймером.
+// Запуски одной задачи не пересекаются: если предыдущий еще идет, очередной пропускается.
+// Start блокируется до отмены ctx и дожидается идущих запусков
+type Scheduler struct {
+	instanceId string
+	log        logger.LoggerAdapter
+	metrics    MetricsObserverJobs
+	jobs       []*jobState
+	byName     map[string]*jobState
+
+	mu     sync.Mutex
+	active bool
+}
+
+func NewScheduler(instanceId string, log logger.LoggerAdapter, metrics MetricsObserverJobs) *Scheduler {
+	return &Scheduler{
+		instanceId: instanceId,
+		log:        log.With("component", "worker/scheduler"),
+		metrics:    metrics,
+		byName:     make(map[string]*jobState),
+	}
+}
+
+// Add регистрирует задачу, вызывается до Start
+func (s *Scheduler) Add(job Job) {
+	if _, ok := s.byName[job.Name]; ok {
+		panic("scheduler: duplicate job " + job.Name)
+	}
+	j := &jobState{Job: job, trigger: make(chan struct{}, 1)}
+	s.jobs = append(s.jobs, j)
+	s.byName[job.Name] = j
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	s.setActive(true)
+	var wg sync.WaitGroup
+	for _, j := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}()
+	}
+	s.log.Info("scheduler is started", "jobs", len(s.jobs))
+
+	wg.Wait()
+	s.setActive(false)
+	s.log.Info("scheduler gracefully stopped")
+}
+
+// Trigger запускает задачу вне расписания. Запуск идет асинхронно, результат виден в Status
+func (s *Scheduler) Trigger(name string) error {
+	j, ok := s.byName[name]
+	if !ok {
+		return service.ErrJobNotFound
+	}
+
+	s.mu.Lock()
+	active, running := s.active, j.running
+	s.mu.Unlock()
+	switch {
+	case !active:
+		return service.ErrSchedulerInactive
+	case running:
+		return service.ErrJobAlreadyRunning
+	}
+
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+		// ручной запуск уже ждет своей очереди
+	}
+	return nil
+}
+
+func (s *Scheduler) Status() dto.JobsResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := dto.JobsResponse{
+		InstanceId: s.instanceId,
+		Active:     s.active,
+		Jobs:       make([]dto.JobResponse, 0, len(s.jobs)),
+	}
+	for _, j := range s.jobs {
+		job := dto.JobResponse{
+			Name:     j.Name,
+			Schedule: j.Schedule.String(),
+			Running:  j.running,
+			NextRun:  timePtr(j.nextRun),
+		}
+		if j.running {
+			job.RunningSince = timePtr(j.runningSince)
+		}
+		if j.lastRun != nil {
+			lastRun := *j.lastRun
+			job.LastRun = &lastRun
+		}
+		job.LastSuccessAt = timePtr(j.lastSuccess)
+		res.Jobs = append(res.Jobs, job)
+	}
+	return res
+}
+
+// Check liveness проверка. Задача зависла, если попытка идет дольше двух MaxRuntime (не реагирует на отмену ctx)
+// или если время запуска по расписанию прошло больше overdueGrace назад, а цикл задачи так и не сработал.
+// Цикл пересчитывает nextRun сразу после срабатывания, даже когда запуск пропущен из-за предыдущего
+func (s *Scheduler) Check(context.Context) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	details := map[string]any{"active": s.active}
+	var errs []error
+	for _, j := range s.jobs {
+		if overdue := now.Sub(j.nextRun); s.active && !j.nextRun.IsZero() && overdue > overdueGrace {
+			errs = append(errs, fmt.Errorf("job %s is overdue by %s", j.Name, overdue.Round(time.Second)))
+		}
+		if !j.running || j.MaxRuntime <= 0 {
+			continue
+		}
+		if stuck := now.Sub(j.attemptSince); stuck > 2*j.MaxRuntime {
+			errs = append(errs, fmt.Errorf("job %s is stuck for %s", j.Name, stuck.Round(time.Second)))
+		}
+	}
+	return details, errors.Join(errs...)
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *jobState) {
+	var runs sync.WaitGroup
+	defer runs.Wait()
+
+	for {
+		// расписание, которое больше не сработает, ждет только ручного запуска
+		timer := time.NewTimer(0)
+		if next := s.next(j); !next.IsZero() {
+			timer.Reset(time.Until(next))
+		} else {
+			timer.Stop()
+		}
+
+		trigger := TriggerSchedule
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// ручной запуск, не успевший стартовать, не должен сработать при следующем лидерстве
+			select {
+			case <-j.trigger:
+			default:
+			}
+			s.setNextRun(j, time.Time{})
+			return
+		case <-timer.C:
+		case <-j.trigger:
+			timer.Stop()
+			trigger = TriggerManual
+		}
+
+		if !s.begin(j) {
+			s.metrics.IncSkipped(j.Name)
+			s.log.Warn("job is still running, run is skipped", "job", j.Name, "trigger", trigger)
+			continue
+		}
+		runs.Add(1)
+		go func() {
+			defer runs.Done()
+			s.execute(ctx, j, trigger)
+		}()
+	}
+}
+
+func (s *Scheduler) next(j *jobState) time.Time {
+	next := j.Schedule.Next(time.Now())
+	if !next.IsZero() && j.Jitter > 0 {
+		next = next.Add(rand.N(j.Jitter))
+	}
+	s.setNextRun(j, next)
+	return next
+}
+
+func (s *Scheduler) execute(ctx context.Context, j *jobState, trigger string) {
+	run := dto.JobRunResponse{Trigger: trigger, StartedAt: time.Now()}
+	s.metrics.SetRunning(j.Name, true)
+
+	var err error
+	for delay := j.RetryDelay; ; delay *= 2 {
+		run.Attempts++
+		if err = s.attempt(ctx, j); err == nil || run.Attempts > j.Retries || ctx.Err() != nil {
+			break
+		}
+		s.log.Warn("job failed, retrying", "job", j.Name, "attempt", run.Attempts, "error", err.Error())
+		if !sleep(ctx, delay) {
+			break
+		}
+	}
+
+	duration := time.Since(run.StartedAt)
+	run.DurationMs = duration.Milliseconds()
+	switch {
+	case err == nil:
+		run.Outcome = OutcomeSuccess
+	case ctx.Err() != nil:
+		run.Outcome = OutcomeCanceled
+	case errors.Is(err, errMaxRuntime):
+		run.Outcome = OutcomeTimeout
+	default:
+		run.Outcome = OutcomeFailed
+	}
+	if err != nil {
+		run.Error = err.Error()
+		s.log.Error("job failed", "job", j.Name, "outcome", run.Outcome, "attempts", run.Attempts, "error", run.Error)
+	}
+
+	s.mu.Lock()
+	j.running = false
+	j.lastRun = &run
+	if err == nil {
+		j.lastSuccess = run.StartedAt.Add(duration)
+	}
+	s.mu.Unlock()
+
+	s.metrics.SetRunning(j.Name, false)
+	s.metrics.ObserveRun(j.Name, run.Outcome, duration)
+	if err == nil {
+		s.metrics.SetLastSuccess(j.Name, run.StartedAt.Add(duration))
+	}
+}
+
+// attempt одна попытка с ограничением MaxRuntime, паника задачи превращается в ошибку
+func (s *Scheduler) attempt(ctx context.Context, j *jobState) (err error) {
+	s.mu.Lock()
+	j.attemptSince = time.Now()
+	s.mu.Unlock()
+
+	if j.MaxRuntime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, j.MaxRuntime, errMaxRuntime)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	err = j.Run(ctx)
+	if err != nil && errors.Is(context.Cause(ctx), errMaxRuntime) {
+		err = fmt.Errorf("%w: %w", errMaxRuntime, err)
+	}
+	return err
+}
+
+// begin отмечает запуск, false если предыдущий запуск задачи еще идет
+func (s *Scheduler) begin(j *jobState) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j.running {
+		return false
+	}
+	j.running = true
+	j.runningSince = time.Now()
+	return true
+}
+
+func (s *Scheduler) setActive(active bool) {
+	s.mu.Lock()
+	s.active = active
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) setNextRun(j *jobState, next time.Time) {
+	s.mu.Lock()
+	j.nextRun = next
+	s.mu.Unlock()
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,271 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"service-order-avito/internal/adapters/logger"
+	"service-order-avito/internal/domain/errors/service"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)                                {}
+func (nopLogger) Error(string, ...any)                               {}
+func (nopLogger) Warn(string, ...any)                                {}
+func (nopLogger) Debug(string, ...any)                               {}
+func (nopLogger) InfoContext(context.Context, string, ...any)        {}
+func (nopLogger) ErrorContext(context.Context, string, ...any)       {}
+func (nopLogger) WarnContext(context.Context, string, ...any)        {}
+func (nopLogger) DebugContext(context.Context, string, ...any)       {}
+func (l nopLogger) With(...any) logger.LoggerAdapter                 { return l }
+func (l nopLogger) WithContext(context.Context) logger.LoggerAdapter { return l }
+
+type stubJobsObserver struct {
+	mu       sync.Mutex
+	outcomes map[string][]string
+	skipped  map[string]int
+}
+
+func newStubJobsObserver() *stubJobsObserver {
+	return &stubJobsObserver{outcomes: map[string][]string{}, skipped: map[string]int{}}
+}
+
+func (o *stubJobsObserver) ObserveRun(job, outcome string, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.outcomes[job] = append(o.outcomes[job], outcome)
+}
+
+func (o *stubJobsObserver) SetRunning(string, bool)          {}
+func (o *stubJobsObserver) SetLastSuccess(string, time.Time) {}
+
+func (o *stubJobsObserver) IncSkipped(job string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.skipped[job]++
+}
+
+func (o *stubJobsObserver) get(job string) ([]string, int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.outcomes[job]...), o.skipped[job]
+}
+
+// start запускает планировщик, остановка и ожидание - в t.Cleanup
+func start(t *testing.T, s *Scheduler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	require.Eventually(t, func() bool { return s.Status().Active }, time.Second, time.Millisecond)
+}
+
+func lastRun(s *Scheduler, name string) func() bool {
+	return func() bool {
+		for _, j := range s.Status().Jobs {
+			if j.Name == name {
+				return j.LastRun != nil
+			}
+		}
+		return false
+	}
+}
+
+func TestScheduler_RunsBySchedule(t *testing.T) {
+	metrics := newStubJobsObserver()
+	s := NewScheduler("replica-1", nopLogger{}, metrics)
+	var runs atomic.Int32
+	s.Add(Job{Name: "tick", Schedule: Every(5 * time.Millisecond), Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	start(t, s)
+
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+
+	status := s.Status()
+	require.Equal(t, "replica-1", status.InstanceId)
+	require.Len(t, status.Jobs, 1)
+	require.Equal(t, "@every 5ms", status.Jobs[0].Schedule)
+	require.NotNil(t, status.Jobs[0].NextRun)
+	require.Equal(t, OutcomeSuccess, status.Jobs[0].LastRun.Outcome)
+	require.Equal(t, TriggerSchedule, status.Jobs[0].LastRun.Trigger)
+	require.NotNil(t, status.Jobs[0].LastSuccessAt)
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	s := NewScheduler("replica-1", nopLogger{}, newStubJobsObserver())
+	release := make(chan struct{})
+	s.Add(Job{Name: "manual", Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}})
+
+	require.ErrorIs(t, s.Trigger("manual"), service.ErrSchedulerInactive)
+	start(t, s)
+	require.ErrorIs(t, s.Trigger("unknown"), service.ErrJobNotFound)
+
+	require.NoError(t, s.Trigger("manual"))
+	require.Eventually(t, func() bool { return s.Status().Jobs[0].Running }, time.Second, time.Millisecond)
+	require.ErrorIs(t, s.Trigger("manual"), service.ErrJobAlreadyRunning)
+
+	close(release)
+	require.Eventually(t, lastRun(s, "manual"), time.Second, time.Millisecond)
+	run := s.Status().Jobs[0].LastRun
+	require.Equal(t, TriggerManual, run.Trigger)
+	require.Equal(t, OutcomeSuccess, run.Outcome)
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	metrics := newStubJobsObserver()
+	s := NewScheduler("replica-1", nopLogger{}, metrics)
+	release := make(chan struct{})
+	var runs atomic.Int32
+	s.Add(Job{Name: "slow", Schedule: Every(2 * time.Millisecond), Run: func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	}})
+	start(t, s)
+
+	require.Eventually(t, func() bool {
+		_, skipped := metrics.get("slow")
+		return skipped >= 3
+	}, time.Second, time.Millisecond)
+	require.EqualValues(t, 1, runs.Load())
+	close(release)
+}
+
+func TestScheduler_RetryAndOutcomes(t *testing.T) {
+	metrics := newStubJobsObserver()
+	s := NewScheduler("replica-1", nopLogger{}, metrics)
+
+	var flakyCalls atomic.Int32
+	s.Add(Job{Name: "flaky", Schedule: Every(time.Hour), Retries: 2, RetryDelay: time.Millisecond, Run: func(context.Context) error {
+		if flakyCalls.Add(1) < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	}})
+	s.Add(Job{Name: "broken", Schedule: Every(time.Hour), Retries: 1, Run: func(context.Context) error {
+		return errors.New("broken")
+	}})
+	s.Add(Job{Name: "slow", Schedule: Every(time.Hour), MaxRuntime: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	s.Add(Job{Name: "panics", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		panic("boom")
+	}})
+	start(t, s)
+
+	tests := []struct {
+		job      string
+		outcome  string
+		attempts int
+	}{
+		{"flaky", OutcomeSuccess, 3},
+		{"broken", OutcomeFailed, 2},
+		{"slow", OutcomeTimeout, 1},
+		{"panics", OutcomeFailed, 1},
+	}
+	for _, tt := range tests {
+		require.NoError(t, s.Trigger(tt.job))
+		require.Eventually(t, lastRun(s, tt.job), time.Second, time.Millisecond, tt.job)
+		for _, j := range s.Status().Jobs {
+			if j.Name == tt.job {
+				require.Equal(t, tt.outcome, j.LastRun.Outcome, tt.job)
+				require.Equal(t, tt.attempts, j.LastRun.Attempts, tt.job)
+			}
+		}
+		outcomes, _ := metrics.get(tt.job)
+		require.Equal(t, []string{tt.outcome}, outcomes, tt.job)
+	}
+}
+
+func TestScheduler_StopWaitsForRuns(t *testing.T) {
+	metrics := newStubJobsObserver()
+	s := NewScheduler("replica-1", nopLogger{}, metrics)
+	started := make(chan struct{})
+	s.Add(Job{Name: "long", Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return s.Status().Active }, time.Second, time.Millisecond)
+	require.NoError(t, s.Trigger("long"))
+	<-started
+
+	cancel()
+	<-done
+	outcomes, _ := metrics.get("long")
+	require.Equal(t, []string{OutcomeCanceled}, outcomes)
+	require.False(t, s.Status().Active)
+	require.Nil(t, s.Status().Jobs[0].NextRun)
+}
+
+func TestScheduler_CheckStuckJob(t *testing.T) {
+	s := NewScheduler("replica-1", nopLogger{}, newStubJobsObserver())
+	release := make(chan struct{})
+	s.Add(Job{Name: "stuck", Schedule: Every(time.Hour), MaxRuntime: time.Millisecond, Run: func(context.Context) error {
+		// отмену ctx не слушает
+		<-release
+		return nil
+	}})
+	start(t, s)
+
+	_, err := s.Check(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Trigger("stuck"))
+	require.Eventually(t, func() bool {
+		_, err := s.Check(context.Background())
+		return err != nil
+	}, time.Second, time.Millisecond)
+	close(release)
+}
+
+func TestScheduler_CheckOverdueJob(t *testing.T) {
+	s := NewScheduler("replica-1", nopLogger{}, newStubJobsObserver())
+	s.Add(Job{Name: "hourly", Schedule: Every(time.Hour), Run: func(context.Context) error { return nil }})
+	start(t, s)
+
+	require.Eventually(t, func() bool { return s.Status().Jobs[0].NextRun != nil }, time.Second, time.Millisecond)
+	_, err := s.Check(context.Background())
+	require.NoError(t, err)
+
+	// цикл задачи остановился и не пересчитал следующий запуск
+	s.setNextRun(s.byName["hourly"], time.Now().Add(-2*overdueGrace))
+	_, err = s.Check(context.Background())
+	require.ErrorContains(t, err, "job hourly is overdue")
+
+	// на неактивном планировщике (не лидер) задачи не запускаются
+	s.setActive(false)
+	_, err = s.Check(context.Background())
+	require.NoError(t, err)
+}
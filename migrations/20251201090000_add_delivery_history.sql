@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE delivery_history (
+                          id                  BIGSERIAL PRIMARY KEY,
+                          courier_id          BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+                          order_id            VARCHAR(255) NOT NULL,
+                          transport_type      TEXT NOT NULL,   -- on_foot | scooter | car (на момент завершения доставки)
+                          status              TEXT NOT NULL,   -- completed | cancelled | expired
+                          assigned_at         TIMESTAMP NOT NULL,
+                          deadline            TIMESTAMP NOT NULL,
+                          finished_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX delivery_history_courier_finished_idx ON delivery_history (courier_id, finished_at);
+CREATE INDEX delivery_history_finished_idx ON delivery_history (finished_at);
+CREATE INDEX delivery_courier_idx ON delivery (courier_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX delivery_courier_idx;
+DROP TABLE delivery_history;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE delivery_feedback (
+                          id                  BIGSERIAL PRIMARY KEY,
+                          order_id            VARCHAR(255) NOT NULL UNIQUE,  -- отзыв оставляется один раз на заказ
+                          courier_id          BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+                          rating              SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+                          tags                TEXT[] NOT NULL DEFAULT '{}',  -- late | rude | damaged
+                          comment             TEXT NOT NULL DEFAULT '',
+                          created_at          TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX delivery_feedback_courier_idx ON delivery_feedback (courier_id, created_at);
+CREATE INDEX delivery_history_order_idx ON delivery_history (order_id);
+
+-- храним сумму, а не среднее, чтобы при каждом отзыве не копить ошибку округления
+ALTER TABLE couriers
+    ADD COLUMN rating_sum INT NOT NULL DEFAULT 0,
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE couriers
+    DROP COLUMN rating_sum,
+    DROP COLUMN rating_count;
+
+DROP INDEX delivery_history_order_idx;
+DROP TABLE delivery_feedback;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE delivery
+    ADD COLUMN sla_state TEXT NOT NULL DEFAULT 'on_time',  -- on_time | at_risk | breached
+    ADD COLUMN at_risk_at TIMESTAMP,
+    ADD COLUMN breached_at TIMESTAMP;
+
+ALTER TABLE delivery_history
+    ADD COLUMN sla_state TEXT NOT NULL DEFAULT 'on_time';
+
+-- монитор регулярно ищет доставки, у которых еще не менялось состояние SLA
+CREATE INDEX delivery_sla_deadline_idx ON delivery (sla_state, deadline);
+
+-- для уже завершенных доставок состояние восстанавливаем по времени завершения
+UPDATE delivery_history SET sla_state = 'breached' WHERE finished_at > deadline;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX delivery_sla_deadline_idx;
+
+ALTER TABLE delivery_history
+    DROP COLUMN sla_state;
+
+ALTER TABLE delivery
+    DROP COLUMN sla_state,
+    DROP COLUMN at_risk_at,
+    DROP COLUMN breached_at;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- текущий этап доставки: assigned | accepted | arrived_at_restaurant | picked_up | en_route | arrived
+ALTER TABLE delivery
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'assigned';
+
+-- события живут дольше самой доставки (она удаляется после завершения), поэтому привязаны к заказу, а не к delivery.id
+CREATE TABLE delivery_events (
+                          id                  BIGSERIAL PRIMARY KEY,
+                          order_id            VARCHAR(255) NOT NULL,
+                          courier_id          BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+                          status              TEXT NOT NULL,
+                          occurred_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX delivery_events_order_idx ON delivery_events (order_id, occurred_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE delivery_events;
+
+ALTER TABLE delivery
+    DROP COLUMN status;
+-- +goose StatementEnd
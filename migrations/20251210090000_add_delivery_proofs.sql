@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- доставку с proof_required нельзя завершить, пока к ней не приложено подтверждение
+ALTER TABLE delivery
+    ADD COLUMN proof_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- сами файлы лежат в blob-хранилище, здесь только их ключи и метаданные.
+-- Подтверждение принадлежит доставке, а не заказу: после снятия и переназначения заказа
+-- новая доставка не должна получать подтверждение прежнего курьера. PIN хранится только как HMAC
+CREATE TABLE delivery_proofs (
+                          id                      BIGSERIAL PRIMARY KEY,
+                          delivery_id             BIGINT NOT NULL UNIQUE,
+                          order_id                VARCHAR(255) NOT NULL,
+                          courier_id              BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+                          photo_key               TEXT NOT NULL,
+                          photo_content_type      TEXT NOT NULL,
+                          photo_size              BIGINT NOT NULL,
+                          signature_key           TEXT,
+                          signature_content_type  TEXT,
+                          signature_size          BIGINT,
+                          pin_hash                TEXT NOT NULL,
+                          latitude                DOUBLE PRECISION NOT NULL,
+                          longitude               DOUBLE PRECISION NOT NULL,
+                          created_at              TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX delivery_proofs_order_idx ON delivery_proofs (order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE delivery_proofs;
+
+ALTER TABLE delivery
+    DROP COLUMN proof_required;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- код передачи заказа для дорогих заказов. Хранится только HMAC кода,
+-- после MaxAttempts неверных попыток ввод блокируется до handoff_locked_until
+ALTER TABLE delivery
+    ADD COLUMN handoff_code_hash TEXT,
+    ADD COLUMN handoff_failed_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN handoff_locked_until TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE delivery
+    DROP COLUMN handoff_code_hash,
+    DROP COLUMN handoff_failed_attempts,
+    DROP COLUMN handoff_locked_until;
+-- +goose StatementEnd
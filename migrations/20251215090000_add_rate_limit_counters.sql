@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- общие счетчики rate limiter для всех реплик: число запросов ключа в окне фиксированной длины.
+-- Реплики прибавляют свои запросы пачкой раз в sync interval, устаревшие окна удаляются по expires_at
+CREATE UNLOGGED TABLE rate_limit_counters (
+    key          TEXT NOT NULL,
+    window_start TIMESTAMP NOT NULL,
+    expires_at   TIMESTAMP NOT NULL,
+    count        INT NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX idx_rate_limit_counters_expires_at ON rate_limit_counters (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rate_limit_counters;
+-- +goose StatementEnd
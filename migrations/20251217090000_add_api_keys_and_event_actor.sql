@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ключи доступа к API. Сам ключ не хранится, только sha256 в hex
+CREATE TABLE api_keys (
+    key_hash   CHAR(64) PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    role       TEXT NOT NULL,
+    courier_id BIGINT REFERENCES couriers(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP
+);
+
+-- кто сменил этап доставки: "courier:7", "dispatcher:ops-bot". NULL - внутренние события (order-service, воркеры)
+ALTER TABLE delivery_events
+    ADD COLUMN actor TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE delivery_events
+    DROP COLUMN actor;
+
+DROP TABLE api_keys;
+-- +goose StatementEnd
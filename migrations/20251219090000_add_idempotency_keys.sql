@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ответы на запросы с заголовком Idempotency-Key. Ключ уникален в пределах клиента (scope).
+-- Пока status NULL, запрос выполняется: lock_token принадлежит выполняющему его запросу,
+-- после locked_until блокировка считается брошенной. Записи удаляются после expires_at
+CREATE TABLE idempotency_keys (
+    scope        TEXT NOT NULL,
+    key          TEXT NOT NULL,
+    fingerprint  CHAR(64) NOT NULL,
+    lock_token   TEXT NOT NULL,
+    status       INT,
+    content_type TEXT,
+    body         BYTEA,
+    locked_until TIMESTAMP NOT NULL,
+    expires_at   TIMESTAMP NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- аренда лидерства для синглтон воркеров. Лидер продлевает expires_at, пока жив,
+-- после expires_at аренду может забрать любая реплика. Время берется из базы, часы реплик не важны
+CREATE TABLE leader_leases (
+    name        TEXT PRIMARY KEY,
+    holder      TEXT NOT NULL,
+    acquired_at TIMESTAMP NOT NULL,
+    renewed_at  TIMESTAMP NOT NULL,
+    expires_at  TIMESTAMP NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE leader_leases;
+-- +goose StatementEnd
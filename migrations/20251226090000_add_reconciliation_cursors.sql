@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- позиция сверки заказов с order-service: последний проверенный заказ в порядке (created_at, order_id)
+CREATE TABLE reconciliation_cursors (
+    name       TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    order_id   TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE reconciliation_cursors;
+-- +goose StatementEnd
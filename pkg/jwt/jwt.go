@@ -0,0 +1,253 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed            = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrSignature            = errors.New("invalid token signature")
+	ErrExpired              = errors.New("token is expired")
+	ErrNotYetValid          = errors.New("token is not valid yet")
+	ErrInvalidIssuer        = errors.New("invalid token issuer")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+const minRSABits = 2048
+
+// Claims поля токена, которые нужны сервису. Role и CourierId - приватные claims
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt float64  `json:"exp"`
+	NotBefore float64  `json:"nbf"`
+	IssuedAt  float64  `json:"iat"`
+	Role      string   `json:"role"`
+	CourierId int      `json:"courier_id"`
+}
+
+// Audience в токене бывает и строкой, и массивом строк
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = Audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a Audience) contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// Config ключи и ожидаемые значения claims. Алгоритм без ключа не принимается:
+// HS256 работает только с HS256Secret, RS256 только с RSAKeys
+type Config struct {
+	HS256Secret []byte
+	RSAKeys     map[string]*rsa.PublicKey // kid -> ключ
+	Issuer      string                    // пусто - не проверять
+	Audience    string                    // пусто - не проверять
+	Leeway      time.Duration             // допуск расхождения часов для exp и nbf
+}
+
+type Verifier struct {
+	cfg Config
+	now func() time.Time
+}
+
+func NewVerifier(cfg Config) *Verifier {
+	return &Verifier{cfg: cfg, now: time.Now}
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify проверяет подпись и сроки токена. exp обязателен, бессрочные токены не принимаются
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformed
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Claims{}, ErrMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if err = v.verifySignature(h, parts[0]+"."+parts[1], signature); err != nil {
+		return Claims{}, err
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, ErrMalformed
+	}
+	return claims, v.validate(claims)
+}
+
+func (v *Verifier) verifySignature(h header, signed string, signature []byte) error {
+	switch h.Alg {
+	case AlgHS256:
+		if len(v.cfg.HS256Secret) == 0 {
+			return ErrUnsupportedAlgorithm
+		}
+		mac := hmac.New(sha256.New, v.cfg.HS256Secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrSignature
+		}
+		return nil
+	case AlgRS256:
+		if len(v.cfg.RSAKeys) == 0 {
+			return ErrUnsupportedAlgorithm
+		}
+		key := v.rsaKey(h.Kid)
+		if key == nil {
+			return ErrUnknownKey
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return ErrSignature
+		}
+		return nil
+	default:
+		// в том числе "none"
+		return ErrUnsupportedAlgorithm
+	}
+}
+
+// rsaKey без kid подходит только единственный ключ, перебирать ключи не будем
+func (v *Verifier) rsaKey(kid string) *rsa.PublicKey {
+	if key, ok := v.cfg.RSAKeys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(v.cfg.RSAKeys) == 1 {
+		for _, key := range v.cfg.RSAKeys {
+			return key
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) validate(c Claims) error {
+	now := v.now()
+	if c.ExpiresAt == 0 || now.After(unix(c.ExpiresAt).Add(v.cfg.Leeway)) {
+		return ErrExpired
+	}
+	if c.NotBefore != 0 && now.Add(v.cfg.Leeway).Before(unix(c.NotBefore)) {
+		return ErrNotYetValid
+	}
+	if v.cfg.Issuer != "" && c.Issuer != v.cfg.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.cfg.Audience != "" && !c.Audience.contains(v.cfg.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func unix(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// LoadJWKS читает публичные RSA ключи из локального JWKS файла. Ключи других типов
+// и ключи не для подписи пропускаются
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") || (k.Alg != "" && k.Alg != AlgRS256) {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: invalid modulus", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jwks key %q: invalid exponent", k.Kid)
+		}
+		if _, exists := keys[k.Kid]; exists {
+			return nil, fmt.Errorf("jwks key %q: duplicate kid", k.Kid)
+		}
+		key := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("jwks key %q: rsa key must be at least %d bits", k.Kid, minRSABits)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no RSA signing keys")
+	}
+	return keys, nil
+}
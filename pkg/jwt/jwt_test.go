@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Unix(1_700_000_000, 0)
+
+func encode(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret []byte, header, claims any) string {
+	signed := encode(t, header) + "." + encode(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims any) string {
+	signed := encode(t, header) + "." + encode(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":        "courier-7",
+		"iss":        "auth.example",
+		"aud":        []string{"service-courier", "other"},
+		"exp":        testNow.Add(time.Minute).Unix(),
+		"role":       "courier",
+		"courier_id": 7,
+	}
+}
+
+func newTestVerifier(cfg Config) *Verifier {
+	v := NewVerifier(cfg)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	v := newTestVerifier(Config{HS256Secret: secret, Issuer: "auth.example", Audience: "service-courier", Leeway: 5 * time.Second})
+	hs := map[string]string{"alg": AlgHS256, "typ": "JWT"}
+
+	claims, err := v.Verify(signHS256(t, secret, hs, validClaims()))
+	require.NoError(t, err)
+	require.Equal(t, "courier-7", claims.Subject)
+	require.Equal(t, "courier", claims.Role)
+	require.Equal(t, 7, claims.CourierId)
+
+	with := func(key string, value any) map[string]any {
+		c := validClaims()
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "wrong secret", token: signHS256(t, []byte("another secret"), hs, validClaims()), wantErr: ErrSignature},
+		{name: "expired", token: signHS256(t, secret, hs, with("exp", testNow.Add(-6*time.Second).Unix())), wantErr: ErrExpired},
+		{name: "expired within leeway", token: signHS256(t, secret, hs, with("exp", testNow.Add(-4*time.Second).Unix()))},
+		{name: "without exp", token: signHS256(t, secret, hs, with("exp", nil)), wantErr: ErrExpired},
+		{name: "not yet valid", token: signHS256(t, secret, hs, with("nbf", testNow.Add(time.Minute).Unix())), wantErr: ErrNotYetValid},
+		{name: "wrong issuer", token: signHS256(t, secret, hs, with("iss", "evil")), wantErr: ErrInvalidIssuer},
+		{name: "audience as string", token: signHS256(t, secret, hs, with("aud", "service-courier"))},
+		{name: "wrong audience", token: signHS256(t, secret, hs, with("aud", "other")), wantErr: ErrInvalidAudience},
+		{name: "alg none", token: encode(t, map[string]string{"alg": "none"}) + "." + encode(t, validClaims()) + ".", wantErr: ErrUnsupportedAlgorithm},
+		{name: "rs256 without keys", token: signHS256(t, secret, map[string]string{"alg": AlgRS256}, validClaims()), wantErr: ErrUnsupportedAlgorithm},
+		{name: "two parts", token: "a.b", wantErr: ErrMalformed},
+		{name: "garbage header", token: "!!.e30.", wantErr: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(tt.token)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksJSON, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ec"},
+		{
+			"kty": "RSA", "kid": "k1", "use": "sig", "alg": AlgRS256,
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+	}})
+	require.NoError(t, err)
+	keys, err := ParseJWKS(jwksJSON)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	v := newTestVerifier(Config{RSAKeys: keys})
+
+	_, err = v.Verify(signRS256(t, key, map[string]string{"alg": AlgRS256, "kid": "k1"}, validClaims()))
+	require.NoError(t, err)
+
+	// единственный ключ подходит и без kid
+	_, err = v.Verify(signRS256(t, key, map[string]string{"alg": AlgRS256}, validClaims()))
+	require.NoError(t, err)
+
+	_, err = v.Verify(signRS256(t, key, map[string]string{"alg": AlgRS256, "kid": "k2"}, validClaims()))
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	_, err = v.Verify(signRS256(t, other, map[string]string{"alg": AlgRS256, "kid": "k1"}, validClaims()))
+	require.ErrorIs(t, err, ErrSignature)
+
+	// подмена алгоритма: публичный ключ как HMAC секрет не принимается
+	_, err = v.Verify(signHS256(t, key.N.Bytes(), map[string]string{"alg": AlgHS256, "kid": "k1"}, validClaims()))
+	require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
+
+func TestParseJWKS_Errors(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	for name, data := range map[string]string{
+		"not json":  "{",
+		"no keys":   `{"keys":[]}`,
+		"small key": `{"keys":[{"kty":"RSA","kid":"k","n":"` + base64.RawURLEncoding.EncodeToString(small.N.Bytes()) + `","e":"AQAB"}]}`,
+		"bad n":     `{"keys":[{"kty":"RSA","kid":"k","n":"!!","e":"AQAB"}]}`,
+	} {
+		_, err = ParseJWKS([]byte(data))
+		require.Error(t, err, name)
+	}
+}
@@ -0,0 +1,177 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidNumber     = errors.New("invalid phone number")
+	ErrCountryNotAllowed = errors.New("phone country is not allowed")
+	ErrUnknownCountry    = errors.New("unknown country")
+)
+
+// countryRule правила нумерации страны. Правила упрощенные: проверяются код страны,
+// длина национального номера и допустимые первые цифры национального номера
+type countryRule struct {
+	code        string // ISO 3166-1 alpha-2
+	callingCode string // код страны без '+'
+	nationalLen int
+	firstDigits string // допустимые первые цифры национального номера
+	trunkPrefix string // префикс междугородней связи в национальном формате (8 у России, 0 у Армении)
+}
+
+// У России и Казахстана общий код +7, различаются они по первой цифре национального номера
+var rules = []countryRule{
+	{code: "RU", callingCode: "7", nationalLen: 10, firstDigits: "3489", trunkPrefix: "8"},
+	{code: "KZ", callingCode: "7", nationalLen: 10, firstDigits: "67", trunkPrefix: "8"},
+	{code: "UZ", callingCode: "998", nationalLen: 9, firstDigits: "3456789"},
+	{code: "AM", callingCode: "374", nationalLen: 8, firstDigits: "123456789", trunkPrefix: "0"},
+	{code: "GE", callingCode: "995", nationalLen: 9, firstDigits: "3457", trunkPrefix: "0"},
+}
+
+// Countries возвращает коды всех стран, которые умеет разбирать парсер
+func Countries() []string {
+	codes := make([]string, len(rules))
+	for i, r := range rules {
+		codes[i] = r.code
+	}
+	return codes
+}
+
+// Parser приводит номера к формату E.164 и пропускает только номера разрешенных стран
+type Parser struct {
+	defaultRule countryRule
+	allowed     map[string]bool
+}
+
+// NewParser создает парсер. defaultCountry используется для номеров, записанных без кода страны
+// (например, "8 (999) 123-45-67"). Если allowed пуст, разрешены все известные страны
+func NewParser(defaultCountry string, allowed ...string) (*Parser, error) {
+	p := &Parser{allowed: make(map[string]bool, len(allowed))}
+
+	def, ok := ruleByCode(defaultCountry)
+	if !ok {
+		return nil, ErrUnknownCountry
+	}
+	p.defaultRule = def
+
+	if len(allowed) == 0 {
+		allowed = Countries()
+	}
+	for _, code := range allowed {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if _, ok := ruleByCode(code); !ok {
+			return nil, ErrUnknownCountry
+		}
+		p.allowed[code] = true
+	}
+
+	return p, nil
+}
+
+// Normalize разбирает номер в одном из распространенных форматов (пробелы, дефисы, скобки, ведущая 8,
+// префикс 00) и возвращает его в формате E.164, например "+79991234567"
+func (p *Parser) Normalize(raw string) (string, error) {
+	digits, international, err := clean(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if !international {
+		if national, ok := p.defaultRule.fromNational(digits); ok {
+			return p.format(p.defaultRule, national)
+		}
+		// номер без '+', но с кодом страны: "79991234567"
+	}
+
+	// известная страна, но не из списка разрешенных - отдельная ошибка, чтобы клиент понимал, что не так
+	var notAllowed bool
+	for _, r := range rules {
+		national, ok := strings.CutPrefix(digits, r.callingCode)
+		if !ok || !r.matches(national) {
+			continue
+		}
+		if !p.allowed[r.code] {
+			notAllowed = true
+			continue
+		}
+		return "+" + r.callingCode + national, nil
+	}
+
+	if notAllowed {
+		return "", ErrCountryNotAllowed
+	}
+	return "", ErrInvalidNumber
+}
+
+func (p *Parser) format(r countryRule, national string) (string, error) {
+	// номер в национальном формате мог оказаться номером другой страны с тем же кодом (+7 у России и Казахстана)
+	for _, other := range rules {
+		if other.callingCode != r.callingCode || !other.matches(national) {
+			continue
+		}
+		if !p.allowed[other.code] {
+			return "", ErrCountryNotAllowed
+		}
+		return "+" + other.callingCode + national, nil
+	}
+	return "", ErrInvalidNumber
+}
+
+// fromNational пробует прочитать номер как национальный (с префиксом междугородней связи или без)
+func (r countryRule) fromNational(digits string) (string, bool) {
+	if r.trunkPrefix != "" && len(digits) == len(r.trunkPrefix)+r.nationalLen {
+		if national, ok := strings.CutPrefix(digits, r.trunkPrefix); ok {
+			return national, true
+		}
+	}
+	if len(digits) == r.nationalLen {
+		return digits, true
+	}
+	return "", false
+}
+
+func (r countryRule) matches(national string) bool {
+	return len(national) == r.nationalLen && strings.ContainsRune(r.firstDigits, rune(national[0]))
+}
+
+// clean убирает разделители и возвращает только цифры номера.
+// international == true, если номер был записан с '+' или международным префиксом 00
+func clean(raw string) (digits string, international bool, err error) {
+	raw = strings.TrimSpace(raw)
+
+	var b strings.Builder
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", false, ErrInvalidNumber
+		}
+	}
+
+	digits = b.String()
+	if !international {
+		if rest, ok := strings.CutPrefix(digits, "00"); ok {
+			digits, international = rest, true
+		}
+	}
+	if digits == "" {
+		return "", false, ErrInvalidNumber
+	}
+	return digits, international, nil
+}
+
+func ruleByCode(code string) (countryRule, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, r := range rules {
+		if r.code == code {
+			return r, true
+		}
+	}
+	return countryRule{}, false
+}
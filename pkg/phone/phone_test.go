@@ -0,0 +1,78 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_Normalize(t *testing.T) {
+	p, err := NewParser("RU")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr error
+	}{
+		{name: "russia e164", raw: "+79991234567", want: "+79991234567"},
+		{name: "russia leading 8 with formatting", raw: "8 (999) 123-45-67", want: "+79991234567"},
+		{name: "russia without plus", raw: "79991234567", want: "+79991234567"},
+		{name: "russia national without trunk", raw: "999.123.45.67", want: "+79991234567"},
+		{name: "russia 00 prefix", raw: "00 7 999 123 45 67", want: "+79991234567"},
+		{name: "kazakhstan", raw: "+7 701 123 45 67", want: "+77011234567"},
+		{name: "kazakhstan leading 8", raw: "8-701-123-45-67", want: "+77011234567"},
+		{name: "uzbekistan", raw: "+998 90 123-45-67", want: "+998901234567"},
+		{name: "armenia", raw: "+374 (10) 12-34-56", want: "+37410123456"},
+		{name: "georgia", raw: "+995 555 12 34 56", want: "+995555123456"},
+		{name: "too short", raw: "+7999123456", wantErr: ErrInvalidNumber},
+		{name: "too long", raw: "+799912345678", wantErr: ErrInvalidNumber},
+		{name: "letters", raw: "+7999abc4567", wantErr: ErrInvalidNumber},
+		{name: "plus in the middle", raw: "7+9991234567", wantErr: ErrInvalidNumber},
+		{name: "empty", raw: "  ", wantErr: ErrInvalidNumber},
+		{name: "unknown country", raw: "+12345678901", wantErr: ErrInvalidNumber},
+		{name: "russia bad first digit", raw: "+71991234567", wantErr: ErrInvalidNumber},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Normalize(tt.raw)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParser_Normalize_AllowedCountries(t *testing.T) {
+	p, err := NewParser("RU", "RU", "AM")
+	require.NoError(t, err)
+
+	got, err := p.Normalize("+37410123456")
+	require.NoError(t, err)
+	require.Equal(t, "+37410123456", got)
+
+	_, err = p.Normalize("+77011234567")
+	require.ErrorIs(t, err, ErrCountryNotAllowed)
+
+	_, err = p.Normalize("8 701 123 45 67")
+	require.ErrorIs(t, err, ErrCountryNotAllowed)
+
+	_, err = p.Normalize("+995555123456")
+	require.ErrorIs(t, err, ErrCountryNotAllowed)
+}
+
+func TestNewParser_UnknownCountry(t *testing.T) {
+	_, err := NewParser("US")
+	require.ErrorIs(t, err, ErrUnknownCountry)
+
+	_, err = NewParser("RU", "RU", "XX")
+	require.ErrorIs(t, err, ErrUnknownCountry)
+}